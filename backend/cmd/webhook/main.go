@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// Posts a signed batch of signals to a running API, the way an external alert source would.
+//
+//	go run ./cmd/webhook -user <user-id> -secret <secret> -ticker AAPL -signal Buy
+//	go run ./cmd/webhook -user <user-id> -secret <secret> -file signals.json
+func main() {
+	baseURL := flag.String("url", "http://localhost:8080/api/v1", "API base URL")
+	userID := flag.String("user", "", "ID of the user the webhook belongs to")
+	secret := flag.String("secret", os.Getenv("WEBHOOK_SECRET"), "webhook secret (defaults to $WEBHOOK_SECRET)")
+	file := flag.String("file", "", "JSON file with a {\"signals\": [...]} batch; overrides the single-signal flags")
+	ticker := flag.String("ticker", "AAPL", "ticker of a single signal")
+	signal := flag.String("signal", "Buy", "signal type of a single signal")
+	date := flag.String("date", time.Now().Format("2006-01-02"), "date of a single signal")
+	source := flag.String("source", "webhook-harness", "source of a single signal")
+	flag.Parse()
+
+	if *userID == "" || *secret == "" {
+		log.Fatal("both -user and -secret are required")
+	}
+
+	var body []byte
+	var err error
+	if *file != "" {
+		body, err = os.ReadFile(*file)
+		if err != nil {
+			log.Fatalf("Failed to read payload file: %v", err)
+		}
+	} else {
+		body, err = json.Marshal(models.SignalWebhookRequest{
+			Signals: []models.SignalWebhookItem{{
+				Ticker: *ticker,
+				Signal: models.SignalType(*signal),
+				Date:   *date,
+				Source: *source,
+			}},
+		})
+		if err != nil {
+			log.Fatalf("Failed to encode payload: %v", err)
+		}
+	}
+
+	timestamp := time.Now().Unix()
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/webhooks/signals/%s", *baseURL, *userID), bytes.NewReader(body))
+	if err != nil {
+		log.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(services.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(services.WebhookSignatureHeader, services.SignWebhookPayload(*secret, timestamp, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatalf("Failed to post webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Fatalf("Failed to read response: %v", err)
+	}
+
+	log.Printf("%s", resp.Status)
+	fmt.Println(string(respBody))
+	if resp.StatusCode >= 300 {
+		os.Exit(1)
+	}
+}
@@ -76,7 +76,7 @@ func (suite *DatabaseIntegrationTestSuite) TearDownSuite() {
 func (suite *DatabaseIntegrationTestSuite) SetupTest() {
 	// Clean up tables before each test
 	tables := []string{
-		"nav_history", "positions", "portfolios", "signals", 
+		"nav_history", "transactions", "positions", "portfolios", "signals", 
 		"strategy_stocks", "strategies", "stocks", "users",
 	}
 	
@@ -259,7 +259,7 @@ func (suite *DatabaseIntegrationTestSuite) TestPortfolioWithPositions() {
 	// Test position details query
 	var positions []struct {
 		Ticker          string  `db:"ticker"`
-		Quantity        int     `db:"quantity"`
+		Quantity        float64 `db:"quantity"`
 		EntryPrice      float64 `db:"entry_price"`
 		AllocationValue float64 `db:"allocation_value"`
 	}
@@ -274,7 +274,7 @@ func (suite *DatabaseIntegrationTestSuite) TestPortfolioWithPositions() {
 	
 	suite.Len(positions, 2)
 	suite.Equal("AAPL", positions[0].Ticker)
-	suite.Equal(33, positions[0].Quantity)
+	suite.Equal(33.0, positions[0].Quantity)
 	suite.Equal(150.50, positions[0].EntryPrice)
 }
 
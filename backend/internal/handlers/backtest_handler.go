@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// BacktestHandler handles HTTP requests for strategy backtests
+type BacktestHandler struct {
+	backtestService services.BacktestService
+}
+
+// NewBacktestHandler creates a new backtest handler
+func NewBacktestHandler(backtestService services.BacktestService) *BacktestHandler {
+	return &BacktestHandler{
+		backtestService: backtestService,
+	}
+}
+
+// CreateBacktest handles POST /backtests. The backtest runs in the background; poll
+// GET /backtests/:id for its status and result.
+func (h *BacktestHandler) CreateBacktest(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	var req models.BacktestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	backtest, err := h.backtestService.StartBacktest(c.Context(), &req, userID)
+	if err != nil {
+		return backtestError(c, err, "Failed to start backtest")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(backtest)
+}
+
+// GetBacktests handles GET /backtests
+func (h *BacktestHandler) GetBacktests(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	backtests, err := h.backtestService.GetUserBacktests(c.Context(), userID)
+	if err != nil {
+		return backtestError(c, err, "Failed to get backtests")
+	}
+
+	if backtests == nil {
+		backtests = []*models.Backtest{}
+	}
+
+	return c.JSON(fiber.Map{
+		"backtests": backtests,
+		"count":     len(backtests),
+	})
+}
+
+// GetBacktest handles GET /backtests/:id
+func (h *BacktestHandler) GetBacktest(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	backtestID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid backtest ID",
+		})
+	}
+
+	backtest, err := h.backtestService.GetBacktest(c.Context(), backtestID, userID)
+	if err != nil {
+		return backtestError(c, err, "Failed to get backtest")
+	}
+
+	return c.JSON(backtest)
+}
+
+// backtestError maps backtest service errors to HTTP responses
+func backtestError(c *fiber.Ctx, err error, message string) error {
+	var notFound *models.NotFoundError
+	if errors.As(err, &notFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Backtest or strategy not found",
+		})
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": validationErr.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"portfolio-app/internal/models"
+)
+
+// MockBacktestService is a mock implementation of BacktestService
+type MockBacktestService struct {
+	mock.Mock
+}
+
+func (m *MockBacktestService) StartBacktest(ctx context.Context, req *models.BacktestRequest, userID uuid.UUID) (*models.Backtest, error) {
+	args := m.Called(ctx, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Backtest), args.Error(1)
+}
+
+func (m *MockBacktestService) GetBacktest(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Backtest, error) {
+	args := m.Called(ctx, id, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Backtest), args.Error(1)
+}
+
+func (m *MockBacktestService) GetUserBacktests(ctx context.Context, userID uuid.UUID) ([]*models.Backtest, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Backtest), args.Error(1)
+}
+
+func (m *MockBacktestService) RunBacktest(ctx context.Context, req *models.BacktestRequest) (*models.BacktestResult, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BacktestResult), args.Error(1)
+}
+
+func TestBacktestHandler_CreateBacktest(t *testing.T) {
+	post := func(mockService *MockBacktestService, reqBody map[string]interface{}) int {
+		handler := NewBacktestHandler(mockService)
+		app := setupStrategyTestApp()
+		app.Post("/backtests", handler.CreateBacktest)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/backtests", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	validBody := func() map[string]interface{} {
+		return map[string]interface{}{
+			"strategy_ids":        []string{uuid.New().String()},
+			"start_date":          "2023-01-01",
+			"end_date":            "2024-01-01",
+			"initial_investment":  "10000",
+			"rebalance_frequency": "monthly",
+			"constraints": map[string]interface{}{
+				"max_allocation_per_stock": "25",
+			},
+		}
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		mockService := new(MockBacktestService)
+		mockService.On("StartBacktest", mock.Anything, mock.AnythingOfType("*models.BacktestRequest"), mock.AnythingOfType("uuid.UUID")).
+			Return(&models.Backtest{ID: uuid.New(), Status: models.BacktestPending}, nil)
+
+		status := post(mockService, validBody())
+
+		assert.Equal(t, fiber.StatusAccepted, status)
+		mockService.AssertExpectations(t)
+		req := mockService.Calls[0].Arguments.Get(1).(*models.BacktestRequest)
+		assert.True(t, req.InitialInvestment.Equal(decimal.NewFromInt(10000)))
+	})
+
+	t.Run("unknown rebalance frequency", func(t *testing.T) {
+		mockService := new(MockBacktestService)
+		body := validBody()
+		body["rebalance_frequency"] = "weekly"
+
+		status := post(mockService, body)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		mockService.AssertNotCalled(t, "StartBacktest", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("date range too long", func(t *testing.T) {
+		mockService := new(MockBacktestService)
+		mockService.On("StartBacktest", mock.Anything, mock.AnythingOfType("*models.BacktestRequest"), mock.AnythingOfType("uuid.UUID")).
+			Return(nil, &models.ValidationError{Field: "end_date", Message: "a backtest can cover at most 10 years"})
+
+		status := post(mockService, validBody())
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+}
+
+func TestBacktestHandler_GetBacktest(t *testing.T) {
+	backtestID := uuid.New()
+	mockService := new(MockBacktestService)
+	handler := NewBacktestHandler(mockService)
+	app := setupStrategyTestApp()
+	app.Get("/backtests/:id", handler.GetBacktest)
+
+	mockService.On("GetBacktest", mock.Anything, backtestID, mock.AnythingOfType("uuid.UUID")).
+		Return(nil, &models.NotFoundError{Resource: "backtest"})
+
+	req := httptest.NewRequest("GET", "/backtests/"+backtestID.String(), nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	req = httptest.NewRequest("GET", "/backtests/not-a-uuid", nil)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// CorporateActionHandler handles HTTP requests for stock splits and their application to portfolios
+type CorporateActionHandler struct {
+	corporateActionService services.CorporateActionService
+}
+
+// NewCorporateActionHandler creates a new corporate action handler
+func NewCorporateActionHandler(corporateActionService services.CorporateActionService) *CorporateActionHandler {
+	return &CorporateActionHandler{
+		corporateActionService: corporateActionService,
+	}
+}
+
+// CreateCorporateAction handles POST /corporate-actions
+func (h *CorporateActionHandler) CreateCorporateAction(c *fiber.Ctx) error {
+	var req models.CreateCorporateActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	action, err := h.corporateActionService.CreateCorporateAction(c.Context(), &req)
+	if err != nil {
+		return corporateActionError(c, err, "Failed to create corporate action")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(action)
+}
+
+// ImportCorporateActions handles POST /corporate-actions/import, recording the splits reported by
+// the market data provider
+func (h *CorporateActionHandler) ImportCorporateActions(c *fiber.Ctx) error {
+	var req models.ImportCorporateActionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	actions, err := h.corporateActionService.ImportCorporateActions(c.Context(), &req)
+	if err != nil {
+		return corporateActionError(c, err, "Failed to import corporate actions")
+	}
+
+	return c.JSON(fiber.Map{
+		"corporate_actions": actions,
+		"count":             len(actions),
+	})
+}
+
+// GetCorporateActions handles GET /corporate-actions?stock_id=...
+func (h *CorporateActionHandler) GetCorporateActions(c *fiber.Ctx) error {
+	var stockID *uuid.UUID
+	if stockIDStr := c.Query("stock_id"); stockIDStr != "" {
+		parsed, err := uuid.Parse(stockIDStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock ID",
+			})
+		}
+		stockID = &parsed
+	}
+
+	actions, err := h.corporateActionService.GetCorporateActions(c.Context(), stockID)
+	if err != nil {
+		return corporateActionError(c, err, "Failed to get corporate actions")
+	}
+
+	if actions == nil {
+		actions = []*models.CorporateAction{}
+	}
+
+	return c.JSON(fiber.Map{
+		"corporate_actions": actions,
+		"count":             len(actions),
+	})
+}
+
+// ApplyCorporateAction handles POST /corporate-actions/:id/apply
+func (h *CorporateActionHandler) ApplyCorporateAction(c *fiber.Ctx) error {
+	actionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid corporate action ID",
+		})
+	}
+
+	result, err := h.corporateActionService.ApplyCorporateAction(c.Context(), actionID)
+	if err != nil {
+		return corporateActionError(c, err, "Failed to apply corporate action")
+	}
+
+	return c.JSON(result)
+}
+
+// GetAdjustments handles GET /corporate-actions/:id/adjustments
+func (h *CorporateActionHandler) GetAdjustments(c *fiber.Ctx) error {
+	actionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid corporate action ID",
+		})
+	}
+
+	adjustments, err := h.corporateActionService.GetAdjustments(c.Context(), actionID)
+	if err != nil {
+		return corporateActionError(c, err, "Failed to get corporate action adjustments")
+	}
+
+	if adjustments == nil {
+		adjustments = []*models.CorporateActionAdjustment{}
+	}
+
+	return c.JSON(fiber.Map{
+		"adjustments": adjustments,
+		"count":       len(adjustments),
+	})
+}
+
+// corporateActionError maps corporate action service errors to HTTP responses
+func corporateActionError(c *fiber.Ctx, err error, message string) error {
+	var notFound *models.NotFoundError
+	if errors.As(err, &notFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Corporate action or stock not found",
+		})
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": validationErr.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
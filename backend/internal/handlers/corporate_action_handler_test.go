@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"portfolio-app/internal/models"
+)
+
+// MockCorporateActionService is a mock implementation of CorporateActionService
+type MockCorporateActionService struct {
+	mock.Mock
+}
+
+func (m *MockCorporateActionService) CreateCorporateAction(ctx context.Context, req *models.CreateCorporateActionRequest) (*models.CorporateAction, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CorporateAction), args.Error(1)
+}
+
+func (m *MockCorporateActionService) ImportCorporateActions(ctx context.Context, req *models.ImportCorporateActionsRequest) ([]*models.CorporateAction, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CorporateAction), args.Error(1)
+}
+
+func (m *MockCorporateActionService) GetCorporateActions(ctx context.Context, stockID *uuid.UUID) ([]*models.CorporateAction, error) {
+	args := m.Called(ctx, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CorporateAction), args.Error(1)
+}
+
+func (m *MockCorporateActionService) ApplyCorporateAction(ctx context.Context, id uuid.UUID) (*models.CorporateActionResult, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CorporateActionResult), args.Error(1)
+}
+
+func (m *MockCorporateActionService) GetAdjustments(ctx context.Context, id uuid.UUID) ([]*models.CorporateActionAdjustment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CorporateActionAdjustment), args.Error(1)
+}
+
+func TestCorporateActionHandler_CreateCorporateAction(t *testing.T) {
+	post := func(mockService *MockCorporateActionService, reqBody map[string]interface{}) int {
+		handler := NewCorporateActionHandler(mockService)
+		app := setupStrategyTestApp()
+		app.Post("/corporate-actions", handler.CreateCorporateAction)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/corporate-actions", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	validBody := func() map[string]interface{} {
+		return map[string]interface{}{
+			"stock_id":       uuid.New().String(),
+			"new_shares":     "4",
+			"old_shares":     "1",
+			"effective_date": "2020-08-31",
+		}
+	}
+
+	t.Run("created", func(t *testing.T) {
+		mockService := new(MockCorporateActionService)
+		mockService.On("CreateCorporateAction", mock.Anything, mock.AnythingOfType("*models.CreateCorporateActionRequest")).
+			Return(&models.CorporateAction{ID: uuid.New(), Status: models.CorporateActionPending}, nil)
+
+		status := post(mockService, validBody())
+
+		assert.Equal(t, fiber.StatusCreated, status)
+		req := mockService.Calls[0].Arguments.Get(1).(*models.CreateCorporateActionRequest)
+		assert.True(t, req.NewShares.Equal(decimal.NewFromInt(4)))
+	})
+
+	t.Run("malformed effective date", func(t *testing.T) {
+		mockService := new(MockCorporateActionService)
+		body := validBody()
+		body["effective_date"] = "31/08/2020"
+
+		status := post(mockService, body)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		mockService.AssertNotCalled(t, "CreateCorporateAction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already recorded", func(t *testing.T) {
+		mockService := new(MockCorporateActionService)
+		mockService.On("CreateCorporateAction", mock.Anything, mock.AnythingOfType("*models.CreateCorporateActionRequest")).
+			Return(nil, &models.ValidationError{Field: "effective_date", Tag: "unique", Message: "already recorded"})
+
+		status := post(mockService, validBody())
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+}
+
+func TestCorporateActionHandler_ApplyCorporateAction(t *testing.T) {
+	actionID := uuid.New()
+	mockService := new(MockCorporateActionService)
+	handler := NewCorporateActionHandler(mockService)
+	app := setupStrategyTestApp()
+	app.Post("/corporate-actions/:id/apply", handler.ApplyCorporateAction)
+
+	mockService.On("ApplyCorporateAction", mock.Anything, actionID).
+		Return(nil, &models.NotFoundError{Resource: "corporate action"})
+
+	req := httptest.NewRequest("POST", "/corporate-actions/"+actionID.String()+"/apply", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+	req = httptest.NewRequest("POST", "/corporate-actions/not-a-uuid/apply", nil)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
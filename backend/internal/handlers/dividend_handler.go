@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// DividendHandler handles HTTP requests for dividends and their payment to portfolios
+type DividendHandler struct {
+	dividendService services.DividendService
+}
+
+// NewDividendHandler creates a new dividend handler
+func NewDividendHandler(dividendService services.DividendService) *DividendHandler {
+	return &DividendHandler{
+		dividendService: dividendService,
+	}
+}
+
+// CreateDividendEvent handles POST /dividends
+func (h *DividendHandler) CreateDividendEvent(c *fiber.Ctx) error {
+	var req models.CreateDividendEventRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	event, err := h.dividendService.CreateDividendEvent(c.Context(), &req)
+	if err != nil {
+		return dividendError(c, err, "Failed to create dividend event")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(event)
+}
+
+// GetDividendEvents handles GET /dividends?stock_id=...
+func (h *DividendHandler) GetDividendEvents(c *fiber.Ctx) error {
+	var stockID *uuid.UUID
+	if stockIDStr := c.Query("stock_id"); stockIDStr != "" {
+		parsed, err := uuid.Parse(stockIDStr)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock ID",
+			})
+		}
+		stockID = &parsed
+	}
+
+	events, err := h.dividendService.GetDividendEvents(c.Context(), stockID)
+	if err != nil {
+		return dividendError(c, err, "Failed to get dividend events")
+	}
+
+	if events == nil {
+		events = []*models.DividendEvent{}
+	}
+
+	return c.JSON(fiber.Map{
+		"dividends": events,
+		"count":     len(events),
+	})
+}
+
+// ProcessDueDividends handles POST /dividends/process, paying every dividend whose pay date has passed
+func (h *DividendHandler) ProcessDueDividends(c *fiber.Ctx) error {
+	results, err := h.dividendService.ProcessDueDividends(c.Context())
+	if err != nil {
+		return dividendError(c, err, "Failed to process dividends")
+	}
+
+	return c.JSON(fiber.Map{
+		"results": results,
+		"count":   len(results),
+	})
+}
+
+// ProcessDividendEvent handles POST /dividends/:id/process
+func (h *DividendHandler) ProcessDividendEvent(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid dividend event ID",
+		})
+	}
+
+	result, err := h.dividendService.ProcessDividendEvent(c.Context(), eventID)
+	if err != nil {
+		return dividendError(c, err, "Failed to process dividend event")
+	}
+
+	return c.JSON(result)
+}
+
+// GetPayments handles GET /dividends/:id/payments
+func (h *DividendHandler) GetPayments(c *fiber.Ctx) error {
+	eventID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid dividend event ID",
+		})
+	}
+
+	payments, err := h.dividendService.GetPayments(c.Context(), eventID)
+	if err != nil {
+		return dividendError(c, err, "Failed to get dividend payments")
+	}
+
+	if payments == nil {
+		payments = []*models.DividendPayment{}
+	}
+
+	return c.JSON(fiber.Map{
+		"payments": payments,
+		"count":    len(payments),
+	})
+}
+
+// dividendError maps dividend service errors to HTTP responses
+func dividendError(c *fiber.Ctx, err error, message string) error {
+	var notFound *models.NotFoundError
+	if errors.As(err, &notFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Dividend event or stock not found",
+		})
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": validationErr.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"portfolio-app/internal/models"
+)
+
+// MockDividendService is a mock implementation of DividendService
+type MockDividendService struct {
+	mock.Mock
+}
+
+func (m *MockDividendService) CreateDividendEvent(ctx context.Context, req *models.CreateDividendEventRequest) (*models.DividendEvent, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DividendEvent), args.Error(1)
+}
+
+func (m *MockDividendService) GetDividendEvents(ctx context.Context, stockID *uuid.UUID) ([]*models.DividendEvent, error) {
+	args := m.Called(ctx, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DividendEvent), args.Error(1)
+}
+
+func (m *MockDividendService) ProcessDividendEvent(ctx context.Context, id uuid.UUID) (*models.DividendEventResult, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DividendEventResult), args.Error(1)
+}
+
+func (m *MockDividendService) ProcessDueDividends(ctx context.Context) ([]*models.DividendEventResult, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DividendEventResult), args.Error(1)
+}
+
+func (m *MockDividendService) GetPayments(ctx context.Context, id uuid.UUID) ([]*models.DividendPayment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DividendPayment), args.Error(1)
+}
+
+func TestDividendHandler_CreateDividendEvent(t *testing.T) {
+	post := func(mockService *MockDividendService, reqBody map[string]interface{}) int {
+		handler := NewDividendHandler(mockService)
+		app := setupStrategyTestApp()
+		app.Post("/dividends", handler.CreateDividendEvent)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/dividends", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	validBody := func() map[string]interface{} {
+		return map[string]interface{}{
+			"stock_id":         uuid.New().String(),
+			"ex_date":          "2024-06-14",
+			"pay_date":         "2024-07-01",
+			"amount_per_share": "0.485",
+		}
+	}
+
+	t.Run("created", func(t *testing.T) {
+		mockService := new(MockDividendService)
+		mockService.On("CreateDividendEvent", mock.Anything, mock.AnythingOfType("*models.CreateDividendEventRequest")).
+			Return(&models.DividendEvent{ID: uuid.New(), Status: models.DividendPending}, nil)
+
+		status := post(mockService, validBody())
+
+		assert.Equal(t, fiber.StatusCreated, status)
+		req := mockService.Calls[0].Arguments.Get(1).(*models.CreateDividendEventRequest)
+		assert.True(t, req.AmountPerShare.Equal(decimal.NewFromFloat(0.485)))
+	})
+
+	t.Run("missing amount", func(t *testing.T) {
+		mockService := new(MockDividendService)
+		body := validBody()
+		delete(body, "amount_per_share")
+
+		status := post(mockService, body)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		mockService.AssertNotCalled(t, "CreateDividendEvent", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDividendHandler_ProcessDividendEvent(t *testing.T) {
+	eventID := uuid.New()
+	mockService := new(MockDividendService)
+	handler := NewDividendHandler(mockService)
+	app := setupStrategyTestApp()
+	app.Post("/dividends/:id/process", handler.ProcessDividendEvent)
+
+	mockService.On("ProcessDividendEvent", mock.Anything, eventID).
+		Return(nil, &models.ValidationError{Field: "pay_date", Message: "the dividend is not paid until 2099-01-01"})
+
+	req := httptest.NewRequest("POST", "/dividends/"+eventID.String()+"/process", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+
+	req = httptest.NewRequest("POST", "/dividends/not-a-uuid/process", nil)
+	resp, err = app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+}
@@ -1,23 +1,29 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"portfolio-app/internal/models"
 	"portfolio-app/internal/services"
 )
 
 // MarketDataHandler handles HTTP requests for market data operations
 type MarketDataHandler struct {
 	marketDataService services.MarketDataService
+	stockService      services.StockService
 }
 
-// NewMarketDataHandler creates a new market data handler
-func NewMarketDataHandler(marketDataService services.MarketDataService) *MarketDataHandler {
+// NewMarketDataHandler creates a new market data handler. The stock service provides the signal
+// history shown as TradingView marks.
+func NewMarketDataHandler(marketDataService services.MarketDataService, stockService services.StockService) *MarketDataHandler {
 	return &MarketDataHandler{
 		marketDataService: marketDataService,
+		stockService:      stockService,
 	}
 }
 
@@ -524,12 +530,125 @@ func (h *MarketDataHandler) TradingViewSymbolInfo(c *fiber.Ctx) error {
 	})
 }
 
-// TradingViewMarks handles GET /marks for TradingView DataFeed (optional)
+// signalMark is how a signal level is drawn on TradingView charts
+type signalMark struct {
+	color string
+	label string
+}
+
+// signalMarks gives each signal level a distinct color and label, from green for StrongBuy to red
+// for StrongSell
+var signalMarks = map[models.SignalType]signalMark{
+	models.SignalStrongBuy:  {color: "#1b5e20", label: "SB"},
+	models.SignalBuy:        {color: "#43a047", label: "B"},
+	models.SignalHold:       {color: "#9e9e9e", label: "H"},
+	models.SignalSell:       {color: "#fb8c00", label: "S"},
+	models.SignalStrongSell: {color: "#b71c1c", label: "SS"},
+}
+
+// TradingViewMarks handles GET /marks for TradingView DataFeed, marking the stock's signals in the
+// requested range. Symbols that are not tracked stocks have no marks.
 func (h *MarketDataHandler) TradingViewMarks(c *fiber.Ctx) error {
-	// Return empty marks - this is optional for basic functionality
+	symbol := c.Query("symbol")
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+
+	if symbol == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"s": "error",
+			"errmsg": "symbol parameter is required",
+		})
+	}
+
+	if fromStr == "" || toStr == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"s": "error",
+			"errmsg": "from and to parameters are required",
+		})
+	}
+
+	fromTimestamp, err := strconv.ParseInt(fromStr, 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"s": "error",
+			"errmsg": "invalid from timestamp",
+		})
+	}
+
+	toTimestamp, err := strconv.ParseInt(toStr, 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"s": "error",
+			"errmsg": "invalid to timestamp",
+		})
+	}
+
+	// Extract ticker from symbol (remove exchange prefix if present)
+	ticker := symbol
+	if strings.Contains(symbol, ":") {
+		parts := strings.Split(symbol, ":")
+		ticker = parts[len(parts)-1]
+	}
+
+	marks := []fiber.Map{}
+	if h.stockService == nil {
+		return c.JSON(fiber.Map{
+			"s": "ok",
+			"d": marks,
+		})
+	}
+
+	stock, err := h.stockService.GetStockByTicker(c.Context(), strings.ToUpper(ticker))
+	if err != nil {
+		var notFound *models.NotFoundError
+		if errors.As(err, &notFound) {
+			return c.JSON(fiber.Map{
+				"s": "ok",
+				"d": marks,
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"s": "error",
+			"errmsg": err.Error(),
+		})
+	}
+
+	signals, err := h.stockService.GetStockSignalHistory(c.Context(), stock.ID, time.Unix(fromTimestamp, 0), time.Unix(toTimestamp, 0))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"s": "error",
+			"errmsg": err.Error(),
+		})
+	}
+
+	for i, signal := range signals {
+		style, known := signalMarks[signal.Signal]
+		if !known {
+			continue
+		}
+
+		text := fmt.Sprintf("%s signal", signal.Signal)
+		if signal.StrategyID != nil {
+			text = fmt.Sprintf("%s signal for strategy %s", signal.Signal, signal.StrategyID)
+		}
+		if signal.Strength != nil {
+			text = fmt.Sprintf("%s (strength %s)", text, signal.Strength.String())
+		}
+
+		marks = append(marks, fiber.Map{
+			"id":             i + 1,
+			"time":           signal.Date.Unix(),
+			"color":          style.color,
+			"text":           text,
+			"label":          style.label,
+			"labelFontColor": "#ffffff",
+			"minSize":        14,
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"s": "ok",
-		"d": []interface{}{},
+		"d": marks,
 	})
 }
 
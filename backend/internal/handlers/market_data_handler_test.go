@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"portfolio-app/internal/models"
 	"portfolio-app/internal/services"
 )
 
@@ -93,7 +95,7 @@ func TestMarketDataHandler_GetQuote(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockService := new(MockMarketDataService)
-			handler := NewMarketDataHandler(mockService)
+			handler := NewMarketDataHandler(mockService, nil)
 			app := fiber.New()
 
 			// Mock expectations
@@ -171,7 +173,7 @@ func TestMarketDataHandler_GetMultipleQuotes(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockService := new(MockMarketDataService)
-			handler := NewMarketDataHandler(mockService)
+			handler := NewMarketDataHandler(mockService, nil)
 			app := fiber.New()
 
 			// Mock expectations
@@ -260,7 +262,7 @@ func TestMarketDataHandler_GetOHLCV(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockService := new(MockMarketDataService)
-			handler := NewMarketDataHandler(mockService)
+			handler := NewMarketDataHandler(mockService, nil)
 			app := fiber.New()
 
 			// Mock expectations
@@ -337,7 +339,7 @@ func TestMarketDataHandler_TradingViewSymbolSearch(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Setup
 			mockService := new(MockMarketDataService)
-			handler := NewMarketDataHandler(mockService)
+			handler := NewMarketDataHandler(mockService, nil)
 			app := fiber.New()
 
 			// Setup route
@@ -363,6 +365,93 @@ func TestMarketDataHandler_TradingViewSymbolSearch(t *testing.T) {
 	}
 }
 
+func TestMarketDataHandler_TradingViewMarks(t *testing.T) {
+	stockID := uuid.New()
+	strategyID := uuid.New()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	strength := decimal.NewFromInt(80)
+
+	signals := []*models.Signal{
+		{StockID: stockID, Signal: models.SignalStrongBuy, Date: day, Strength: &strength},
+		{StockID: stockID, Signal: models.SignalBuy, Date: day.AddDate(0, 0, 1)},
+		{StockID: stockID, Signal: models.SignalHold, Date: day.AddDate(0, 0, 2)},
+		{StockID: stockID, StrategyID: &strategyID, Signal: models.SignalSell, Date: day.AddDate(0, 0, 3)},
+		{StockID: stockID, Signal: models.SignalStrongSell, Date: day.AddDate(0, 0, 4)},
+	}
+
+	t.Run("marks each signal level distinctly", func(t *testing.T) {
+		mockStockService := new(MockStockService)
+		mockStockService.On("GetStockByTicker", mock.Anything, "AAPL").Return(&models.Stock{ID: stockID, Ticker: "AAPL"}, nil)
+		mockStockService.On("GetStockSignalHistory", mock.Anything, stockID, time.Unix(1709251200, 0), time.Unix(1710000000, 0)).Return(signals, nil)
+
+		handler := NewMarketDataHandler(new(MockMarketDataService), mockStockService)
+		app := fiber.New()
+		app.Get("/marks", handler.TradingViewMarks)
+
+		req := httptest.NewRequest("GET", "/marks?symbol=NASDAQ:aapl&from=1709251200&to=1710000000&resolution=D", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body struct {
+			S string                   `json:"s"`
+			D []map[string]interface{} `json:"d"`
+		}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "ok", body.S)
+		assert.Len(t, body.D, 5)
+
+		colors := map[interface{}]bool{}
+		labels := map[interface{}]bool{}
+		for _, mark := range body.D {
+			colors[mark["color"]] = true
+			labels[mark["label"]] = true
+		}
+		assert.Len(t, colors, 5)
+		assert.Len(t, labels, 5)
+
+		assert.Equal(t, "SB", body.D[0]["label"])
+		assert.Equal(t, float64(day.Unix()), body.D[0]["time"])
+		assert.Equal(t, "StrongBuy signal (strength 80)", body.D[0]["text"])
+		assert.Equal(t, "Sell signal for strategy "+strategyID.String(), body.D[3]["text"])
+		assert.Equal(t, "SS", body.D[4]["label"])
+		mockStockService.AssertExpectations(t)
+	})
+
+	t.Run("untracked symbol has no marks", func(t *testing.T) {
+		mockStockService := new(MockStockService)
+		mockStockService.On("GetStockByTicker", mock.Anything, "XYZ").Return(nil, fmt.Errorf("failed to get stock by ticker: %w", &models.NotFoundError{Resource: "stock"}))
+
+		handler := NewMarketDataHandler(new(MockMarketDataService), mockStockService)
+		app := fiber.New()
+		app.Get("/marks", handler.TradingViewMarks)
+
+		req := httptest.NewRequest("GET", "/marks?symbol=XYZ&from=1709251200&to=1710000000", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 200, resp.StatusCode)
+
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, "ok", body["s"])
+		assert.Empty(t, body["d"])
+	})
+
+	t.Run("requires a time range", func(t *testing.T) {
+		handler := NewMarketDataHandler(new(MockMarketDataService), new(MockStockService))
+		app := fiber.New()
+		app.Get("/marks", handler.TradingViewMarks)
+
+		req := httptest.NewRequest("GET", "/marks?symbol=AAPL", nil)
+		resp, err := app.Test(req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 400, resp.StatusCode)
+	})
+}
+
 // Helper functions
 func generateLongSymbolList(count int) string {
 	symbols := make([]string, count)
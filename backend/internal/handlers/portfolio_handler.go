@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -377,14 +382,88 @@ func (h *PortfolioHandler) GetPortfolioHistory(c *fiber.Ctx) error {
 		responses[i] = entry.ToResponse()
 	}
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"data": responses,
 		"from": from,
 		"to":   to,
+	}
+
+	// Include the benchmark series when the portfolio has one; a market data failure doesn't fail the history
+	benchmark, err := h.portfolioService.GetBenchmarkHistory(c.Context(), portfolioID, from, to)
+	if err != nil {
+		response["benchmark_error"] = err.Error()
+	} else if benchmark != nil {
+		response["benchmark"] = benchmark
+	}
+
+	return c.JSON(response)
+}
+
+// GetPositionHistory handles GET /api/portfolios/:id/positions/history?from=...&to=...&stock_id=...
+func (h *PortfolioHandler) GetPositionHistory(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Parse date range from query parameters, the last 30 days unless given
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		to, err = time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid 'to' date format. Use RFC3339 format",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err = time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid 'from' date format. Use RFC3339 format",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// Optionally limit the history to a single stock
+	var stockID *uuid.UUID
+	if stockIDStr := c.Query("stock_id"); stockIDStr != "" {
+		id, err := uuid.Parse(stockIDStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid stock ID",
+				"details": err.Error(),
+			})
+		}
+		stockID = &id
+	}
+
+	// Get position history
+	history, err := h.portfolioService.GetPositionHistory(c.Context(), portfolioID, from, to, stockID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get position history",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": history,
+		"from": from,
+		"to":   to,
 	})
 }
 
-// GetPortfolioPerformance handles GET /api/portfolios/:id/performance
+// GetPortfolioPerformance handles GET /api/portfolios/:id/performance?period=ytd&risk_free_rate=4.5
 func (h *PortfolioHandler) GetPortfolioPerformance(c *fiber.Ctx) error {
 	// Parse portfolio ID
 	portfolioIDStr := c.Params("id")
@@ -396,8 +475,29 @@ func (h *PortfolioHandler) GetPortfolioPerformance(c *fiber.Ctx) error {
 		})
 	}
 
+	// Annual risk-free rate in percent for Sharpe and Sortino ratios, zero unless given
+	riskFreeRate := decimal.Zero
+	if riskFreeRateStr := c.Query("risk_free_rate"); riskFreeRateStr != "" {
+		riskFreeRate, err = decimal.NewFromString(riskFreeRateStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid risk_free_rate",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// Period for time- and money-weighted returns, since inception unless given
+	period, err := models.ParseReturnPeriod(c.Query("period"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid period",
+			"details": err.Error(),
+		})
+	}
+
 	// Get performance metrics
-	metrics, err := h.portfolioService.GetPortfolioPerformanceMetrics(c.Context(), portfolioID)
+	metrics, err := h.portfolioService.GetPortfolioPerformanceMetrics(c.Context(), portfolioID, riskFreeRate, period)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to get portfolio performance",
@@ -410,6 +510,136 @@ func (h *PortfolioHandler) GetPortfolioPerformance(c *fiber.Ctx) error {
 	})
 }
 
+// GetPortfolioAttribution handles GET /api/portfolios/:id/attribution?from=...&to=...&benchmark_weights=Technology:40:XLK,Health Care:25:XLV
+func (h *PortfolioHandler) GetPortfolioAttribution(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Parse date range from query parameters, the last 30 days unless given
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		to, err = time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid 'to' date format. Use RFC3339 format",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if fromStr := c.Query("from"); fromStr != "" {
+		from, err = time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid 'from' date format. Use RFC3339 format",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// Benchmark weight set for Brinson attribution, optional
+	benchmark, err := models.ParseBenchmarkWeights(c.Query("benchmark_weights"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid benchmark_weights",
+			"details": err.Error(),
+		})
+	}
+
+	// Get attribution
+	attribution, err := h.portfolioService.GetPortfolioAttribution(c.Context(), portfolioID, from, to, benchmark)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid date range",
+				"details": validationErr.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get portfolio attribution",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": attribution,
+	})
+}
+
+// GetTaxReport handles GET /api/portfolios/:id/reports/tax?year=2026&format=csv
+func (h *PortfolioHandler) GetTaxReport(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Parse the tax year, the current year unless given
+	year := time.Now().Year()
+	if yearStr := c.Query("year"); yearStr != "" {
+		year, err = strconv.Atoi(yearStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid year",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	if format != "json" && format != "csv" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid format",
+			"details": "format must be json or csv",
+		})
+	}
+
+	// Get tax report
+	report, err := h.portfolioService.GetTaxReport(c.Context(), portfolioID, year)
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid year",
+				"details": validationErr.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get tax report",
+			"details": err.Error(),
+		})
+	}
+
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := report.WriteCSV(&buf); err != nil {
+			return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to write tax report",
+				"details": err.Error(),
+			})
+		}
+		c.Attachment(fmt.Sprintf("tax-report-%s-%d.csv", portfolioID, year))
+		return c.Send(buf.Bytes())
+	}
+
+	return c.JSON(fiber.Map{
+		"data": report,
+	})
+}
+
 // UpdatePortfolioNAV handles POST /api/portfolios/:id/nav/update
 func (h *PortfolioHandler) UpdatePortfolioNAV(c *fiber.Ctx) error {
 	// Parse portfolio ID
@@ -459,8 +689,8 @@ func (h *PortfolioHandler) GenerateRebalancePreview(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate rebalance preview
-	preview, err := h.portfolioService.GenerateRebalancePreview(c.Context(), portfolioID, reqBody.NewTotalInvestment)
+	// Generate rebalance plan
+	plan, err := h.portfolioService.GenerateRebalancePreview(c.Context(), portfolioID, reqBody.NewTotalInvestment)
 	if err != nil {
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate rebalance preview",
@@ -469,7 +699,7 @@ func (h *PortfolioHandler) GenerateRebalancePreview(c *fiber.Ctx) error {
 	}
 
 	return c.JSON(fiber.Map{
-		"data": preview,
+		"data": plan,
 	})
 }
 
@@ -510,6 +740,381 @@ func (h *PortfolioHandler) RebalancePortfolio(c *fiber.Ctx) error {
 	})
 }
 
+// CreateTransaction handles POST /api/portfolios/:id/transactions
+func (h *PortfolioHandler) CreateTransaction(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	var req models.CreateTransactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	// Record transaction
+	transaction, err := h.portfolioService.RecordTransaction(c.Context(), portfolioID, &req)
+	if err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": "Validation failed",
+				"details": validationErr.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "portfolio not found") {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Portfolio not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to record transaction",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"data": transaction.ToResponse(),
+	})
+}
+
+// GetTransactions handles GET /api/portfolios/:id/transactions
+func (h *PortfolioHandler) GetTransactions(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Parse date range from query parameters, defaulting to the whole ledger
+	fromStr := c.Query("from")
+	toStr := c.Query("to")
+
+	var from time.Time
+	to := time.Now()
+	if fromStr != "" {
+		from, err = time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid 'from' date format. Use RFC3339 format",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	if toStr != "" {
+		to, err = time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "Invalid 'to' date format. Use RFC3339 format",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// Get transactions
+	transactions, err := h.portfolioService.GetTransactions(c.Context(), portfolioID, from, to)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get portfolio transactions",
+			"details": err.Error(),
+		})
+	}
+
+	// Convert to response format
+	responses := make([]*models.TransactionResponse, len(transactions))
+	for i, transaction := range transactions {
+		responses[i] = transaction.ToResponse()
+	}
+
+	return c.JSON(fiber.Map{
+		"data": responses,
+		"from": from,
+		"to":   to,
+	})
+}
+
+// GetAllocationConfig handles GET /api/portfolios/:id/allocation-config
+func (h *PortfolioHandler) GetAllocationConfig(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Get stored allocation configuration
+	config, err := h.portfolioService.GetAllocationConfig(c.Context(), portfolioID)
+	if err != nil {
+		if notFoundErr, ok := err.(*models.NotFoundError); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Allocation config not found",
+				"details": notFoundErr.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "portfolio not found") {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Portfolio not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get allocation config",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": config,
+	})
+}
+
+// UpdateAllocationConfig handles PUT /api/portfolios/:id/allocation-config
+func (h *PortfolioHandler) UpdateAllocationConfig(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	var req models.UpdateAllocationConfigRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	// Update stored allocation configuration
+	config, err := h.portfolioService.UpdateAllocationConfig(c.Context(), portfolioID, &req)
+	if err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": "Validation failed",
+				"details": validationErr.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "portfolio not found") {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Portfolio not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update allocation config",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": config,
+	})
+}
+
+// GetRebalancePolicy handles GET /api/portfolios/:id/rebalance-policy
+func (h *PortfolioHandler) GetRebalancePolicy(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Get rebalance policy
+	policy, err := h.portfolioService.GetRebalancePolicy(c.Context(), portfolioID)
+	if err != nil {
+		if notFoundErr, ok := err.(*models.NotFoundError); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Rebalance policy not found",
+				"details": notFoundErr.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "portfolio not found") {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Portfolio not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get rebalance policy",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": policy,
+	})
+}
+
+// UpdateRebalancePolicy handles PUT /api/portfolios/:id/rebalance-policy
+func (h *PortfolioHandler) UpdateRebalancePolicy(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	var req models.UpdateRebalancePolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	// Save rebalance policy
+	policy, err := h.portfolioService.UpdateRebalancePolicy(c.Context(), portfolioID, &req)
+	if err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			return c.Status(http.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": "Validation failed",
+				"details": validationErr.Error(),
+			})
+		}
+		if strings.Contains(err.Error(), "portfolio not found") {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Portfolio not found",
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update rebalance policy",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": policy,
+	})
+}
+
+// GetRebalanceRuns handles GET /api/portfolios/:id/rebalance-runs
+func (h *PortfolioHandler) GetRebalanceRuns(c *fiber.Ctx) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Get rebalance runs
+	runs, err := h.portfolioService.GetRebalanceRuns(c.Context(), portfolioID)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to get rebalance runs",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": runs,
+	})
+}
+
+// ApproveRebalanceRun handles POST /api/portfolios/:id/rebalance-runs/:runId/approve
+func (h *PortfolioHandler) ApproveRebalanceRun(c *fiber.Ctx) error {
+	return h.resolveRebalanceRun(c, h.portfolioService.ApproveRebalanceRun, "Failed to approve rebalance run")
+}
+
+// RejectRebalanceRun handles POST /api/portfolios/:id/rebalance-runs/:runId/reject
+func (h *PortfolioHandler) RejectRebalanceRun(c *fiber.Ctx) error {
+	return h.resolveRebalanceRun(c, h.portfolioService.RejectRebalanceRun, "Failed to reject rebalance run")
+}
+
+// resolveRebalanceRun parses the portfolio and run IDs and applies the given resolution to a pending run
+func (h *PortfolioHandler) resolveRebalanceRun(c *fiber.Ctx, resolve func(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error), failureMessage string) error {
+	// Parse portfolio ID
+	portfolioIDStr := c.Params("id")
+	portfolioID, err := uuid.Parse(portfolioIDStr)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid portfolio ID",
+			"details": err.Error(),
+		})
+	}
+
+	// Parse run ID
+	runID, err := uuid.Parse(c.Params("runId"))
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid rebalance run ID",
+			"details": err.Error(),
+		})
+	}
+
+	run, err := resolve(c.Context(), portfolioID, runID)
+	if err != nil {
+		if notFoundErr, ok := err.(*models.NotFoundError); ok {
+			return c.Status(http.StatusNotFound).JSON(fiber.Map{
+				"error": "Rebalance run not found",
+				"details": notFoundErr.Error(),
+			})
+		}
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			return c.Status(http.StatusConflict).JSON(fiber.Map{
+				"error": "Rebalance run is not pending",
+				"details": validationErr.Error(),
+			})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error": failureMessage,
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": run,
+	})
+}
+
 // ClearAllocationCache handles DELETE /api/portfolios/cache
 func (h *PortfolioHandler) ClearAllocationCache(c *fiber.Ctx) error {
 	h.cache.Clear()
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -99,20 +100,52 @@ func (m *MockPortfolioService) GetPortfolioHistory(ctx context.Context, portfoli
 	return args.Get(0).([]*models.NAVHistory), args.Error(1)
 }
 
-func (m *MockPortfolioService) GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID) (*models.PerformanceMetrics, error) {
-	args := m.Called(ctx, portfolioID)
+func (m *MockPortfolioService) GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID, riskFreeRatePct decimal.Decimal, period models.ReturnPeriod) (*models.PerformanceMetrics, error) {
+	args := m.Called(ctx, portfolioID, riskFreeRatePct, period)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.PerformanceMetrics), args.Error(1)
 }
 
-func (m *MockPortfolioService) GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.AllocationPreview, error) {
+func (m *MockPortfolioService) GetBenchmarkHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) (*models.BenchmarkHistory, error) {
+	args := m.Called(ctx, portfolioID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BenchmarkHistory), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetPositionHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]models.PositionHistory, error) {
+	args := m.Called(ctx, portfolioID, from, to, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PositionHistory), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetPortfolioAttribution(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, benchmark []models.BenchmarkSectorWeight) (*models.PortfolioAttribution, error) {
+	args := m.Called(ctx, portfolioID, from, to, benchmark)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAttribution), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetTaxReport(ctx context.Context, portfolioID uuid.UUID, year int) (*models.TaxReport, error) {
+	args := m.Called(ctx, portfolioID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaxReport), args.Error(1)
+}
+
+func (m *MockPortfolioService) GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.RebalancePlan, error) {
 	args := m.Called(ctx, portfolioID, newTotalInvestment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.AllocationPreview), args.Error(1)
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
 }
 
 func (m *MockPortfolioService) RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.Portfolio, error) {
@@ -123,6 +156,86 @@ func (m *MockPortfolioService) RebalancePortfolio(ctx context.Context, portfolio
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
+func (m *MockPortfolioService) RecordTransaction(ctx context.Context, portfolioID uuid.UUID, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	args := m.Called(ctx, portfolioID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetTransactions(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	args := m.Called(ctx, portfolioID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAllocationConfig), args.Error(1)
+}
+
+func (m *MockPortfolioService) UpdateAllocationConfig(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateAllocationConfigRequest) (*models.PortfolioAllocationConfig, error) {
+	args := m.Called(ctx, portfolioID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAllocationConfig), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePolicy), args.Error(1)
+}
+
+func (m *MockPortfolioService) UpdateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateRebalancePolicyRequest) (*models.RebalancePolicy, error) {
+	args := m.Called(ctx, portfolioID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePolicy), args.Error(1)
+}
+
+func (m *MockPortfolioService) EvaluateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioService) GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioService) ApproveRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioService) RejectRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
 func setupTestApp(mockService *MockPortfolioService) *fiber.App {
 	app := fiber.New()
 	handler := NewPortfolioHandler(mockService)
@@ -144,6 +257,16 @@ func setupTestApp(mockService *MockPortfolioService) *fiber.App {
 	portfolios.Post("/:id/nav/update", handler.UpdatePortfolioNAV)
 	portfolios.Post("/:id/rebalance/preview", handler.GenerateRebalancePreview)
 	portfolios.Post("/:id/rebalance", handler.RebalancePortfolio)
+	portfolios.Get("/:id/reports/tax", handler.GetTaxReport)
+	portfolios.Get("/:id/transactions", handler.GetTransactions)
+	portfolios.Post("/:id/transactions", handler.CreateTransaction)
+	portfolios.Get("/:id/allocation-config", handler.GetAllocationConfig)
+	portfolios.Put("/:id/allocation-config", handler.UpdateAllocationConfig)
+	portfolios.Get("/:id/rebalance-policy", handler.GetRebalancePolicy)
+	portfolios.Put("/:id/rebalance-policy", handler.UpdateRebalancePolicy)
+	portfolios.Get("/:id/rebalance-runs", handler.GetRebalanceRuns)
+	portfolios.Post("/:id/rebalance-runs/:runId/approve", handler.ApproveRebalanceRun)
+	portfolios.Post("/:id/rebalance-runs/:runId/reject", handler.RejectRebalanceRun)
 	portfolios.Delete("/cache", handler.ClearAllocationCache)
 
 	return app
@@ -173,7 +296,7 @@ func TestPortfolioHandler_GenerateAllocationPreview(t *testing.T) {
 				Weight:          decimal.NewFromFloat(100.0),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				Price:           decimal.NewFromFloat(150.00),
-				Quantity:        66,
+				Quantity:        decimal.NewFromInt(66),
 				ActualValue:     decimal.NewFromFloat(9900.00),
 			},
 		},
@@ -233,7 +356,7 @@ func TestPortfolioHandler_CreatePortfolio(t *testing.T) {
 		Positions: []models.CreatePositionRequest{
 			{
 				StockID:         uuid.New(),
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				StrategyContrib: map[string]decimal.Decimal{
@@ -397,7 +520,7 @@ func TestPortfolioHandler_GenerateRebalancePreview(t *testing.T) {
 				Ticker:          "AAPL",
 				AllocationValue: decimal.NewFromFloat(20000.00),
 				Price:           decimal.NewFromFloat(150.00),
-				Quantity:        133,
+				Quantity:        decimal.NewFromInt(133),
 				ActualValue:     decimal.NewFromFloat(19950.00),
 			},
 		},
@@ -408,7 +531,11 @@ func TestPortfolioHandler_GenerateRebalancePreview(t *testing.T) {
 	// Setup expectations
 	mockService.On("GenerateRebalancePreview", mock.Anything, portfolioID, mock.MatchedBy(func(d decimal.Decimal) bool {
 		return d.Equal(decimal.NewFromFloat(20000.00))
-	})).Return(expectedPreview, nil)
+	})).Return(&models.RebalancePlan{
+		PortfolioID:        portfolioID,
+		NewTotalInvestment: newInvestment,
+		Target:             expectedPreview,
+	}, nil)
 
 	// Create request
 	reqBodyBytes, _ := json.Marshal(reqBody)
@@ -477,6 +604,339 @@ func TestPortfolioHandler_RebalancePortfolio(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestPortfolioHandler_CreateTransaction(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+
+	reqBody := map[string]interface{}{
+		"stock_id": stockID.String(),
+		"type":     "buy",
+		"quantity": 10,
+		"price":    150.00,
+	}
+
+	expectedTransaction := models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(10), decimal.NewFromFloat(150.00), time.Now())
+
+	// Setup expectations
+	mockService.On("RecordTransaction", mock.Anything, portfolioID, mock.MatchedBy(func(req *models.CreateTransactionRequest) bool {
+		return req.Type == models.TransactionBuy && req.Quantity.Equal(decimal.NewFromInt(10)) && *req.StockID == stockID
+	})).Return(expectedTransaction, nil)
+
+	// Create request
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	httpReq := httptest.NewRequest("POST", fmt.Sprintf("/api/portfolios/%s/transactions", portfolioID.String()), bytes.NewReader(reqBodyBytes))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	var response map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+
+	data := response["data"].(map[string]interface{})
+	assert.Equal(t, "buy", data["type"])
+	assert.Equal(t, "-1500", data["cash_effect"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_CreateTransaction_Oversell(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+
+	reqBody := map[string]interface{}{
+		"stock_id": stockID.String(),
+		"type":     "sell",
+		"quantity": 500,
+		"price":    150.00,
+	}
+
+	// Setup expectations
+	mockService.On("RecordTransaction", mock.Anything, portfolioID, mock.AnythingOfType("*models.CreateTransactionRequest")).
+		Return(nil, &models.ValidationError{Field: "quantity", Message: "cannot sell 500 shares"})
+
+	// Create request
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	httpReq := httptest.NewRequest("POST", fmt.Sprintf("/api/portfolios/%s/transactions", portfolioID.String()), bytes.NewReader(reqBodyBytes))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_GetTransactions(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	transactions := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(10000.00), time.Now().Add(-time.Hour)),
+		models.NewTradeTransaction(portfolioID, uuid.New(), models.TransactionBuy, decimal.NewFromInt(10), decimal.NewFromFloat(150.00), time.Now().Add(-time.Hour)),
+	}
+
+	// Setup expectations
+	mockService.On("GetTransactions", mock.Anything, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(transactions, nil)
+
+	// Create request
+	httpReq := httptest.NewRequest("GET", fmt.Sprintf("/api/portfolios/%s/transactions", portfolioID.String()), nil)
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var response map[string]interface{}
+	err = json.NewDecoder(resp.Body).Decode(&response)
+	require.NoError(t, err)
+
+	data := response["data"].([]interface{})
+	assert.Len(t, data, 2)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_GetTaxReport_CSV(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	report := &models.TaxReport{
+		PortfolioID: portfolioID,
+		Year:        2025,
+		Rows: []models.TaxReportRow{
+			{
+				Ticker:         "AAPL",
+				AcquiredAt:     time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+				SoldAt:         time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC),
+				Quantity:       decimal.NewFromInt(10),
+				Proceeds:       decimal.NewFromFloat(1800.00),
+				CostBasis:      decimal.NewFromFloat(1500.00),
+				Gain:           decimal.NewFromFloat(300.00),
+				Term:           models.HoldingLongTerm,
+				DisallowedLoss: decimal.Zero,
+			},
+		},
+		Totals: models.TaxReportTotals{
+			Proceeds:     decimal.NewFromFloat(1800.00),
+			CostBasis:    decimal.NewFromFloat(1500.00),
+			Gain:         decimal.NewFromFloat(300.00),
+			LongTermGain: decimal.NewFromFloat(300.00),
+		},
+	}
+
+	// Setup expectations
+	mockService.On("GetTaxReport", mock.Anything, portfolioID, 2025).Return(report, nil)
+
+	// Create request
+	httpReq := httptest.NewRequest("GET", fmt.Sprintf("/api/portfolios/%s/reports/tax?year=2025&format=csv", portfolioID.String()), nil)
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("Content-Type"), "text/csv")
+	assert.Contains(t, resp.Header.Get("Content-Disposition"), "attachment")
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ticker,quantity,acquired_date,sold_date,proceeds,cost_basis,gain,term,wash_sale,disallowed_loss\n"+
+		"AAPL,10,2024-01-15,2025-03-10,1800.00,1500.00,300.00,long_term,false,0.00\n"+
+		"TOTAL,,,,1800.00,1500.00,300.00,,,0.00\n", string(body))
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_GetTaxReport_InvalidFormat(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	httpReq := httptest.NewRequest("GET", fmt.Sprintf("/api/portfolios/%s/reports/tax?format=xml", uuid.New().String()), nil)
+
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	mockService.AssertNotCalled(t, "GetTaxReport", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortfolioHandler_UpdateAllocationConfig(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	strategyID := uuid.New()
+
+	reqBody := map[string]interface{}{
+		"constraints": map[string]interface{}{
+			"max_allocation_per_stock": 25,
+			"min_allocation_amount":    200,
+		},
+	}
+
+	expectedConfig := &models.PortfolioAllocationConfig{
+		PortfolioID: portfolioID,
+		StrategyIDs: []uuid.UUID{strategyID},
+		Constraints: models.AllocationConstraints{
+			MaxAllocationPerStock: decimal.NewFromInt(25),
+			MinAllocationAmount:   decimal.NewFromInt(200),
+		},
+	}
+
+	// Setup expectations
+	mockService.On("UpdateAllocationConfig", mock.Anything, portfolioID, mock.MatchedBy(func(req *models.UpdateAllocationConfigRequest) bool {
+		return req.Constraints != nil && req.Constraints.MaxAllocationPerStock.Equal(decimal.NewFromInt(25))
+	})).Return(expectedConfig, nil)
+
+	// Create request
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	httpReq := httptest.NewRequest("PUT", fmt.Sprintf("/api/portfolios/%s/allocation-config", portfolioID.String()), bytes.NewReader(reqBodyBytes))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_GetAllocationConfig_NotFound(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+
+	// Setup expectations
+	mockService.On("GetAllocationConfig", mock.Anything, portfolioID).Return(nil, &models.NotFoundError{Resource: "allocation config"})
+
+	// Create request
+	httpReq := httptest.NewRequest("GET", fmt.Sprintf("/api/portfolios/%s/allocation-config", portfolioID.String()), nil)
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_UpdateRebalancePolicy(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	threshold := decimal.NewFromInt(5)
+
+	reqBody := map[string]interface{}{
+		"trigger_type":    "threshold",
+		"drift_threshold": 5,
+		"execution_mode":  "approval",
+	}
+
+	expectedPolicy := &models.RebalancePolicy{
+		PortfolioID:    portfolioID,
+		TriggerType:    models.RebalanceTriggerThreshold,
+		DriftThreshold: &threshold,
+		ExecutionMode:  models.RebalanceModeApproval,
+	}
+
+	// Setup expectations
+	mockService.On("UpdateRebalancePolicy", mock.Anything, portfolioID, mock.MatchedBy(func(req *models.UpdateRebalancePolicyRequest) bool {
+		return req.TriggerType == models.RebalanceTriggerThreshold && req.DriftThreshold != nil && req.DriftThreshold.Equal(threshold)
+	})).Return(expectedPolicy, nil)
+
+	// Create request
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	httpReq := httptest.NewRequest("PUT", fmt.Sprintf("/api/portfolios/%s/rebalance-policy", portfolioID.String()), bytes.NewReader(reqBodyBytes))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestPortfolioHandler_UpdateRebalancePolicy_InvalidTrigger(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+
+	reqBody := map[string]interface{}{
+		"trigger_type":   "weekly",
+		"execution_mode": "auto",
+	}
+
+	// Create request
+	reqBodyBytes, _ := json.Marshal(reqBody)
+	httpReq := httptest.NewRequest("PUT", fmt.Sprintf("/api/portfolios/%s/rebalance-policy", portfolioID.String()), bytes.NewReader(reqBodyBytes))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	mockService.AssertNotCalled(t, "UpdateRebalancePolicy", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestPortfolioHandler_ApproveRebalanceRun_NotPending(t *testing.T) {
+	mockService := &MockPortfolioService{}
+	app := setupTestApp(mockService)
+
+	portfolioID := uuid.New()
+	runID := uuid.New()
+
+	// Setup expectations
+	mockService.On("ApproveRebalanceRun", mock.Anything, portfolioID, runID).Return(nil, &models.ValidationError{
+		Field:   "status",
+		Message: "rebalance run is already executed",
+	})
+
+	// Create request
+	httpReq := httptest.NewRequest("POST", fmt.Sprintf("/api/portfolios/%s/rebalance-runs/%s/approve", portfolioID.String(), runID.String()), nil)
+
+	// Execute
+	resp, err := app.Test(httpReq)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	mockService.AssertExpectations(t)
+}
+
 func TestPortfolioHandler_DeletePortfolio(t *testing.T) {
 	mockService := &MockPortfolioService{}
 	app := setupTestApp(mockService)
@@ -532,7 +992,7 @@ func TestPortfolioHandler_CreatePortfolio_NoUserContext(t *testing.T) {
 		Positions: []models.CreatePositionRequest{
 			{
 				StockID:         uuid.New(),
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				StrategyContrib: map[string]decimal.Decimal{
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// SignalRuleHandler handles HTTP requests for strategy signal rules
+type SignalRuleHandler struct {
+	signalEngine services.SignalEngine
+}
+
+// NewSignalRuleHandler creates a new signal rule handler
+func NewSignalRuleHandler(signalEngine services.SignalEngine) *SignalRuleHandler {
+	return &SignalRuleHandler{
+		signalEngine: signalEngine,
+	}
+}
+
+// CreateRule handles POST /strategies/:id/signal-rules
+func (h *SignalRuleHandler) CreateRule(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	var req models.CreateSignalRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	rule, err := h.signalEngine.CreateRule(c.Context(), strategyID, &req, userID)
+	if err != nil {
+		return signalRuleError(c, err, "Failed to create signal rule")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// GetRules handles GET /strategies/:id/signal-rules
+func (h *SignalRuleHandler) GetRules(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	rules, err := h.signalEngine.GetRules(c.Context(), strategyID, userID)
+	if err != nil {
+		return signalRuleError(c, err, "Failed to get signal rules")
+	}
+
+	if rules == nil {
+		rules = []*models.SignalRule{}
+	}
+
+	return c.JSON(fiber.Map{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+// UpdateRule handles PUT /strategies/:id/signal-rules/:ruleId
+func (h *SignalRuleHandler) UpdateRule(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	ruleID, err := uuid.Parse(c.Params("ruleId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signal rule ID",
+		})
+	}
+
+	var req models.UpdateSignalRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	rule, err := h.signalEngine.UpdateRule(c.Context(), strategyID, ruleID, &req, userID)
+	if err != nil {
+		return signalRuleError(c, err, "Failed to update signal rule")
+	}
+
+	return c.JSON(rule)
+}
+
+// DeleteRule handles DELETE /strategies/:id/signal-rules/:ruleId
+func (h *SignalRuleHandler) DeleteRule(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	ruleID, err := uuid.Parse(c.Params("ruleId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid signal rule ID",
+		})
+	}
+
+	if err := h.signalEngine.DeleteRule(c.Context(), strategyID, ruleID, userID); err != nil {
+		return signalRuleError(c, err, "Failed to delete signal rule")
+	}
+
+	return c.Status(fiber.StatusNoContent).Send(nil)
+}
+
+// EvaluateRules handles POST /strategies/:id/signal-rules/evaluate
+func (h *SignalRuleHandler) EvaluateRules(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	evaluation, err := h.signalEngine.EvaluateStrategy(c.Context(), strategyID, userID)
+	if err != nil {
+		return signalRuleError(c, err, "Failed to evaluate signal rules")
+	}
+
+	return c.JSON(evaluation)
+}
+
+// signalRuleError maps signal engine errors to HTTP responses
+func signalRuleError(c *fiber.Ctx, err error, message string) error {
+	var notFound *models.NotFoundError
+	if errors.As(err, &notFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Strategy or signal rule not found",
+		})
+	}
+
+	var validationErr *models.ValidationError
+	if errors.As(err, &validationErr) {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": validationErr.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"portfolio-app/internal/models"
+)
+
+// MockSignalEngine is a mock implementation of SignalEngine
+type MockSignalEngine struct {
+	mock.Mock
+}
+
+func (m *MockSignalEngine) CreateRule(ctx context.Context, strategyID uuid.UUID, req *models.CreateSignalRuleRequest, userID uuid.UUID) (*models.SignalRule, error) {
+	args := m.Called(ctx, strategyID, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalEngine) UpdateRule(ctx context.Context, strategyID, ruleID uuid.UUID, req *models.UpdateSignalRuleRequest, userID uuid.UUID) (*models.SignalRule, error) {
+	args := m.Called(ctx, strategyID, ruleID, req, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalEngine) GetRules(ctx context.Context, strategyID uuid.UUID, userID uuid.UUID) ([]*models.SignalRule, error) {
+	args := m.Called(ctx, strategyID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalEngine) DeleteRule(ctx context.Context, strategyID, ruleID uuid.UUID, userID uuid.UUID) error {
+	args := m.Called(ctx, strategyID, ruleID, userID)
+	return args.Error(0)
+}
+
+func (m *MockSignalEngine) EvaluateStrategy(ctx context.Context, strategyID uuid.UUID, userID uuid.UUID) (*models.SignalRuleEvaluation, error) {
+	args := m.Called(ctx, strategyID, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalRuleEvaluation), args.Error(1)
+}
+
+func (m *MockSignalEngine) EvaluateAll(ctx context.Context) ([]*models.SignalRuleEvaluation, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SignalRuleEvaluation), args.Error(1)
+}
+
+func TestSignalRuleHandler_CreateRule(t *testing.T) {
+	strategyID := uuid.New()
+
+	post := func(mockEngine *MockSignalEngine, reqBody map[string]interface{}) int {
+		handler := NewSignalRuleHandler(mockEngine)
+		app := setupStrategyTestApp()
+		app.Post("/strategies/:id/signal-rules", handler.CreateRule)
+
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/strategies/"+strategyID.String()+"/signal-rules", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	t.Run("successful creation", func(t *testing.T) {
+		mockEngine := new(MockSignalEngine)
+		mockEngine.On("CreateRule", mock.Anything, strategyID, mock.AnythingOfType("*models.CreateSignalRuleRequest"), mock.AnythingOfType("uuid.UUID")).
+			Return(&models.SignalRule{ID: uuid.New(), StrategyID: strategyID, RuleType: models.SignalRuleSMACross}, nil)
+
+		status := post(mockEngine, map[string]interface{}{
+			"rule_type":   "sma_cross",
+			"period":      50,
+			"slow_period": 200,
+		})
+
+		assert.Equal(t, fiber.StatusCreated, status)
+		mockEngine.AssertExpectations(t)
+	})
+
+	t.Run("unknown rule type", func(t *testing.T) {
+		mockEngine := new(MockSignalEngine)
+
+		status := post(mockEngine, map[string]interface{}{
+			"rule_type": "macd",
+			"period":    12,
+		})
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		mockEngine.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("inconsistent parameters", func(t *testing.T) {
+		mockEngine := new(MockSignalEngine)
+		mockEngine.On("CreateRule", mock.Anything, strategyID, mock.AnythingOfType("*models.CreateSignalRuleRequest"), mock.AnythingOfType("uuid.UUID")).
+			Return(nil, &models.ValidationError{Field: "slow_period", Message: "slow_period is required for crossover rules and must be greater than period"})
+
+		status := post(mockEngine, map[string]interface{}{
+			"rule_type": "ema_cross",
+			"period":    12,
+		})
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, status)
+	})
+
+	t.Run("strategy not found", func(t *testing.T) {
+		mockEngine := new(MockSignalEngine)
+		mockEngine.On("CreateRule", mock.Anything, strategyID, mock.AnythingOfType("*models.CreateSignalRuleRequest"), mock.AnythingOfType("uuid.UUID")).
+			Return(nil, fmt.Errorf("strategy not found or access denied: %w", &models.NotFoundError{Resource: "strategy"}))
+
+		status := post(mockEngine, map[string]interface{}{
+			"rule_type": "breakout",
+			"period":    20,
+		})
+
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+}
+
+func TestSignalRuleHandler_EvaluateRules(t *testing.T) {
+	strategyID := uuid.New()
+	mockEngine := new(MockSignalEngine)
+	handler := NewSignalRuleHandler(mockEngine)
+	app := setupStrategyTestApp()
+	app.Post("/strategies/:id/signal-rules/evaluate", handler.EvaluateRules)
+
+	signal := models.SignalBuy
+	mockEngine.On("EvaluateStrategy", mock.Anything, strategyID, mock.AnythingOfType("uuid.UUID")).Return(&models.SignalRuleEvaluation{
+		StrategyID: strategyID,
+		RuleCount:  1,
+		Results:    []models.StockSignalEvaluation{{StockID: uuid.New(), Ticker: "AAPL", Signal: &signal}},
+	}, nil)
+
+	req := httptest.NewRequest("POST", "/strategies/"+strategyID.String()+"/signal-rules/evaluate", nil)
+	resp, err := app.Test(req)
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var evaluation models.SignalRuleEvaluation
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&evaluation))
+	assert.Equal(t, strategyID, evaluation.StrategyID)
+	assert.Len(t, evaluation.Results, 1)
+	mockEngine.AssertExpectations(t)
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
 	"time"
 
@@ -338,6 +339,64 @@ func (h *StockHandler) GetStockSignalHistory(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateStrategyStockSignal handles PUT /stocks/:id/strategies/:strategyId/signal
+func (h *StockHandler) UpdateStrategyStockSignal(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	stockID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid stock ID",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("strategyId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	var req models.UpdateSignalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	signal, err := h.stockService.UpdateStrategyStockSignal(c.Context(), strategyID, stockID, req.Signal, userID)
+	if err != nil {
+		var notFound *models.NotFoundError
+		if errors.As(err, &notFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Stock or strategy not found",
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update strategy stock signal",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(signal.ToResponse())
+}
+
 // AddStockToStrategy handles POST /stocks/:id/strategies/:strategyId
 func (h *StockHandler) AddStockToStrategy(c *fiber.Ctx) error {
 	// Extract user ID from JWT token (set by auth middleware)
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
@@ -73,6 +75,14 @@ func (m *MockStockService) DeleteStock(ctx context.Context, id uuid.UUID) error
 	return args.Error(0)
 }
 
+func (m *MockStockService) UpdateStrategyStockSignal(ctx context.Context, strategyID, stockID uuid.UUID, signal models.SignalType, userID uuid.UUID) (*models.Signal, error) {
+	args := m.Called(ctx, strategyID, stockID, signal, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Signal), args.Error(1)
+}
+
 func (m *MockStockService) UpdateStockSignal(ctx context.Context, stockID uuid.UUID, signal models.SignalType) (*models.Signal, error) {
 	args := m.Called(ctx, stockID, signal)
 	if args.Get(0) == nil {
@@ -354,6 +364,61 @@ func TestStockHandler_UpdateStockSignal(t *testing.T) {
 	})
 }
 
+func TestStockHandler_UpdateStrategyStockSignal(t *testing.T) {
+	userID := uuid.New()
+	stockID := uuid.New()
+	strategyID := uuid.New()
+
+	setupApp := func(handler *StockHandler) *fiber.App {
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("userID", userID)
+			return c.Next()
+		})
+		app.Put("/stocks/:id/strategies/:strategyId/signal", handler.UpdateStrategyStockSignal)
+		return app
+	}
+	newRequest := func() *http.Request {
+		reqBody, _ := json.Marshal(models.UpdateSignalRequest{Signal: models.SignalSell})
+		httpReq := httptest.NewRequest("PUT", "/stocks/"+stockID.String()+"/strategies/"+strategyID.String()+"/signal", bytes.NewReader(reqBody))
+		httpReq.Header.Set("Content-Type", "application/json")
+		return httpReq
+	}
+
+	t.Run("successful signal update", func(t *testing.T) {
+		mockService := new(MockStockService)
+		app := setupApp(NewStockHandler(mockService))
+
+		mockService.On("UpdateStrategyStockSignal", mock.Anything, strategyID, stockID, models.SignalSell, userID).Return(&models.Signal{
+			StockID:    stockID,
+			StrategyID: &strategyID,
+			Signal:     models.SignalSell,
+			Date:       time.Now(),
+		}, nil)
+
+		resp, err := app.Test(newRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("strategy not found", func(t *testing.T) {
+		mockService := new(MockStockService)
+		app := setupApp(NewStockHandler(mockService))
+
+		// The service wraps the repository's not found error
+		notFoundErr := fmt.Errorf("strategy not found or access denied: %w", &models.NotFoundError{Resource: "strategy"})
+		mockService.On("UpdateStrategyStockSignal", mock.Anything, strategyID, stockID, models.SignalSell, userID).Return(nil, notFoundErr)
+
+		resp, err := app.Test(newRequest())
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+		mockService.AssertExpectations(t)
+	})
+}
+
 // Helper function to create string pointers for stock handler tests
 func stockHandlerStringPtr(s string) *string {
 	return &s
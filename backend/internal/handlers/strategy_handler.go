@@ -353,4 +353,62 @@ func (h *StrategyHandler) UpdateStockEligibility(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"message": "Stock eligibility updated successfully",
 	})
+}
+
+// UpdateStockWeight handles PUT /strategies/:id/stocks/:stockId/weight
+func (h *StrategyHandler) UpdateStockWeight(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	strategyID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid strategy ID",
+		})
+	}
+
+	stockID, err := uuid.Parse(c.Params("stockId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid stock ID",
+		})
+	}
+
+	var req models.UpdateStockWeightRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	err = h.strategyService.UpdateStockWeight(c.Context(), strategyID, stockID, req.CustomWeight, userID)
+	if err != nil {
+		if validationErr, ok := err.(*models.ValidationError); ok {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Validation failed",
+				"details": validationErr.Message,
+			})
+		}
+
+		if _, ok := err.(*models.NotFoundError); ok {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Strategy or stock not found",
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update stock weight",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Stock weight updated successfully",
+	})
 }
\ No newline at end of file
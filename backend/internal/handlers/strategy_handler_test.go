@@ -50,6 +50,11 @@ func (m *MockStrategyService) UpdateStockEligibility(ctx context.Context, strate
 	return args.Error(0)
 }
 
+func (m *MockStrategyService) UpdateStockWeight(ctx context.Context, strategyID, stockID uuid.UUID, weight *decimal.Decimal, userID uuid.UUID) error {
+	args := m.Called(ctx, strategyID, stockID, weight, userID)
+	return args.Error(0)
+}
+
 func (m *MockStrategyService) GetStrategy(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Strategy, error) {
 	args := m.Called(ctx, id, userID)
 	if args.Get(0) == nil {
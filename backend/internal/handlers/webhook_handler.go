@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// WebhookHandler handles HTTP requests from external signal sources
+type WebhookHandler struct {
+	webhookService services.SignalWebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService services.SignalWebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// RotateSecret handles POST /webhooks/secret
+func (h *WebhookHandler) RotateSecret(c *fiber.Ctx) error {
+	// Extract user ID from JWT token (set by auth middleware)
+	userID, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "User authentication required",
+		})
+	}
+
+	secret, err := h.webhookService.RotateSecret(c.Context(), userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to generate webhook secret",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(models.WebhookSecretResponse{Secret: secret})
+}
+
+// IngestSignals handles POST /webhooks/signals/:userId. The request must be signed with the
+// user's webhook secret; see services.SignWebhookPayload.
+func (h *WebhookHandler) IngestSignals(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	body := c.Body()
+	err = h.webhookService.VerifySignature(c.Context(), userID,
+		c.Get(services.WebhookTimestampHeader), c.Get(services.WebhookSignatureHeader), body)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidWebhookSignature) || errors.Is(err, services.ErrWebhookSecretNotSet) {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to verify webhook signature",
+			"details": err.Error(),
+		})
+	}
+
+	var req models.SignalWebhookRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	// Validate request
+	if err := models.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Validation failed",
+			"details": err.Error(),
+		})
+	}
+
+	result, err := h.webhookService.IngestSignals(c.Context(), userID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to ingest signals",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(result)
+}
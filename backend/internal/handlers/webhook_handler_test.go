@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/services"
+)
+
+// MockSignalWebhookService is a mock implementation of SignalWebhookService
+type MockSignalWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockSignalWebhookService) RotateSecret(ctx context.Context, userID uuid.UUID) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSignalWebhookService) VerifySignature(ctx context.Context, userID uuid.UUID, timestamp, signature string, body []byte) error {
+	args := m.Called(ctx, userID, timestamp, signature, body)
+	return args.Error(0)
+}
+
+func (m *MockSignalWebhookService) IngestSignals(ctx context.Context, userID uuid.UUID, req *models.SignalWebhookRequest) (*models.SignalWebhookResult, error) {
+	args := m.Called(ctx, userID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalWebhookResult), args.Error(1)
+}
+
+func TestWebhookHandler_IngestSignals(t *testing.T) {
+	userID := uuid.New()
+	secret := "test-webhook-secret"
+	payload := models.SignalWebhookRequest{
+		Signals: []models.SignalWebhookItem{
+			{Ticker: "AAPL", Signal: models.SignalBuy, Date: "2024-03-01", Source: "tradingview"},
+		},
+	}
+	body, _ := json.Marshal(payload)
+
+	// signedRequest returns a mocked service and a function posting body signed with the user's secret
+	signedRequest := func(body []byte) (*MockSignalWebhookService, func() int) {
+		mockService := new(MockSignalWebhookService)
+		handler := NewWebhookHandler(mockService)
+		app := fiber.New()
+		app.Post("/webhooks/signals/:userId", handler.IngestSignals)
+
+		send := func() int {
+			timestamp := time.Now().Unix()
+			req := httptest.NewRequest("POST", "/webhooks/signals/"+userID.String(), bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(services.WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+			req.Header.Set(services.WebhookSignatureHeader, services.SignWebhookPayload(secret, timestamp, body))
+
+			resp, err := app.Test(req)
+			assert.NoError(t, err)
+			return resp.StatusCode
+		}
+		return mockService, send
+	}
+
+	t.Run("signed batch is ingested", func(t *testing.T) {
+		mockService, send := signedRequest(body)
+
+		mockService.On("VerifySignature", mock.Anything, userID, mock.AnythingOfType("string"), mock.AnythingOfType("string"), body).Return(nil)
+		mockService.On("IngestSignals", mock.Anything, userID, &payload).Return(&models.SignalWebhookResult{
+			Accepted: 1,
+			Results:  []models.SignalWebhookItemResult{{Index: 0, Ticker: "AAPL", Status: "accepted"}},
+		}, nil)
+
+		assert.Equal(t, fiber.StatusOK, send())
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("invalid signature is rejected", func(t *testing.T) {
+		mockService, send := signedRequest(body)
+
+		mockService.On("VerifySignature", mock.Anything, userID, mock.Anything, mock.Anything, body).Return(services.ErrInvalidWebhookSignature)
+
+		assert.Equal(t, fiber.StatusUnauthorized, send())
+		mockService.AssertNotCalled(t, "IngestSignals", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid payload is rejected after verification", func(t *testing.T) {
+		invalidBody := []byte(`{"signals":[{"ticker":"AAPL","signal":"Maybe","date":"2024-03-01","source":"tradingview"}]}`)
+		mockService, send := signedRequest(invalidBody)
+
+		mockService.On("VerifySignature", mock.Anything, userID, mock.Anything, mock.Anything, invalidBody).Return(nil)
+
+		assert.Equal(t, fiber.StatusBadRequest, send())
+		mockService.AssertNotCalled(t, "IngestSignals", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
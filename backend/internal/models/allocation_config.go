@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PortfolioAllocationConfig represents the allocation settings a portfolio was built with,
+// reused whenever the portfolio is rebalanced
+type PortfolioAllocationConfig struct {
+	PortfolioID    uuid.UUID             `json:"portfolio_id" db:"portfolio_id"`
+	StrategyIDs    []uuid.UUID           `json:"strategy_ids" db:"strategy_ids" validate:"required,min=1"`
+	Constraints    AllocationConstraints `json:"constraints"`
+	ExcludedStocks []uuid.UUID           `json:"excluded_stocks" db:"excluded_stocks"`
+	CreatedAt      time.Time             `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateAllocationConfigRequest represents the request to edit a portfolio's stored allocation settings
+type UpdateAllocationConfigRequest struct {
+	StrategyIDs    []uuid.UUID            `json:"strategy_ids,omitempty" validate:"omitempty,min=1"`
+	Constraints    *AllocationConstraints `json:"constraints,omitempty"`
+	ExcludedStocks *[]uuid.UUID           `json:"excluded_stocks,omitempty"`
+}
+
+// NewPortfolioAllocationConfig creates the stored allocation settings from an AllocationRequest
+func NewPortfolioAllocationConfig(portfolioID uuid.UUID, req *AllocationRequest) *PortfolioAllocationConfig {
+	now := time.Now()
+	return &PortfolioAllocationConfig{
+		PortfolioID:    portfolioID,
+		StrategyIDs:    append([]uuid.UUID(nil), req.StrategyIDs...),
+		Constraints:    req.Constraints,
+		ExcludedStocks: append([]uuid.UUID{}, req.ExcludedStocks...),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// ToAllocationRequest builds an AllocationRequest from the stored settings for the given investment
+func (c *PortfolioAllocationConfig) ToAllocationRequest(totalInvestment decimal.Decimal) *AllocationRequest {
+	return &AllocationRequest{
+		StrategyIDs:     append([]uuid.UUID(nil), c.StrategyIDs...),
+		TotalInvestment: totalInvestment,
+		Constraints:     c.Constraints,
+		ExcludedStocks:  append([]uuid.UUID(nil), c.ExcludedStocks...),
+	}
+}
+
+// ApplyUpdate applies an UpdateAllocationConfigRequest to the stored settings
+func (c *PortfolioAllocationConfig) ApplyUpdate(req *UpdateAllocationConfigRequest) {
+	if len(req.StrategyIDs) > 0 {
+		c.StrategyIDs = req.StrategyIDs
+	}
+	if req.Constraints != nil {
+		c.Constraints = *req.Constraints
+	}
+	if req.ExcludedStocks != nil {
+		c.ExcludedStocks = *req.ExcludedStocks
+	}
+	c.UpdatedAt = time.Now()
+}
@@ -0,0 +1,359 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// UnattributedStrategy groups P&L of holdings without a strategy contribution map, such as
+	// positions closed before the attribution period ended
+	UnattributedStrategy = "unattributed"
+	// UnclassifiedSector groups P&L of stocks without a sector
+	UnclassifiedSector = "Unclassified"
+)
+
+// BenchmarkSectorWeight represents a sector of the benchmark weight set Brinson attribution compares
+// against. The sector's benchmark return is the return of Symbol, typically a sector ETF.
+type BenchmarkSectorWeight struct {
+	Sector    string          `json:"sector"`
+	WeightPct decimal.Decimal `json:"weight_pct"`
+	Symbol    string          `json:"symbol"`
+}
+
+// HoldingPerformance represents a stock's value change and trading over an attribution period
+type HoldingPerformance struct {
+	StockID    uuid.UUID
+	Ticker     string
+	Sector     string
+	StartValue decimal.Decimal
+	EndValue   decimal.Decimal
+	Purchases  decimal.Decimal
+	Sales      decimal.Decimal
+	Dividends  decimal.Decimal
+	// StrategyContrib maps strategy IDs to the amount each contributed to the position; P&L is
+	// pro-rated by each strategy's share of the total
+	StrategyContrib map[string]decimal.Decimal
+}
+
+// PnL returns the holding's profit over the period including dividends received
+func (h HoldingPerformance) PnL() decimal.Decimal {
+	return h.EndValue.Sub(h.StartValue).Sub(h.Purchases).Add(h.Sales).Add(h.Dividends)
+}
+
+// capitalBase returns the simple Dietz denominator: the start value plus half the net trading flow
+func (h HoldingPerformance) capitalBase() decimal.Decimal {
+	return h.StartValue.Add(h.Purchases.Sub(h.Sales).Div(decimal.NewFromInt(2)))
+}
+
+// AttributionSegment represents the P&L of a strategy or sector over an attribution period
+type AttributionSegment struct {
+	Key           string          `json:"key"`
+	Name          string          `json:"name"`
+	StartValue    decimal.Decimal `json:"start_value"`
+	EndValue      decimal.Decimal `json:"end_value"`
+	NetInvestment decimal.Decimal `json:"net_investment"`
+	Dividends     decimal.Decimal `json:"dividends"`
+	PnL           decimal.Decimal `json:"pnl"`
+	// ReturnPct is the segment's simple Dietz return on the capital it had invested
+	ReturnPct *decimal.Decimal `json:"return_pct,omitempty"`
+	// ContributionPct is the segment's P&L as a percentage of the portfolio's value at the start
+	ContributionPct *decimal.Decimal `json:"contribution_pct,omitempty"`
+	WeightPct       *decimal.Decimal `json:"weight_pct,omitempty"`
+
+	capitalBase decimal.Decimal
+}
+
+// BrinsonSector represents the Brinson-Fachler effects of a single sector
+type BrinsonSector struct {
+	Sector               string           `json:"sector"`
+	PortfolioWeightPct   decimal.Decimal  `json:"portfolio_weight_pct"`
+	BenchmarkWeightPct   decimal.Decimal  `json:"benchmark_weight_pct"`
+	PortfolioReturnPct   *decimal.Decimal `json:"portfolio_return_pct,omitempty"`
+	BenchmarkReturnPct   decimal.Decimal  `json:"benchmark_return_pct"`
+	AllocationEffectPct  decimal.Decimal  `json:"allocation_effect_pct"`
+	SelectionEffectPct   decimal.Decimal  `json:"selection_effect_pct"`
+	InteractionEffectPct decimal.Decimal  `json:"interaction_effect_pct"`
+}
+
+// BrinsonAttribution splits the portfolio's active return against a benchmark weight set into
+// allocation (sector weighting), selection (stock picking) and interaction effects, which sum to
+// the active return
+type BrinsonAttribution struct {
+	PortfolioReturnPct   decimal.Decimal `json:"portfolio_return_pct"`
+	BenchmarkReturnPct   decimal.Decimal `json:"benchmark_return_pct"`
+	ActiveReturnPct      decimal.Decimal `json:"active_return_pct"`
+	AllocationEffectPct  decimal.Decimal `json:"allocation_effect_pct"`
+	SelectionEffectPct   decimal.Decimal `json:"selection_effect_pct"`
+	InteractionEffectPct decimal.Decimal `json:"interaction_effect_pct"`
+	Sectors              []BrinsonSector `json:"sectors"`
+}
+
+// PortfolioAttribution represents a portfolio's P&L over a date range broken down by strategy and sector
+type PortfolioAttribution struct {
+	PortfolioID uuid.UUID            `json:"portfolio_id"`
+	From        time.Time            `json:"from"`
+	To          time.Time            `json:"to"`
+	StartValue  decimal.Decimal      `json:"start_value"`
+	TotalPnL    decimal.Decimal      `json:"total_pnl"`
+	Strategies  []AttributionSegment `json:"strategies"`
+	Sectors     []AttributionSegment `json:"sectors"`
+	Brinson     *BrinsonAttribution  `json:"brinson,omitempty"`
+}
+
+// ParseBenchmarkWeights parses a benchmark weight set of the form
+// "Technology:40:XLK,Health Care:25:XLV", where weights are percentages
+func ParseBenchmarkWeights(s string) ([]BenchmarkSectorWeight, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var weights []BenchmarkSectorWeight
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(s, ",") {
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, &ValidationError{
+				Field:   "benchmark_weights",
+				Tag:     "format",
+				Value:   entry,
+				Message: fmt.Sprintf("invalid benchmark weight %q, expected sector:weight:symbol", entry),
+			}
+		}
+
+		sector := strings.TrimSpace(parts[0])
+		symbol := strings.ToUpper(strings.TrimSpace(parts[2]))
+		weight, err := decimal.NewFromString(strings.TrimSpace(parts[1]))
+		if sector == "" || symbol == "" || err != nil || weight.IsNegative() {
+			return nil, &ValidationError{
+				Field:   "benchmark_weights",
+				Tag:     "format",
+				Value:   entry,
+				Message: fmt.Sprintf("invalid benchmark weight %q, expected a sector, a non-negative weight and a symbol", entry),
+			}
+		}
+		if seen[sector] {
+			return nil, &ValidationError{
+				Field:   "benchmark_weights",
+				Tag:     "unique",
+				Value:   sector,
+				Message: fmt.Sprintf("sector %s is weighted more than once", sector),
+			}
+		}
+		seen[sector] = true
+
+		weights = append(weights, BenchmarkSectorWeight{Sector: sector, WeightPct: weight, Symbol: symbol})
+	}
+
+	total := decimal.Zero
+	for _, w := range weights {
+		total = total.Add(w.WeightPct)
+	}
+	if !total.IsPositive() {
+		return nil, &ValidationError{
+			Field:   "benchmark_weights",
+			Tag:     "gt",
+			Message: "benchmark weights must sum to more than zero",
+		}
+	}
+
+	return weights, nil
+}
+
+// CalculateAttribution attributes the P&L of holdings to strategies and sectors. startValue is the
+// portfolio's value at the start of the period and is the base of contributions; the holdings' capital
+// is used when it is not positive. benchmarkReturns holds the period return in percent of each
+// benchmark sector; Brinson effects are only calculated when benchmark weights are given.
+func CalculateAttribution(holdings []HoldingPerformance, startValue decimal.Decimal, strategyNames map[string]string, benchmark []BenchmarkSectorWeight, benchmarkReturns map[string]decimal.Decimal) *PortfolioAttribution {
+	attribution := &PortfolioAttribution{
+		StartValue: startValue,
+		TotalPnL:   decimal.Zero,
+		Strategies: []AttributionSegment{},
+		Sectors:    []AttributionSegment{},
+	}
+
+	strategies := make(map[string]*AttributionSegment)
+	sectors := make(map[string]*AttributionSegment)
+	totalBase := decimal.Zero
+
+	for _, h := range holdings {
+		sector := h.Sector
+		if sector == "" {
+			sector = UnclassifiedSector
+		}
+		addToSegment(sectors, sector, sector, h, decimal.NewFromInt(1))
+
+		contribTotal := decimal.Zero
+		for _, amount := range h.StrategyContrib {
+			if amount.IsPositive() {
+				contribTotal = contribTotal.Add(amount)
+			}
+		}
+		if contribTotal.IsPositive() {
+			for strategyID, amount := range h.StrategyContrib {
+				if amount.IsPositive() {
+					addToSegment(strategies, strategyID, strategyNames[strategyID], h, amount.Div(contribTotal))
+				}
+			}
+		} else {
+			addToSegment(strategies, UnattributedStrategy, "Unattributed", h, decimal.NewFromInt(1))
+		}
+
+		attribution.TotalPnL = attribution.TotalPnL.Add(h.PnL())
+		totalBase = totalBase.Add(h.capitalBase())
+	}
+
+	contributionBase := startValue
+	if !contributionBase.IsPositive() {
+		contributionBase = totalBase
+	}
+
+	attribution.Strategies = finalizeSegments(strategies, contributionBase, totalBase)
+	attribution.Sectors = finalizeSegments(sectors, contributionBase, totalBase)
+
+	if len(benchmark) > 0 {
+		attribution.Brinson = calculateBrinson(sectors, totalBase, benchmark, benchmarkReturns)
+	}
+
+	return attribution
+}
+
+// addToSegment adds a share of a holding's figures to the segment with the given key
+func addToSegment(segments map[string]*AttributionSegment, key, name string, h HoldingPerformance, share decimal.Decimal) {
+	segment, exists := segments[key]
+	if !exists {
+		segment = &AttributionSegment{Key: key, Name: name}
+		segments[key] = segment
+	}
+
+	segment.StartValue = segment.StartValue.Add(h.StartValue.Mul(share))
+	segment.EndValue = segment.EndValue.Add(h.EndValue.Mul(share))
+	segment.NetInvestment = segment.NetInvestment.Add(h.Purchases.Sub(h.Sales).Mul(share))
+	segment.Dividends = segment.Dividends.Add(h.Dividends.Mul(share))
+	segment.PnL = segment.PnL.Add(h.PnL().Mul(share))
+	segment.capitalBase = segment.capitalBase.Add(h.capitalBase().Mul(share))
+}
+
+// finalizeSegments rounds segment figures, derives their percentages and orders them by P&L, largest first
+func finalizeSegments(segments map[string]*AttributionSegment, contributionBase, totalBase decimal.Decimal) []AttributionSegment {
+	result := make([]AttributionSegment, 0, len(segments))
+	for _, segment := range segments {
+		segment.ReturnPct = percentOf(segment.PnL, segment.capitalBase)
+		segment.ContributionPct = percentOf(segment.PnL, contributionBase)
+		segment.WeightPct = percentOf(segment.capitalBase, totalBase)
+		segment.StartValue = segment.StartValue.Round(2)
+		segment.EndValue = segment.EndValue.Round(2)
+		segment.NetInvestment = segment.NetInvestment.Round(2)
+		segment.Dividends = segment.Dividends.Round(2)
+		segment.PnL = segment.PnL.Round(2)
+		result = append(result, *segment)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].PnL.Equal(result[j].PnL) {
+			return result[i].PnL.GreaterThan(result[j].PnL)
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// calculateBrinson calculates Brinson-Fachler effects per sector. Benchmark weights are normalized to
+// sum to 100%. Portfolio sectors missing from the benchmark are compared against the total benchmark
+// return, so they only contribute an interaction effect.
+func calculateBrinson(sectors map[string]*AttributionSegment, totalBase decimal.Decimal, benchmark []BenchmarkSectorWeight, benchmarkReturns map[string]decimal.Decimal) *BrinsonAttribution {
+	hundred := decimal.NewFromInt(100)
+
+	totalWeight := decimal.Zero
+	for _, w := range benchmark {
+		totalWeight = totalWeight.Add(w.WeightPct)
+	}
+
+	benchmarkWeights := make(map[string]decimal.Decimal, len(benchmark))
+	benchmarkReturn := decimal.Zero
+	for _, w := range benchmark {
+		weight := decimal.Zero
+		if totalWeight.IsPositive() {
+			weight = w.WeightPct.Div(totalWeight)
+		}
+		benchmarkWeights[w.Sector] = weight
+		benchmarkReturn = benchmarkReturn.Add(weight.Mul(benchmarkReturns[w.Sector].Div(hundred)))
+	}
+
+	names := make([]string, 0, len(sectors)+len(benchmark))
+	for name := range sectors {
+		names = append(names, name)
+	}
+	for _, w := range benchmark {
+		if _, exists := sectors[w.Sector]; !exists {
+			names = append(names, w.Sector)
+		}
+	}
+	sort.Strings(names)
+
+	brinson := &BrinsonAttribution{Sectors: make([]BrinsonSector, 0, len(names))}
+	portfolioReturn := decimal.Zero
+	var allocation, selection, interaction decimal.Decimal
+
+	for _, name := range names {
+		wb := benchmarkWeights[name]
+		rb := benchmarkReturn
+		if _, exists := benchmarkWeights[name]; exists {
+			rb = benchmarkReturns[name].Div(hundred)
+		}
+
+		wp := decimal.Zero
+		rp := rb
+		var portfolioReturnPct *decimal.Decimal
+		if segment, exists := sectors[name]; exists && totalBase.IsPositive() {
+			wp = segment.capitalBase.Div(totalBase)
+			if segment.capitalBase.IsPositive() {
+				rp = segment.PnL.Div(segment.capitalBase)
+				pct := rp.Mul(hundred).Round(4)
+				portfolioReturnPct = &pct
+			}
+			portfolioReturn = portfolioReturn.Add(segment.PnL.Div(totalBase))
+		}
+
+		sectorAllocation := wp.Sub(wb).Mul(rb.Sub(benchmarkReturn))
+		sectorSelection := wb.Mul(rp.Sub(rb))
+		sectorInteraction := wp.Sub(wb).Mul(rp.Sub(rb))
+		allocation = allocation.Add(sectorAllocation)
+		selection = selection.Add(sectorSelection)
+		interaction = interaction.Add(sectorInteraction)
+
+		brinson.Sectors = append(brinson.Sectors, BrinsonSector{
+			Sector:               name,
+			PortfolioWeightPct:   wp.Mul(hundred).Round(4),
+			BenchmarkWeightPct:   wb.Mul(hundred).Round(4),
+			PortfolioReturnPct:   portfolioReturnPct,
+			BenchmarkReturnPct:   rb.Mul(hundred).Round(4),
+			AllocationEffectPct:  sectorAllocation.Mul(hundred).Round(4),
+			SelectionEffectPct:   sectorSelection.Mul(hundred).Round(4),
+			InteractionEffectPct: sectorInteraction.Mul(hundred).Round(4),
+		})
+	}
+
+	brinson.PortfolioReturnPct = portfolioReturn.Mul(hundred).Round(4)
+	brinson.BenchmarkReturnPct = benchmarkReturn.Mul(hundred).Round(4)
+	brinson.ActiveReturnPct = portfolioReturn.Sub(benchmarkReturn).Mul(hundred).Round(4)
+	brinson.AllocationEffectPct = allocation.Mul(hundred).Round(4)
+	brinson.SelectionEffectPct = selection.Mul(hundred).Round(4)
+	brinson.InteractionEffectPct = interaction.Mul(hundred).Round(4)
+
+	return brinson
+}
+
+// percentOf returns part as a percentage of whole, or nil when whole is not positive
+func percentOf(part, whole decimal.Decimal) *decimal.Decimal {
+	if !whole.IsPositive() {
+		return nil
+	}
+	pct := part.Div(whole).Mul(decimal.NewFromInt(100)).Round(4)
+	return &pct
+}
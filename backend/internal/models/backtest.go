@@ -0,0 +1,168 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// BacktestStatus represents the progress of a backtest job
+type BacktestStatus string
+
+const (
+	BacktestPending   BacktestStatus = "pending"
+	BacktestRunning   BacktestStatus = "running"
+	BacktestCompleted BacktestStatus = "completed"
+	BacktestFailed    BacktestStatus = "failed"
+)
+
+// MaxBacktestYears bounds the date range of a single backtest
+const MaxBacktestYears = 10
+
+// BacktestRequest represents the request to replay a set of strategies over a date range
+type BacktestRequest struct {
+	StrategyIDs        []uuid.UUID           `json:"strategy_ids" validate:"required,min=1"`
+	StartDate          string                `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate            string                `json:"end_date" validate:"required,datetime=2006-01-02"`
+	InitialInvestment  decimal.Decimal       `json:"initial_investment" validate:"required,gt=0"`
+	RebalanceFrequency RebalanceFrequency    `json:"rebalance_frequency" validate:"required,oneof=monthly quarterly"`
+	Constraints        AllocationConstraints `json:"constraints"`
+	// FractionalShares sizes positions in fractions of a share, rounded down to SharePrecision decimal places
+	FractionalShares bool  `json:"fractional_shares,omitempty"`
+	SharePrecision   int32 `json:"share_precision,omitempty" validate:"gte=0,lte=8"`
+	// StrongBuyMultiplier scales the stock weight of StrongBuy names within their strategy; zero leaves them unchanged
+	StrongBuyMultiplier decimal.Decimal `json:"strong_buy_multiplier,omitempty"`
+	// RiskFreeRatePct is the annual risk-free rate in percent used for the Sharpe and Sortino ratios
+	RiskFreeRatePct decimal.Decimal `json:"risk_free_rate_pct,omitempty"`
+}
+
+// BacktestNAVPoint represents the simulated portfolio value at the close of a trading day
+type BacktestNAVPoint struct {
+	Date     time.Time        `json:"date"`
+	NAV      decimal.Decimal  `json:"nav"`
+	Cash     decimal.Decimal  `json:"cash"`
+	Drawdown *decimal.Decimal `json:"drawdown"`
+}
+
+// BacktestHolding represents a simulated position held after a rebalance
+type BacktestHolding struct {
+	StockID  uuid.UUID       `json:"stock_id"`
+	Ticker   string          `json:"ticker"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Price    decimal.Decimal `json:"price"`
+	Value    decimal.Decimal `json:"value"`
+}
+
+// BacktestRebalance represents the portfolio the allocation engine produced on a rebalance date
+type BacktestRebalance struct {
+	Date     time.Time         `json:"date"`
+	NAV      decimal.Decimal   `json:"nav"`
+	Holdings []BacktestHolding `json:"holdings"`
+	Cash     decimal.Decimal   `json:"cash"`
+	Warnings []string          `json:"warnings,omitempty"`
+}
+
+// BacktestResult represents the simulated NAV series of a backtest and its performance
+type BacktestResult struct {
+	// Currency is the currency the strategies' stocks are quoted in and the NAV is reported in
+	Currency   string              `json:"currency"`
+	NAVSeries  []BacktestNAVPoint  `json:"nav_series"`
+	Rebalances []BacktestRebalance `json:"rebalances"`
+	Metrics    *PerformanceMetrics `json:"metrics"`
+}
+
+// Backtest represents a backtest job with its request and, once completed, its result
+type Backtest struct {
+	ID          uuid.UUID        `json:"id" db:"id"`
+	UserID      uuid.UUID        `json:"user_id" db:"user_id"`
+	Status      BacktestStatus   `json:"status" db:"status"`
+	Request     *BacktestRequest `json:"request" db:"request"`
+	Result      *BacktestResult  `json:"result,omitempty" db:"result"`
+	Error       *string          `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time       `json:"started_at,omitempty" db:"started_at"`
+	CompletedAt *time.Time       `json:"completed_at,omitempty" db:"completed_at"`
+}
+
+// DateRange parses the start and end dates of the request
+func (r *BacktestRequest) DateRange() (time.Time, time.Time, error) {
+	start, err := time.Parse("2006-01-02", r.StartDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, &ValidationError{
+			Field:   "start_date",
+			Tag:     "datetime",
+			Message: "start_date must be formatted as YYYY-MM-DD",
+		}
+	}
+
+	end, err := time.Parse("2006-01-02", r.EndDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, &ValidationError{
+			Field:   "end_date",
+			Tag:     "datetime",
+			Message: "end_date must be formatted as YYYY-MM-DD",
+		}
+	}
+
+	return start, end, nil
+}
+
+// Validate checks that the date range is ordered and not longer than MaxBacktestYears
+func (r *BacktestRequest) Validate() error {
+	start, end, err := r.DateRange()
+	if err != nil {
+		return err
+	}
+
+	if !end.After(start) {
+		return &ValidationError{
+			Field:   "end_date",
+			Tag:     "gtfield",
+			Message: "end_date must be after start_date",
+		}
+	}
+
+	if end.After(start.AddDate(MaxBacktestYears, 0, 0)) {
+		return &ValidationError{
+			Field:   "end_date",
+			Tag:     "range",
+			Message: fmt.Sprintf("a backtest can cover at most %d years", MaxBacktestYears),
+		}
+	}
+
+	return nil
+}
+
+// NewBacktest creates a pending backtest job for a user
+func NewBacktest(userID uuid.UUID, req *BacktestRequest) *Backtest {
+	return &Backtest{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    BacktestPending,
+		Request:   req,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Start marks the backtest as running
+func (b *Backtest) Start() {
+	now := time.Now()
+	b.Status = BacktestRunning
+	b.StartedAt = &now
+}
+
+// Complete records the outcome of the backtest, failing it when err is set
+func (b *Backtest) Complete(result *BacktestResult, err error) {
+	now := time.Now()
+	b.CompletedAt = &now
+	if err != nil {
+		message := err.Error()
+		b.Status = BacktestFailed
+		b.Error = &message
+		return
+	}
+	b.Status = BacktestCompleted
+	b.Result = result
+}
@@ -0,0 +1,150 @@
+package models
+
+import (
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BenchmarkPoint represents a benchmark close scaled to the portfolio's NAV at the start of the series
+type BenchmarkPoint struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Close     decimal.Decimal `json:"close"`
+	Value     decimal.Decimal `json:"value"`
+}
+
+// BenchmarkHistory represents the benchmark series returned alongside a portfolio's NAV history
+type BenchmarkHistory struct {
+	Symbol string           `json:"symbol"`
+	Points []BenchmarkPoint `json:"points"`
+}
+
+// BenchmarkMetrics represents portfolio performance relative to its benchmark, computed from daily
+// returns on the days both have a close
+type BenchmarkMetrics struct {
+	Symbol             string           `json:"symbol"`
+	BenchmarkReturnPct *decimal.Decimal `json:"benchmark_return_pct,omitempty"`
+	// AlphaPct is Jensen's alpha, annualized
+	AlphaPct         *decimal.Decimal `json:"alpha_pct,omitempty"`
+	Beta             *decimal.Decimal `json:"beta,omitempty"`
+	TrackingErrorPct *decimal.Decimal `json:"tracking_error_pct,omitempty"`
+	InformationRatio *decimal.Decimal `json:"information_ratio,omitempty"`
+	UpCapturePct     *decimal.Decimal `json:"up_capture_pct,omitempty"`
+	DownCapturePct   *decimal.Decimal `json:"down_capture_pct,omitempty"`
+	ObservationCount int              `json:"observation_count"`
+}
+
+// NormalizeBenchmark scales benchmark closes ordered by time so the series starts at startValue,
+// letting it be charted against the portfolio's NAV
+func NormalizeBenchmark(symbol string, timestamps []time.Time, closes []decimal.Decimal, startValue decimal.Decimal) *BenchmarkHistory {
+	history := &BenchmarkHistory{Symbol: symbol, Points: make([]BenchmarkPoint, 0, len(closes))}
+	if len(closes) == 0 || !closes[0].IsPositive() {
+		return history
+	}
+
+	scale := startValue.Div(closes[0])
+	for i, close := range closes {
+		history.Points = append(history.Points, BenchmarkPoint{
+			Timestamp: timestamps[i],
+			Close:     close,
+			Value:     close.Mul(scale).Round(2),
+		})
+	}
+	return history
+}
+
+// CalculateBenchmarkMetrics compares NAV history ordered by timestamp with benchmark closes by UTC date.
+// Portfolio returns are taken net of the external cash flows, and riskFreeRatePct is the annual
+// risk-free rate in percent used for alpha. Statistics need at least two daily returns on common dates.
+func CalculateBenchmarkMetrics(symbol string, history []NAVHistory, flows []CashFlow, benchmarkCloses map[time.Time]decimal.Decimal, riskFreeRatePct decimal.Decimal) *BenchmarkMetrics {
+	metrics := &BenchmarkMetrics{Symbol: symbol}
+
+	// Pair daily portfolio closes with the benchmark close of the same date
+	var entries []NAVHistory
+	var closes []float64
+	for _, entry := range DailyCloses(history) {
+		ts := entry.Timestamp.UTC()
+		close, ok := benchmarkCloses[time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)]
+		if !ok || !close.IsPositive() || !entry.NAV.IsPositive() {
+			continue
+		}
+		entries = append(entries, entry)
+		closes = append(closes, close.InexactFloat64())
+	}
+
+	if len(closes) >= 2 {
+		metrics.BenchmarkReturnPct = percentDecimal(closes[len(closes)-1]/closes[0] - 1)
+	}
+
+	portfolioReturns := make([]float64, 0, len(entries))
+	benchmarkReturns := make([]float64, 0, len(closes))
+	for i := 1; i < len(entries); i++ {
+		r, ok := modifiedDietzReturn(entries[i-1], entries[i], flows)
+		if !ok {
+			continue
+		}
+		portfolioReturns = append(portfolioReturns, r)
+		benchmarkReturns = append(benchmarkReturns, closes[i]/closes[i-1]-1)
+	}
+	metrics.ObservationCount = len(portfolioReturns)
+	if len(portfolioReturns) < 2 {
+		return metrics
+	}
+
+	portfolioMean := mean(portfolioReturns)
+	benchmarkMean := mean(benchmarkReturns)
+
+	var covariance, benchmarkVariance float64
+	active := make([]float64, len(portfolioReturns))
+	for i := range portfolioReturns {
+		covariance += (portfolioReturns[i] - portfolioMean) * (benchmarkReturns[i] - benchmarkMean)
+		benchmarkVariance += (benchmarkReturns[i] - benchmarkMean) * (benchmarkReturns[i] - benchmarkMean)
+		active[i] = portfolioReturns[i] - benchmarkReturns[i]
+	}
+
+	if benchmarkVariance > 0 {
+		beta := covariance / benchmarkVariance
+		metrics.Beta = ratioDecimal(beta)
+
+		dailyRiskFree := riskFreeRatePct.InexactFloat64() / 100 / TradingDaysPerYear
+		alpha := (portfolioMean - dailyRiskFree) - beta*(benchmarkMean-dailyRiskFree)
+		metrics.AlphaPct = percentDecimal(alpha * TradingDaysPerYear)
+	}
+
+	trackingError := sampleStdDev(active) * math.Sqrt(TradingDaysPerYear)
+	metrics.TrackingErrorPct = percentDecimal(trackingError)
+	if trackingError > 0 {
+		metrics.InformationRatio = ratioDecimal(mean(active) * TradingDaysPerYear / trackingError)
+	}
+
+	metrics.UpCapturePct = captureRatio(portfolioReturns, benchmarkReturns, func(r float64) bool { return r > 0 })
+	metrics.DownCapturePct = captureRatio(portfolioReturns, benchmarkReturns, func(r float64) bool { return r < 0 })
+
+	return metrics
+}
+
+// captureRatio compares the mean portfolio return with the mean benchmark return on the days the
+// benchmark return satisfies include, in percent
+func captureRatio(portfolioReturns, benchmarkReturns []float64, include func(float64) bool) *decimal.Decimal {
+	var portfolioDays, benchmarkDays []float64
+	for i, r := range benchmarkReturns {
+		if include(r) {
+			portfolioDays = append(portfolioDays, portfolioReturns[i])
+			benchmarkDays = append(benchmarkDays, r)
+		}
+	}
+	if len(benchmarkDays) == 0 {
+		return nil
+	}
+	return percentDecimal(mean(portfolioDays) / mean(benchmarkDays))
+}
+
+// mean calculates the arithmetic mean of values
+func mean(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
@@ -0,0 +1,161 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// CorporateActionType represents the kind of corporate action
+type CorporateActionType string
+
+const (
+	// CorporateActionSplit covers both splits and reverse splits, which have fewer new than old shares
+	CorporateActionSplit CorporateActionType = "split"
+)
+
+// CorporateActionSource represents where a corporate action was recorded from
+type CorporateActionSource string
+
+const (
+	CorporateActionSourceManual   CorporateActionSource = "manual"
+	CorporateActionSourceProvider CorporateActionSource = "provider"
+)
+
+// CorporateActionStatus represents whether a corporate action has been applied to portfolios
+type CorporateActionStatus string
+
+const (
+	CorporateActionPending CorporateActionStatus = "pending"
+	CorporateActionApplied CorporateActionStatus = "applied"
+)
+
+// CorporateAction represents a stock split: every OldShares held before the effective date become
+// NewShares
+type CorporateAction struct {
+	ID            uuid.UUID             `json:"id" db:"id"`
+	StockID       uuid.UUID             `json:"stock_id" db:"stock_id"`
+	Type          CorporateActionType   `json:"type" db:"type"`
+	NewShares     decimal.Decimal       `json:"new_shares" db:"new_shares"`
+	OldShares     decimal.Decimal       `json:"old_shares" db:"old_shares"`
+	EffectiveDate time.Time             `json:"effective_date" db:"effective_date"`
+	Source        CorporateActionSource `json:"source" db:"source"`
+	Status        CorporateActionStatus `json:"status" db:"status"`
+	AppliedAt     *time.Time            `json:"applied_at,omitempty" db:"applied_at"`
+	CreatedAt     time.Time             `json:"created_at" db:"created_at"`
+
+	// Related data (not stored in database)
+	Stock *Stock `json:"stock,omitempty"`
+}
+
+// CreateCorporateActionRequest represents the request to record a stock split
+type CreateCorporateActionRequest struct {
+	StockID       uuid.UUID           `json:"stock_id" validate:"required"`
+	Type          CorporateActionType `json:"type,omitempty" validate:"omitempty,oneof=split"`
+	NewShares     decimal.Decimal     `json:"new_shares" validate:"gt=0"`
+	OldShares     decimal.Decimal     `json:"old_shares" validate:"gt=0"`
+	EffectiveDate string              `json:"effective_date" validate:"required,datetime=2006-01-02"`
+}
+
+// ImportCorporateActionsRequest represents the request to import the splits of a stock from the
+// market data provider
+type ImportCorporateActionsRequest struct {
+	StockID uuid.UUID `json:"stock_id" validate:"required"`
+	From    string    `json:"from" validate:"required,datetime=2006-01-02"`
+	To      string    `json:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+}
+
+// CorporateActionAdjustment audits the change a corporate action made to a portfolio's holding
+type CorporateActionAdjustment struct {
+	ID                uuid.UUID       `json:"id" db:"id"`
+	CorporateActionID uuid.UUID       `json:"corporate_action_id" db:"corporate_action_id"`
+	PortfolioID       uuid.UUID       `json:"portfolio_id" db:"portfolio_id"`
+	StockID           uuid.UUID       `json:"stock_id" db:"stock_id"`
+	TransactionID     uuid.UUID       `json:"transaction_id" db:"transaction_id"`
+	QuantityBefore    decimal.Decimal `json:"quantity_before" db:"quantity_before"`
+	QuantityAfter     decimal.Decimal `json:"quantity_after" db:"quantity_after"`
+	EntryPriceBefore  decimal.Decimal `json:"entry_price_before" db:"entry_price_before"`
+	EntryPriceAfter   decimal.Decimal `json:"entry_price_after" db:"entry_price_after"`
+	CreatedAt         time.Time       `json:"created_at" db:"created_at"`
+}
+
+// CorporateActionResult represents a corporate action and the adjustments made applying it
+type CorporateActionResult struct {
+	Action      *CorporateAction             `json:"action"`
+	Adjustments []*CorporateActionAdjustment `json:"adjustments"`
+}
+
+// NewCorporateAction creates a pending corporate action from a request
+func NewCorporateAction(req *CreateCorporateActionRequest, source CorporateActionSource) (*CorporateAction, error) {
+	effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+	if err != nil {
+		return nil, &ValidationError{Field: "effective_date", Tag: "datetime", Message: "effective_date must be a date in YYYY-MM-DD format"}
+	}
+
+	if !req.NewShares.IsPositive() || !req.OldShares.IsPositive() {
+		return nil, &ValidationError{Field: "new_shares", Tag: "gt", Message: "new_shares and old_shares must be greater than 0"}
+	}
+	if req.NewShares.Equal(req.OldShares) {
+		return nil, &ValidationError{Field: "new_shares", Tag: "nefield", Message: "a split must change the number of shares"}
+	}
+
+	actionType := req.Type
+	if actionType == "" {
+		actionType = CorporateActionSplit
+	}
+
+	return &CorporateAction{
+		ID:            uuid.New(),
+		StockID:       req.StockID,
+		Type:          actionType,
+		NewShares:     req.NewShares,
+		OldShares:     req.OldShares,
+		EffectiveDate: effectiveDate,
+		Source:        source,
+		Status:        CorporateActionPending,
+		CreatedAt:     time.Now(),
+	}, nil
+}
+
+// Ratio returns the number of shares held after the split for each share held before
+func (a *CorporateAction) Ratio() decimal.Decimal {
+	return a.NewShares.Div(a.OldShares)
+}
+
+// Description describes the split, e.g. "4-for-1 split"
+func (a *CorporateAction) Description() string {
+	if a.NewShares.LessThan(a.OldShares) {
+		return fmt.Sprintf("%s-for-%s reverse split", a.NewShares, a.OldShares)
+	}
+	return fmt.Sprintf("%s-for-%s split", a.NewShares, a.OldShares)
+}
+
+// SplitTransaction returns the ledger entry splitting the shares held on the effective date, with the
+// new share count rounded down to decimals places. It returns nil when the share count is unchanged.
+// Fractional shares lost to rounding are not paid out as cash.
+func (a *CorporateAction) SplitTransaction(portfolioID uuid.UUID, held decimal.Decimal, decimals int32) *Transaction {
+	after := held.Mul(a.Ratio()).RoundFloor(decimals)
+	change := after.Sub(held)
+	if change.IsZero() {
+		return nil
+	}
+
+	notes := a.Description()
+	transaction := NewTradeTransaction(portfolioID, a.StockID, TransactionSplit, change, decimal.Zero, a.EffectiveDate)
+	transaction.Notes = &notes
+	return transaction
+}
+
+// SplitFactor returns the product of the ratios of the splits effective after at: the number of
+// shares each share held at that time has become
+func SplitFactor(actions []*CorporateAction, at time.Time) decimal.Decimal {
+	factor := decimal.NewFromInt(1)
+	for _, action := range actions {
+		if action.Type == CorporateActionSplit && action.EffectiveDate.After(at) {
+			factor = factor.Mul(action.Ratio())
+		}
+	}
+	return factor
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// DividendStatus represents whether a dividend has been paid to portfolios
+type DividendStatus string
+
+const (
+	DividendPending DividendStatus = "pending"
+	DividendPaid    DividendStatus = "paid"
+)
+
+// DividendEvent represents a cash dividend: every share held before the ex-date is paid
+// AmountPerShare, in the stock's currency, on the pay date
+type DividendEvent struct {
+	ID             uuid.UUID       `json:"id" db:"id"`
+	StockID        uuid.UUID       `json:"stock_id" db:"stock_id"`
+	ExDate         time.Time       `json:"ex_date" db:"ex_date"`
+	PayDate        time.Time       `json:"pay_date" db:"pay_date"`
+	AmountPerShare decimal.Decimal `json:"amount_per_share" db:"amount_per_share"`
+	Status         DividendStatus  `json:"status" db:"status"`
+	PaidAt         *time.Time      `json:"paid_at,omitempty" db:"paid_at"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+
+	// Related data (not stored in database)
+	Stock *Stock `json:"stock,omitempty"`
+}
+
+// CreateDividendEventRequest represents the request to record a dividend
+type CreateDividendEventRequest struct {
+	StockID        uuid.UUID       `json:"stock_id" validate:"required"`
+	ExDate         string          `json:"ex_date" validate:"required,datetime=2006-01-02"`
+	PayDate        string          `json:"pay_date" validate:"required,datetime=2006-01-02"`
+	AmountPerShare decimal.Decimal `json:"amount_per_share" validate:"gt=0"`
+}
+
+// DividendPayment records the dividend credited to a portfolio and the shares bought with it when
+// the portfolio reinvests dividends
+type DividendPayment struct {
+	ID                        uuid.UUID       `json:"id" db:"id"`
+	DividendEventID           uuid.UUID       `json:"dividend_event_id" db:"dividend_event_id"`
+	PortfolioID               uuid.UUID       `json:"portfolio_id" db:"portfolio_id"`
+	StockID                   uuid.UUID       `json:"stock_id" db:"stock_id"`
+	Shares                    decimal.Decimal `json:"shares" db:"shares"`
+	Amount                    decimal.Decimal `json:"amount" db:"amount"`
+	TransactionID             uuid.UUID       `json:"transaction_id" db:"transaction_id"`
+	ReinvestmentTransactionID *uuid.UUID      `json:"reinvestment_transaction_id,omitempty" db:"reinvestment_transaction_id"`
+	ReinvestedShares          decimal.Decimal `json:"reinvested_shares" db:"reinvested_shares"`
+	CreatedAt                 time.Time       `json:"created_at" db:"created_at"`
+}
+
+// DividendEventResult represents a dividend and the payments made processing it
+type DividendEventResult struct {
+	Event    *DividendEvent     `json:"event"`
+	Payments []*DividendPayment `json:"payments"`
+}
+
+// NewDividendEvent creates a pending dividend from a request
+func NewDividendEvent(req *CreateDividendEventRequest) (*DividendEvent, error) {
+	exDate, err := time.Parse("2006-01-02", req.ExDate)
+	if err != nil {
+		return nil, &ValidationError{Field: "ex_date", Tag: "datetime", Message: "ex_date must be a date in YYYY-MM-DD format"}
+	}
+	payDate, err := time.Parse("2006-01-02", req.PayDate)
+	if err != nil {
+		return nil, &ValidationError{Field: "pay_date", Tag: "datetime", Message: "pay_date must be a date in YYYY-MM-DD format"}
+	}
+	if payDate.Before(exDate) {
+		return nil, &ValidationError{Field: "pay_date", Tag: "gtefield", Message: "pay_date cannot be before ex_date"}
+	}
+
+	if !req.AmountPerShare.IsPositive() {
+		return nil, &ValidationError{Field: "amount_per_share", Tag: "gt", Message: "amount_per_share must be greater than 0"}
+	}
+
+	return &DividendEvent{
+		ID:             uuid.New(),
+		StockID:        req.StockID,
+		ExDate:         exDate,
+		PayDate:        payDate,
+		AmountPerShare: req.AmountPerShare,
+		Status:         DividendPending,
+		CreatedAt:      time.Now(),
+	}, nil
+}
+
+// DividendTransaction returns the ledger entry crediting the dividend on the shares held before the
+// ex-date, converted to the portfolio base currency at fxRate, with the shares and converted amount
+// per share recorded as its quantity and price. It returns nil when the dividend rounds to nothing.
+func (e *DividendEvent) DividendTransaction(portfolioID uuid.UUID, shares decimal.Decimal, fxRate decimal.Decimal) *Transaction {
+	perShare := e.AmountPerShare.Mul(fxRate)
+	amount := shares.Mul(perShare).Round(2)
+	if !amount.IsPositive() {
+		return nil
+	}
+
+	notes := fmt.Sprintf("Dividend of %s per share", e.AmountPerShare)
+	transaction := NewCashTransaction(portfolioID, TransactionDividend, amount, e.PayDate)
+	transaction.StockID = &e.StockID
+	transaction.Quantity = shares
+	transaction.Price = perShare
+	transaction.SetFXRate(fxRate)
+	transaction.Notes = &notes
+	return transaction
+}
+
+// ReinvestmentTransaction returns the buy reinvesting a dividend amount in the paying stock on the
+// pay date at a local price converted at fxRate, with shares rounded down to decimals places so the
+// buy never costs more than the dividend. It returns nil when the amount buys no shares.
+func (e *DividendEvent) ReinvestmentTransaction(portfolioID uuid.UUID, amount decimal.Decimal, localPrice decimal.Decimal, fxRate decimal.Decimal, decimals int32) *Transaction {
+	price := localPrice.Mul(fxRate)
+	if !price.IsPositive() {
+		return nil
+	}
+
+	shares := amount.Div(price).RoundFloor(decimals)
+	if !shares.IsPositive() {
+		return nil
+	}
+
+	notes := "Dividend reinvestment"
+	transaction := NewTradeTransaction(portfolioID, e.StockID, TransactionBuy, shares, price, e.PayDate)
+	transaction.SetFXRate(fxRate)
+	transaction.Notes = &notes
+	return transaction
+}
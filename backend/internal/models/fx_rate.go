@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultCurrency is the currency of stocks and portfolios created without one
+const DefaultCurrency = "USD"
+
+// FXRate represents the exchange rate of a currency pair on a date: one unit of BaseCurrency is
+// worth Rate units of QuoteCurrency
+type FXRate struct {
+	BaseCurrency  string          `json:"base_currency" db:"base_currency"`
+	QuoteCurrency string          `json:"quote_currency" db:"quote_currency"`
+	RateDate      time.Time       `json:"rate_date" db:"rate_date"`
+	Rate          decimal.Decimal `json:"rate" db:"rate"`
+	Source        string          `json:"source" db:"source"`
+	CreatedAt     time.Time       `json:"created_at" db:"created_at"`
+}
+
+// CurrencyOrDefault returns currency, or DefaultCurrency for records stored before currencies
+// were tracked
+func CurrencyOrDefault(currency string) string {
+	if currency == "" {
+		return DefaultCurrency
+	}
+	return currency
+}
@@ -8,7 +8,13 @@ package models
 // - strategy.go: Strategy entity and related DTOs  
 // - stock.go: Stock entity and related DTOs
 // - signal.go: Signal entity and related DTOs
+// - signal_rule.go: Technical signal rules and their evaluation results
 // - portfolio.go: Portfolio entity and related DTOs
 // - position.go: Position entity and related DTOs
 // - nav_history.go: NAVHistory entity and related DTOs
+// - transaction.go: Transaction ledger entity, related DTOs and ledger replay
+// - allocation_config.go: Stored portfolio allocation settings and related DTOs
+// - rebalance.go: Rebalance plan and trade list calculation
+// - rebalance_policy.go: Rebalance policies and the runs they trigger
+// - backtest.go: Backtest jobs, their requests and simulated results
 // - validation.go: Validation utilities and custom validators
\ No newline at end of file
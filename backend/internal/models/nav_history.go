@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,10 +15,22 @@ type NAVHistory struct {
 	NAV         decimal.Decimal  `json:"nav" db:"nav" validate:"required,gte=0"`
 	PnL         decimal.Decimal  `json:"pnl" db:"pnl"`
 	Drawdown    *decimal.Decimal `json:"drawdown" db:"drawdown"`
+	Cash        decimal.Decimal  `json:"cash" db:"cash"`
+	// RealizedPnL is the gain from every lot sold so far; UnrealizedPnL that of the lots still held
+	RealizedPnL   decimal.Decimal `json:"realized_pnl" db:"realized_pnl"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl" db:"unrealized_pnl"`
+	// DividendIncome is the dividend income received so far
+	DividendIncome decimal.Decimal `json:"dividend_income" db:"dividend_income"`
+	// FXPnL is the part of UnrealizedPnL caused by exchange rates moving since holdings quoted in
+	// other currencies were bought
+	FXPnL decimal.Decimal `json:"fx_pnl" db:"fx_pnl"`
 	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
 	
 	// Related data (not stored in database)
 	Portfolio *Portfolio `json:"portfolio,omitempty"`
+	
+	// Positions are the holdings at the timestamp, stored as position snapshots with the entry
+	Positions []PositionSnapshot `json:"positions,omitempty"`
 }
 
 // CreateNAVHistoryRequest represents the request to create a new NAV history entry
@@ -27,6 +40,7 @@ type CreateNAVHistoryRequest struct {
 	NAV         decimal.Decimal  `json:"nav" validate:"required,gte=0"`
 	PnL         decimal.Decimal  `json:"pnl"`
 	Drawdown    *decimal.Decimal `json:"drawdown,omitempty"`
+	Cash        decimal.Decimal  `json:"cash"`
 }
 
 // NAVHistoryResponse represents the NAV history data returned in API responses
@@ -36,6 +50,11 @@ type NAVHistoryResponse struct {
 	NAV         decimal.Decimal  `json:"nav"`
 	PnL         decimal.Decimal  `json:"pnl"`
 	Drawdown    *decimal.Decimal `json:"drawdown"`
+	Cash        decimal.Decimal  `json:"cash"`
+	RealizedPnL   decimal.Decimal `json:"realized_pnl"`
+	UnrealizedPnL decimal.Decimal `json:"unrealized_pnl"`
+	DividendIncome decimal.Decimal `json:"dividend_income"`
+	FXPnL       decimal.Decimal  `json:"fx_pnl"`
 	CreatedAt   time.Time        `json:"created_at"`
 	Portfolio   *Portfolio       `json:"portfolio,omitempty"`
 }
@@ -49,8 +68,26 @@ type PerformanceMetrics struct {
 	CurrentDrawdown   *decimal.Decimal `json:"current_drawdown"`
 	VolatilityPct     *decimal.Decimal `json:"volatility_pct,omitempty"`
 	SharpeRatio       *decimal.Decimal `json:"sharpe_ratio,omitempty"`
+	SortinoRatio      *decimal.Decimal `json:"sortino_ratio,omitempty"`
+	CalmarRatio       *decimal.Decimal `json:"calmar_ratio,omitempty"`
+	// MaxDrawdownDays is the longest time in days the portfolio spent below a previous high
+	MaxDrawdownDays   int              `json:"max_drawdown_days"`
+	RiskFreeRatePct   decimal.Decimal  `json:"risk_free_rate_pct"`
 	DaysActive        int              `json:"days_active"`
 	HighWaterMark     decimal.Decimal  `json:"high_water_mark"`
+	// DividendIncome is the dividend income received to date, included in the returns above;
+	// DividendReturnPct is its share of the initial investment
+	DividendIncome    decimal.Decimal  `json:"dividend_income"`
+	DividendReturnPct decimal.Decimal  `json:"dividend_return_pct"`
+	// FXPnL is the latest P&L from exchange rate moves; TotalReturnPct splits into FXReturnPct
+	// from it and LocalReturnPct from everything else, both as shares of the initial investment
+	FXPnL             decimal.Decimal  `json:"fx_pnl"`
+	FXReturnPct       decimal.Decimal  `json:"fx_return_pct"`
+	LocalReturnPct    decimal.Decimal  `json:"local_return_pct"`
+	// Returns holds cash flow adjusted returns over the requested period
+	Returns           *PeriodReturns   `json:"returns,omitempty"`
+	// Benchmark compares the portfolio with its benchmark when one is set
+	Benchmark         *BenchmarkMetrics `json:"benchmark,omitempty"`
 }
 
 // ToResponse converts a NAVHistory to NAVHistoryResponse
@@ -61,6 +98,11 @@ func (n *NAVHistory) ToResponse() *NAVHistoryResponse {
 		NAV:         n.NAV,
 		PnL:         n.PnL,
 		Drawdown:    n.Drawdown,
+		Cash:        n.Cash,
+		RealizedPnL:   n.RealizedPnL,
+		UnrealizedPnL: n.UnrealizedPnL,
+		DividendIncome: n.DividendIncome,
+		FXPnL:       n.FXPnL,
 		CreatedAt:   n.CreatedAt,
 		Portfolio:   n.Portfolio,
 	}
@@ -73,6 +115,7 @@ func (n *NAVHistory) FromCreateRequest(req *CreateNAVHistoryRequest) {
 	n.NAV = req.NAV
 	n.PnL = req.PnL
 	n.Drawdown = req.Drawdown
+	n.Cash = req.Cash
 	n.CreatedAt = time.Now()
 }
 
@@ -88,10 +131,16 @@ func (n *NAVHistory) CalculateDrawdown(highWaterMark decimal.Decimal) {
 	}
 }
 
-// CalculatePerformanceMetrics calculates performance metrics from NAV history
-func CalculatePerformanceMetrics(history []NAVHistory, initialInvestment decimal.Decimal) *PerformanceMetrics {
+// TradingDaysPerYear is used to annualize statistics computed from daily returns
+const TradingDaysPerYear = 252
+
+// CalculatePerformanceMetrics calculates performance metrics from NAV history ordered by timestamp.
+// Return statistics are computed from daily closes so intraday updates don't distort them, and the
+// external cash flows are taken out of every return so deposits and withdrawals don't count as gains
+// or losses; riskFreeRatePct is the annual risk-free rate in percent used for the Sharpe and Sortino ratios.
+func CalculatePerformanceMetrics(history []NAVHistory, flows []CashFlow, initialInvestment decimal.Decimal, riskFreeRatePct decimal.Decimal) *PerformanceMetrics {
 	if len(history) == 0 {
-		return &PerformanceMetrics{}
+		return &PerformanceMetrics{RiskFreeRatePct: riskFreeRatePct}
 	}
 	
 	latest := history[len(history)-1]
@@ -127,12 +176,24 @@ func CalculatePerformanceMetrics(history []NAVHistory, initialInvestment decimal
 		daysActive = int(lastEntry.Timestamp.Sub(firstEntry.Timestamp).Hours() / 24)
 	}
 	
+	daily := DailyCloses(history)
+	
 	metrics := &PerformanceMetrics{
-		TotalReturn:    totalReturn,
-		TotalReturnPct: totalReturnPct,
-		MaxDrawdown:    maxDrawdown,
-		DaysActive:     daysActive,
-		HighWaterMark:  highWaterMark,
+		TotalReturn:     totalReturn,
+		TotalReturnPct:  totalReturnPct,
+		MaxDrawdown:     maxDrawdown,
+		MaxDrawdownDays: maxDrawdownDays(daily, initialInvestment),
+		RiskFreeRatePct: riskFreeRatePct,
+		DaysActive:      daysActive,
+		HighWaterMark:   highWaterMark,
+		FXPnL:           latest.FXPnL,
+		LocalReturnPct:  totalReturnPct,
+	}
+	
+	// Split the total return into the part from exchange rate moves and the rest
+	if initialInvestment.GreaterThan(decimal.Zero) {
+		metrics.FXReturnPct = latest.FXPnL.Div(initialInvestment).Mul(decimal.NewFromInt(100))
+		metrics.LocalReturnPct = totalReturnPct.Sub(metrics.FXReturnPct)
 	}
 	
 	// Calculate current drawdown
@@ -141,19 +202,140 @@ func CalculatePerformanceMetrics(history []NAVHistory, initialInvestment decimal
 		metrics.CurrentDrawdown = &currentDrawdown
 	}
 	
-	// Calculate annualized return if we have enough data
-	if daysActive > 0 {
-		years := decimal.NewFromFloat(float64(daysActive) / 365.25)
-		if years.GreaterThan(decimal.Zero) && initialInvestment.GreaterThan(decimal.Zero) {
-			// Annualized return = (Final Value / Initial Value)^(1/years) - 1
-			finalRatio := latest.NAV.Div(initialInvestment)
-			if finalRatio.GreaterThan(decimal.Zero) {
-				// Simplified calculation - for more accurate results, would need proper power function
-				annualizedReturn := finalRatio.Sub(decimal.NewFromInt(1)).Div(years).Mul(decimal.NewFromInt(100))
-				metrics.AnnualizedReturn = &annualizedReturn
+	// Annualized return is the compound annual growth rate of the time-weighted return: growth^(1/years) - 1
+	if growth, ok := timeWeightedGrowth(history, flows); ok && daysActive > 0 && growth > 0 {
+		years := float64(daysActive) / 365.25
+		metrics.AnnualizedReturn = percentDecimal(math.Pow(growth, 1/years) - 1)
+	}
+	
+	// Volatility, Sharpe and Sortino need at least two daily returns
+	returns := dailyReturns(daily, flows)
+	if len(returns) >= 2 {
+		dailyRiskFree := riskFreeRatePct.InexactFloat64() / 100 / TradingDaysPerYear
+		annualization := math.Sqrt(TradingDaysPerYear)
+		
+		var meanExcess, downsideSquares float64
+		for _, r := range returns {
+			excess := r - dailyRiskFree
+			meanExcess += excess
+			if excess < 0 {
+				downsideSquares += excess * excess
 			}
 		}
+		meanExcess /= float64(len(returns))
+		
+		stdDev := sampleStdDev(returns)
+		metrics.VolatilityPct = percentDecimal(stdDev * annualization)
+		if stdDev > 0 {
+			metrics.SharpeRatio = ratioDecimal(meanExcess / stdDev * annualization)
+		}
+		
+		downsideDev := math.Sqrt(downsideSquares / float64(len(returns)))
+		if downsideDev > 0 {
+			metrics.SortinoRatio = ratioDecimal(meanExcess / downsideDev * annualization)
+		}
+	}
+	
+	// Calmar ratio is the annualized return over the magnitude of the max drawdown
+	if metrics.AnnualizedReturn != nil && maxDrawdown != nil && maxDrawdown.IsNegative() {
+		calmar := metrics.AnnualizedReturn.Div(maxDrawdown.Abs()).Round(4)
+		metrics.CalmarRatio = &calmar
 	}
 	
 	return metrics
+}
+
+// DailyCloses resamples NAV history ordered by timestamp to the last entry of each UTC trading day,
+// dropping weekend entries as markets are closed
+func DailyCloses(history []NAVHistory) []NAVHistory {
+	var daily []NAVHistory
+	var lastDay time.Time
+	for _, entry := range history {
+		ts := entry.Timestamp.UTC()
+		if ts.Weekday() == time.Saturday || ts.Weekday() == time.Sunday {
+			continue
+		}
+		day := time.Date(ts.Year(), ts.Month(), ts.Day(), 0, 0, 0, 0, time.UTC)
+		if len(daily) > 0 && day.Equal(lastDay) {
+			daily[len(daily)-1] = entry
+			continue
+		}
+		daily = append(daily, entry)
+		lastDay = day
+	}
+	return daily
+}
+
+// dailyReturns calculates the returns between consecutive daily closes net of cash flows
+func dailyReturns(daily []NAVHistory, flows []CashFlow) []float64 {
+	var returns []float64
+	for i := 1; i < len(daily); i++ {
+		if r, ok := modifiedDietzReturn(daily[i-1], daily[i], flows); ok {
+			returns = append(returns, r)
+		}
+	}
+	return returns
+}
+
+// maxDrawdownDays finds the longest stretch in days from a high water mark until NAV recovers to it,
+// counting an unrecovered drawdown up to the last close
+func maxDrawdownDays(daily []NAVHistory, initialInvestment decimal.Decimal) int {
+	if len(daily) == 0 {
+		return 0
+	}
+	
+	highWaterMark := initialInvestment
+	peakTime := daily[0].Timestamp
+	longest := 0
+	underwater := false
+	for _, entry := range daily {
+		if entry.NAV.GreaterThanOrEqual(highWaterMark) {
+			if underwater {
+				longest = max(longest, int(entry.Timestamp.Sub(peakTime).Hours()/24))
+				underwater = false
+			}
+			highWaterMark = entry.NAV
+			peakTime = entry.Timestamp
+			continue
+		}
+		underwater = true
+	}
+	
+	if underwater {
+		longest = max(longest, int(daily[len(daily)-1].Timestamp.Sub(peakTime).Hours()/24))
+	}
+	return longest
+}
+
+// sampleStdDev calculates the sample standard deviation of values
+func sampleStdDev(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
+
+// percentDecimal converts a fraction to a percentage rounded to 4 decimal places
+func percentDecimal(fraction float64) *decimal.Decimal {
+	if math.IsNaN(fraction) || math.IsInf(fraction, 0) {
+		return nil
+	}
+	value := decimal.NewFromFloat(fraction * 100).Round(4)
+	return &value
+}
+
+// ratioDecimal converts a ratio to a decimal rounded to 4 decimal places
+func ratioDecimal(ratio float64) *decimal.Decimal {
+	if math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+		return nil
+	}
+	value := decimal.NewFromFloat(ratio).Round(4)
+	return &value
 }
\ No newline at end of file
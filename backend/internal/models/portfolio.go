@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,12 +14,26 @@ type Portfolio struct {
 	UserID          uuid.UUID       `json:"user_id" db:"user_id"`
 	Name            string          `json:"name" db:"name" validate:"required,min=1,max=255"`
 	TotalInvestment decimal.Decimal `json:"total_investment" db:"total_investment" validate:"required,gt=0"`
+	CashBalance     decimal.Decimal `json:"cash_balance" db:"cash_balance"`
+	// FractionalShares lets allocations buy fractions of a share, rounded down to SharePrecision decimal places
+	FractionalShares bool           `json:"fractional_shares" db:"fractional_shares"`
+	SharePrecision   int32          `json:"share_precision" db:"share_precision" validate:"gte=0,lte=8"`
+	// BenchmarkSymbol is the ticker, such as SPY or QQQ, performance is compared against
+	BenchmarkSymbol *string         `json:"benchmark_symbol,omitempty" db:"benchmark_symbol"`
+	// LotReliefMethod chooses the tax lots sells relieve; sells keep the lots they relieved when it changes
+	LotReliefMethod LotReliefMethod `json:"lot_relief_method" db:"lot_relief_method"`
+	// DRIPEnabled reinvests dividends in the paying stock instead of keeping them as cash
+	DRIPEnabled     bool            `json:"drip_enabled" db:"drip_enabled"`
+	// BaseCurrency is the currency the portfolio is valued in; holdings quoted in other currencies are converted to it
+	BaseCurrency    string          `json:"base_currency" db:"base_currency"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
 	
 	// Related data (not stored in database)
-	Positions  []Position   `json:"positions,omitempty"`
-	NAVHistory []NAVHistory `json:"nav_history,omitempty"`
+	Positions        []Position                 `json:"positions,omitempty"`
+	NAVHistory       []NAVHistory               `json:"nav_history,omitempty"`
+	AllocationConfig *PortfolioAllocationConfig `json:"allocation_config,omitempty"`
+	Gains            *GainsSummary              `json:"gains,omitempty"`
 }
 
 // CreatePortfolioRequest represents the request to create a new portfolio
@@ -26,12 +41,27 @@ type CreatePortfolioRequest struct {
 	Name            string                   `json:"name" validate:"required,min=1,max=255"`
 	TotalInvestment decimal.Decimal          `json:"total_investment" validate:"required,gt=0"`
 	Positions       []CreatePositionRequest  `json:"positions" validate:"required,min=1,dive"`
+	FractionalShares bool                    `json:"fractional_shares,omitempty"`
+	SharePrecision   int32                   `json:"share_precision,omitempty" validate:"gte=0,lte=8"`
+	BenchmarkSymbol  *string                 `json:"benchmark_symbol,omitempty" validate:"omitempty,min=1,max=20"`
+	LotReliefMethod  LotReliefMethod         `json:"lot_relief_method,omitempty" validate:"omitempty,oneof=fifo lifo specific_id"`
+	DRIPEnabled      bool                    `json:"drip_enabled,omitempty"`
+	// BaseCurrency defaults to USD and cannot be changed once the portfolio is created
+	BaseCurrency     string                  `json:"base_currency,omitempty" validate:"omitempty,len=3,uppercase"`
+	// AllocationRequest is the request the positions were previewed with; stored for rebalancing
+	AllocationRequest *AllocationRequest `json:"allocation_request,omitempty"`
 }
 
 // UpdatePortfolioRequest represents the request to update a portfolio
 type UpdatePortfolioRequest struct {
 	Name            *string          `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
 	TotalInvestment *decimal.Decimal `json:"total_investment,omitempty" validate:"omitempty,gt=0"`
+	FractionalShares *bool           `json:"fractional_shares,omitempty"`
+	SharePrecision   *int32          `json:"share_precision,omitempty" validate:"omitempty,gte=0,lte=8"`
+	// BenchmarkSymbol sets the benchmark ticker; an empty string removes the benchmark
+	BenchmarkSymbol  *string         `json:"benchmark_symbol,omitempty" validate:"omitempty,max=20"`
+	LotReliefMethod  *LotReliefMethod `json:"lot_relief_method,omitempty" validate:"omitempty,oneof=fifo lifo specific_id"`
+	DRIPEnabled      *bool            `json:"drip_enabled,omitempty"`
 }
 
 // PortfolioResponse represents the portfolio data returned in API responses
@@ -40,10 +70,19 @@ type PortfolioResponse struct {
 	UserID          uuid.UUID       `json:"user_id"`
 	Name            string          `json:"name"`
 	TotalInvestment decimal.Decimal `json:"total_investment"`
+	CashBalance     decimal.Decimal `json:"cash_balance"`
+	FractionalShares bool           `json:"fractional_shares"`
+	SharePrecision   int32          `json:"share_precision"`
+	BenchmarkSymbol *string         `json:"benchmark_symbol,omitempty"`
+	LotReliefMethod LotReliefMethod `json:"lot_relief_method"`
+	DRIPEnabled     bool            `json:"drip_enabled"`
+	BaseCurrency    string          `json:"base_currency"`
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 	Positions       []Position      `json:"positions,omitempty"`
 	NAVHistory      []NAVHistory    `json:"nav_history,omitempty"`
+	AllocationConfig *PortfolioAllocationConfig `json:"allocation_config,omitempty"`
+	Gains           *GainsSummary   `json:"gains,omitempty"`
 	CurrentNAV      *decimal.Decimal `json:"current_nav,omitempty"`
 	TotalPnL        *decimal.Decimal `json:"total_pnl,omitempty"`
 	MaxDrawdown     *decimal.Decimal `json:"max_drawdown,omitempty"`
@@ -56,6 +95,33 @@ type AllocationPreview struct {
 	UnallocatedCash decimal.Decimal      `json:"unallocated_cash"`
 	TotalAllocated  decimal.Decimal      `json:"total_allocated"`
 	Constraints     AllocationConstraints `json:"constraints"`
+	CappedStocks    []uuid.UUID          `json:"capped_stocks,omitempty"`
+	Violations      []ConstraintViolation `json:"violations,omitempty"`
+	Warnings        []ConstraintViolation `json:"warnings,omitempty"`
+	Suggestions     []string             `json:"suggestions,omitempty"`
+	CashSweep       *CashSweepResult     `json:"cash_sweep,omitempty"`
+	// ExitStocks lists strategy stocks whose latest signal is Sell or StrongSell
+	ExitStocks      []uuid.UUID          `json:"exit_stocks,omitempty"`
+}
+
+// CashSweepResult reports how the residual cash sweep changed an allocation preview. Tracking error is
+// the root-sum-square gap, in percentage points, between actual and ideal stock weights.
+type CashSweepResult struct {
+	UnallocatedCashBefore decimal.Decimal `json:"unallocated_cash_before"`
+	UnallocatedCashAfter  decimal.Decimal `json:"unallocated_cash_after"`
+	TrackingErrorBefore   decimal.Decimal `json:"tracking_error_before"`
+	TrackingErrorAfter    decimal.Decimal `json:"tracking_error_after"`
+	SharesAdded           decimal.Decimal `json:"shares_added"`
+}
+
+// ConstraintViolation represents a constraint violation with suggestions
+type ConstraintViolation struct {
+	Type         string          `json:"type"`
+	Message      string          `json:"message"`
+	StockTicker  string          `json:"stock_ticker,omitempty"`
+	CurrentValue decimal.Decimal `json:"current_value,omitempty"`
+	LimitValue   decimal.Decimal `json:"limit_value,omitempty"`
+	Suggestions  []string        `json:"suggestions"`
 }
 
 // StockAllocation represents the allocation for a single stock
@@ -66,9 +132,15 @@ type StockAllocation struct {
 	Weight          decimal.Decimal `json:"weight"`
 	AllocationValue decimal.Decimal `json:"allocation_value"`
 	Price           decimal.Decimal `json:"price"`
-	Quantity        int             `json:"quantity"`
+	Quantity        decimal.Decimal `json:"quantity"`
 	ActualValue     decimal.Decimal `json:"actual_value"`
 	StrategyContrib map[string]decimal.Decimal `json:"strategy_contrib"`
+	Capped          bool            `json:"capped,omitempty"`
+	// Price and values are in the base currency; LocalPrice is the quote in the stock's Currency,
+	// converted at FXRate
+	Currency        string          `json:"currency,omitempty"`
+	LocalPrice      decimal.Decimal `json:"local_price"`
+	FXRate          decimal.Decimal `json:"fx_rate"`
 }
 
 // AllocationConstraints represents constraints for portfolio allocation
@@ -83,6 +155,17 @@ type AllocationRequest struct {
 	TotalInvestment decimal.Decimal       `json:"total_investment" validate:"required,gt=0"`
 	Constraints     AllocationConstraints `json:"constraints"`
 	ExcludedStocks  []uuid.UUID          `json:"excluded_stocks,omitempty"`
+	// Strict makes constraint violations fail the request instead of being reported in the preview
+	Strict bool `json:"strict,omitempty"`
+	// FractionalShares sizes positions in fractions of a share, rounded down to SharePrecision decimal places
+	FractionalShares bool  `json:"fractional_shares,omitempty"`
+	SharePrecision   int32 `json:"share_precision,omitempty" validate:"gte=0,lte=8"`
+	// SweepResidualCash spends cash left over by rounding on more shares of the most underweight stocks
+	SweepResidualCash bool `json:"sweep_residual_cash,omitempty"`
+	// StrongBuyMultiplier scales the stock weight of StrongBuy names within their strategy; zero leaves them unchanged
+	StrongBuyMultiplier decimal.Decimal `json:"strong_buy_multiplier,omitempty"`
+	// BaseCurrency is the currency allocation values and prices are in; it defaults to USD
+	BaseCurrency string `json:"base_currency,omitempty" validate:"omitempty,len=3,uppercase"`
 }
 
 // ToResponse converts a Portfolio to PortfolioResponse
@@ -92,10 +175,19 @@ func (p *Portfolio) ToResponse() *PortfolioResponse {
 		UserID:          p.UserID,
 		Name:            p.Name,
 		TotalInvestment: p.TotalInvestment,
+		CashBalance:     p.CashBalance,
+		FractionalShares: p.FractionalShares,
+		SharePrecision:   p.SharePrecision,
+		BenchmarkSymbol: p.BenchmarkSymbol,
+		LotReliefMethod: p.LotReliefMethod,
+		DRIPEnabled:     p.DRIPEnabled,
+		BaseCurrency:    CurrencyOrDefault(p.BaseCurrency),
 		CreatedAt:       p.CreatedAt,
 		UpdatedAt:       p.UpdatedAt,
 		Positions:       p.Positions,
 		NAVHistory:      p.NAVHistory,
+		AllocationConfig: p.AllocationConfig,
+		Gains:           p.Gains,
 	}
 	
 	// Calculate current metrics if NAV history exists
@@ -115,10 +207,27 @@ func (p *Portfolio) FromCreateRequest(req *CreatePortfolioRequest, userID uuid.U
 	p.UserID = userID
 	p.Name = req.Name
 	p.TotalInvestment = req.TotalInvestment
+	p.FractionalShares = req.FractionalShares
+	p.SharePrecision = req.SharePrecision
+	p.BenchmarkSymbol = normalizeBenchmarkSymbol(req.BenchmarkSymbol)
+	p.LotReliefMethod = req.LotReliefMethod
+	if p.LotReliefMethod == "" {
+		p.LotReliefMethod = LotReliefFIFO
+	}
+	p.DRIPEnabled = req.DRIPEnabled
+	p.BaseCurrency = CurrencyOrDefault(req.BaseCurrency)
 	p.CreatedAt = time.Now()
 	p.UpdatedAt = time.Now()
 }
 
+// ShareDecimals returns the number of decimal places share quantities may have in the portfolio
+func (p *Portfolio) ShareDecimals() int32 {
+	if !p.FractionalShares {
+		return 0
+	}
+	return p.SharePrecision
+}
+
 // ApplyUpdate applies an UpdatePortfolioRequest to the portfolio
 func (p *Portfolio) ApplyUpdate(req *UpdatePortfolioRequest) {
 	if req.Name != nil {
@@ -127,5 +236,32 @@ func (p *Portfolio) ApplyUpdate(req *UpdatePortfolioRequest) {
 	if req.TotalInvestment != nil {
 		p.TotalInvestment = *req.TotalInvestment
 	}
+	if req.FractionalShares != nil {
+		p.FractionalShares = *req.FractionalShares
+	}
+	if req.SharePrecision != nil {
+		p.SharePrecision = *req.SharePrecision
+	}
+	if req.BenchmarkSymbol != nil {
+		p.BenchmarkSymbol = normalizeBenchmarkSymbol(req.BenchmarkSymbol)
+	}
+	if req.LotReliefMethod != nil {
+		p.LotReliefMethod = *req.LotReliefMethod
+	}
+	if req.DRIPEnabled != nil {
+		p.DRIPEnabled = *req.DRIPEnabled
+	}
 	p.UpdatedAt = time.Now()
+}
+
+// normalizeBenchmarkSymbol upper-cases a benchmark ticker, returning nil for an empty one
+func normalizeBenchmarkSymbol(symbol *string) *string {
+	if symbol == nil {
+		return nil
+	}
+	normalized := strings.ToUpper(strings.TrimSpace(*symbol))
+	if normalized == "" {
+		return nil
+	}
+	return &normalized
 }
\ No newline at end of file
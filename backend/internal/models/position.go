@@ -12,9 +12,11 @@ import (
 type Position struct {
 	PortfolioID     uuid.UUID       `json:"portfolio_id" db:"portfolio_id"`
 	StockID         uuid.UUID       `json:"stock_id" db:"stock_id"`
-	Quantity        int             `json:"quantity" db:"quantity" validate:"required,gt=0"`
+	Quantity        decimal.Decimal `json:"quantity" db:"quantity" validate:"required,gt=0"`
 	EntryPrice      decimal.Decimal `json:"entry_price" db:"entry_price" validate:"required,gt=0"`
 	AllocationValue decimal.Decimal `json:"allocation_value" db:"allocation_value" validate:"required,gt=0"`
+	// EntryFXRate is the cost-weighted rate the shares were bought at, converting the stock's currency to the portfolio's
+	EntryFXRate     decimal.Decimal `json:"entry_fx_rate" db:"entry_fx_rate"`
 	StrategyContrib json.RawMessage `json:"strategy_contrib" db:"strategy_contrib"`
 	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
@@ -25,21 +27,31 @@ type Position struct {
 	CurrentValue        *decimal.Decimal `json:"current_value,omitempty"`
 	PnL                 *decimal.Decimal `json:"pnl,omitempty"`
 	PnLPercentage       *decimal.Decimal `json:"pnl_percentage,omitempty"`
+	// LocalPrice, FXRate, LocalPnL and FXPnL are set for stocks quoted in another currency than
+	// the portfolio's; PnL is LocalPnL, the return on the stock at the entry rate, plus FXPnL
+	LocalPrice          *decimal.Decimal `json:"local_price,omitempty"`
+	FXRate              *decimal.Decimal `json:"fx_rate,omitempty"`
+	LocalPnL            *decimal.Decimal `json:"local_pnl,omitempty"`
+	FXPnL               *decimal.Decimal `json:"fx_pnl,omitempty"`
 	StrategyContribMap  map[string]decimal.Decimal `json:"strategy_contrib_map,omitempty"`
+	Lots                []TaxLot         `json:"lots,omitempty"`
+	Gains               *GainsSummary    `json:"gains,omitempty"`
 }
 
 // CreatePositionRequest represents the request to create a new position
 type CreatePositionRequest struct {
 	StockID         uuid.UUID                   `json:"stock_id" validate:"required"`
-	Quantity        int                         `json:"quantity" validate:"required,gt=0"`
+	Quantity        decimal.Decimal             `json:"quantity" validate:"required,gt=0"`
 	EntryPrice      decimal.Decimal             `json:"entry_price" validate:"required,gt=0"`
 	AllocationValue decimal.Decimal             `json:"allocation_value" validate:"required,gt=0"`
 	StrategyContrib map[string]decimal.Decimal  `json:"strategy_contrib" validate:"required"`
+	// FXRate is the allocation preview's rate converting the stock's currency to the portfolio's; it defaults to 1
+	FXRate          *decimal.Decimal            `json:"fx_rate,omitempty" validate:"omitempty,gt=0"`
 }
 
 // UpdatePositionRequest represents the request to update a position
 type UpdatePositionRequest struct {
-	Quantity        *int             `json:"quantity,omitempty" validate:"omitempty,gt=0"`
+	Quantity        *decimal.Decimal `json:"quantity,omitempty" validate:"omitempty,gt=0"`
 	EntryPrice      *decimal.Decimal `json:"entry_price,omitempty" validate:"omitempty,gt=0"`
 	AllocationValue *decimal.Decimal `json:"allocation_value,omitempty" validate:"omitempty,gt=0"`
 }
@@ -48,9 +60,10 @@ type UpdatePositionRequest struct {
 type PositionResponse struct {
 	PortfolioID         uuid.UUID                   `json:"portfolio_id"`
 	StockID             uuid.UUID                   `json:"stock_id"`
-	Quantity            int                         `json:"quantity"`
+	Quantity            decimal.Decimal             `json:"quantity"`
 	EntryPrice          decimal.Decimal             `json:"entry_price"`
 	AllocationValue     decimal.Decimal             `json:"allocation_value"`
+	EntryFXRate         decimal.Decimal             `json:"entry_fx_rate"`
 	StrategyContrib     map[string]decimal.Decimal  `json:"strategy_contrib"`
 	CreatedAt           time.Time                   `json:"created_at"`
 	UpdatedAt           time.Time                   `json:"updated_at"`
@@ -59,6 +72,12 @@ type PositionResponse struct {
 	CurrentValue        *decimal.Decimal            `json:"current_value,omitempty"`
 	PnL                 *decimal.Decimal            `json:"pnl,omitempty"`
 	PnLPercentage       *decimal.Decimal            `json:"pnl_percentage,omitempty"`
+	LocalPrice          *decimal.Decimal            `json:"local_price,omitempty"`
+	FXRate              *decimal.Decimal            `json:"fx_rate,omitempty"`
+	LocalPnL            *decimal.Decimal            `json:"local_pnl,omitempty"`
+	FXPnL               *decimal.Decimal            `json:"fx_pnl,omitempty"`
+	Lots                []TaxLot                    `json:"lots,omitempty"`
+	Gains               *GainsSummary               `json:"gains,omitempty"`
 }
 
 // ToResponse converts a Position to PositionResponse
@@ -69,6 +88,7 @@ func (p *Position) ToResponse() *PositionResponse {
 		Quantity:        p.Quantity,
 		EntryPrice:      p.EntryPrice,
 		AllocationValue: p.AllocationValue,
+		EntryFXRate:     p.EntryFXRate,
 		CreatedAt:       p.CreatedAt,
 		UpdatedAt:       p.UpdatedAt,
 		Stock:           p.Stock,
@@ -76,6 +96,12 @@ func (p *Position) ToResponse() *PositionResponse {
 		CurrentValue:    p.CurrentValue,
 		PnL:             p.PnL,
 		PnLPercentage:   p.PnLPercentage,
+		LocalPrice:      p.LocalPrice,
+		FXRate:          p.FXRate,
+		LocalPnL:        p.LocalPnL,
+		FXPnL:           p.FXPnL,
+		Lots:            p.Lots,
+		Gains:           p.Gains,
 	}
 	
 	// Convert strategy contribution from JSON to map
@@ -98,6 +124,10 @@ func (p *Position) FromCreateRequest(req *CreatePositionRequest, portfolioID uui
 	p.Quantity = req.Quantity
 	p.EntryPrice = req.EntryPrice
 	p.AllocationValue = req.AllocationValue
+	p.EntryFXRate = decimal.NewFromInt(1)
+	if req.FXRate != nil {
+		p.EntryFXRate = *req.FXRate
+	}
 	p.CreatedAt = time.Now()
 	p.UpdatedAt = time.Now()
 	
@@ -131,7 +161,7 @@ func (p *Position) CalculateMetrics(currentPrice decimal.Decimal) {
 	p.CurrentPrice = &currentPrice
 	
 	// Calculate current value
-	currentValue := currentPrice.Mul(decimal.NewFromInt(int64(p.Quantity)))
+	currentValue := currentPrice.Mul(p.Quantity)
 	p.CurrentValue = &currentValue
 	
 	// Calculate P&L
@@ -143,4 +173,25 @@ func (p *Position) CalculateMetrics(currentPrice decimal.Decimal) {
 		pnlPercentage := pnl.Div(p.AllocationValue).Mul(decimal.NewFromInt(100))
 		p.PnLPercentage = &pnlPercentage
 	}
+}
+
+// CalculateMetricsInBaseCurrency calculates the metrics of a position in a stock quoted in another
+// currency from its local price and the rate converting it to the portfolio's base currency, splitting
+// P&L into the return on the stock at the entry rate and the return from the rate moving since
+func (p *Position) CalculateMetricsInBaseCurrency(localPrice decimal.Decimal, fxRate decimal.Decimal) {
+	p.CalculateMetrics(localPrice.Mul(fxRate))
+	p.LocalPrice = &localPrice
+	p.FXRate = &fxRate
+	
+	entryFXRate := p.EntryFXRate
+	if !entryFXRate.IsPositive() {
+		entryFXRate = decimal.NewFromInt(1)
+	}
+	localValue := localPrice.Mul(p.Quantity)
+	
+	localPnL := localValue.Mul(entryFXRate).Sub(p.AllocationValue)
+	p.LocalPnL = &localPnL
+	
+	fxPnL := localValue.Mul(fxRate.Sub(entryFXRate))
+	p.FXPnL = &fxPnL
 }
\ No newline at end of file
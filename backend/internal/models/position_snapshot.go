@@ -0,0 +1,98 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// PositionSnapshot represents a holding of a portfolio at the time of a NAV history entry
+type PositionSnapshot struct {
+	PortfolioID uuid.UUID       `json:"portfolio_id" db:"portfolio_id"`
+	Timestamp   time.Time       `json:"timestamp" db:"timestamp"`
+	StockID     uuid.UUID       `json:"stock_id" db:"stock_id"`
+	Quantity    decimal.Decimal `json:"quantity" db:"quantity"`
+	Price       decimal.Decimal `json:"price" db:"price"`
+	Value       decimal.Decimal `json:"value" db:"value"`
+	// Weight is the position's value as a percentage of the NAV
+	Weight    decimal.Decimal `json:"weight" db:"weight"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+
+	// Related data (not stored in database)
+	Stock *Stock `json:"stock,omitempty"`
+}
+
+// PositionHistoryPoint represents a holding's value at a single NAV timestamp
+type PositionHistoryPoint struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	Price     decimal.Decimal `json:"price"`
+	Value     decimal.Decimal `json:"value"`
+	Weight    decimal.Decimal `json:"weight"`
+}
+
+// PositionHistory represents the snapshots of a single holding over time
+type PositionHistory struct {
+	StockID uuid.UUID              `json:"stock_id"`
+	Stock   *Stock                 `json:"stock,omitempty"`
+	Points  []PositionHistoryPoint `json:"points"`
+}
+
+// NewPositionSnapshots snapshots positions enriched with market data for a NAV history entry. Positions
+// without a current price are valued at entry, as the NAV is.
+func NewPositionSnapshots(navHistory *NAVHistory, positions []Position) []PositionSnapshot {
+	snapshots := make([]PositionSnapshot, 0, len(positions))
+	for _, position := range positions {
+		price := position.EntryPrice
+		if position.CurrentPrice != nil {
+			price = *position.CurrentPrice
+		}
+		value := position.AllocationValue
+		if position.CurrentValue != nil {
+			value = *position.CurrentValue
+		}
+
+		weight := decimal.Zero
+		if navHistory.NAV.IsPositive() {
+			weight = value.Div(navHistory.NAV).Mul(decimal.NewFromInt(100)).Round(4)
+		}
+
+		snapshots = append(snapshots, PositionSnapshot{
+			PortfolioID: navHistory.PortfolioID,
+			Timestamp:   navHistory.Timestamp,
+			StockID:     position.StockID,
+			Quantity:    position.Quantity,
+			Price:       price,
+			Value:       value.Round(2),
+			Weight:      weight,
+			CreatedAt:   navHistory.CreatedAt,
+			Stock:       position.Stock,
+		})
+	}
+	return snapshots
+}
+
+// GroupPositionSnapshots groups snapshots ordered by timestamp into a series per stock, ordered by
+// the stock's first appearance
+func GroupPositionSnapshots(snapshots []*PositionSnapshot) []PositionHistory {
+	histories := []PositionHistory{}
+	index := make(map[uuid.UUID]int)
+	for _, snapshot := range snapshots {
+		i, exists := index[snapshot.StockID]
+		if !exists {
+			i = len(histories)
+			index[snapshot.StockID] = i
+			histories = append(histories, PositionHistory{StockID: snapshot.StockID, Stock: snapshot.Stock})
+		}
+
+		histories[i].Points = append(histories[i].Points, PositionHistoryPoint{
+			Timestamp: snapshot.Timestamp,
+			Quantity:  snapshot.Quantity,
+			Price:     snapshot.Price,
+			Value:     snapshot.Value,
+			Weight:    snapshot.Weight,
+		})
+	}
+	return histories
+}
@@ -0,0 +1,212 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RebalanceAction represents what a rebalance does with a single stock
+type RebalanceAction string
+
+const (
+	RebalanceBuy   RebalanceAction = "buy"
+	RebalanceSell  RebalanceAction = "sell"
+	RebalanceHold  RebalanceAction = "hold"
+	RebalanceClose RebalanceAction = "close"
+)
+
+// RebalanceTrade represents the planned change for one stock in a rebalance
+type RebalanceTrade struct {
+	StockID         uuid.UUID                  `json:"stock_id"`
+	Ticker          string                     `json:"ticker"`
+	Name            string                     `json:"name"`
+	Action          RebalanceAction            `json:"action"`
+	CurrentQuantity decimal.Decimal            `json:"current_quantity"`
+	TargetQuantity  decimal.Decimal            `json:"target_quantity"`
+	SharesToBuy     decimal.Decimal            `json:"shares_to_buy"`
+	SharesToSell    decimal.Decimal            `json:"shares_to_sell"`
+	Price           decimal.Decimal            `json:"price"`
+	FXRate          decimal.Decimal            `json:"fx_rate"`
+	EstimatedValue  decimal.Decimal            `json:"estimated_value"`
+	CurrentWeight   decimal.Decimal            `json:"current_weight"`
+	TargetWeight    decimal.Decimal            `json:"target_weight"`
+	Drift           decimal.Decimal            `json:"drift"`
+	StrategyContrib map[string]decimal.Decimal `json:"strategy_contrib,omitempty"`
+	SignalExit      bool                       `json:"signal_exit,omitempty"`
+}
+
+// RebalancePlan represents the trade list that moves a portfolio from its current holdings to a target allocation
+type RebalancePlan struct {
+	PortfolioID            uuid.UUID          `json:"portfolio_id"`
+	CurrentTotalInvestment decimal.Decimal    `json:"current_total_investment"`
+	NewTotalInvestment     decimal.Decimal    `json:"new_total_investment"`
+	CapitalChange          decimal.Decimal    `json:"capital_change"`
+	Trades                 []RebalanceTrade   `json:"trades"`
+	PositionsToClose       []uuid.UUID        `json:"positions_to_close"`
+	SignalExits            []uuid.UUID        `json:"signal_exits,omitempty"`
+	TotalBuyValue          decimal.Decimal    `json:"total_buy_value"`
+	TotalSellValue         decimal.Decimal    `json:"total_sell_value"`
+	TotalTurnover          decimal.Decimal    `json:"total_turnover"`
+	EstimatedCashAfter     decimal.Decimal    `json:"estimated_cash_after"`
+	MaxDrift               decimal.Decimal    `json:"max_drift"`
+	Target                 *AllocationPreview `json:"target"`
+}
+
+// NewRebalancePlan compares the current positions of a portfolio with a target allocation and
+// lists the trades needed to reach it. Positions missing from the target are closed at their
+// current price, or at their entry price when no market price is available, and closes of stocks
+// with a Sell or StrongSell signal are flagged as signal exits. Each trade also reports how far
+// the stock's current weight has drifted from its target weight, in percentage points. Both weights
+// are shares of NAV including cash: the current weight of the current NAV and the target weight of
+// the NAV after the capital change, so a portfolio holding its target positions alongside cash
+// does not drift.
+func NewRebalancePlan(portfolio *Portfolio, target *AllocationPreview, newTotalInvestment decimal.Decimal) *RebalancePlan {
+	plan := &RebalancePlan{
+		PortfolioID:            portfolio.ID,
+		CurrentTotalInvestment: portfolio.TotalInvestment,
+		NewTotalInvestment:     newTotalInvestment,
+		CapitalChange:          newTotalInvestment.Sub(portfolio.TotalInvestment),
+		Trades:                 make([]RebalanceTrade, 0, len(target.Allocations)),
+		PositionsToClose:       []uuid.UUID{},
+		TotalBuyValue:          decimal.Zero,
+		TotalSellValue:         decimal.Zero,
+		MaxDrift:               decimal.Zero,
+		Target:                 target,
+	}
+
+	current := make(map[uuid.UUID]*Position, len(portfolio.Positions))
+	currentNAV := portfolio.CashBalance
+	for i := range portfolio.Positions {
+		current[portfolio.Positions[i].StockID] = &portfolio.Positions[i]
+		currentNAV = currentNAV.Add(positionValue(&portfolio.Positions[i]))
+	}
+
+	targetNAV := currentNAV.Add(plan.CapitalChange)
+
+	exits := make(map[uuid.UUID]bool, len(target.ExitStocks))
+	for _, stockID := range target.ExitStocks {
+		exits[stockID] = true
+	}
+
+	targeted := make(map[uuid.UUID]bool, len(target.Allocations))
+	for _, allocation := range target.Allocations {
+		targeted[allocation.StockID] = true
+
+		currentQuantity := decimal.Zero
+		currentWeight := decimal.Zero
+		if position, exists := current[allocation.StockID]; exists {
+			currentQuantity = position.Quantity
+			currentWeight = weightOf(positionValue(position), currentNAV)
+		}
+
+		if currentQuantity.IsZero() && allocation.Quantity.IsZero() {
+			continue
+		}
+
+		plan.addTrade(RebalanceTrade{
+			StockID:         allocation.StockID,
+			Ticker:          allocation.Ticker,
+			Name:            allocation.Name,
+			CurrentQuantity: currentQuantity,
+			TargetQuantity:  allocation.Quantity,
+			Price:           allocation.Price,
+			FXRate:          allocation.FXRate,
+			CurrentWeight:   currentWeight,
+			TargetWeight:    weightOf(allocation.Price.Mul(allocation.Quantity), targetNAV),
+			StrategyContrib: allocation.StrategyContrib,
+		})
+	}
+
+	// Close positions in stocks that dropped out of the target allocation
+	for _, position := range portfolio.Positions {
+		if targeted[position.StockID] {
+			continue
+		}
+
+		price, fxRate := position.EntryPrice, position.EntryFXRate
+		if position.CurrentPrice != nil {
+			price = *position.CurrentPrice
+		}
+		if position.FXRate != nil {
+			fxRate = *position.FXRate
+		}
+
+		trade := RebalanceTrade{
+			StockID:         position.StockID,
+			CurrentQuantity: position.Quantity,
+			TargetQuantity:  decimal.Zero,
+			Price:           price,
+			FXRate:          fxRate,
+			CurrentWeight:   weightOf(positionValue(&position), currentNAV),
+			TargetWeight:    decimal.Zero,
+			SignalExit:      exits[position.StockID],
+		}
+		if position.Stock != nil {
+			trade.Ticker = position.Stock.Ticker
+			trade.Name = position.Stock.Name
+		}
+		plan.addTrade(trade)
+	}
+
+	plan.TotalTurnover = plan.TotalBuyValue.Add(plan.TotalSellValue)
+	plan.EstimatedCashAfter = portfolio.CashBalance.Add(plan.CapitalChange).
+		Add(plan.TotalSellValue).Sub(plan.TotalBuyValue)
+
+	return plan
+}
+
+// addTrade classifies a trade by comparing current and target quantities and updates the plan totals
+func (p *RebalancePlan) addTrade(trade RebalanceTrade) {
+	delta := trade.TargetQuantity.Sub(trade.CurrentQuantity)
+
+	switch {
+	case trade.TargetQuantity.IsZero():
+		trade.Action = RebalanceClose
+		trade.SharesToSell = trade.CurrentQuantity
+		p.PositionsToClose = append(p.PositionsToClose, trade.StockID)
+		if trade.SignalExit {
+			p.SignalExits = append(p.SignalExits, trade.StockID)
+		}
+	case delta.IsPositive():
+		trade.Action = RebalanceBuy
+		trade.SharesToBuy = delta
+	case delta.IsNegative():
+		trade.Action = RebalanceSell
+		trade.SharesToSell = delta.Neg()
+	default:
+		trade.Action = RebalanceHold
+	}
+
+	shares := trade.SharesToBuy.Add(trade.SharesToSell)
+	trade.EstimatedValue = trade.Price.Mul(shares).Round(2)
+
+	if trade.SharesToBuy.IsPositive() {
+		p.TotalBuyValue = p.TotalBuyValue.Add(trade.EstimatedValue)
+	} else {
+		p.TotalSellValue = p.TotalSellValue.Add(trade.EstimatedValue)
+	}
+
+	trade.Drift = trade.CurrentWeight.Sub(trade.TargetWeight)
+	if trade.Drift.Abs().GreaterThan(p.MaxDrift) {
+		p.MaxDrift = trade.Drift.Abs()
+	}
+
+	p.Trades = append(p.Trades, trade)
+}
+
+// positionValue returns the market value of a position, falling back to its allocation value
+// when no market price is available
+func positionValue(position *Position) decimal.Decimal {
+	if position.CurrentValue != nil {
+		return *position.CurrentValue
+	}
+	return position.AllocationValue
+}
+
+// weightOf returns value as a percentage of total, rounded to two decimal places
+func weightOf(value, total decimal.Decimal) decimal.Decimal {
+	if total.IsZero() {
+		return decimal.Zero
+	}
+	return value.Div(total).Mul(decimal.NewFromInt(100)).Round(2)
+}
@@ -0,0 +1,188 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// RebalanceTrigger represents what causes a portfolio to be rebalanced automatically
+type RebalanceTrigger string
+
+const (
+	RebalanceTriggerNone      RebalanceTrigger = "none"
+	RebalanceTriggerCalendar  RebalanceTrigger = "calendar"
+	RebalanceTriggerThreshold RebalanceTrigger = "threshold"
+)
+
+// RebalanceFrequency represents how often a calendar policy rebalances
+type RebalanceFrequency string
+
+const (
+	RebalanceMonthly   RebalanceFrequency = "monthly"
+	RebalanceQuarterly RebalanceFrequency = "quarterly"
+)
+
+// RebalanceExecutionMode represents what happens once a policy triggers
+type RebalanceExecutionMode string
+
+const (
+	RebalanceModeApproval RebalanceExecutionMode = "approval"
+	RebalanceModeAuto     RebalanceExecutionMode = "auto"
+)
+
+// RebalanceRunStatus represents the outcome of a triggered rebalance
+type RebalanceRunStatus string
+
+const (
+	RebalanceRunPending  RebalanceRunStatus = "pending"
+	RebalanceRunExecuted RebalanceRunStatus = "executed"
+	RebalanceRunRejected RebalanceRunStatus = "rejected"
+	RebalanceRunFailed   RebalanceRunStatus = "failed"
+)
+
+// RebalancePolicy represents when a portfolio should be rebalanced and whether the resulting
+// plan is executed straight away or waits for approval
+type RebalancePolicy struct {
+	PortfolioID     uuid.UUID              `json:"portfolio_id" db:"portfolio_id"`
+	TriggerType     RebalanceTrigger       `json:"trigger_type" db:"trigger_type"`
+	Frequency       *RebalanceFrequency    `json:"frequency,omitempty" db:"frequency"`
+	DriftThreshold  *decimal.Decimal       `json:"drift_threshold,omitempty" db:"drift_threshold"`
+	ExecutionMode   RebalanceExecutionMode `json:"execution_mode" db:"execution_mode"`
+	LastEvaluatedAt *time.Time             `json:"last_evaluated_at,omitempty" db:"last_evaluated_at"`
+	LastTriggeredAt *time.Time             `json:"last_triggered_at,omitempty" db:"last_triggered_at"`
+	CreatedAt       time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at" db:"updated_at"`
+}
+
+// UpdateRebalancePolicyRequest represents the request to set a portfolio's rebalance policy
+type UpdateRebalancePolicyRequest struct {
+	TriggerType    RebalanceTrigger       `json:"trigger_type" validate:"required,oneof=none calendar threshold"`
+	Frequency      *RebalanceFrequency    `json:"frequency,omitempty" validate:"omitempty,oneof=monthly quarterly"`
+	DriftThreshold *decimal.Decimal       `json:"drift_threshold,omitempty"`
+	ExecutionMode  RebalanceExecutionMode `json:"execution_mode" validate:"required,oneof=approval auto"`
+}
+
+// RebalanceRun represents a rebalance triggered by a policy together with its plan and outcome
+type RebalanceRun struct {
+	ID          uuid.UUID          `json:"id" db:"id"`
+	PortfolioID uuid.UUID          `json:"portfolio_id" db:"portfolio_id"`
+	TriggerType RebalanceTrigger   `json:"trigger_type" db:"trigger_type"`
+	Status      RebalanceRunStatus `json:"status" db:"status"`
+	MaxDrift    decimal.Decimal    `json:"max_drift" db:"max_drift"`
+	Plan        *RebalancePlan     `json:"plan" db:"plan"`
+	Error       *string            `json:"error,omitempty" db:"error"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	ResolvedAt  *time.Time         `json:"resolved_at,omitempty" db:"resolved_at"`
+}
+
+// Validate checks that the fields required by the chosen trigger are present
+func (r *UpdateRebalancePolicyRequest) Validate() error {
+	switch r.TriggerType {
+	case RebalanceTriggerCalendar:
+		if r.Frequency == nil {
+			return &ValidationError{
+				Field:   "frequency",
+				Tag:     "required",
+				Message: "frequency is required for calendar rebalancing",
+			}
+		}
+	case RebalanceTriggerThreshold:
+		if r.DriftThreshold == nil || !r.DriftThreshold.IsPositive() ||
+			r.DriftThreshold.GreaterThan(decimal.NewFromInt(100)) {
+			return &ValidationError{
+				Field:   "drift_threshold",
+				Tag:     "range",
+				Message: "drift_threshold must be greater than 0 and at most 100 percentage points",
+			}
+		}
+	}
+	return nil
+}
+
+// NewRebalancePolicy creates a rebalance policy for a portfolio from an UpdateRebalancePolicyRequest
+func NewRebalancePolicy(portfolioID uuid.UUID, req *UpdateRebalancePolicyRequest) *RebalancePolicy {
+	now := time.Now()
+	policy := &RebalancePolicy{
+		PortfolioID: portfolioID,
+		CreatedAt:   now,
+	}
+	policy.ApplyUpdate(req)
+	return policy
+}
+
+// ApplyUpdate replaces the policy settings, keeping only the fields relevant to the trigger
+func (p *RebalancePolicy) ApplyUpdate(req *UpdateRebalancePolicyRequest) {
+	p.TriggerType = req.TriggerType
+	p.ExecutionMode = req.ExecutionMode
+	p.Frequency = nil
+	p.DriftThreshold = nil
+
+	switch req.TriggerType {
+	case RebalanceTriggerCalendar:
+		p.Frequency = req.Frequency
+	case RebalanceTriggerThreshold:
+		p.DriftThreshold = req.DriftThreshold
+	}
+	p.UpdatedAt = time.Now()
+}
+
+// IsCalendarDue reports whether a calendar policy has entered a new period since it last triggered.
+// A policy that never triggered counts from its creation time.
+func (p *RebalancePolicy) IsCalendarDue(now time.Time) bool {
+	if p.TriggerType != RebalanceTriggerCalendar || p.Frequency == nil {
+		return false
+	}
+
+	since := p.CreatedAt
+	if p.LastTriggeredAt != nil {
+		since = *p.LastTriggeredAt
+	}
+
+	switch *p.Frequency {
+	case RebalanceMonthly:
+		return periodIndex(now, 1) > periodIndex(since, 1)
+	case RebalanceQuarterly:
+		return periodIndex(now, 3) > periodIndex(since, 3)
+	}
+	return false
+}
+
+// IsDriftExceeded reports whether a threshold policy is triggered by the given drift in percentage points
+func (p *RebalancePolicy) IsDriftExceeded(drift decimal.Decimal) bool {
+	if p.TriggerType != RebalanceTriggerThreshold || p.DriftThreshold == nil {
+		return false
+	}
+	return drift.GreaterThan(*p.DriftThreshold)
+}
+
+// NewRebalanceRun records a triggered rebalance plan in the given status
+func NewRebalanceRun(portfolioID uuid.UUID, trigger RebalanceTrigger, plan *RebalancePlan, status RebalanceRunStatus) *RebalanceRun {
+	return &RebalanceRun{
+		ID:          uuid.New(),
+		PortfolioID: portfolioID,
+		TriggerType: trigger,
+		Status:      status,
+		MaxDrift:    plan.MaxDrift,
+		Plan:        plan,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Resolve sets the final status of a run, recording the error message for failed runs
+func (r *RebalanceRun) Resolve(status RebalanceRunStatus, err error) {
+	now := time.Now()
+	r.Status = status
+	r.ResolvedAt = &now
+	if err != nil {
+		message := err.Error()
+		r.Error = &message
+	}
+}
+
+// periodIndex numbers the calendar periods of the given length in months so that consecutive
+// periods have consecutive indexes
+func periodIndex(t time.Time, months int) int {
+	return (t.Year()*12 + int(t.Month()) - 1) / months
+}
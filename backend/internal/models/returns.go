@@ -0,0 +1,230 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ReturnPeriod represents the window returns are measured over
+type ReturnPeriod string
+
+const (
+	ReturnPeriodMTD       ReturnPeriod = "mtd"
+	ReturnPeriodQTD       ReturnPeriod = "qtd"
+	ReturnPeriodYTD       ReturnPeriod = "ytd"
+	ReturnPeriodOneYear   ReturnPeriod = "1y"
+	ReturnPeriodInception ReturnPeriod = "inception"
+)
+
+// CashFlow represents capital added to (positive) or withdrawn from (negative) a portfolio
+type CashFlow struct {
+	Date   time.Time       `json:"date"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// PeriodReturns represents cash flow adjusted returns of a portfolio over a period
+type PeriodReturns struct {
+	Period      ReturnPeriod    `json:"period"`
+	From        time.Time       `json:"from"`
+	To          time.Time       `json:"to"`
+	StartValue  decimal.Decimal `json:"start_value"`
+	EndValue    decimal.Decimal `json:"end_value"`
+	NetCashFlow decimal.Decimal `json:"net_cash_flow"`
+	// TimeWeightedReturnPct chain-links the returns between valuations, removing the effect of cash flows
+	TimeWeightedReturnPct *decimal.Decimal `json:"time_weighted_return_pct,omitempty"`
+	// MoneyWeightedReturnPct is the annualized internal rate of return (XIRR) of the cash flows
+	MoneyWeightedReturnPct *decimal.Decimal `json:"money_weighted_return_pct,omitempty"`
+}
+
+// ParseReturnPeriod parses a return period, defaulting to since inception when empty
+func ParseReturnPeriod(s string) (ReturnPeriod, error) {
+	switch period := ReturnPeriod(s); period {
+	case "":
+		return ReturnPeriodInception, nil
+	case ReturnPeriodMTD, ReturnPeriodQTD, ReturnPeriodYTD, ReturnPeriodOneYear, ReturnPeriodInception:
+		return period, nil
+	default:
+		return "", &ValidationError{
+			Field:   "period",
+			Tag:     "oneof",
+			Message: fmt.Sprintf("unknown period %s, expected one of mtd, qtd, ytd, 1y, inception", s),
+		}
+	}
+}
+
+// Start returns the beginning of the period ending at now; the zero time for since inception
+func (p ReturnPeriod) Start(now time.Time) time.Time {
+	now = now.UTC()
+	switch p {
+	case ReturnPeriodMTD:
+		return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	case ReturnPeriodQTD:
+		quarterMonth := time.Month((int(now.Month())-1)/3*3 + 1)
+		return time.Date(now.Year(), quarterMonth, 1, 0, 0, 0, 0, time.UTC)
+	case ReturnPeriodYTD:
+		return time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	case ReturnPeriodOneYear:
+		return now.AddDate(-1, 0, 0)
+	default:
+		return time.Time{}
+	}
+}
+
+// ExternalCashFlows extracts deposits and withdrawals from ledger entries
+func ExternalCashFlows(transactions []*Transaction) []CashFlow {
+	var flows []CashFlow
+	for _, t := range transactions {
+		if t.Type == TransactionDeposit || t.Type == TransactionWithdrawal {
+			flows = append(flows, CashFlow{Date: t.ExecutedAt, Amount: t.CashEffect()})
+		}
+	}
+	return flows
+}
+
+// CalculatePeriodReturns calculates time- and money-weighted returns over [from, to] from NAV history
+// ordered by timestamp. The period starts at the last valuation on or before from, or at the first
+// valuation in the period when there is none; cash flows up to the start are part of its value.
+// Returns nil when the period has fewer than two valuations.
+func CalculatePeriodReturns(period ReturnPeriod, history []NAVHistory, flows []CashFlow, from, to time.Time) *PeriodReturns {
+	startIdx := -1
+	for i, entry := range history {
+		if entry.Timestamp.After(to) {
+			break
+		}
+		if !entry.Timestamp.After(from) || startIdx == -1 {
+			startIdx = i
+		}
+	}
+	if startIdx == -1 {
+		return nil
+	}
+
+	valuations := []NAVHistory{history[startIdx]}
+	for _, entry := range history[startIdx+1:] {
+		if entry.Timestamp.After(to) {
+			break
+		}
+		valuations = append(valuations, entry)
+	}
+	if len(valuations) < 2 {
+		return nil
+	}
+
+	start := valuations[0]
+	end := valuations[len(valuations)-1]
+	returns := &PeriodReturns{
+		Period:      period,
+		From:        start.Timestamp,
+		To:          end.Timestamp,
+		StartValue:  start.NAV,
+		EndValue:    end.NAV,
+		NetCashFlow: sumCashFlows(flows, start.Timestamp, end.Timestamp),
+	}
+
+	if growth, ok := timeWeightedGrowth(valuations, flows); ok {
+		returns.TimeWeightedReturnPct = percentDecimal(growth - 1)
+	}
+
+	// From the investor's side the starting value and contributions are paid in and the end value is received
+	xirrFlows := []CashFlow{{Date: start.Timestamp, Amount: start.NAV.Neg()}}
+	for _, flow := range flows {
+		if flow.Date.After(start.Timestamp) && !flow.Date.After(end.Timestamp) {
+			xirrFlows = append(xirrFlows, CashFlow{Date: flow.Date, Amount: flow.Amount.Neg()})
+		}
+	}
+	xirrFlows = append(xirrFlows, CashFlow{Date: end.Timestamp, Amount: end.NAV})
+	if rate, ok := xirr(xirrFlows); ok {
+		returns.MoneyWeightedReturnPct = percentDecimal(rate)
+	}
+
+	return returns
+}
+
+// timeWeightedGrowth chain-links the returns between consecutive valuations into a growth factor,
+// reporting false when capital was invested in none of the sub-periods
+func timeWeightedGrowth(valuations []NAVHistory, flows []CashFlow) (float64, bool) {
+	growth := 1.0
+	linked := false
+	for i := 1; i < len(valuations); i++ {
+		r, ok := modifiedDietzReturn(valuations[i-1], valuations[i], flows)
+		if !ok {
+			continue
+		}
+		growth *= 1 + r
+		linked = true
+	}
+	return growth, linked
+}
+
+// modifiedDietzReturn measures the return between two valuations net of the cash flows between them,
+// weighting each flow by the share of the interval it was invested for. It reports false when no
+// capital was invested over the interval.
+func modifiedDietzReturn(previous, current NAVHistory, flows []CashFlow) (float64, bool) {
+	length := current.Timestamp.Sub(previous.Timestamp).Seconds()
+
+	flow := decimal.Zero
+	invested := previous.NAV
+	for _, cf := range flows {
+		if cf.Date.After(previous.Timestamp) && !cf.Date.After(current.Timestamp) {
+			weight := current.Timestamp.Sub(cf.Date).Seconds() / length
+			flow = flow.Add(cf.Amount)
+			invested = invested.Add(cf.Amount.Mul(decimal.NewFromFloat(weight)))
+		}
+	}
+	if !invested.IsPositive() {
+		return 0, false
+	}
+
+	return current.NAV.Sub(previous.NAV).Sub(flow).Div(invested).InexactFloat64(), true
+}
+
+// sumCashFlows adds up the cash flows in (from, to]
+func sumCashFlows(flows []CashFlow, from, to time.Time) decimal.Decimal {
+	total := decimal.Zero
+	for _, flow := range flows {
+		if flow.Date.After(from) && !flow.Date.After(to) {
+			total = total.Add(flow.Amount)
+		}
+	}
+	return total
+}
+
+// xirr finds the annual rate at which the net present value of dated cash flows is zero by bisection.
+// It reports false when the flows span less than a day or no rate in range zeroes them.
+func xirr(flows []CashFlow) (float64, bool) {
+	first := flows[0].Date
+	if flows[len(flows)-1].Date.Sub(first) < 24*time.Hour {
+		return 0, false
+	}
+
+	npv := func(rate float64) float64 {
+		var total float64
+		for _, flow := range flows {
+			years := flow.Date.Sub(first).Hours() / 24 / 365
+			total += flow.Amount.InexactFloat64() / math.Pow(1+rate, years)
+		}
+		return total
+	}
+
+	low, high := -0.9999, 1.0
+	for npv(high) > 0 && high < 1e6 {
+		high *= 2
+	}
+	lowValue := npv(low)
+	if math.IsNaN(lowValue) || math.Signbit(lowValue) == math.Signbit(npv(high)) {
+		return 0, false
+	}
+
+	for i := 0; i < 200 && high-low > 1e-10; i++ {
+		mid := (low + high) / 2
+		if math.Signbit(npv(mid)) == math.Signbit(lowValue) {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, true
+}
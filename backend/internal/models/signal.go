@@ -4,22 +4,52 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // SignalType represents the type of signal for a stock
 type SignalType string
 
 const (
-	SignalBuy  SignalType = "Buy"
-	SignalHold SignalType = "Hold"
+	SignalStrongBuy  SignalType = "StrongBuy"
+	SignalBuy        SignalType = "Buy"
+	SignalHold       SignalType = "Hold"
+	SignalSell       SignalType = "Sell"
+	SignalStrongSell SignalType = "StrongSell"
 )
 
-// Signal represents a trading signal for a stock
+// SignalSourceManual is the source recorded for signals entered through the API
+const SignalSourceManual = "manual"
+
+// IsValid checks if the signal type is supported
+func (t SignalType) IsValid() bool {
+	switch t {
+	case SignalStrongBuy, SignalBuy, SignalHold, SignalSell, SignalStrongSell:
+		return true
+	}
+	return false
+}
+
+// IsBuy reports whether the signal makes a stock eligible for new allocations
+func (t SignalType) IsBuy() bool {
+	return t == SignalBuy || t == SignalStrongBuy
+}
+
+// IsExit reports whether the signal calls for closing existing positions in a stock
+func (t SignalType) IsExit() bool {
+	return t == SignalSell || t == SignalStrongSell
+}
+
+// Signal represents a trading signal for a stock. A signal with a StrategyID applies only to that
+// strategy and takes precedence over the stock's global signal.
 type Signal struct {
-	StockID   uuid.UUID  `json:"stock_id" db:"stock_id"`
-	Signal    SignalType `json:"signal" db:"signal" validate:"required,oneof=Buy Hold"`
-	Date      time.Time  `json:"date" db:"date" validate:"required"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	StockID    uuid.UUID        `json:"stock_id" db:"stock_id"`
+	StrategyID *uuid.UUID       `json:"strategy_id,omitempty" db:"strategy_id"`
+	Signal    SignalType       `json:"signal" db:"signal" validate:"required,oneof=StrongBuy Buy Hold Sell StrongSell"`
+	Date      time.Time        `json:"date" db:"date" validate:"required"`
+	Strength  *decimal.Decimal `json:"strength,omitempty" db:"strength" validate:"omitempty,gte=0,lte=100"`
+	Source    string           `json:"source" db:"source"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
 	
 	// Related data (not stored in database)
 	Stock *Stock `json:"stock,omitempty"`
@@ -27,31 +57,75 @@ type Signal struct {
 
 // CreateSignalRequest represents the request to create a new signal
 type CreateSignalRequest struct {
-	StockID uuid.UUID  `json:"stock_id" validate:"required"`
-	Signal  SignalType `json:"signal" validate:"required,oneof=Buy Hold"`
-	Date    time.Time  `json:"date" validate:"required"`
+	StockID  uuid.UUID        `json:"stock_id" validate:"required"`
+	Signal   SignalType       `json:"signal" validate:"required,oneof=StrongBuy Buy Hold Sell StrongSell"`
+	Date     time.Time        `json:"date" validate:"required"`
+	Strength *decimal.Decimal `json:"strength,omitempty" validate:"omitempty,gte=0,lte=100"`
 }
 
 // UpdateSignalRequest represents the request to update a signal
 type UpdateSignalRequest struct {
-	Signal SignalType `json:"signal" validate:"required,oneof=Buy Hold"`
+	Signal SignalType `json:"signal" validate:"required,oneof=StrongBuy Buy Hold Sell StrongSell"`
 }
 
 // SignalResponse represents the signal data returned in API responses
 type SignalResponse struct {
-	StockID   uuid.UUID  `json:"stock_id"`
-	Signal    SignalType `json:"signal"`
-	Date      time.Time  `json:"date"`
-	CreatedAt time.Time  `json:"created_at"`
-	Stock     *Stock     `json:"stock,omitempty"`
+	StockID    uuid.UUID        `json:"stock_id"`
+	StrategyID *uuid.UUID       `json:"strategy_id,omitempty"`
+	Signal    SignalType       `json:"signal"`
+	Date      time.Time        `json:"date"`
+	Strength  *decimal.Decimal `json:"strength,omitempty"`
+	Source    string           `json:"source"`
+	CreatedAt time.Time        `json:"created_at"`
+	Stock     *Stock           `json:"stock,omitempty"`
+}
+
+// SignalWebhookRequest represents a batch of signals delivered by an external alert source
+type SignalWebhookRequest struct {
+	Signals []SignalWebhookItem `json:"signals" validate:"required,min=1,max=500,dive"`
+}
+
+// SignalWebhookItem represents one signal in a webhook batch. Deliveries are idempotent on
+// ticker, strategy, date and source: redelivering an item overwrites the signal it created.
+type SignalWebhookItem struct {
+	Ticker   string           `json:"ticker" validate:"required,min=1,max=20"`
+	Signal   SignalType       `json:"signal" validate:"required,oneof=StrongBuy Buy Hold Sell StrongSell"`
+	Date     string           `json:"date" validate:"required,datetime=2006-01-02"`
+	Strategy *uuid.UUID       `json:"strategy,omitempty"`
+	Source   string           `json:"source" validate:"required,min=1,max=50"`
+	Strength *decimal.Decimal `json:"strength,omitempty" validate:"omitempty,gte=0,lte=100"`
+}
+
+// SignalWebhookItemResult reports what happened to one item of a webhook batch
+type SignalWebhookItemResult struct {
+	Index  int             `json:"index"`
+	Ticker string          `json:"ticker"`
+	Status string          `json:"status"`
+	Error  string          `json:"error,omitempty"`
+	Signal *SignalResponse `json:"signal,omitempty"`
+}
+
+// SignalWebhookResult summarises a processed webhook batch
+type SignalWebhookResult struct {
+	Accepted int                       `json:"accepted"`
+	Rejected int                       `json:"rejected"`
+	Results  []SignalWebhookItemResult `json:"results"`
+}
+
+// WebhookSecretResponse returns a newly generated webhook signing secret
+type WebhookSecretResponse struct {
+	Secret string `json:"secret"`
 }
 
 // ToResponse converts a Signal to SignalResponse
 func (s *Signal) ToResponse() *SignalResponse {
 	return &SignalResponse{
-		StockID:   s.StockID,
+		StockID:    s.StockID,
+		StrategyID: s.StrategyID,
 		Signal:    s.Signal,
 		Date:      s.Date,
+		Strength:  s.Strength,
+		Source:    s.Source,
 		CreatedAt: s.CreatedAt,
 		Stock:     s.Stock,
 	}
@@ -62,5 +136,7 @@ func (s *Signal) FromCreateRequest(req *CreateSignalRequest) {
 	s.StockID = req.StockID
 	s.Signal = req.Signal
 	s.Date = req.Date
+	s.Strength = req.Strength
+	s.Source = SignalSourceManual
 	s.CreatedAt = time.Now()
 }
\ No newline at end of file
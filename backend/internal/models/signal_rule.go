@@ -0,0 +1,196 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// SignalRuleType represents the technical indicator a signal rule evaluates
+type SignalRuleType string
+
+const (
+	SignalRuleSMACross SignalRuleType = "sma_cross"
+	SignalRuleEMACross SignalRuleType = "ema_cross"
+	SignalRuleRSI      SignalRuleType = "rsi"
+	SignalRuleBreakout SignalRuleType = "breakout"
+)
+
+// Default RSI thresholds used when a rule does not set its own
+var (
+	DefaultRSIOversold   = decimal.NewFromInt(30)
+	DefaultRSIOverbought = decimal.NewFromInt(70)
+)
+
+// SignalRule represents a technical rule that generates signals for the stocks of a strategy.
+//
+// Period is the fast moving average for crossovers, the RSI period for rsi rules and the
+// lookback window for breakouts. SlowPeriod is only used by crossovers, and LowerThreshold and
+// UpperThreshold are the oversold and overbought RSI levels.
+type SignalRule struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	StrategyID     uuid.UUID        `json:"strategy_id" db:"strategy_id"`
+	RuleType       SignalRuleType   `json:"rule_type" db:"rule_type"`
+	Period         int              `json:"period" db:"period"`
+	SlowPeriod     *int             `json:"slow_period,omitempty" db:"slow_period"`
+	LowerThreshold *decimal.Decimal `json:"lower_threshold,omitempty" db:"lower_threshold"`
+	UpperThreshold *decimal.Decimal `json:"upper_threshold,omitempty" db:"upper_threshold"`
+	Enabled        bool             `json:"enabled" db:"enabled"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at" db:"updated_at"`
+}
+
+// CreateSignalRuleRequest represents the request to add a signal rule to a strategy
+type CreateSignalRuleRequest struct {
+	RuleType       SignalRuleType   `json:"rule_type" validate:"required,oneof=sma_cross ema_cross rsi breakout"`
+	Period         int              `json:"period" validate:"required,min=1,max=250"`
+	SlowPeriod     *int             `json:"slow_period,omitempty" validate:"omitempty,min=2,max=250"`
+	LowerThreshold *decimal.Decimal `json:"lower_threshold,omitempty" validate:"omitempty,gte=0,lte=100"`
+	UpperThreshold *decimal.Decimal `json:"upper_threshold,omitempty" validate:"omitempty,gte=0,lte=100"`
+	Enabled        *bool            `json:"enabled,omitempty"`
+}
+
+// UpdateSignalRuleRequest represents the request to update a signal rule
+type UpdateSignalRuleRequest struct {
+	Period         *int             `json:"period,omitempty" validate:"omitempty,min=1,max=250"`
+	SlowPeriod     *int             `json:"slow_period,omitempty" validate:"omitempty,min=2,max=250"`
+	LowerThreshold *decimal.Decimal `json:"lower_threshold,omitempty" validate:"omitempty,gte=0,lte=100"`
+	UpperThreshold *decimal.Decimal `json:"upper_threshold,omitempty" validate:"omitempty,gte=0,lte=100"`
+	Enabled        *bool            `json:"enabled,omitempty"`
+}
+
+// StockSignalEvaluation represents the signal generated for one stock of a strategy
+type StockSignalEvaluation struct {
+	StockID uuid.UUID   `json:"stock_id"`
+	Ticker  string      `json:"ticker"`
+	Signal  *SignalType `json:"signal,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// SignalRuleEvaluation represents the outcome of running a strategy's signal rules
+type SignalRuleEvaluation struct {
+	StrategyID  uuid.UUID               `json:"strategy_id"`
+	RuleCount   int                     `json:"rule_count"`
+	Results     []StockSignalEvaluation `json:"results"`
+	EvaluatedAt time.Time               `json:"evaluated_at"`
+}
+
+// NewSignalRule creates a signal rule for a strategy from a CreateSignalRuleRequest, filling in
+// the default RSI thresholds
+func NewSignalRule(strategyID uuid.UUID, req *CreateSignalRuleRequest) *SignalRule {
+	now := time.Now()
+	rule := &SignalRule{
+		ID:             uuid.New(),
+		StrategyID:     strategyID,
+		RuleType:       req.RuleType,
+		Period:         req.Period,
+		SlowPeriod:     req.SlowPeriod,
+		LowerThreshold: req.LowerThreshold,
+		UpperThreshold: req.UpperThreshold,
+		Enabled:        true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	rule.normalize()
+	return rule
+}
+
+// ApplyUpdate applies the fields of an UpdateSignalRuleRequest to the rule
+func (r *SignalRule) ApplyUpdate(req *UpdateSignalRuleRequest) {
+	if req.Period != nil {
+		r.Period = *req.Period
+	}
+	if req.SlowPeriod != nil {
+		r.SlowPeriod = req.SlowPeriod
+	}
+	if req.LowerThreshold != nil {
+		r.LowerThreshold = req.LowerThreshold
+	}
+	if req.UpperThreshold != nil {
+		r.UpperThreshold = req.UpperThreshold
+	}
+	if req.Enabled != nil {
+		r.Enabled = *req.Enabled
+	}
+	r.normalize()
+	r.UpdatedAt = time.Now()
+}
+
+// normalize drops the parameters the rule type does not use and fills in RSI defaults
+func (r *SignalRule) normalize() {
+	switch r.RuleType {
+	case SignalRuleSMACross, SignalRuleEMACross:
+		r.LowerThreshold = nil
+		r.UpperThreshold = nil
+	case SignalRuleRSI:
+		r.SlowPeriod = nil
+		if r.LowerThreshold == nil {
+			lower := DefaultRSIOversold
+			r.LowerThreshold = &lower
+		}
+		if r.UpperThreshold == nil {
+			upper := DefaultRSIOverbought
+			r.UpperThreshold = &upper
+		}
+	case SignalRuleBreakout:
+		r.SlowPeriod = nil
+		r.LowerThreshold = nil
+		r.UpperThreshold = nil
+	}
+}
+
+// Validate checks that the parameters required by the rule type are present and consistent
+func (r *SignalRule) Validate() error {
+	switch r.RuleType {
+	case SignalRuleSMACross, SignalRuleEMACross:
+		if r.SlowPeriod == nil || *r.SlowPeriod <= r.Period {
+			return &ValidationError{
+				Field:   "slow_period",
+				Tag:     "gtfield",
+				Message: "slow_period is required for crossover rules and must be greater than period",
+			}
+		}
+	case SignalRuleRSI:
+		if r.Period < 2 {
+			return &ValidationError{
+				Field:   "period",
+				Tag:     "min",
+				Message: "period must be at least 2 for rsi rules",
+			}
+		}
+		if !r.LowerThreshold.LessThan(*r.UpperThreshold) {
+			return &ValidationError{
+				Field:   "lower_threshold",
+				Tag:     "ltfield",
+				Message: "lower_threshold must be less than upper_threshold",
+			}
+		}
+	case SignalRuleBreakout:
+	default:
+		return &ValidationError{
+			Field:   "rule_type",
+			Tag:     "oneof",
+			Message: "rule_type must be one of sma_cross, ema_cross, rsi, breakout",
+		}
+	}
+	return nil
+}
+
+// RequiredBars returns the number of daily bars needed to evaluate the rule
+func (r *SignalRule) RequiredBars() int {
+	switch r.RuleType {
+	case SignalRuleSMACross:
+		// One extra bar to detect a crossover on the latest bar
+		return *r.SlowPeriod + 1
+	case SignalRuleEMACross:
+		// EMAs are seeded with an SMA, so allow them to warm up before the crossover is read
+		return 3*(*r.SlowPeriod) + 1
+	case SignalRuleRSI, SignalRuleBreakout:
+		return r.Period + 1
+	}
+	return r.Period
+}
@@ -4,17 +4,21 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 )
 
 // Stock represents a stock in the system
 type Stock struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Ticker    string    `json:"ticker" db:"ticker" validate:"required,min=1,max=20,uppercase"`
-	Name      string    `json:"name" db:"name" validate:"required,min=1,max=255"`
-	Sector    *string   `json:"sector" db:"sector" validate:"omitempty,max=100"`
-	Exchange  *string   `json:"exchange" db:"exchange" validate:"omitempty,max=50"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID        uuid.UUID        `json:"id" db:"id"`
+	Ticker    string           `json:"ticker" db:"ticker" validate:"required,min=1,max=20,uppercase"`
+	Name      string           `json:"name" db:"name" validate:"required,min=1,max=255"`
+	Sector    *string          `json:"sector" db:"sector" validate:"omitempty,max=100"`
+	Exchange  *string          `json:"exchange" db:"exchange" validate:"omitempty,max=50"`
+	MarketCap *decimal.Decimal `json:"market_cap,omitempty" db:"market_cap" validate:"omitempty,gt=0"`
+	// Currency is the ISO 4217 code of the currency the stock is quoted in
+	Currency  string           `json:"currency" db:"currency" validate:"omitempty,len=3,uppercase"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at" db:"updated_at"`
 	
 	// Related data (not stored in database)
 	CurrentSignal *Signal `json:"current_signal,omitempty"`
@@ -22,37 +26,44 @@ type Stock struct {
 
 // CreateStockRequest represents the request to create a new stock
 type CreateStockRequest struct {
-	Ticker   string  `json:"ticker" validate:"required,min=1,max=20,uppercase"`
-	Name     string  `json:"name" validate:"required,min=1,max=255"`
-	Sector   *string `json:"sector,omitempty" validate:"omitempty,max=100"`
-	Exchange *string `json:"exchange,omitempty" validate:"omitempty,max=50"`
+	Ticker    string           `json:"ticker" validate:"required,min=1,max=20,uppercase"`
+	Name      string           `json:"name" validate:"required,min=1,max=255"`
+	Sector    *string          `json:"sector,omitempty" validate:"omitempty,max=100"`
+	Exchange  *string          `json:"exchange,omitempty" validate:"omitempty,max=50"`
+	MarketCap *decimal.Decimal `json:"market_cap,omitempty" validate:"omitempty,gt=0"`
+	Currency  string           `json:"currency,omitempty" validate:"omitempty,len=3,uppercase"`
 }
 
 // UpdateStockRequest represents the request to update a stock
 type UpdateStockRequest struct {
-	Name     *string `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
-	Sector   *string `json:"sector,omitempty" validate:"omitempty,max=100"`
-	Exchange *string `json:"exchange,omitempty" validate:"omitempty,max=50"`
+	Name      *string          `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	Sector    *string          `json:"sector,omitempty" validate:"omitempty,max=100"`
+	Exchange  *string          `json:"exchange,omitempty" validate:"omitempty,max=50"`
+	MarketCap *decimal.Decimal `json:"market_cap,omitempty" validate:"omitempty,gt=0"`
+	Currency  *string          `json:"currency,omitempty" validate:"omitempty,len=3,uppercase"`
 }
 
 // StockResponse represents the stock data returned in API responses
 type StockResponse struct {
-	ID            uuid.UUID `json:"id"`
-	Ticker        string    `json:"ticker"`
-	Name          string    `json:"name"`
-	Sector        *string   `json:"sector"`
-	Exchange      *string   `json:"exchange"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
-	CurrentSignal *Signal   `json:"current_signal,omitempty"`
+	ID            uuid.UUID        `json:"id"`
+	Ticker        string           `json:"ticker"`
+	Name          string           `json:"name"`
+	Sector        *string          `json:"sector"`
+	Exchange      *string          `json:"exchange"`
+	MarketCap     *decimal.Decimal `json:"market_cap,omitempty"`
+	Currency      string           `json:"currency"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	CurrentSignal *Signal          `json:"current_signal,omitempty"`
 }
 
 // StrategyStock represents the relationship between a strategy and stock
 type StrategyStock struct {
-	StrategyID uuid.UUID `json:"strategy_id" db:"strategy_id"`
-	StockID    uuid.UUID `json:"stock_id" db:"stock_id"`
-	Eligible   bool      `json:"eligible" db:"eligible"`
-	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	StrategyID   uuid.UUID        `json:"strategy_id" db:"strategy_id"`
+	StockID      uuid.UUID        `json:"stock_id" db:"stock_id"`
+	Eligible     bool             `json:"eligible" db:"eligible"`
+	CustomWeight *decimal.Decimal `json:"custom_weight,omitempty" db:"custom_weight"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
 	
 	// Related data (not stored in database)
 	Stock *Stock `json:"stock,omitempty"`
@@ -63,6 +74,11 @@ type UpdateStockEligibilityRequest struct {
 	Eligible bool `json:"eligible" validate:"required"`
 }
 
+// UpdateStockWeightRequest represents the request to set or clear the custom weight of a stock within a strategy
+type UpdateStockWeightRequest struct {
+	CustomWeight *decimal.Decimal `json:"custom_weight" validate:"omitempty,gt=0"`
+}
+
 // ToResponse converts a Stock to StockResponse
 func (s *Stock) ToResponse() *StockResponse {
 	return &StockResponse{
@@ -71,6 +87,8 @@ func (s *Stock) ToResponse() *StockResponse {
 		Name:          s.Name,
 		Sector:        s.Sector,
 		Exchange:      s.Exchange,
+		MarketCap:     s.MarketCap,
+		Currency:      CurrencyOrDefault(s.Currency),
 		CreatedAt:     s.CreatedAt,
 		UpdatedAt:     s.UpdatedAt,
 		CurrentSignal: s.CurrentSignal,
@@ -84,6 +102,8 @@ func (s *Stock) FromCreateRequest(req *CreateStockRequest) {
 	s.Name = req.Name
 	s.Sector = req.Sector
 	s.Exchange = req.Exchange
+	s.MarketCap = req.MarketCap
+	s.Currency = CurrencyOrDefault(req.Currency)
 	s.CreatedAt = time.Now()
 	s.UpdatedAt = time.Now()
 }
@@ -99,5 +119,11 @@ func (s *Stock) ApplyUpdate(req *UpdateStockRequest) {
 	if req.Exchange != nil {
 		s.Exchange = req.Exchange
 	}
+	if req.MarketCap != nil {
+		s.MarketCap = req.MarketCap
+	}
+	if req.Currency != nil {
+		s.Currency = *req.Currency
+	}
 	s.UpdatedAt = time.Now()
 }
\ No newline at end of file
@@ -15,15 +15,27 @@ const (
 	WeightModeBudget  WeightMode = "budget"
 )
 
+// StockWeighting represents how a strategy splits its allocation across its stocks
+type StockWeighting string
+
+const (
+	StockWeightingEqual             StockWeighting = "equal"
+	StockWeightingMarketCap         StockWeighting = "market_cap"
+	StockWeightingInverseVolatility StockWeighting = "inverse_volatility"
+	StockWeightingCustom            StockWeighting = "custom"
+	StockWeightingSignalStrength    StockWeighting = "signal_strength"
+)
+
 // Strategy represents an investment strategy
 type Strategy struct {
-	ID          uuid.UUID       `json:"id" db:"id"`
-	UserID      uuid.UUID       `json:"user_id" db:"user_id"`
-	Name        string          `json:"name" db:"name" validate:"required,min=1,max=255"`
-	WeightMode  WeightMode      `json:"weight_mode" db:"weight_mode" validate:"required,oneof=percent budget"`
-	WeightValue decimal.Decimal `json:"weight_value" db:"weight_value" validate:"required,gt=0"`
-	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	ID             uuid.UUID       `json:"id" db:"id"`
+	UserID         uuid.UUID       `json:"user_id" db:"user_id"`
+	Name           string          `json:"name" db:"name" validate:"required,min=1,max=255"`
+	WeightMode     WeightMode      `json:"weight_mode" db:"weight_mode" validate:"required,oneof=percent budget"`
+	WeightValue    decimal.Decimal `json:"weight_value" db:"weight_value" validate:"required,gt=0"`
+	StockWeighting StockWeighting  `json:"stock_weighting" db:"stock_weighting" validate:"omitempty,oneof=equal market_cap inverse_volatility custom signal_strength"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at" db:"updated_at"`
 	
 	// Related data (not stored in database)
 	Stocks []StrategyStock `json:"stocks,omitempty"`
@@ -31,41 +43,45 @@ type Strategy struct {
 
 // CreateStrategyRequest represents the request to create a new strategy
 type CreateStrategyRequest struct {
-	Name        string          `json:"name" validate:"required,min=1,max=255"`
-	WeightMode  WeightMode      `json:"weight_mode" validate:"required,oneof=percent budget"`
-	WeightValue decimal.Decimal `json:"weight_value" validate:"required,gt=0"`
+	Name           string          `json:"name" validate:"required,min=1,max=255"`
+	WeightMode     WeightMode      `json:"weight_mode" validate:"required,oneof=percent budget"`
+	WeightValue    decimal.Decimal `json:"weight_value" validate:"required,gt=0"`
+	StockWeighting StockWeighting  `json:"stock_weighting,omitempty" validate:"omitempty,oneof=equal market_cap inverse_volatility custom signal_strength"`
 }
 
 // UpdateStrategyRequest represents the request to update a strategy
 type UpdateStrategyRequest struct {
-	Name        *string          `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
-	WeightMode  *WeightMode      `json:"weight_mode,omitempty" validate:"omitempty,oneof=percent budget"`
-	WeightValue *decimal.Decimal `json:"weight_value,omitempty" validate:"omitempty,gt=0"`
+	Name           *string          `json:"name,omitempty" validate:"omitempty,min=1,max=255"`
+	WeightMode     *WeightMode      `json:"weight_mode,omitempty" validate:"omitempty,oneof=percent budget"`
+	WeightValue    *decimal.Decimal `json:"weight_value,omitempty" validate:"omitempty,gt=0"`
+	StockWeighting *StockWeighting  `json:"stock_weighting,omitempty" validate:"omitempty,oneof=equal market_cap inverse_volatility custom signal_strength"`
 }
 
 // StrategyResponse represents the strategy data returned in API responses
 type StrategyResponse struct {
-	ID          uuid.UUID       `json:"id"`
-	UserID      uuid.UUID       `json:"user_id"`
-	Name        string          `json:"name"`
-	WeightMode  WeightMode      `json:"weight_mode"`
-	WeightValue decimal.Decimal `json:"weight_value"`
-	CreatedAt   time.Time       `json:"created_at"`
-	UpdatedAt   time.Time       `json:"updated_at"`
-	Stocks      []StrategyStock `json:"stocks,omitempty"`
+	ID             uuid.UUID       `json:"id"`
+	UserID         uuid.UUID       `json:"user_id"`
+	Name           string          `json:"name"`
+	WeightMode     WeightMode      `json:"weight_mode"`
+	WeightValue    decimal.Decimal `json:"weight_value"`
+	StockWeighting StockWeighting  `json:"stock_weighting"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	Stocks         []StrategyStock `json:"stocks,omitempty"`
 }
 
 // ToResponse converts a Strategy to StrategyResponse
 func (s *Strategy) ToResponse() *StrategyResponse {
 	return &StrategyResponse{
-		ID:          s.ID,
-		UserID:      s.UserID,
-		Name:        s.Name,
-		WeightMode:  s.WeightMode,
-		WeightValue: s.WeightValue,
-		CreatedAt:   s.CreatedAt,
-		UpdatedAt:   s.UpdatedAt,
-		Stocks:      s.Stocks,
+		ID:             s.ID,
+		UserID:         s.UserID,
+		Name:           s.Name,
+		WeightMode:     s.WeightMode,
+		WeightValue:    s.WeightValue,
+		StockWeighting: s.StockWeighting,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+		Stocks:         s.Stocks,
 	}
 }
 
@@ -76,6 +92,10 @@ func (s *Strategy) FromCreateRequest(req *CreateStrategyRequest, userID uuid.UUI
 	s.Name = req.Name
 	s.WeightMode = req.WeightMode
 	s.WeightValue = req.WeightValue
+	s.StockWeighting = req.StockWeighting
+	if s.StockWeighting == "" {
+		s.StockWeighting = StockWeightingEqual
+	}
 	s.CreatedAt = time.Now()
 	s.UpdatedAt = time.Now()
 }
@@ -91,5 +111,18 @@ func (s *Strategy) ApplyUpdate(req *UpdateStrategyRequest) {
 	if req.WeightValue != nil {
 		s.WeightValue = *req.WeightValue
 	}
+	if req.StockWeighting != nil {
+		s.StockWeighting = *req.StockWeighting
+	}
 	s.UpdatedAt = time.Now()
+}
+
+// IsValid reports whether the stock weighting is one of the supported schemes
+func (w StockWeighting) IsValid() bool {
+	switch w {
+	case StockWeightingEqual, StockWeightingMarketCap, StockWeightingInverseVolatility,
+		StockWeightingCustom, StockWeightingSignalStrength:
+		return true
+	}
+	return false
 }
\ No newline at end of file
@@ -0,0 +1,261 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// LotReliefMethod represents how a sell chooses the tax lots it relieves
+type LotReliefMethod string
+
+const (
+	LotReliefFIFO LotReliefMethod = "fifo"
+	LotReliefLIFO LotReliefMethod = "lifo"
+	// LotReliefSpecificID relieves the lots named by each sell, oldest first when a sell names none
+	LotReliefSpecificID LotReliefMethod = "specific_id"
+)
+
+// HoldingTerm classifies gains by how long the shares were held
+type HoldingTerm string
+
+const (
+	HoldingShortTerm HoldingTerm = "short_term"
+	HoldingLongTerm  HoldingTerm = "long_term"
+)
+
+// TermOf classifies shares acquired at acquiredAt and disposed of or valued at asOf; shares held for
+// more than one year are long-term
+func TermOf(acquiredAt, asOf time.Time) HoldingTerm {
+	if asOf.After(acquiredAt.AddDate(1, 0, 0)) {
+		return HoldingLongTerm
+	}
+	return HoldingShortTerm
+}
+
+// TaxLot represents the shares still held from a single buy, identified by the buy transaction's ID,
+// with the rate the buy converted the stock's currency to the portfolio base currency at
+type TaxLot struct {
+	ID         uuid.UUID       `json:"id"`
+	StockID    uuid.UUID       `json:"stock_id"`
+	AcquiredAt time.Time       `json:"acquired_at"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	CostBasis  decimal.Decimal `json:"cost_basis"`
+	FXRate     decimal.Decimal `json:"fx_rate"`
+
+	// Valuation at a current price (not stored in database)
+	Term          HoldingTerm      `json:"term,omitempty"`
+	CurrentValue  *decimal.Decimal `json:"current_value,omitempty"`
+	UnrealizedPnL *decimal.Decimal `json:"unrealized_pnl,omitempty"`
+}
+
+// UnitCost returns the lot's cost per share
+func (l *TaxLot) UnitCost() decimal.Decimal {
+	if !l.Quantity.IsPositive() {
+		return decimal.Zero
+	}
+	return l.CostBasis.Div(l.Quantity)
+}
+
+// LotSelection names a lot relieved by a sell and the number of its shares sold
+type LotSelection struct {
+	LotID    uuid.UUID       `json:"lot_id" validate:"required"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+// RealizedGain represents the gain from selling shares of a single lot
+type RealizedGain struct {
+	StockID       uuid.UUID       `json:"stock_id"`
+	LotID         uuid.UUID       `json:"lot_id"`
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	AcquiredAt    time.Time       `json:"acquired_at"`
+	SoldAt        time.Time       `json:"sold_at"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	CostBasis     decimal.Decimal `json:"cost_basis"`
+	Gain          decimal.Decimal `json:"gain"`
+	Term          HoldingTerm     `json:"term"`
+}
+
+// GainsSummary splits P&L into realized and unrealized gains, each by holding term. Unrealized gains
+// are only set when the open lots could be valued at a current price.
+type GainsSummary struct {
+	RealizedPnL         decimal.Decimal  `json:"realized_pnl"`
+	RealizedShortTerm   decimal.Decimal  `json:"realized_short_term"`
+	RealizedLongTerm    decimal.Decimal  `json:"realized_long_term"`
+	UnrealizedPnL       *decimal.Decimal `json:"unrealized_pnl,omitempty"`
+	UnrealizedShortTerm *decimal.Decimal `json:"unrealized_short_term,omitempty"`
+	UnrealizedLongTerm  *decimal.Decimal `json:"unrealized_long_term,omitempty"`
+}
+
+// ValueLots returns copies of lots valued at price as of asOf
+func ValueLots(lots []*TaxLot, price decimal.Decimal, asOf time.Time) []TaxLot {
+	valued := make([]TaxLot, 0, len(lots))
+	for _, lot := range lots {
+		copied := *lot
+		value := price.Mul(lot.Quantity).Round(2)
+		pnl := value.Sub(lot.CostBasis).Round(2)
+		copied.CostBasis = lot.CostBasis.Round(2)
+		copied.Term = TermOf(lot.AcquiredAt, asOf)
+		copied.CurrentValue = &value
+		copied.UnrealizedPnL = &pnl
+		valued = append(valued, copied)
+	}
+	return valued
+}
+
+// SummarizeGains totals realized gains and the unrealized gains of valued lots by holding term. Lots
+// without a valuation leave the unrealized gains unset.
+func SummarizeGains(realized []RealizedGain, lots []TaxLot) *GainsSummary {
+	summary := &GainsSummary{}
+	for _, gain := range realized {
+		summary.RealizedPnL = summary.RealizedPnL.Add(gain.Gain)
+		if gain.Term == HoldingLongTerm {
+			summary.RealizedLongTerm = summary.RealizedLongTerm.Add(gain.Gain)
+		} else {
+			summary.RealizedShortTerm = summary.RealizedShortTerm.Add(gain.Gain)
+		}
+	}
+	summary.RealizedPnL = summary.RealizedPnL.Round(2)
+	summary.RealizedShortTerm = summary.RealizedShortTerm.Round(2)
+	summary.RealizedLongTerm = summary.RealizedLongTerm.Round(2)
+
+	unrealized, shortTerm, longTerm := decimal.Zero, decimal.Zero, decimal.Zero
+	for _, lot := range lots {
+		if lot.UnrealizedPnL == nil {
+			return summary
+		}
+		unrealized = unrealized.Add(*lot.UnrealizedPnL)
+		if lot.Term == HoldingLongTerm {
+			longTerm = longTerm.Add(*lot.UnrealizedPnL)
+		} else {
+			shortTerm = shortTerm.Add(*lot.UnrealizedPnL)
+		}
+	}
+	summary.UnrealizedPnL = &unrealized
+	summary.UnrealizedShortTerm = &shortTerm
+	summary.UnrealizedLongTerm = &longTerm
+	return summary
+}
+
+// selectLots chooses the lots a sell relieves: the lots it names, or else its quantity taken from the
+// oldest lots first, or the newest first under LIFO
+func selectLots(lots []*TaxLot, t *Transaction, method LotReliefMethod) ([]LotSelection, error) {
+	if len(t.LotSelections) > 0 {
+		remaining := make(map[uuid.UUID]decimal.Decimal, len(lots))
+		for _, lot := range lots {
+			remaining[lot.ID] = lot.Quantity
+		}
+		for _, selection := range t.LotSelections {
+			available, exists := remaining[selection.LotID]
+			if !exists {
+				return nil, fmt.Errorf("lot %s is not an open lot of stock %s", selection.LotID, t.StockID)
+			}
+			if selection.Quantity.GreaterThan(available) {
+				return nil, fmt.Errorf("cannot sell %s shares of lot %s, only %s held", selection.Quantity, selection.LotID, available)
+			}
+			remaining[selection.LotID] = available.Sub(selection.Quantity)
+		}
+		return t.LotSelections, nil
+	}
+
+	ordered := lots
+	if method == LotReliefLIFO {
+		ordered = make([]*TaxLot, len(lots))
+		for i, lot := range lots {
+			ordered[len(lots)-1-i] = lot
+		}
+	}
+
+	var selections []LotSelection
+	remaining := t.Quantity
+	for _, lot := range ordered {
+		if !remaining.IsPositive() {
+			break
+		}
+		quantity := decimal.Min(remaining, lot.Quantity)
+		selections = append(selections, LotSelection{LotID: lot.ID, Quantity: quantity})
+		remaining = remaining.Sub(quantity)
+	}
+	if remaining.IsPositive() {
+		return nil, fmt.Errorf("cannot sell %s shares of stock %s, only %s held in lots", t.Quantity, t.StockID, t.Quantity.Sub(remaining))
+	}
+	return selections, nil
+}
+
+// relieveLots removes sold shares from a holding's lots, relieving cost basis lot by lot, and returns
+// the realized gains with the sale proceeds split across lots by quantity
+func relieveLots(holding *Holding, t *Transaction, selections []LotSelection) []RealizedGain {
+	gains := make([]RealizedGain, 0, len(selections))
+	proceedsLeft := t.Amount
+	for i, selection := range selections {
+		var lot *TaxLot
+		for _, candidate := range holding.Lots {
+			if candidate.ID == selection.LotID {
+				lot = candidate
+				break
+			}
+		}
+
+		cost := lot.CostBasis
+		if selection.Quantity.LessThan(lot.Quantity) {
+			cost = lot.UnitCost().Mul(selection.Quantity)
+		}
+		proceeds := proceedsLeft
+		if i < len(selections)-1 {
+			proceeds = t.Amount.Mul(selection.Quantity).Div(t.Quantity)
+		}
+		proceedsLeft = proceedsLeft.Sub(proceeds)
+
+		lot.Quantity = lot.Quantity.Sub(selection.Quantity)
+		lot.CostBasis = lot.CostBasis.Sub(cost)
+		holding.CostBasis = holding.CostBasis.Sub(cost)
+
+		gains = append(gains, RealizedGain{
+			StockID:       lot.StockID,
+			LotID:         lot.ID,
+			TransactionID: t.ID,
+			AcquiredAt:    lot.AcquiredAt,
+			SoldAt:        t.ExecutedAt,
+			Quantity:      selection.Quantity,
+			Proceeds:      proceeds,
+			CostBasis:     cost,
+			Gain:          proceeds.Sub(cost),
+			Term:          TermOf(lot.AcquiredAt, t.ExecutedAt),
+		})
+	}
+
+	open := holding.Lots[:0]
+	for _, lot := range holding.Lots {
+		if lot.Quantity.IsPositive() {
+			open = append(open, lot)
+		}
+	}
+	holding.Lots = open
+	return gains
+}
+
+// splitLots scales the share count of a holding's lots by a split, keeping each lot's cost basis
+func splitLots(holding *Holding, t *Transaction) {
+	held := holding.Quantity.Sub(t.Quantity)
+	ratio := holding.Quantity.Div(held)
+	allocated := decimal.Zero
+	for i, lot := range holding.Lots {
+		if i == len(holding.Lots)-1 {
+			lot.Quantity = holding.Quantity.Sub(allocated)
+			break
+		}
+		lot.Quantity = lot.Quantity.Mul(ratio)
+		allocated = allocated.Add(lot.Quantity)
+	}
+
+	open := holding.Lots[:0]
+	for _, lot := range holding.Lots {
+		if lot.Quantity.IsPositive() {
+			open = append(open, lot)
+		}
+	}
+	holding.Lots = open
+}
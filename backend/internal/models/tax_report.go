@@ -0,0 +1,194 @@
+package models
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// WashSaleWindowDays is how many days before or after a loss sale a repurchase of the same stock
+// makes it a wash sale
+const WashSaleWindowDays = 30
+
+// TaxReportRow represents a single disposal of shares from one tax lot
+type TaxReportRow struct {
+	StockID       uuid.UUID       `json:"stock_id"`
+	Ticker        string          `json:"ticker"`
+	LotID         uuid.UUID       `json:"lot_id"`
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	AcquiredAt    time.Time       `json:"acquired_at"`
+	SoldAt        time.Time       `json:"sold_at"`
+	Quantity      decimal.Decimal `json:"quantity"`
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	CostBasis     decimal.Decimal `json:"cost_basis"`
+	Gain          decimal.Decimal `json:"gain"`
+	Term          HoldingTerm     `json:"term"`
+	// WashSale flags a loss with a repurchase of the same stock within the wash-sale window
+	WashSale bool `json:"wash_sale"`
+	// DisallowedLoss is the part of the loss covered by repurchased shares
+	DisallowedLoss decimal.Decimal `json:"disallowed_loss"`
+}
+
+// TaxReportTotals represents the totals of a tax report
+type TaxReportTotals struct {
+	Proceeds       decimal.Decimal `json:"proceeds"`
+	CostBasis      decimal.Decimal `json:"cost_basis"`
+	Gain           decimal.Decimal `json:"gain"`
+	ShortTermGain  decimal.Decimal `json:"short_term_gain"`
+	LongTermGain   decimal.Decimal `json:"long_term_gain"`
+	DisallowedLoss decimal.Decimal `json:"disallowed_loss"`
+}
+
+// TaxReport represents the disposals of a portfolio within a calendar year
+type TaxReport struct {
+	PortfolioID uuid.UUID       `json:"portfolio_id"`
+	Year        int             `json:"year"`
+	Rows        []TaxReportRow  `json:"rows"`
+	Totals      TaxReportTotals `json:"totals"`
+}
+
+// NewTaxReport builds the report of gains realized during year (UTC) from a ledger replay. The
+// transactions are the ones replayed and are searched for repurchases that make a loss a wash sale,
+// so they should extend past the end of the year by the wash-sale window.
+func NewTaxReport(portfolioID uuid.UUID, year int, realized []RealizedGain, transactions []*Transaction) *TaxReport {
+	report := &TaxReport{PortfolioID: portfolioID, Year: year, Rows: []TaxReportRow{}}
+
+	tickers := make(map[uuid.UUID]string)
+	var buys []*Transaction
+	for _, t := range transactions {
+		if t.StockID == nil {
+			continue
+		}
+		if t.Stock != nil {
+			tickers[*t.StockID] = t.Stock.Ticker
+		}
+		if t.Type == TransactionBuy {
+			buys = append(buys, t)
+		}
+	}
+
+	// Replacement shares cover one loss each, so they are matched to loss sales in the order of the
+	// replay, including sales outside the year that took them first
+	remaining := make(map[uuid.UUID]decimal.Decimal, len(buys))
+	for _, buy := range buys {
+		remaining[buy.ID] = buy.Quantity
+	}
+
+	for _, gain := range realized {
+		var replaced decimal.Decimal
+		if gain.Gain.Round(2).IsNegative() {
+			replaced = repurchasedShares(buys, remaining, gain)
+		}
+		if gain.SoldAt.UTC().Year() != year {
+			continue
+		}
+
+		row := TaxReportRow{
+			StockID:        gain.StockID,
+			Ticker:         tickers[gain.StockID],
+			LotID:          gain.LotID,
+			TransactionID:  gain.TransactionID,
+			AcquiredAt:     gain.AcquiredAt,
+			SoldAt:         gain.SoldAt,
+			Quantity:       gain.Quantity,
+			Proceeds:       gain.Proceeds.Round(2),
+			CostBasis:      gain.CostBasis.Round(2),
+			Gain:           gain.Gain.Round(2),
+			Term:           gain.Term,
+			DisallowedLoss: decimal.Zero,
+		}
+
+		if replaced.IsPositive() {
+			row.WashSale = true
+			row.DisallowedLoss = row.Gain.Neg()
+			if replaced.LessThan(gain.Quantity) {
+				row.DisallowedLoss = row.DisallowedLoss.Mul(replaced).Div(gain.Quantity).Round(2)
+			}
+		}
+
+		report.Rows = append(report.Rows, row)
+		report.Totals.Proceeds = report.Totals.Proceeds.Add(row.Proceeds)
+		report.Totals.CostBasis = report.Totals.CostBasis.Add(row.CostBasis)
+		report.Totals.Gain = report.Totals.Gain.Add(row.Gain)
+		if row.Term == HoldingLongTerm {
+			report.Totals.LongTermGain = report.Totals.LongTermGain.Add(row.Gain)
+		} else {
+			report.Totals.ShortTermGain = report.Totals.ShortTermGain.Add(row.Gain)
+		}
+		report.Totals.DisallowedLoss = report.Totals.DisallowedLoss.Add(row.DisallowedLoss)
+	}
+
+	return report
+}
+
+// repurchasedShares takes up to the sold quantity from the shares of the sold stock bought within the
+// wash-sale window around a sale, other than the lot sold, that earlier loss sales have not taken.
+// remaining holds the shares of each buy still available and is reduced by the shares taken.
+func repurchasedShares(buys []*Transaction, remaining map[uuid.UUID]decimal.Decimal, gain RealizedGain) decimal.Decimal {
+	windowStart := gain.SoldAt.AddDate(0, 0, -WashSaleWindowDays)
+	windowEnd := gain.SoldAt.AddDate(0, 0, WashSaleWindowDays)
+
+	shares := decimal.Zero
+	for _, buy := range buys {
+		if shares.GreaterThanOrEqual(gain.Quantity) {
+			break
+		}
+		if *buy.StockID != gain.StockID || buy.ID == gain.LotID {
+			continue
+		}
+		if buy.ExecutedAt.Before(windowStart) || buy.ExecutedAt.After(windowEnd) {
+			continue
+		}
+		taken := decimal.Min(remaining[buy.ID], gain.Quantity.Sub(shares))
+		remaining[buy.ID] = remaining[buy.ID].Sub(taken)
+		shares = shares.Add(taken)
+	}
+	return shares
+}
+
+// WriteCSV writes the report rows followed by a totals row as CSV
+func (r *TaxReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"ticker", "quantity", "acquired_date", "sold_date", "proceeds", "cost_basis", "gain", "term", "wash_sale", "disallowed_loss"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		record := []string{
+			row.Ticker,
+			row.Quantity.String(),
+			row.AcquiredAt.UTC().Format("2006-01-02"),
+			row.SoldAt.UTC().Format("2006-01-02"),
+			row.Proceeds.StringFixed(2),
+			row.CostBasis.StringFixed(2),
+			row.Gain.StringFixed(2),
+			string(row.Term),
+			strconv.FormatBool(row.WashSale),
+			row.DisallowedLoss.StringFixed(2),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	totals := []string{
+		"TOTAL", "", "", "",
+		r.Totals.Proceeds.StringFixed(2),
+		r.Totals.CostBasis.StringFixed(2),
+		r.Totals.Gain.StringFixed(2),
+		"", "",
+		r.Totals.DisallowedLoss.StringFixed(2),
+	}
+	if err := writer.Write(totals); err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
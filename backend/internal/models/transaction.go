@@ -0,0 +1,406 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// TransactionType represents the kind of ledger entry
+type TransactionType string
+
+const (
+	TransactionBuy        TransactionType = "buy"
+	TransactionSell       TransactionType = "sell"
+	TransactionDeposit    TransactionType = "deposit"
+	TransactionWithdrawal TransactionType = "withdrawal"
+	TransactionDividend   TransactionType = "dividend"
+	TransactionFee        TransactionType = "fee"
+	TransactionSplit      TransactionType = "split"
+)
+
+// Transaction represents an append-only ledger entry for a portfolio. Price and Amount are in the
+// portfolio base currency, converted from the stock's currency at FXRate.
+type Transaction struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	PortfolioID uuid.UUID       `json:"portfolio_id" db:"portfolio_id"`
+	StockID     *uuid.UUID      `json:"stock_id,omitempty" db:"stock_id"`
+	Type        TransactionType `json:"type" db:"type" validate:"required,oneof=buy sell deposit withdrawal dividend fee split"`
+	Quantity    decimal.Decimal `json:"quantity" db:"quantity"`
+	Price       decimal.Decimal `json:"price" db:"price" validate:"gte=0"`
+	Amount      decimal.Decimal `json:"amount" db:"amount" validate:"gte=0"`
+	FXRate      decimal.Decimal `json:"fx_rate" db:"fx_rate"`
+	Notes       *string         `json:"notes,omitempty" db:"notes"`
+	ExecutedAt  time.Time       `json:"executed_at" db:"executed_at"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	// LotSelections are the tax lots a sell relieved
+	LotSelections []LotSelection `json:"lots,omitempty" db:"lot_selections"`
+
+	// Related data (not stored in database)
+	Stock *Stock `json:"stock,omitempty"`
+}
+
+// CreateTransactionRequest represents the request to record a new ledger entry
+type CreateTransactionRequest struct {
+	StockID    *uuid.UUID      `json:"stock_id,omitempty"`
+	Type       TransactionType `json:"type" validate:"required,oneof=buy sell deposit withdrawal dividend fee split"`
+	Quantity   decimal.Decimal `json:"quantity"`
+	Price      decimal.Decimal `json:"price" validate:"gte=0"`
+	Amount     decimal.Decimal `json:"amount" validate:"gte=0"`
+	Notes      *string         `json:"notes,omitempty" validate:"omitempty,max=500"`
+	ExecutedAt *time.Time      `json:"executed_at,omitempty"`
+	// Lots names the tax lots a sell relieves in portfolios using specific identification
+	Lots []LotSelection `json:"lots,omitempty" validate:"omitempty,dive"`
+	// FXRate is the rate converting the stock's currency to the portfolio base currency Price is in;
+	// when omitted it is looked up for stocks quoted in another currency
+	FXRate *decimal.Decimal `json:"fx_rate,omitempty" validate:"omitempty,gt=0"`
+}
+
+// TransactionResponse represents the transaction data returned in API responses
+type TransactionResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	PortfolioID uuid.UUID       `json:"portfolio_id"`
+	StockID     *uuid.UUID      `json:"stock_id,omitempty"`
+	Type        TransactionType `json:"type"`
+	Quantity    decimal.Decimal `json:"quantity"`
+	Price       decimal.Decimal `json:"price"`
+	Amount      decimal.Decimal `json:"amount"`
+	FXRate      decimal.Decimal `json:"fx_rate"`
+	CashEffect  decimal.Decimal `json:"cash_effect"`
+	Notes       *string         `json:"notes,omitempty"`
+	ExecutedAt  time.Time       `json:"executed_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Lots        []LotSelection  `json:"lots,omitempty"`
+	Stock       *Stock          `json:"stock,omitempty"`
+}
+
+// Holding represents the shares and cost basis of one stock derived from the ledger
+type Holding struct {
+	StockID   uuid.UUID       `json:"stock_id"`
+	Quantity  decimal.Decimal `json:"quantity"`
+	CostBasis decimal.Decimal `json:"cost_basis"`
+	// Lots are the open tax lots in acquisition order
+	Lots []*TaxLot `json:"lots"`
+}
+
+// Ledger represents the portfolio state derived by replaying its transactions
+type Ledger struct {
+	Holdings map[uuid.UUID]*Holding `json:"holdings"`
+	Cash     decimal.Decimal        `json:"cash"`
+	// Realized are the gains of every lot relieved by a sell, in execution order
+	Realized []RealizedGain `json:"realized"`
+	// Dividends is the dividend income received
+	Dividends decimal.Decimal `json:"dividends"`
+	// Overdraft is the first entry that left cash below zero, or nil when cash never went negative
+	Overdraft *CashOverdraft `json:"overdraft,omitempty"`
+}
+
+// CashOverdraft represents a ledger entry that left the running cash balance below zero
+type CashOverdraft struct {
+	TransactionID uuid.UUID       `json:"transaction_id"`
+	ExecutedAt    time.Time       `json:"executed_at"`
+	Cash          decimal.Decimal `json:"cash"`
+}
+
+// LedgerUpdate represents the portfolio state derived from its ledger that is written with new entries
+type LedgerUpdate struct {
+	Positions   []*Position
+	CashBalance decimal.Decimal
+	// InvestmentChange is added to the total investment, which does not go below zero
+	InvestmentChange decimal.Decimal
+}
+
+// ToResponse converts a Transaction to TransactionResponse
+func (t *Transaction) ToResponse() *TransactionResponse {
+	return &TransactionResponse{
+		ID:          t.ID,
+		PortfolioID: t.PortfolioID,
+		StockID:     t.StockID,
+		Type:        t.Type,
+		Quantity:    t.Quantity,
+		Price:       t.Price,
+		Amount:      t.Amount,
+		FXRate:      t.FXRate,
+		CashEffect:  t.CashEffect(),
+		Notes:       t.Notes,
+		ExecutedAt:  t.ExecutedAt,
+		CreatedAt:   t.CreatedAt,
+		Lots:        t.LotSelections,
+		Stock:       t.Stock,
+	}
+}
+
+// FromCreateRequest creates a Transaction from CreateTransactionRequest
+func (t *Transaction) FromCreateRequest(req *CreateTransactionRequest, portfolioID uuid.UUID) {
+	t.ID = uuid.New()
+	t.PortfolioID = portfolioID
+	t.StockID = req.StockID
+	t.Type = req.Type
+	t.Quantity = req.Quantity
+	t.Price = req.Price
+	t.Amount = req.Amount
+	t.FXRate = decimal.NewFromInt(1)
+	if req.FXRate != nil {
+		t.FXRate = *req.FXRate
+	}
+	t.Notes = req.Notes
+	t.LotSelections = req.Lots
+	t.CreatedAt = time.Now()
+	t.ExecutedAt = t.CreatedAt
+	if req.ExecutedAt != nil {
+		t.ExecutedAt = *req.ExecutedAt
+	}
+
+	// Trades carry their gross value so the ledger can be replayed without prices
+	if (t.Type == TransactionBuy || t.Type == TransactionSell) && t.Amount.IsZero() {
+		t.Amount = t.Price.Mul(t.Quantity).Round(2)
+	}
+}
+
+// NewTradeTransaction creates a buy or sell ledger entry for a stock
+func NewTradeTransaction(portfolioID, stockID uuid.UUID, txType TransactionType, quantity decimal.Decimal, price decimal.Decimal, executedAt time.Time) *Transaction {
+	return &Transaction{
+		ID:          uuid.New(),
+		PortfolioID: portfolioID,
+		StockID:     &stockID,
+		Type:        txType,
+		Quantity:    quantity,
+		Price:       price,
+		Amount:      price.Mul(quantity).Round(2),
+		FXRate:      decimal.NewFromInt(1),
+		ExecutedAt:  executedAt,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// NewCashTransaction creates a deposit, withdrawal or fee ledger entry
+func NewCashTransaction(portfolioID uuid.UUID, txType TransactionType, amount decimal.Decimal, executedAt time.Time) *Transaction {
+	return &Transaction{
+		ID:          uuid.New(),
+		PortfolioID: portfolioID,
+		Type:        txType,
+		Amount:      amount,
+		FXRate:      decimal.NewFromInt(1),
+		ExecutedAt:  executedAt,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// SetFXRate records the rate converting the traded stock's currency to the portfolio base currency,
+// keeping the rate of 1 when it is not positive
+func (t *Transaction) SetFXRate(rate decimal.Decimal) {
+	if rate.IsPositive() {
+		t.FXRate = rate
+	}
+}
+
+// Validate checks the field combinations required by each transaction type
+func (t *Transaction) Validate() error {
+	switch t.Type {
+	case TransactionBuy, TransactionSell:
+		if t.StockID == nil {
+			return &ValidationError{Field: "stock_id", Tag: "required", Message: fmt.Sprintf("stock_id is required for %s transactions", t.Type)}
+		}
+		if !t.Quantity.IsPositive() {
+			return &ValidationError{Field: "quantity", Tag: "gt", Message: "quantity must be greater than 0"}
+		}
+		if t.Price.LessThanOrEqual(decimal.Zero) {
+			return &ValidationError{Field: "price", Tag: "gt", Message: "price must be greater than 0"}
+		}
+	case TransactionDeposit, TransactionWithdrawal, TransactionFee, TransactionDividend:
+		if t.Amount.LessThanOrEqual(decimal.Zero) {
+			return &ValidationError{Field: "amount", Tag: "gt", Message: fmt.Sprintf("amount must be greater than 0 for %s transactions", t.Type)}
+		}
+	case TransactionSplit:
+		if t.StockID == nil {
+			return &ValidationError{Field: "stock_id", Tag: "required", Message: "stock_id is required for split transactions"}
+		}
+		if t.Quantity.IsZero() {
+			return &ValidationError{Field: "quantity", Tag: "required", Message: "quantity must be the non-zero change in shares held"}
+		}
+	default:
+		return &ValidationError{Field: "type", Tag: "oneof", Message: fmt.Sprintf("unknown transaction type %s", t.Type)}
+	}
+
+	if len(t.LotSelections) > 0 {
+		if t.Type != TransactionSell {
+			return &ValidationError{Field: "lots", Tag: "excluded_unless", Message: "lots can only be named for sell transactions"}
+		}
+		total := decimal.Zero
+		for _, selection := range t.LotSelections {
+			if !selection.Quantity.IsPositive() {
+				return &ValidationError{Field: "lots", Tag: "gt", Message: "lot quantities must be greater than 0"}
+			}
+			total = total.Add(selection.Quantity)
+		}
+		if !total.Equal(t.Quantity) {
+			return &ValidationError{Field: "lots", Tag: "eqfield", Message: fmt.Sprintf("lot quantities add up to %s, not the %s shares sold", total, t.Quantity)}
+		}
+	}
+
+	return nil
+}
+
+// CashEffect returns the signed change in cash caused by the transaction
+func (t *Transaction) CashEffect() decimal.Decimal {
+	switch t.Type {
+	case TransactionDeposit, TransactionSell, TransactionDividend:
+		return t.Amount
+	case TransactionWithdrawal, TransactionBuy, TransactionFee:
+		return t.Amount.Neg()
+	default:
+		return decimal.Zero
+	}
+}
+
+// AverageCost returns the average cost per share of the holding
+func (h *Holding) AverageCost() decimal.Decimal {
+	if !h.Quantity.IsPositive() {
+		return decimal.Zero
+	}
+	return h.CostBasis.Div(h.Quantity)
+}
+
+// EntryFXRate returns the rate the open lots were bought at, weighted by cost, or 1 when none are held
+func (h *Holding) EntryFXRate() decimal.Decimal {
+	cost, localCost := decimal.Zero, decimal.Zero
+	for _, lot := range h.Lots {
+		cost = cost.Add(lot.CostBasis)
+		localCost = localCost.Add(lot.CostBasis.Div(fxRateOrOne(lot.FXRate)))
+	}
+	if !localCost.IsPositive() {
+		return decimal.NewFromInt(1)
+	}
+	return cost.Div(localCost)
+}
+
+// BuildLedger replays transactions in execution order and derives holdings, their tax lots and cash.
+// Sells relieve the lots they name, or else lots chosen by method, realizing a gain per lot; splits
+// change the share count but not the cost basis. The first entry that overdraws cash is recorded.
+func BuildLedger(transactions []*Transaction, method LotReliefMethod) (*Ledger, error) {
+	ordered := make([]*Transaction, len(transactions))
+	copy(ordered, transactions)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].ExecutedAt.Before(ordered[j].ExecutedAt)
+	})
+
+	ledger := &Ledger{
+		Holdings:  make(map[uuid.UUID]*Holding),
+		Cash:      decimal.Zero,
+		Dividends: decimal.Zero,
+	}
+
+	for _, t := range ordered {
+		ledger.Cash = ledger.Cash.Add(t.CashEffect())
+		if ledger.Cash.IsNegative() && ledger.Overdraft == nil {
+			ledger.Overdraft = &CashOverdraft{TransactionID: t.ID, ExecutedAt: t.ExecutedAt, Cash: ledger.Cash}
+		}
+		if t.Type == TransactionDividend {
+			ledger.Dividends = ledger.Dividends.Add(t.Amount)
+		}
+
+		if t.StockID == nil {
+			continue
+		}
+
+		holding, exists := ledger.Holdings[*t.StockID]
+		if !exists {
+			holding = &Holding{StockID: *t.StockID, Quantity: decimal.Zero, CostBasis: decimal.Zero}
+		}
+
+		switch t.Type {
+		case TransactionBuy:
+			holding.Quantity = holding.Quantity.Add(t.Quantity)
+			holding.CostBasis = holding.CostBasis.Add(t.Amount)
+			holding.Lots = append(holding.Lots, &TaxLot{
+				ID:         t.ID,
+				StockID:    *t.StockID,
+				AcquiredAt: t.ExecutedAt,
+				Quantity:   t.Quantity,
+				CostBasis:  t.Amount,
+				FXRate:     fxRateOrOne(t.FXRate),
+			})
+		case TransactionSell:
+			if t.Quantity.GreaterThan(holding.Quantity) {
+				return nil, fmt.Errorf("cannot sell %s shares of stock %s, only %s held", t.Quantity, t.StockID, holding.Quantity)
+			}
+			selections, err := selectLots(holding.Lots, t, method)
+			if err != nil {
+				return nil, err
+			}
+			ledger.Realized = append(ledger.Realized, relieveLots(holding, t, selections)...)
+			holding.Quantity = holding.Quantity.Sub(t.Quantity)
+			if holding.Quantity.IsZero() {
+				holding.CostBasis = decimal.Zero
+			}
+		case TransactionSplit:
+			if !holding.Quantity.IsPositive() {
+				return nil, fmt.Errorf("cannot split stock %s, no shares are held on %s", t.StockID, t.ExecutedAt.UTC().Format("2006-01-02"))
+			}
+			if holding.Quantity.Add(t.Quantity).IsNegative() {
+				return nil, fmt.Errorf("split of stock %s would leave a negative share count", t.StockID)
+			}
+			holding.Quantity = holding.Quantity.Add(t.Quantity)
+			splitLots(holding, t)
+		}
+
+		ledger.Holdings[*t.StockID] = holding
+	}
+
+	return ledger, nil
+}
+
+// OpenHoldings returns the holdings with shares still held, ordered by stock ID
+func (l *Ledger) OpenHoldings() []*Holding {
+	holdings := make([]*Holding, 0, len(l.Holdings))
+	for _, holding := range l.Holdings {
+		if holding.Quantity.IsPositive() {
+			holdings = append(holdings, holding)
+		}
+	}
+	sort.Slice(holdings, func(i, j int) bool {
+		return holdings[i].StockID.String() < holdings[j].StockID.String()
+	})
+	return holdings
+}
+
+// Quantity returns the number of shares held for a stock
+func (l *Ledger) Quantity(stockID uuid.UUID) decimal.Decimal {
+	if holding, exists := l.Holdings[stockID]; exists {
+		return holding.Quantity
+	}
+	return decimal.Zero
+}
+
+// RealizedFor returns the realized gains of a stock
+func (l *Ledger) RealizedFor(stockID uuid.UUID) []RealizedGain {
+	var gains []RealizedGain
+	for _, gain := range l.Realized {
+		if gain.StockID == stockID {
+			gains = append(gains, gain)
+		}
+	}
+	return gains
+}
+
+// LotSelections returns the lots relieved by a sell transaction
+func (l *Ledger) LotSelections(transactionID uuid.UUID) []LotSelection {
+	var selections []LotSelection
+	for _, gain := range l.Realized {
+		if gain.TransactionID == transactionID {
+			selections = append(selections, LotSelection{LotID: gain.LotID, Quantity: gain.Quantity})
+		}
+	}
+	return selections
+}
+
+// fxRateOrOne returns rate, or 1 for entries recorded before FX rates were tracked
+func fxRateOrOne(rate decimal.Decimal) decimal.Decimal {
+	if !rate.IsPositive() {
+		return decimal.NewFromInt(1)
+	}
+	return rate
+}
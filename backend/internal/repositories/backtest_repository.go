@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+)
+
+// BacktestRepository defines the interface for backtest job data operations
+type BacktestRepository interface {
+	Create(ctx context.Context, backtest *models.Backtest) error
+	Update(ctx context.Context, backtest *models.Backtest) error
+	GetByID(ctx context.Context, id, userID uuid.UUID) (*models.Backtest, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Backtest, error)
+}
+
+// backtestRepository implements the BacktestRepository interface
+type backtestRepository struct {
+	db *sql.DB
+}
+
+// NewBacktestRepository creates a new backtest repository instance
+func NewBacktestRepository(db *sql.DB) BacktestRepository {
+	return &backtestRepository{db: db}
+}
+
+// Create records a new backtest job
+func (r *backtestRepository) Create(ctx context.Context, backtest *models.Backtest) error {
+	requestJSON, err := json.Marshal(backtest.Request)
+	if err != nil {
+		return fmt.Errorf("failed to marshal backtest request: %w", err)
+	}
+
+	query := `
+		INSERT INTO backtests (id, user_id, status, request, error, created_at, started_at, completed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err = r.db.ExecContext(ctx, query,
+		backtest.ID, backtest.UserID, backtest.Status, requestJSON,
+		backtest.Error, backtest.CreatedAt, backtest.StartedAt, backtest.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create backtest: %w", err)
+	}
+
+	return nil
+}
+
+// Update saves the status and outcome of a backtest job
+func (r *backtestRepository) Update(ctx context.Context, backtest *models.Backtest) error {
+	var resultJSON []byte
+	if backtest.Result != nil {
+		var err error
+		resultJSON, err = json.Marshal(backtest.Result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal backtest result: %w", err)
+		}
+	}
+
+	query := `
+		UPDATE backtests
+		SET status = $1, result = $2, error = $3, started_at = $4, completed_at = $5
+		WHERE id = $6`
+
+	result, err := r.db.ExecContext(ctx, query,
+		backtest.Status, resultJSON, backtest.Error, backtest.StartedAt, backtest.CompletedAt, backtest.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update backtest: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "backtest"}
+	}
+
+	return nil
+}
+
+// GetByID retrieves a backtest job of a user by ID
+func (r *backtestRepository) GetByID(ctx context.Context, id, userID uuid.UUID) (*models.Backtest, error) {
+	query := `
+		SELECT id, user_id, status, request, result, error, created_at, started_at, completed_at
+		FROM backtests
+		WHERE id = $1 AND user_id = $2`
+
+	backtest, err := scanBacktest(r.db.QueryRowContext(ctx, query, id, userID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "backtest"}
+		}
+		return nil, fmt.Errorf("failed to get backtest: %w", err)
+	}
+
+	return backtest, nil
+}
+
+// GetByUserID retrieves the backtest jobs of a user, newest first. Results are left out to keep
+// the listing small; fetch a single backtest to get its result.
+func (r *backtestRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Backtest, error) {
+	query := `
+		SELECT id, user_id, status, request, NULL, error, created_at, started_at, completed_at
+		FROM backtests
+		WHERE user_id = $1
+		ORDER BY created_at DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backtests: %w", err)
+	}
+	defer rows.Close()
+
+	var backtests []*models.Backtest
+	for rows.Next() {
+		backtest, err := scanBacktest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan backtest: %w", err)
+		}
+		backtests = append(backtests, backtest)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating backtests: %w", err)
+	}
+
+	return backtests, nil
+}
+
+// scanBacktest scans a backtest row and decodes its stored request and result
+func scanBacktest(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.Backtest, error) {
+	backtest := &models.Backtest{}
+	var requestJSON, resultJSON []byte
+
+	err := row.Scan(&backtest.ID, &backtest.UserID, &backtest.Status, &requestJSON, &resultJSON,
+		&backtest.Error, &backtest.CreatedAt, &backtest.StartedAt, &backtest.CompletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(requestJSON, &backtest.Request); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal backtest request: %w", err)
+	}
+
+	if resultJSON != nil {
+		if err := json.Unmarshal(resultJSON, &backtest.Result); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal backtest result: %w", err)
+		}
+	}
+
+	return backtest, nil
+}
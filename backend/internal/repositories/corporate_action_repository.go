@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+)
+
+// CorporateActionRepository defines the interface for corporate action data operations
+type CorporateActionRepository interface {
+	Create(ctx context.Context, action *models.CorporateAction) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.CorporateAction, error)
+	GetAll(ctx context.Context, stockID *uuid.UUID) ([]*models.CorporateAction, error)
+	GetByStockID(ctx context.Context, stockID uuid.UUID) ([]*models.CorporateAction, error)
+	MarkApplied(ctx context.Context, id uuid.UUID, appliedAt time.Time) error
+	GetUnadjustedPortfolioIDs(ctx context.Context, action *models.CorporateAction) ([]uuid.UUID, error)
+	ApplyToPortfolio(ctx context.Context, adjustment *models.CorporateActionAdjustment, transaction *models.Transaction, positions []*models.Position) error
+	GetAdjustments(ctx context.Context, actionID uuid.UUID) ([]*models.CorporateActionAdjustment, error)
+}
+
+// corporateActionRepository implements the CorporateActionRepository interface
+type corporateActionRepository struct {
+	db *sql.DB
+}
+
+// NewCorporateActionRepository creates a new corporate action repository instance
+func NewCorporateActionRepository(db *sql.DB) CorporateActionRepository {
+	return &corporateActionRepository{db: db}
+}
+
+// Create records a corporate action unless one is already recorded for the stock on the same
+// effective date, reporting whether it was created
+func (r *corporateActionRepository) Create(ctx context.Context, action *models.CorporateAction) (bool, error) {
+	query := `
+		INSERT INTO corporate_actions (id, stock_id, type, new_shares, old_shares, effective_date, source, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (stock_id, effective_date) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query,
+		action.ID, action.StockID, action.Type, action.NewShares, action.OldShares,
+		action.EffectiveDate, action.Source, action.Status, action.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create corporate action: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// corporateActionColumns are the columns scanned by scanCorporateAction
+const corporateActionColumns = `
+		SELECT ca.id, ca.stock_id, ca.type, ca.new_shares, ca.old_shares, ca.effective_date,
+		       ca.source, ca.status, ca.applied_at, ca.created_at, s.ticker, s.name
+		FROM corporate_actions ca
+		JOIN stocks s ON ca.stock_id = s.id`
+
+// GetByID retrieves a corporate action by ID
+func (r *corporateActionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CorporateAction, error) {
+	row := r.db.QueryRowContext(ctx, corporateActionColumns+`
+		WHERE ca.id = $1`, id)
+
+	action, err := scanCorporateAction(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "corporate action"}
+		}
+		return nil, fmt.Errorf("failed to get corporate action: %w", err)
+	}
+
+	return action, nil
+}
+
+// GetAll retrieves corporate actions by effective date, newest first, optionally for a single stock
+func (r *corporateActionRepository) GetAll(ctx context.Context, stockID *uuid.UUID) ([]*models.CorporateAction, error) {
+	query := corporateActionColumns + `
+		WHERE ($1::uuid IS NULL OR ca.stock_id = $1)
+		ORDER BY ca.effective_date DESC, ca.created_at DESC`
+
+	return r.queryCorporateActions(ctx, query, stockID)
+}
+
+// GetByStockID retrieves the corporate actions of a stock, oldest first
+func (r *corporateActionRepository) GetByStockID(ctx context.Context, stockID uuid.UUID) ([]*models.CorporateAction, error) {
+	query := corporateActionColumns + `
+		WHERE ca.stock_id = $1
+		ORDER BY ca.effective_date ASC`
+
+	return r.queryCorporateActions(ctx, query, stockID)
+}
+
+// queryCorporateActions runs a corporate action query and scans its rows
+func (r *corporateActionRepository) queryCorporateActions(ctx context.Context, query string, args ...interface{}) ([]*models.CorporateAction, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get corporate actions: %w", err)
+	}
+	defer rows.Close()
+
+	var actions []*models.CorporateAction
+	for rows.Next() {
+		action, err := scanCorporateAction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan corporate action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating corporate actions: %w", err)
+	}
+
+	return actions, nil
+}
+
+// scanCorporateAction scans a corporate action row with its stock
+func scanCorporateAction(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.CorporateAction, error) {
+	action := &models.CorporateAction{}
+	var ticker, name string
+
+	err := row.Scan(&action.ID, &action.StockID, &action.Type, &action.NewShares, &action.OldShares,
+		&action.EffectiveDate, &action.Source, &action.Status, &action.AppliedAt, &action.CreatedAt,
+		&ticker, &name)
+	if err != nil {
+		return nil, err
+	}
+
+	action.Stock = &models.Stock{ID: action.StockID, Ticker: ticker, Name: name}
+	return action, nil
+}
+
+// MarkApplied records that a corporate action has been applied to every affected portfolio
+func (r *corporateActionRepository) MarkApplied(ctx context.Context, id uuid.UUID, appliedAt time.Time) error {
+	query := `UPDATE corporate_actions SET status = $1, applied_at = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, models.CorporateActionApplied, appliedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark corporate action applied: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "corporate action"}
+	}
+
+	return nil
+}
+
+// GetUnadjustedPortfolioIDs retrieves the portfolios that traded the stock of a corporate action
+// before its effective date and have not been adjusted for it yet
+func (r *corporateActionRepository) GetUnadjustedPortfolioIDs(ctx context.Context, action *models.CorporateAction) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT t.portfolio_id
+		FROM transactions t
+		WHERE t.stock_id = $1 AND t.executed_at < $2
+		  AND NOT EXISTS (
+		      SELECT 1 FROM corporate_action_adjustments a
+		      WHERE a.corporate_action_id = $3 AND a.portfolio_id = t.portfolio_id
+		  )`
+
+	rows, err := r.db.QueryContext(ctx, query, action.StockID, action.EffectiveDate, action.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected portfolios: %w", err)
+	}
+	defer rows.Close()
+
+	var portfolioIDs []uuid.UUID
+	for rows.Next() {
+		var portfolioID uuid.UUID
+		if err := rows.Scan(&portfolioID); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio ID: %w", err)
+		}
+		portfolioIDs = append(portfolioIDs, portfolioID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating affected portfolios: %w", err)
+	}
+
+	return portfolioIDs, nil
+}
+
+// ApplyToPortfolio records the split ledger entry of a corporate action, replaces the positions
+// derived from the ledger and audits the adjustment in a single database transaction
+func (r *corporateActionRepository) ApplyToPortfolio(ctx context.Context, adjustment *models.CorporateActionAdjustment, transaction *models.Transaction, positions []*models.Position) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	if err := replacePositions(ctx, tx, adjustment.PortfolioID, positions); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO corporate_action_adjustments (id, corporate_action_id, portfolio_id, stock_id, transaction_id,
+			quantity_before, quantity_after, entry_price_before, entry_price_after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = tx.ExecContext(ctx, query,
+		adjustment.ID, adjustment.CorporateActionID, adjustment.PortfolioID, adjustment.StockID, adjustment.TransactionID,
+		adjustment.QuantityBefore, adjustment.QuantityAfter, adjustment.EntryPriceBefore, adjustment.EntryPriceAfter,
+		adjustment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record corporate action adjustment: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetAdjustments retrieves the portfolio adjustments made for a corporate action
+func (r *corporateActionRepository) GetAdjustments(ctx context.Context, actionID uuid.UUID) ([]*models.CorporateActionAdjustment, error) {
+	query := `
+		SELECT id, corporate_action_id, portfolio_id, stock_id, transaction_id,
+		       quantity_before, quantity_after, entry_price_before, entry_price_after, created_at
+		FROM corporate_action_adjustments
+		WHERE corporate_action_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, actionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get corporate action adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	var adjustments []*models.CorporateActionAdjustment
+	for rows.Next() {
+		var adjustment models.CorporateActionAdjustment
+		err := rows.Scan(&adjustment.ID, &adjustment.CorporateActionID, &adjustment.PortfolioID,
+			&adjustment.StockID, &adjustment.TransactionID, &adjustment.QuantityBefore, &adjustment.QuantityAfter,
+			&adjustment.EntryPriceBefore, &adjustment.EntryPriceAfter, &adjustment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan corporate action adjustment: %w", err)
+		}
+		adjustments = append(adjustments, &adjustment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating corporate action adjustments: %w", err)
+	}
+
+	return adjustments, nil
+}
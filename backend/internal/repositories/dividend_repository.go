@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"portfolio-app/internal/models"
+)
+
+// DividendRepository defines the interface for dividend data operations
+type DividendRepository interface {
+	Create(ctx context.Context, event *models.DividendEvent) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DividendEvent, error)
+	GetAll(ctx context.Context, stockID *uuid.UUID) ([]*models.DividendEvent, error)
+	GetDue(ctx context.Context, asOf time.Time) ([]*models.DividendEvent, error)
+	MarkPaid(ctx context.Context, id uuid.UUID, paidAt time.Time) error
+	GetUnpaidPortfolioIDs(ctx context.Context, event *models.DividendEvent) ([]uuid.UUID, error)
+	RecordPayment(ctx context.Context, payment *models.DividendPayment, transactions []*models.Transaction, positions []*models.Position, cashBalance decimal.Decimal) error
+	GetPayments(ctx context.Context, eventID uuid.UUID) ([]*models.DividendPayment, error)
+}
+
+// dividendRepository implements the DividendRepository interface
+type dividendRepository struct {
+	db *sql.DB
+}
+
+// NewDividendRepository creates a new dividend repository instance
+func NewDividendRepository(db *sql.DB) DividendRepository {
+	return &dividendRepository{db: db}
+}
+
+// Create records a dividend unless one is already recorded for the stock on the same ex-date,
+// reporting whether it was created
+func (r *dividendRepository) Create(ctx context.Context, event *models.DividendEvent) (bool, error) {
+	query := `
+		INSERT INTO dividend_events (id, stock_id, ex_date, pay_date, amount_per_share, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (stock_id, ex_date) DO NOTHING`
+
+	result, err := r.db.ExecContext(ctx, query,
+		event.ID, event.StockID, event.ExDate, event.PayDate, event.AmountPerShare, event.Status, event.CreatedAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to create dividend event: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// dividendEventColumns are the columns scanned by scanDividendEvent
+const dividendEventColumns = `
+		SELECT de.id, de.stock_id, de.ex_date, de.pay_date, de.amount_per_share, de.status, de.paid_at,
+		       de.created_at, s.ticker, s.name, s.currency
+		FROM dividend_events de
+		JOIN stocks s ON de.stock_id = s.id`
+
+// GetByID retrieves a dividend event by ID
+func (r *dividendRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DividendEvent, error) {
+	row := r.db.QueryRowContext(ctx, dividendEventColumns+`
+		WHERE de.id = $1`, id)
+
+	event, err := scanDividendEvent(row)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "dividend event"}
+		}
+		return nil, fmt.Errorf("failed to get dividend event: %w", err)
+	}
+
+	return event, nil
+}
+
+// GetAll retrieves dividend events by ex-date, newest first, optionally for a single stock
+func (r *dividendRepository) GetAll(ctx context.Context, stockID *uuid.UUID) ([]*models.DividendEvent, error) {
+	query := dividendEventColumns + `
+		WHERE ($1::uuid IS NULL OR de.stock_id = $1)
+		ORDER BY de.ex_date DESC, de.created_at DESC`
+
+	return r.queryDividendEvents(ctx, query, stockID)
+}
+
+// GetDue retrieves the pending dividend events paid on or before asOf, oldest first
+func (r *dividendRepository) GetDue(ctx context.Context, asOf time.Time) ([]*models.DividendEvent, error) {
+	query := dividendEventColumns + `
+		WHERE de.status = $1 AND de.pay_date <= $2
+		ORDER BY de.pay_date ASC, de.ex_date ASC`
+
+	return r.queryDividendEvents(ctx, query, models.DividendPending, asOf)
+}
+
+// queryDividendEvents runs a dividend event query and scans its rows
+func (r *dividendRepository) queryDividendEvents(ctx context.Context, query string, args ...interface{}) ([]*models.DividendEvent, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividend events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.DividendEvent
+	for rows.Next() {
+		event, err := scanDividendEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dividend event: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dividend events: %w", err)
+	}
+
+	return events, nil
+}
+
+// scanDividendEvent scans a dividend event row with its stock
+func scanDividendEvent(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.DividendEvent, error) {
+	event := &models.DividendEvent{}
+	var ticker, name, currency string
+
+	err := row.Scan(&event.ID, &event.StockID, &event.ExDate, &event.PayDate, &event.AmountPerShare,
+		&event.Status, &event.PaidAt, &event.CreatedAt, &ticker, &name, &currency)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Stock = &models.Stock{ID: event.StockID, Ticker: ticker, Name: name, Currency: currency}
+	return event, nil
+}
+
+// MarkPaid records that a dividend has been paid to every entitled portfolio
+func (r *dividendRepository) MarkPaid(ctx context.Context, id uuid.UUID, paidAt time.Time) error {
+	query := `UPDATE dividend_events SET status = $1, paid_at = $2 WHERE id = $3`
+
+	result, err := r.db.ExecContext(ctx, query, models.DividendPaid, paidAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark dividend event paid: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "dividend event"}
+	}
+
+	return nil
+}
+
+// GetUnpaidPortfolioIDs retrieves the portfolios that traded the stock of a dividend before its
+// ex-date and have not been paid it yet
+func (r *dividendRepository) GetUnpaidPortfolioIDs(ctx context.Context, event *models.DividendEvent) ([]uuid.UUID, error) {
+	query := `
+		SELECT DISTINCT t.portfolio_id
+		FROM transactions t
+		WHERE t.stock_id = $1 AND t.executed_at < $2 AND t.type IN ('buy', 'sell', 'split')
+		  AND NOT EXISTS (
+		      SELECT 1 FROM dividend_payments p
+		      WHERE p.dividend_event_id = $3 AND p.portfolio_id = t.portfolio_id
+		  )`
+
+	rows, err := r.db.QueryContext(ctx, query, event.StockID, event.ExDate, event.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entitled portfolios: %w", err)
+	}
+	defer rows.Close()
+
+	var portfolioIDs []uuid.UUID
+	for rows.Next() {
+		var portfolioID uuid.UUID
+		if err := rows.Scan(&portfolioID); err != nil {
+			return nil, fmt.Errorf("failed to scan portfolio ID: %w", err)
+		}
+		portfolioIDs = append(portfolioIDs, portfolioID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating entitled portfolios: %w", err)
+	}
+
+	return portfolioIDs, nil
+}
+
+// RecordPayment records the dividend ledger entry and any reinvestment buy, replaces the positions
+// derived from the ledger, updates the cash balance and records the payment in a single database
+// transaction
+func (r *dividendRepository) RecordPayment(ctx context.Context, payment *models.DividendPayment, transactions []*models.Transaction, positions []*models.Position, cashBalance decimal.Decimal) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, transaction := range transactions {
+		if err := insertTransaction(ctx, tx, transaction); err != nil {
+			return err
+		}
+	}
+
+	if err := replacePositions(ctx, tx, payment.PortfolioID, positions); err != nil {
+		return err
+	}
+
+	cashQuery := `UPDATE portfolios SET cash_balance = $1, updated_at = $2 WHERE id = $3`
+	if _, err := tx.ExecContext(ctx, cashQuery, cashBalance, time.Now(), payment.PortfolioID); err != nil {
+		return fmt.Errorf("failed to update cash balance: %w", err)
+	}
+
+	query := `
+		INSERT INTO dividend_payments (id, dividend_event_id, portfolio_id, stock_id, shares, amount, transaction_id,
+			reinvestment_transaction_id, reinvested_shares, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	_, err = tx.ExecContext(ctx, query,
+		payment.ID, payment.DividendEventID, payment.PortfolioID, payment.StockID, payment.Shares, payment.Amount,
+		payment.TransactionID, payment.ReinvestmentTransactionID, payment.ReinvestedShares, payment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record dividend payment: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// GetPayments retrieves the portfolio payments made for a dividend event
+func (r *dividendRepository) GetPayments(ctx context.Context, eventID uuid.UUID) ([]*models.DividendPayment, error) {
+	query := `
+		SELECT id, dividend_event_id, portfolio_id, stock_id, shares, amount, transaction_id,
+		       reinvestment_transaction_id, reinvested_shares, created_at
+		FROM dividend_payments
+		WHERE dividend_event_id = $1
+		ORDER BY created_at ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, eventID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dividend payments: %w", err)
+	}
+	defer rows.Close()
+
+	var payments []*models.DividendPayment
+	for rows.Next() {
+		var payment models.DividendPayment
+		err := rows.Scan(&payment.ID, &payment.DividendEventID, &payment.PortfolioID, &payment.StockID,
+			&payment.Shares, &payment.Amount, &payment.TransactionID, &payment.ReinvestmentTransactionID,
+			&payment.ReinvestedShares, &payment.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan dividend payment: %w", err)
+		}
+		payments = append(payments, &payment)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dividend payments: %w", err)
+	}
+
+	return payments, nil
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"portfolio-app/internal/models"
+)
+
+// FXRateRepository defines the interface for historical exchange rate storage
+type FXRateRepository interface {
+	Save(ctx context.Context, rate *models.FXRate) error
+	GetOnOrBefore(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (*models.FXRate, error)
+	GetHistory(ctx context.Context, baseCurrency, quoteCurrency string, from, to time.Time) ([]*models.FXRate, error)
+}
+
+// fxRateRepository implements the FXRateRepository interface
+type fxRateRepository struct {
+	db *sql.DB
+}
+
+// NewFXRateRepository creates a new FX rate repository instance
+func NewFXRateRepository(db *sql.DB) FXRateRepository {
+	return &fxRateRepository{db: db}
+}
+
+// Save stores the rate of a currency pair on a date, replacing any rate already stored for it
+func (r *fxRateRepository) Save(ctx context.Context, rate *models.FXRate) error {
+	query := `
+		INSERT INTO fx_rates (base_currency, quote_currency, rate_date, rate, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (base_currency, quote_currency, rate_date)
+		DO UPDATE SET rate = EXCLUDED.rate, source = EXCLUDED.source, created_at = EXCLUDED.created_at`
+
+	_, err := r.db.ExecContext(ctx, query,
+		rate.BaseCurrency, rate.QuoteCurrency, rate.RateDate, rate.Rate, rate.Source, rate.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save FX rate: %w", err)
+	}
+
+	return nil
+}
+
+// GetOnOrBefore retrieves the latest stored rate of a currency pair on or before a date
+func (r *fxRateRepository) GetOnOrBefore(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (*models.FXRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate_date, rate, source, created_at
+		FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND rate_date <= $3
+		ORDER BY rate_date DESC
+		LIMIT 1`
+
+	var rate models.FXRate
+	err := r.db.QueryRowContext(ctx, query, baseCurrency, quoteCurrency, date).Scan(
+		&rate.BaseCurrency, &rate.QuoteCurrency, &rate.RateDate, &rate.Rate, &rate.Source, &rate.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "FX rate"}
+		}
+		return nil, fmt.Errorf("failed to get FX rate: %w", err)
+	}
+
+	return &rate, nil
+}
+
+// GetHistory retrieves the stored rates of a currency pair within a date range, oldest first
+func (r *fxRateRepository) GetHistory(ctx context.Context, baseCurrency, quoteCurrency string, from, to time.Time) ([]*models.FXRate, error) {
+	query := `
+		SELECT base_currency, quote_currency, rate_date, rate, source, created_at
+		FROM fx_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND rate_date BETWEEN $3 AND $4
+		ORDER BY rate_date ASC`
+
+	rows, err := r.db.QueryContext(ctx, query, baseCurrency, quoteCurrency, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get FX rate history: %w", err)
+	}
+	defer rows.Close()
+
+	var rates []*models.FXRate
+	for rows.Next() {
+		var rate models.FXRate
+		err := rows.Scan(&rate.BaseCurrency, &rate.QuoteCurrency, &rate.RateDate, &rate.Rate, &rate.Source, &rate.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan FX rate: %w", err)
+		}
+		rates = append(rates, &rate)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating FX rates: %w", err)
+	}
+
+	return rates, nil
+}
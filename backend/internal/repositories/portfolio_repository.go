@@ -38,8 +38,26 @@ type PortfolioRepositoryInterface interface {
 	GetNAVHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.NAVHistory, error)
 	GetLatestNAV(ctx context.Context, portfolioID uuid.UUID) (*models.NAVHistory, error)
 	
+	// Position snapshot operations
+	GetPositionSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]*models.PositionSnapshot, error)
+	PrunePositionSnapshots(ctx context.Context, downsampleBefore, deleteBefore time.Time) (int64, error)
+	
+	// Allocation configuration operations
+	GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error)
+	SaveAllocationConfig(ctx context.Context, config *models.PortfolioAllocationConfig) error
+	
+	// Rebalance policy operations
+	GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error)
+	SaveRebalancePolicy(ctx context.Context, policy *models.RebalancePolicy) error
+	CreateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error
+	GetRebalanceRun(ctx context.Context, id uuid.UUID) (*models.RebalanceRun, error)
+	GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error)
+	UpdateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error
+	
 	// Batch operations
 	CreatePortfolioWithPositions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position) error
+	CreatePortfolioWithTransactions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position, transactions []*models.Transaction) error
+	ExecuteRebalance(ctx context.Context, portfolio *models.Portfolio, transactions []*models.Transaction, positions []*models.Position, closedStockIDs []uuid.UUID) error
 }
 
 // NewPortfolioRepository creates a new portfolio repository
@@ -52,11 +70,12 @@ func NewPortfolioRepository(db *sql.DB) *PortfolioRepository {
 // Create creates a new portfolio
 func (r *PortfolioRepository) Create(ctx context.Context, portfolio *models.Portfolio) error {
 	query := `
-		INSERT INTO portfolios (id, user_id, name, total_investment, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO portfolios (id, user_id, name, total_investment, cash_balance, fractional_shares, share_precision, benchmark_symbol, lot_relief_method, drip_enabled, base_currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'fifo'), $10, COALESCE(NULLIF($11, ''), 'USD'), $12, $13)`
 	
 	_, err := r.db.ExecContext(ctx, query, portfolio.ID, portfolio.UserID, portfolio.Name, 
-		portfolio.TotalInvestment, portfolio.CreatedAt, portfolio.UpdatedAt)
+		portfolio.TotalInvestment, portfolio.CashBalance, portfolio.FractionalShares, portfolio.SharePrecision,
+		portfolio.BenchmarkSymbol, portfolio.LotReliefMethod, portfolio.DRIPEnabled, portfolio.BaseCurrency, portfolio.CreatedAt, portfolio.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create portfolio: %w", err)
 	}
@@ -69,13 +88,14 @@ func (r *PortfolioRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 	portfolio := &models.Portfolio{}
 	
 	query := `
-		SELECT id, user_id, name, total_investment, created_at, updated_at
+		SELECT id, user_id, name, total_investment, cash_balance, fractional_shares, share_precision, benchmark_symbol, lot_relief_method, drip_enabled, base_currency, created_at, updated_at
 		FROM portfolios 
 		WHERE id = $1`
 	
 	err := r.db.QueryRowContext(ctx, query, id).Scan(
 		&portfolio.ID, &portfolio.UserID, &portfolio.Name, &portfolio.TotalInvestment,
-		&portfolio.CreatedAt, &portfolio.UpdatedAt)
+		&portfolio.CashBalance, &portfolio.FractionalShares, &portfolio.SharePrecision,
+		&portfolio.BenchmarkSymbol, &portfolio.LotReliefMethod, &portfolio.DRIPEnabled, &portfolio.BaseCurrency, &portfolio.CreatedAt, &portfolio.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("portfolio not found")
@@ -99,6 +119,13 @@ func (r *PortfolioRepository) GetByID(ctx context.Context, id uuid.UUID) (*model
 		portfolio.NAVHistory = []models.NAVHistory{*latestNAV}
 	}
 	
+	// Load stored allocation configuration
+	config, err := r.GetAllocationConfig(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load allocation config: %w", err)
+	}
+	portfolio.AllocationConfig = config
+	
 	return portfolio, nil
 }
 
@@ -107,7 +134,7 @@ func (r *PortfolioRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 	var portfolios []*models.Portfolio
 	
 	query := `
-		SELECT id, user_id, name, total_investment, created_at, updated_at
+		SELECT id, user_id, name, total_investment, cash_balance, fractional_shares, share_precision, benchmark_symbol, lot_relief_method, drip_enabled, base_currency, created_at, updated_at
 		FROM portfolios 
 		WHERE user_id = $1
 		ORDER BY created_at DESC`
@@ -121,7 +148,8 @@ func (r *PortfolioRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 	for rows.Next() {
 		portfolio := &models.Portfolio{}
 		err := rows.Scan(&portfolio.ID, &portfolio.UserID, &portfolio.Name, 
-			&portfolio.TotalInvestment, &portfolio.CreatedAt, &portfolio.UpdatedAt)
+			&portfolio.TotalInvestment, &portfolio.CashBalance, &portfolio.FractionalShares, &portfolio.SharePrecision,
+			&portfolio.BenchmarkSymbol, &portfolio.LotReliefMethod, &portfolio.DRIPEnabled, &portfolio.BaseCurrency, &portfolio.CreatedAt, &portfolio.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan portfolio: %w", err)
 		}
@@ -184,11 +212,14 @@ func (r *PortfolioRepository) GetAllPortfolioIDs(ctx context.Context) ([]uuid.UU
 func (r *PortfolioRepository) Update(ctx context.Context, portfolio *models.Portfolio) error {
 	query := `
 		UPDATE portfolios 
-		SET name = $1, total_investment = $2, updated_at = $3
-		WHERE id = $4`
+		SET name = $1, total_investment = $2, cash_balance = $3, fractional_shares = $4, share_precision = $5,
+			benchmark_symbol = $6, lot_relief_method = COALESCE(NULLIF($7, ''), lot_relief_method), drip_enabled = $8,
+			updated_at = $9
+		WHERE id = $10`
 	
 	result, err := r.db.ExecContext(ctx, query, portfolio.Name, portfolio.TotalInvestment, 
-		portfolio.UpdatedAt, portfolio.ID)
+		portfolio.CashBalance, portfolio.FractionalShares, portfolio.SharePrecision, portfolio.BenchmarkSymbol,
+		portfolio.LotReliefMethod, portfolio.DRIPEnabled, portfolio.UpdatedAt, portfolio.ID)
 	if err != nil {
 		return fmt.Errorf("failed to update portfolio: %w", err)
 	}
@@ -229,11 +260,11 @@ func (r *PortfolioRepository) Delete(ctx context.Context, id uuid.UUID) error {
 // CreatePosition creates a new position
 func (r *PortfolioRepository) CreatePosition(ctx context.Context, position *models.Position) error {
 	query := `
-		INSERT INTO positions (portfolio_id, stock_id, quantity, entry_price, allocation_value, strategy_contrib, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		INSERT INTO positions (portfolio_id, stock_id, quantity, entry_price, allocation_value, entry_fx_rate, strategy_contrib, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 	
 	_, err := r.db.ExecContext(ctx, query, position.PortfolioID, position.StockID, position.Quantity,
-		position.EntryPrice, position.AllocationValue, position.StrategyContrib,
+		position.EntryPrice, position.AllocationValue, position.EntryFXRate, position.StrategyContrib,
 		position.CreatedAt, position.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create position: %w", err)
@@ -247,9 +278,9 @@ func (r *PortfolioRepository) GetPositions(ctx context.Context, portfolioID uuid
 	var positions []*models.Position
 	
 	query := `
-		SELECT p.portfolio_id, p.stock_id, p.quantity, p.entry_price, p.allocation_value, 
+		SELECT p.portfolio_id, p.stock_id, p.quantity, p.entry_price, p.allocation_value, p.entry_fx_rate,
 		       p.strategy_contrib, p.created_at, p.updated_at,
-		       s.ticker, s.name, s.sector
+		       s.ticker, s.name, s.sector, s.currency
 		FROM positions p
 		JOIN stocks s ON p.stock_id = s.id
 		WHERE p.portfolio_id = $1
@@ -267,9 +298,9 @@ func (r *PortfolioRepository) GetPositions(ctx context.Context, portfolioID uuid
 		
 		err := rows.Scan(
 			&position.PortfolioID, &position.StockID, &position.Quantity,
-			&position.EntryPrice, &position.AllocationValue, &position.StrategyContrib,
+			&position.EntryPrice, &position.AllocationValue, &position.EntryFXRate, &position.StrategyContrib,
 			&position.CreatedAt, &position.UpdatedAt,
-			&stock.Ticker, &stock.Name, &stock.Sector,
+			&stock.Ticker, &stock.Name, &stock.Sector, &stock.Currency,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan position: %w", err)
@@ -325,9 +356,14 @@ func (r *PortfolioRepository) UpdatePosition(ctx context.Context, position *mode
 
 // DeletePosition deletes a position
 func (r *PortfolioRepository) DeletePosition(ctx context.Context, portfolioID, stockID uuid.UUID) error {
+	return deletePosition(ctx, r.db, portfolioID, stockID)
+}
+
+// deletePosition deletes a single position using the given executor
+func deletePosition(ctx context.Context, exec execer, portfolioID, stockID uuid.UUID) error {
 	query := `DELETE FROM positions WHERE portfolio_id = $1 AND stock_id = $2`
 	
-	result, err := r.db.ExecContext(ctx, query, portfolioID, stockID)
+	result, err := exec.ExecContext(ctx, query, portfolioID, stockID)
 	if err != nil {
 		return fmt.Errorf("failed to delete position: %w", err)
 	}
@@ -344,19 +380,38 @@ func (r *PortfolioRepository) DeletePosition(ctx context.Context, portfolioID, s
 	return nil
 }
 
-// CreateNAVHistory creates a new NAV history entry
+// CreateNAVHistory creates a new NAV history entry and its position snapshots in a transaction
 func (r *PortfolioRepository) CreateNAVHistory(ctx context.Context, navHistory *models.NAVHistory) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
 	query := `
-		INSERT INTO nav_history (portfolio_id, timestamp, nav, pnl, drawdown, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO nav_history (portfolio_id, timestamp, nav, pnl, drawdown, cash, realized_pnl, unrealized_pnl, dividend_income, fx_pnl, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
 	
-	_, err := r.db.ExecContext(ctx, query, navHistory.PortfolioID, navHistory.Timestamp, 
-		navHistory.NAV, navHistory.PnL, navHistory.Drawdown, navHistory.CreatedAt)
+	_, err = tx.ExecContext(ctx, query, navHistory.PortfolioID, navHistory.Timestamp, 
+		navHistory.NAV, navHistory.PnL, navHistory.Drawdown, navHistory.Cash,
+		navHistory.RealizedPnL, navHistory.UnrealizedPnL, navHistory.DividendIncome, navHistory.FXPnL, navHistory.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create NAV history: %w", err)
 	}
 	
-	return nil
+	snapshotQuery := `
+		INSERT INTO position_snapshots (portfolio_id, timestamp, stock_id, quantity, price, value, weight, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	
+	for _, snapshot := range navHistory.Positions {
+		_, err = tx.ExecContext(ctx, snapshotQuery, snapshot.PortfolioID, snapshot.Timestamp,
+			snapshot.StockID, snapshot.Quantity, snapshot.Price, snapshot.Value, snapshot.Weight, snapshot.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create position snapshot for stock %s: %w", snapshot.StockID, err)
+		}
+	}
+	
+	return tx.Commit()
 }
 
 // GetNAVHistory retrieves NAV history for a portfolio within a date range
@@ -364,7 +419,7 @@ func (r *PortfolioRepository) GetNAVHistory(ctx context.Context, portfolioID uui
 	var navHistory []*models.NAVHistory
 	
 	query := `
-		SELECT portfolio_id, timestamp, nav, pnl, drawdown, created_at
+		SELECT portfolio_id, timestamp, nav, pnl, drawdown, cash, realized_pnl, unrealized_pnl, dividend_income, fx_pnl, created_at
 		FROM nav_history 
 		WHERE portfolio_id = $1 AND timestamp BETWEEN $2 AND $3
 		ORDER BY timestamp ASC`
@@ -378,7 +433,7 @@ func (r *PortfolioRepository) GetNAVHistory(ctx context.Context, portfolioID uui
 	for rows.Next() {
 		nav := &models.NAVHistory{}
 		err := rows.Scan(&nav.PortfolioID, &nav.Timestamp, &nav.NAV, &nav.PnL, 
-			&nav.Drawdown, &nav.CreatedAt)
+			&nav.Drawdown, &nav.Cash, &nav.RealizedPnL, &nav.UnrealizedPnL, &nav.DividendIncome, &nav.FXPnL, &nav.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan NAV history: %w", err)
 		}
@@ -397,7 +452,7 @@ func (r *PortfolioRepository) GetLatestNAV(ctx context.Context, portfolioID uuid
 	navHistory := &models.NAVHistory{}
 	
 	query := `
-		SELECT portfolio_id, timestamp, nav, pnl, drawdown, created_at
+		SELECT portfolio_id, timestamp, nav, pnl, drawdown, cash, realized_pnl, unrealized_pnl, dividend_income, fx_pnl, created_at
 		FROM nav_history 
 		WHERE portfolio_id = $1
 		ORDER BY timestamp DESC
@@ -405,7 +460,8 @@ func (r *PortfolioRepository) GetLatestNAV(ctx context.Context, portfolioID uuid
 	
 	err := r.db.QueryRowContext(ctx, query, portfolioID).Scan(
 		&navHistory.PortfolioID, &navHistory.Timestamp, &navHistory.NAV, 
-		&navHistory.PnL, &navHistory.Drawdown, &navHistory.CreatedAt)
+		&navHistory.PnL, &navHistory.Drawdown, &navHistory.Cash, &navHistory.RealizedPnL,
+		&navHistory.UnrealizedPnL, &navHistory.DividendIncome, &navHistory.FXPnL, &navHistory.CreatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil // No NAV history yet
@@ -416,8 +472,339 @@ func (r *PortfolioRepository) GetLatestNAV(ctx context.Context, portfolioID uuid
 	return navHistory, nil
 }
 
+// GetPositionSnapshots retrieves position snapshots for a portfolio within a date range, optionally
+// for a single stock, ordered by timestamp
+func (r *PortfolioRepository) GetPositionSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]*models.PositionSnapshot, error) {
+	query := `
+		SELECT ps.portfolio_id, ps.timestamp, ps.stock_id, ps.quantity, ps.price, ps.value, ps.weight, ps.created_at,
+		       s.ticker, s.name, s.sector
+		FROM position_snapshots ps
+		JOIN stocks s ON ps.stock_id = s.id
+		WHERE ps.portfolio_id = $1 AND ps.timestamp BETWEEN $2 AND $3
+		  AND ($4::uuid IS NULL OR ps.stock_id = $4)
+		ORDER BY ps.timestamp ASC, s.ticker ASC`
+	
+	rows, err := r.db.QueryContext(ctx, query, portfolioID, from, to, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position snapshots: %w", err)
+	}
+	defer rows.Close()
+	
+	var snapshots []*models.PositionSnapshot
+	for rows.Next() {
+		snapshot := &models.PositionSnapshot{}
+		stock := &models.Stock{}
+		err := rows.Scan(&snapshot.PortfolioID, &snapshot.Timestamp, &snapshot.StockID, &snapshot.Quantity,
+			&snapshot.Price, &snapshot.Value, &snapshot.Weight, &snapshot.CreatedAt,
+			&stock.Ticker, &stock.Name, &stock.Sector)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan position snapshot: %w", err)
+		}
+		stock.ID = snapshot.StockID
+		snapshot.Stock = stock
+		snapshots = append(snapshots, snapshot)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating position snapshots: %w", err)
+	}
+	
+	return snapshots, nil
+}
+
+// PrunePositionSnapshots deletes position snapshots older than deleteBefore and downsamples those older
+// than downsampleBefore to the last snapshot of each day per portfolio. It returns the number of
+// snapshot rows deleted.
+func (r *PortfolioRepository) PrunePositionSnapshots(ctx context.Context, downsampleBefore, deleteBefore time.Time) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	result, err := tx.ExecContext(ctx, `DELETE FROM position_snapshots WHERE timestamp < $1`, deleteBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired position snapshots: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	
+	downsampleQuery := `
+		DELETE FROM position_snapshots ps
+		WHERE ps.timestamp < $1
+		  AND ps.timestamp < (
+		      SELECT MAX(d.timestamp)
+		      FROM position_snapshots d
+		      WHERE d.portfolio_id = ps.portfolio_id
+		        AND d.timestamp >= date_trunc('day', ps.timestamp)
+		        AND d.timestamp < date_trunc('day', ps.timestamp) + INTERVAL '1 day'
+		  )`
+	
+	result, err = tx.ExecContext(ctx, downsampleQuery, downsampleBefore)
+	if err != nil {
+		return 0, fmt.Errorf("failed to downsample position snapshots: %w", err)
+	}
+	downsampled, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	
+	return deleted + downsampled, nil
+}
+
+// GetAllocationConfig retrieves the stored allocation configuration for a portfolio
+func (r *PortfolioRepository) GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error) {
+	config := &models.PortfolioAllocationConfig{}
+	var strategyIDsJSON, excludedStocksJSON []byte
+	
+	query := `
+		SELECT portfolio_id, strategy_ids, max_allocation_per_stock, min_allocation_amount,
+		       excluded_stocks, created_at, updated_at
+		FROM portfolio_allocation_configs
+		WHERE portfolio_id = $1`
+	
+	err := r.db.QueryRowContext(ctx, query, portfolioID).Scan(
+		&config.PortfolioID, &strategyIDsJSON, &config.Constraints.MaxAllocationPerStock,
+		&config.Constraints.MinAllocationAmount, &excludedStocksJSON, &config.CreatedAt, &config.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Portfolio was created without a stored configuration
+		}
+		return nil, fmt.Errorf("failed to get allocation config: %w", err)
+	}
+	
+	if err := json.Unmarshal(strategyIDsJSON, &config.StrategyIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal strategy IDs: %w", err)
+	}
+	if err := json.Unmarshal(excludedStocksJSON, &config.ExcludedStocks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal excluded stocks: %w", err)
+	}
+	
+	return config, nil
+}
+
+// SaveAllocationConfig creates or replaces the stored allocation configuration for a portfolio
+func (r *PortfolioRepository) SaveAllocationConfig(ctx context.Context, config *models.PortfolioAllocationConfig) error {
+	return saveAllocationConfig(ctx, r.db, config)
+}
+
+// saveAllocationConfig upserts an allocation configuration using the given executor
+func saveAllocationConfig(ctx context.Context, exec execer, config *models.PortfolioAllocationConfig) error {
+	strategyIDsJSON, err := json.Marshal(config.StrategyIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal strategy IDs: %w", err)
+	}
+	
+	excludedStocks := config.ExcludedStocks
+	if excludedStocks == nil {
+		excludedStocks = []uuid.UUID{}
+	}
+	excludedStocksJSON, err := json.Marshal(excludedStocks)
+	if err != nil {
+		return fmt.Errorf("failed to marshal excluded stocks: %w", err)
+	}
+	
+	query := `
+		INSERT INTO portfolio_allocation_configs (portfolio_id, strategy_ids, max_allocation_per_stock,
+		                                          min_allocation_amount, excluded_stocks, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (portfolio_id)
+		DO UPDATE SET strategy_ids = EXCLUDED.strategy_ids,
+		              max_allocation_per_stock = EXCLUDED.max_allocation_per_stock,
+		              min_allocation_amount = EXCLUDED.min_allocation_amount,
+		              excluded_stocks = EXCLUDED.excluded_stocks,
+		              updated_at = EXCLUDED.updated_at`
+	
+	_, err = exec.ExecContext(ctx, query, config.PortfolioID, strategyIDsJSON,
+		config.Constraints.MaxAllocationPerStock, config.Constraints.MinAllocationAmount,
+		excludedStocksJSON, config.CreatedAt, config.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save allocation config: %w", err)
+	}
+	
+	return nil
+}
+
+// GetRebalancePolicy retrieves the rebalance policy for a portfolio
+func (r *PortfolioRepository) GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error) {
+	policy := &models.RebalancePolicy{}
+	
+	query := `
+		SELECT portfolio_id, trigger_type, frequency, drift_threshold, execution_mode,
+		       last_evaluated_at, last_triggered_at, created_at, updated_at
+		FROM rebalance_policies
+		WHERE portfolio_id = $1`
+	
+	err := r.db.QueryRowContext(ctx, query, portfolioID).Scan(
+		&policy.PortfolioID, &policy.TriggerType, &policy.Frequency, &policy.DriftThreshold,
+		&policy.ExecutionMode, &policy.LastEvaluatedAt, &policy.LastTriggeredAt,
+		&policy.CreatedAt, &policy.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil // Portfolio has no rebalance policy
+		}
+		return nil, fmt.Errorf("failed to get rebalance policy: %w", err)
+	}
+	
+	return policy, nil
+}
+
+// SaveRebalancePolicy creates or replaces the rebalance policy for a portfolio
+func (r *PortfolioRepository) SaveRebalancePolicy(ctx context.Context, policy *models.RebalancePolicy) error {
+	query := `
+		INSERT INTO rebalance_policies (portfolio_id, trigger_type, frequency, drift_threshold, execution_mode,
+		                                last_evaluated_at, last_triggered_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (portfolio_id)
+		DO UPDATE SET trigger_type = EXCLUDED.trigger_type,
+		              frequency = EXCLUDED.frequency,
+		              drift_threshold = EXCLUDED.drift_threshold,
+		              execution_mode = EXCLUDED.execution_mode,
+		              last_evaluated_at = EXCLUDED.last_evaluated_at,
+		              last_triggered_at = EXCLUDED.last_triggered_at,
+		              updated_at = EXCLUDED.updated_at`
+	
+	_, err := r.db.ExecContext(ctx, query,
+		policy.PortfolioID, policy.TriggerType, policy.Frequency, policy.DriftThreshold,
+		policy.ExecutionMode, policy.LastEvaluatedAt, policy.LastTriggeredAt,
+		policy.CreatedAt, policy.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save rebalance policy: %w", err)
+	}
+	
+	return nil
+}
+
+// CreateRebalanceRun records a rebalance triggered by a policy
+func (r *PortfolioRepository) CreateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error {
+	planJSON, err := json.Marshal(run.Plan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rebalance plan: %w", err)
+	}
+	
+	query := `
+		INSERT INTO rebalance_runs (id, portfolio_id, trigger_type, status, max_drift, plan, error, created_at, resolved_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	
+	_, err = r.db.ExecContext(ctx, query,
+		run.ID, run.PortfolioID, run.TriggerType, run.Status, run.MaxDrift,
+		planJSON, run.Error, run.CreatedAt, run.ResolvedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create rebalance run: %w", err)
+	}
+	
+	return nil
+}
+
+// GetRebalanceRun retrieves a rebalance run by ID
+func (r *PortfolioRepository) GetRebalanceRun(ctx context.Context, id uuid.UUID) (*models.RebalanceRun, error) {
+	query := `
+		SELECT id, portfolio_id, trigger_type, status, max_drift, plan, error, created_at, resolved_at
+		FROM rebalance_runs
+		WHERE id = $1`
+	
+	run, err := scanRebalanceRun(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "rebalance run"}
+		}
+		return nil, fmt.Errorf("failed to get rebalance run: %w", err)
+	}
+	
+	return run, nil
+}
+
+// GetRebalanceRuns retrieves the rebalance runs of a portfolio, newest first
+func (r *PortfolioRepository) GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error) {
+	query := `
+		SELECT id, portfolio_id, trigger_type, status, max_drift, plan, error, created_at, resolved_at
+		FROM rebalance_runs
+		WHERE portfolio_id = $1
+		ORDER BY created_at DESC`
+	
+	rows, err := r.db.QueryContext(ctx, query, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rebalance runs: %w", err)
+	}
+	defer rows.Close()
+	
+	var runs []*models.RebalanceRun
+	for rows.Next() {
+		run, err := scanRebalanceRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan rebalance run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rebalance runs: %w", err)
+	}
+	
+	return runs, nil
+}
+
+// UpdateRebalanceRun saves the status and outcome of a rebalance run with the plan it was resolved with
+func (r *PortfolioRepository) UpdateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error {
+	planJSON, err := json.Marshal(run.Plan)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rebalance plan: %w", err)
+	}
+	
+	query := `
+		UPDATE rebalance_runs 
+		SET status = $1, error = $2, resolved_at = $3, max_drift = $4, plan = $5
+		WHERE id = $6`
+	
+	result, err := r.db.ExecContext(ctx, query, run.Status, run.Error, run.ResolvedAt, run.MaxDrift, planJSON, run.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update rebalance run: %w", err)
+	}
+	
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "rebalance run"}
+	}
+	
+	return nil
+}
+
+// scanRebalanceRun scans a rebalance run row and decodes its stored plan
+func scanRebalanceRun(row interface{ Scan(dest ...interface{}) error }) (*models.RebalanceRun, error) {
+	run := &models.RebalanceRun{}
+	var planJSON []byte
+	
+	err := row.Scan(&run.ID, &run.PortfolioID, &run.TriggerType, &run.Status, &run.MaxDrift,
+		&planJSON, &run.Error, &run.CreatedAt, &run.ResolvedAt)
+	if err != nil {
+		return nil, err
+	}
+	
+	if err := json.Unmarshal(planJSON, &run.Plan); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rebalance plan: %w", err)
+	}
+	
+	return run, nil
+}
+
 // CreatePortfolioWithPositions creates a portfolio and its positions in a transaction
 func (r *PortfolioRepository) CreatePortfolioWithPositions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position) error {
+	return r.CreatePortfolioWithTransactions(ctx, portfolio, positions, nil)
+}
+
+// CreatePortfolioWithTransactions creates a portfolio, its opening ledger entries and the
+// positions derived from them in a transaction
+func (r *PortfolioRepository) CreatePortfolioWithTransactions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position, transactions []*models.Transaction) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -426,26 +813,41 @@ func (r *PortfolioRepository) CreatePortfolioWithPositions(ctx context.Context,
 	
 	// Create portfolio
 	portfolioQuery := `
-		INSERT INTO portfolios (id, user_id, name, total_investment, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO portfolios (id, user_id, name, total_investment, cash_balance, fractional_shares, share_precision, benchmark_symbol, lot_relief_method, drip_enabled, base_currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE(NULLIF($9, ''), 'fifo'), $10, COALESCE(NULLIF($11, ''), 'USD'), $12, $13)`
 	
 	_, err = tx.ExecContext(ctx, portfolioQuery,
 		portfolio.ID, portfolio.UserID, portfolio.Name, portfolio.TotalInvestment,
-		portfolio.CreatedAt, portfolio.UpdatedAt)
+		portfolio.CashBalance, portfolio.FractionalShares, portfolio.SharePrecision,
+		portfolio.BenchmarkSymbol, portfolio.LotReliefMethod, portfolio.DRIPEnabled, portfolio.BaseCurrency, portfolio.CreatedAt, portfolio.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create portfolio: %w", err)
 	}
 	
+	// Store the allocation request the portfolio was built with
+	if portfolio.AllocationConfig != nil {
+		if err := saveAllocationConfig(ctx, tx, portfolio.AllocationConfig); err != nil {
+			return err
+		}
+	}
+	
+	// Record opening ledger entries
+	for _, transaction := range transactions {
+		if err := insertTransaction(ctx, tx, transaction); err != nil {
+			return err
+		}
+	}
+	
 	// Create positions
 	if len(positions) > 0 {
 		positionQuery := `
-			INSERT INTO positions (portfolio_id, stock_id, quantity, entry_price, allocation_value, strategy_contrib, created_at, updated_at)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+			INSERT INTO positions (portfolio_id, stock_id, quantity, entry_price, allocation_value, entry_fx_rate, strategy_contrib, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
 		
 		for _, position := range positions {
 			_, err = tx.ExecContext(ctx, positionQuery,
 				position.PortfolioID, position.StockID, position.Quantity,
-				position.EntryPrice, position.AllocationValue, position.StrategyContrib,
+				position.EntryPrice, position.AllocationValue, position.EntryFXRate, position.StrategyContrib,
 				position.CreatedAt, position.UpdatedAt)
 			if err != nil {
 				return fmt.Errorf("failed to create position for stock %s: %w", position.StockID, err)
@@ -460,19 +862,72 @@ func (r *PortfolioRepository) CreatePortfolioWithPositions(ctx context.Context,
 		NAV:         portfolio.TotalInvestment,
 		PnL:         decimal.Zero,
 		Drawdown:    nil,
+		Cash:        portfolio.CashBalance,
 		CreatedAt:   portfolio.CreatedAt,
 	}
 	
 	navQuery := `
-		INSERT INTO nav_history (portfolio_id, timestamp, nav, pnl, drawdown, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6)`
+		INSERT INTO nav_history (portfolio_id, timestamp, nav, pnl, drawdown, cash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
 	
 	_, err = tx.ExecContext(ctx, navQuery,
 		initialNAV.PortfolioID, initialNAV.Timestamp, initialNAV.NAV,
-		initialNAV.PnL, initialNAV.Drawdown, initialNAV.CreatedAt)
+		initialNAV.PnL, initialNAV.Drawdown, initialNAV.Cash, initialNAV.CreatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create initial NAV history: %w", err)
 	}
 	
+	return tx.Commit()
+}
+
+// ExecuteRebalance records the rebalance trades, saves the resulting positions, deletes the
+// positions that were closed and updates the portfolio totals in a single transaction
+func (r *PortfolioRepository) ExecuteRebalance(ctx context.Context, portfolio *models.Portfolio, transactions []*models.Transaction, positions []*models.Position, closedStockIDs []uuid.UUID) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Record rebalance trades in the ledger
+	for _, transaction := range transactions {
+		if err := insertTransaction(ctx, tx, transaction); err != nil {
+			return err
+		}
+	}
+	
+	// Save remaining positions
+	if err := upsertPositions(ctx, tx, portfolio.ID, positions); err != nil {
+		return err
+	}
+	
+	// Delete exited positions
+	for _, stockID := range closedStockIDs {
+		if err := deletePosition(ctx, tx, portfolio.ID, stockID); err != nil {
+			return fmt.Errorf("failed to close position for stock %s: %w", stockID, err)
+		}
+	}
+	
+	// Update portfolio totals
+	query := `
+		UPDATE portfolios 
+		SET total_investment = $1, cash_balance = $2, updated_at = $3
+		WHERE id = $4`
+	
+	result, err := tx.ExecContext(ctx, query, portfolio.TotalInvestment, portfolio.CashBalance,
+		portfolio.UpdatedAt, portfolio.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update portfolio: %w", err)
+	}
+	
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	
+	if rowsAffected == 0 {
+		return fmt.Errorf("portfolio not found")
+	}
+	
 	return tx.Commit()
 }
\ No newline at end of file
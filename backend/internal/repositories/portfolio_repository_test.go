@@ -191,7 +191,7 @@ func TestPortfolioRepository_CreatePosition(t *testing.T) {
 	position := &models.Position{
 		PortfolioID:     portfolioID,
 		StockID:         stockID,
-		Quantity:        100,
+		Quantity:        decimal.NewFromInt(100),
 		EntryPrice:      decimal.NewFromFloat(150.00),
 		AllocationValue: decimal.NewFromFloat(15000.00),
 		StrategyContrib: []byte(`{"strategy1": 15000.00}`),
@@ -207,7 +207,7 @@ func TestPortfolioRepository_CreatePosition(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, positions, 1)
 	assert.Equal(t, stockID, positions[0].StockID)
-	assert.Equal(t, 100, positions[0].Quantity)
+	assert.True(t, decimal.NewFromInt(100).Equal(positions[0].Quantity))
 	assert.True(t, decimal.NewFromFloat(150.00).Equal(positions[0].EntryPrice))
 }
 
@@ -459,7 +459,7 @@ func TestPortfolioRepository_CreatePortfolioWithPositions(t *testing.T) {
 		{
 			PortfolioID:     portfolioID,
 			StockID:         stockID1,
-			Quantity:        100,
+			Quantity:        decimal.NewFromInt(100),
 			EntryPrice:      decimal.NewFromFloat(150.00),
 			AllocationValue: decimal.NewFromFloat(15000.00),
 			StrategyContrib: []byte(`{"strategy1": 15000.00}`),
@@ -469,7 +469,7 @@ func TestPortfolioRepository_CreatePortfolioWithPositions(t *testing.T) {
 		{
 			PortfolioID:     portfolioID,
 			StockID:         stockID2,
-			Quantity:        20,
+			Quantity:        decimal.NewFromInt(20),
 			EntryPrice:      decimal.NewFromFloat(250.00),
 			AllocationValue: decimal.NewFromFloat(5000.00),
 			StrategyContrib: []byte(`{"strategy1": 5000.00}`),
@@ -15,8 +15,9 @@ import (
 type SignalRepository interface {
 	Create(ctx context.Context, signal *models.Signal) (*models.Signal, error)
 	Update(ctx context.Context, stockID uuid.UUID, signal models.SignalType) (*models.Signal, error)
+	UpdateForStrategy(ctx context.Context, strategyID, stockID uuid.UUID, signal models.SignalType) (*models.Signal, error)
 	GetCurrentSignal(ctx context.Context, stockID uuid.UUID) (*models.Signal, error)
-	GetLatestSignals(ctx context.Context, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error)
+	GetLatestSignals(ctx context.Context, strategyID *uuid.UUID, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error)
 	GetSignalHistory(ctx context.Context, stockID uuid.UUID, from, to time.Time) ([]*models.Signal, error)
 	Delete(ctx context.Context, stockID uuid.UUID, date time.Time) error
 }
@@ -34,24 +35,35 @@ func NewSignalRepository(db *sql.DB) SignalRepository {
 // Create creates a new signal in the database
 func (r *signalRepository) Create(ctx context.Context, signal *models.Signal) (*models.Signal, error) {
 	query := `
-		INSERT INTO signals (stock_id, signal, date, created_at)
-		VALUES ($1, $2, $3, $4)
-		ON CONFLICT (stock_id, date) 
-		DO UPDATE SET signal = EXCLUDED.signal, created_at = EXCLUDED.created_at
-		RETURNING stock_id, signal, date, created_at`
+		INSERT INTO signals (stock_id, strategy_id, signal, date, strength, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT ON CONSTRAINT signals_stock_strategy_date_source_key 
+		DO UPDATE SET signal = EXCLUDED.signal, strength = EXCLUDED.strength, created_at = EXCLUDED.created_at
+		RETURNING stock_id, strategy_id, signal, date, strength, source, created_at`
+
+	source := signal.Source
+	if source == "" {
+		source = models.SignalSourceManual
+	}
 
 	row := r.db.QueryRowContext(ctx, query,
 		signal.StockID,
+		signal.StrategyID,
 		signal.Signal,
 		signal.Date,
+		signal.Strength,
+		source,
 		signal.CreatedAt,
 	)
 
 	var created models.Signal
 	err := row.Scan(
 		&created.StockID,
+		&created.StrategyID,
 		&created.Signal,
 		&created.Date,
+		&created.Strength,
+		&created.Source,
 		&created.CreatedAt,
 	)
 	if err != nil {
@@ -70,18 +82,36 @@ func (r *signalRepository) Update(ctx context.Context, stockID uuid.UUID, signal
 		StockID:   stockID,
 		Signal:    signalType,
 		Date:      today,
+		Source:    models.SignalSourceManual,
 		CreatedAt: now,
 	}
 
 	return r.Create(ctx, signal)
 }
 
-// GetCurrentSignal retrieves the most recent signal for a stock
+// UpdateForStrategy sets today's signal for a stock within a single strategy
+func (r *signalRepository) UpdateForStrategy(ctx context.Context, strategyID, stockID uuid.UUID, signalType models.SignalType) (*models.Signal, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	signal := &models.Signal{
+		StockID:    stockID,
+		StrategyID: &strategyID,
+		Signal:     signalType,
+		Date:       today,
+		Source:     models.SignalSourceManual,
+		CreatedAt:  now,
+	}
+
+	return r.Create(ctx, signal)
+}
+
+// GetCurrentSignal retrieves the most recent global signal for a stock
 func (r *signalRepository) GetCurrentSignal(ctx context.Context, stockID uuid.UUID) (*models.Signal, error) {
 	query := `
-		SELECT stock_id, signal, date, created_at
+		SELECT stock_id, strategy_id, signal, date, strength, source, created_at
 		FROM signals
-		WHERE stock_id = $1
+		WHERE stock_id = $1 AND strategy_id IS NULL
 		ORDER BY date DESC, created_at DESC
 		LIMIT 1`
 
@@ -90,8 +120,11 @@ func (r *signalRepository) GetCurrentSignal(ctx context.Context, stockID uuid.UU
 	var signal models.Signal
 	err := row.Scan(
 		&signal.StockID,
+		&signal.StrategyID,
 		&signal.Signal,
 		&signal.Date,
+		&signal.Strength,
+		&signal.Source,
 		&signal.CreatedAt,
 	)
 	if err != nil {
@@ -104,10 +137,10 @@ func (r *signalRepository) GetCurrentSignal(ctx context.Context, stockID uuid.UU
 	return &signal, nil
 }
 
-// GetSignalHistory retrieves signal history for a stock within a date range
+// GetSignalHistory retrieves global and strategy signal history for a stock within a date range
 func (r *signalRepository) GetSignalHistory(ctx context.Context, stockID uuid.UUID, from, to time.Time) ([]*models.Signal, error) {
 	query := `
-		SELECT stock_id, signal, date, created_at
+		SELECT stock_id, strategy_id, signal, date, strength, source, created_at
 		FROM signals
 		WHERE stock_id = $1 AND date >= $2 AND date <= $3
 		ORDER BY date DESC, created_at DESC`
@@ -123,8 +156,11 @@ func (r *signalRepository) GetSignalHistory(ctx context.Context, stockID uuid.UU
 		var signal models.Signal
 		err := rows.Scan(
 			&signal.StockID,
+			&signal.StrategyID,
 			&signal.Signal,
 			&signal.Date,
+			&signal.Strength,
+			&signal.Source,
 			&signal.CreatedAt,
 		)
 		if err != nil {
@@ -141,9 +177,9 @@ func (r *signalRepository) GetSignalHistory(ctx context.Context, stockID uuid.UU
 	return signals, nil
 }
 
-// Delete deletes a specific signal
+// Delete deletes a specific global signal
 func (r *signalRepository) Delete(ctx context.Context, stockID uuid.UUID, date time.Time) error {
-	query := `DELETE FROM signals WHERE stock_id = $1 AND date = $2`
+	query := `DELETE FROM signals WHERE stock_id = $1 AND date = $2 AND strategy_id IS NULL`
 
 	result, err := r.db.ExecContext(ctx, query, stockID, date)
 	if err != nil {
@@ -162,25 +198,27 @@ func (r *signalRepository) Delete(ctx context.Context, stockID uuid.UUID, date t
 	return nil
 }
 
-// GetLatestSignals retrieves the most recent signals for multiple stocks
-func (r *signalRepository) GetLatestSignals(ctx context.Context, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
+// GetLatestSignals retrieves the most recent signals for multiple stocks. When a strategy is given,
+// a stock's latest signal for that strategy takes precedence over its latest global signal.
+func (r *signalRepository) GetLatestSignals(ctx context.Context, strategyID *uuid.UUID, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
 	if len(stockIDs) == 0 {
 		return make(map[uuid.UUID]*models.Signal), nil
 	}
 
-	// Create placeholders for the IN clause
+	// Create placeholders for the IN clause; $1 is the strategy
 	placeholders := make([]string, len(stockIDs))
-	args := make([]interface{}, len(stockIDs))
+	args := make([]interface{}, len(stockIDs)+1)
+	args[0] = strategyID
 	for i, id := range stockIDs {
-		placeholders[i] = fmt.Sprintf("$%d", i+1)
-		args[i] = id
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+		args[i+1] = id
 	}
 
 	query := fmt.Sprintf(`
-		SELECT DISTINCT ON (stock_id) stock_id, signal, date, created_at
+		SELECT DISTINCT ON (stock_id) stock_id, strategy_id, signal, date, strength, source, created_at
 		FROM signals
-		WHERE stock_id IN (%s)
-		ORDER BY stock_id, date DESC, created_at DESC`, 
+		WHERE stock_id IN (%s) AND (strategy_id IS NULL OR strategy_id = $1)
+		ORDER BY stock_id, strategy_id IS NULL, date DESC, created_at DESC`, 
 		strings.Join(placeholders, ","))
 
 	rows, err := r.db.QueryContext(ctx, query, args...)
@@ -194,8 +232,11 @@ func (r *signalRepository) GetLatestSignals(ctx context.Context, stockIDs []uuid
 		var signal models.Signal
 		err := rows.Scan(
 			&signal.StockID,
+			&signal.StrategyID,
 			&signal.Signal,
 			&signal.Date,
+			&signal.Strength,
+			&signal.Source,
 			&signal.CreatedAt,
 		)
 		if err != nil {
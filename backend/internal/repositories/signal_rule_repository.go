@@ -0,0 +1,207 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+)
+
+// SignalRuleRepository defines the interface for signal rule data operations
+type SignalRuleRepository interface {
+	Create(ctx context.Context, rule *models.SignalRule) (*models.SignalRule, error)
+	Update(ctx context.Context, rule *models.SignalRule) (*models.SignalRule, error)
+	GetByID(ctx context.Context, id, strategyID uuid.UUID) (*models.SignalRule, error)
+	GetByStrategyID(ctx context.Context, strategyID uuid.UUID) ([]*models.SignalRule, error)
+	GetStrategyIDsWithEnabledRules(ctx context.Context) ([]uuid.UUID, error)
+	Delete(ctx context.Context, id, strategyID uuid.UUID) error
+}
+
+// signalRuleRepository implements the SignalRuleRepository interface
+type signalRuleRepository struct {
+	db *sql.DB
+}
+
+// NewSignalRuleRepository creates a new signal rule repository instance
+func NewSignalRuleRepository(db *sql.DB) SignalRuleRepository {
+	return &signalRuleRepository{db: db}
+}
+
+// Create creates a new signal rule in the database
+func (r *signalRuleRepository) Create(ctx context.Context, rule *models.SignalRule) (*models.SignalRule, error) {
+	query := `
+		INSERT INTO signal_rules (id, strategy_id, rule_type, period, slow_period, lower_threshold, upper_threshold,
+		                          enabled, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, strategy_id, rule_type, period, slow_period, lower_threshold, upper_threshold,
+		          enabled, created_at, updated_at`
+
+	created, err := scanSignalRule(r.db.QueryRowContext(ctx, query,
+		rule.ID,
+		rule.StrategyID,
+		rule.RuleType,
+		rule.Period,
+		rule.SlowPeriod,
+		rule.LowerThreshold,
+		rule.UpperThreshold,
+		rule.Enabled,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signal rule: %w", err)
+	}
+
+	return created, nil
+}
+
+// Update updates an existing signal rule in the database
+func (r *signalRuleRepository) Update(ctx context.Context, rule *models.SignalRule) (*models.SignalRule, error) {
+	query := `
+		UPDATE signal_rules
+		SET period = $3, slow_period = $4, lower_threshold = $5, upper_threshold = $6, enabled = $7, updated_at = $8
+		WHERE id = $1 AND strategy_id = $2
+		RETURNING id, strategy_id, rule_type, period, slow_period, lower_threshold, upper_threshold,
+		          enabled, created_at, updated_at`
+
+	updated, err := scanSignalRule(r.db.QueryRowContext(ctx, query,
+		rule.ID,
+		rule.StrategyID,
+		rule.Period,
+		rule.SlowPeriod,
+		rule.LowerThreshold,
+		rule.UpperThreshold,
+		rule.Enabled,
+		rule.UpdatedAt,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "signal rule"}
+		}
+		return nil, fmt.Errorf("failed to update signal rule: %w", err)
+	}
+
+	return updated, nil
+}
+
+// GetByID retrieves a signal rule of a strategy by ID
+func (r *signalRuleRepository) GetByID(ctx context.Context, id, strategyID uuid.UUID) (*models.SignalRule, error) {
+	query := `
+		SELECT id, strategy_id, rule_type, period, slow_period, lower_threshold, upper_threshold,
+		       enabled, created_at, updated_at
+		FROM signal_rules
+		WHERE id = $1 AND strategy_id = $2`
+
+	rule, err := scanSignalRule(r.db.QueryRowContext(ctx, query, id, strategyID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &models.NotFoundError{Resource: "signal rule"}
+		}
+		return nil, fmt.Errorf("failed to get signal rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+// GetByStrategyID retrieves all signal rules of a strategy in creation order
+func (r *signalRuleRepository) GetByStrategyID(ctx context.Context, strategyID uuid.UUID) ([]*models.SignalRule, error) {
+	query := `
+		SELECT id, strategy_id, rule_type, period, slow_period, lower_threshold, upper_threshold,
+		       enabled, created_at, updated_at
+		FROM signal_rules
+		WHERE strategy_id = $1
+		ORDER BY created_at`
+
+	rows, err := r.db.QueryContext(ctx, query, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query signal rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*models.SignalRule
+	for rows.Next() {
+		rule, err := scanSignalRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan signal rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating signal rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// GetStrategyIDsWithEnabledRules retrieves the IDs of all strategies that have at least one enabled rule
+func (r *signalRuleRepository) GetStrategyIDsWithEnabledRules(ctx context.Context) ([]uuid.UUID, error) {
+	query := `SELECT DISTINCT strategy_id FROM signal_rules WHERE enabled`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query strategies with signal rules: %w", err)
+	}
+	defer rows.Close()
+
+	var strategyIDs []uuid.UUID
+	for rows.Next() {
+		var strategyID uuid.UUID
+		if err := rows.Scan(&strategyID); err != nil {
+			return nil, fmt.Errorf("failed to scan strategy ID: %w", err)
+		}
+		strategyIDs = append(strategyIDs, strategyID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating strategy IDs: %w", err)
+	}
+
+	return strategyIDs, nil
+}
+
+// Delete deletes a signal rule of a strategy
+func (r *signalRuleRepository) Delete(ctx context.Context, id, strategyID uuid.UUID) error {
+	query := `DELETE FROM signal_rules WHERE id = $1 AND strategy_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, id, strategyID)
+	if err != nil {
+		return fmt.Errorf("failed to delete signal rule: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "signal rule"}
+	}
+
+	return nil
+}
+
+// scanSignalRule scans a signal rule row
+func scanSignalRule(row interface {
+	Scan(dest ...interface{}) error
+}) (*models.SignalRule, error) {
+	rule := &models.SignalRule{}
+	err := row.Scan(
+		&rule.ID,
+		&rule.StrategyID,
+		&rule.RuleType,
+		&rule.Period,
+		&rule.SlowPeriod,
+		&rule.LowerThreshold,
+		&rule.UpperThreshold,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
@@ -36,9 +36,9 @@ func NewStockRepository(db *sql.DB) StockRepository {
 // Create creates a new stock in the database
 func (r *stockRepository) Create(ctx context.Context, stock *models.Stock) (*models.Stock, error) {
 	query := `
-		INSERT INTO stocks (id, ticker, name, sector, exchange, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, ticker, name, sector, exchange, created_at, updated_at`
+		INSERT INTO stocks (id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at`
 
 	row := r.db.QueryRowContext(ctx, query,
 		stock.ID,
@@ -46,6 +46,8 @@ func (r *stockRepository) Create(ctx context.Context, stock *models.Stock) (*mod
 		stock.Name,
 		stock.Sector,
 		stock.Exchange,
+		stock.MarketCap,
+		models.CurrencyOrDefault(stock.Currency),
 		stock.CreatedAt,
 		stock.UpdatedAt,
 	)
@@ -57,6 +59,8 @@ func (r *stockRepository) Create(ctx context.Context, stock *models.Stock) (*mod
 		&created.Name,
 		&created.Sector,
 		&created.Exchange,
+		&created.MarketCap,
+		&created.Currency,
 		&created.CreatedAt,
 		&created.UpdatedAt,
 	)
@@ -71,15 +75,17 @@ func (r *stockRepository) Create(ctx context.Context, stock *models.Stock) (*mod
 func (r *stockRepository) Update(ctx context.Context, stock *models.Stock) (*models.Stock, error) {
 	query := `
 		UPDATE stocks 
-		SET name = $2, sector = $3, exchange = $4, updated_at = $5
+		SET name = $2, sector = $3, exchange = $4, market_cap = $5, currency = $6, updated_at = $7
 		WHERE id = $1
-		RETURNING id, ticker, name, sector, exchange, created_at, updated_at`
+		RETURNING id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at`
 
 	row := r.db.QueryRowContext(ctx, query,
 		stock.ID,
 		stock.Name,
 		stock.Sector,
 		stock.Exchange,
+		stock.MarketCap,
+		models.CurrencyOrDefault(stock.Currency),
 		stock.UpdatedAt,
 	)
 
@@ -90,6 +96,8 @@ func (r *stockRepository) Update(ctx context.Context, stock *models.Stock) (*mod
 		&updated.Name,
 		&updated.Sector,
 		&updated.Exchange,
+		&updated.MarketCap,
+		&updated.Currency,
 		&updated.CreatedAt,
 		&updated.UpdatedAt,
 	)
@@ -106,7 +114,7 @@ func (r *stockRepository) Update(ctx context.Context, stock *models.Stock) (*mod
 // GetByID retrieves a stock by ID
 func (r *stockRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Stock, error) {
 	query := `
-		SELECT id, ticker, name, sector, exchange, created_at, updated_at
+		SELECT id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at
 		FROM stocks
 		WHERE id = $1`
 
@@ -119,6 +127,8 @@ func (r *stockRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.St
 		&stock.Name,
 		&stock.Sector,
 		&stock.Exchange,
+		&stock.MarketCap,
+		&stock.Currency,
 		&stock.CreatedAt,
 		&stock.UpdatedAt,
 	)
@@ -143,7 +153,7 @@ func (r *stockRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mod
 	// For simplicity, query each stock individually
 	for _, id := range ids {
 		query := `
-			SELECT id, ticker, name, sector, exchange, created_at, updated_at
+			SELECT id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at
 			FROM stocks
 			WHERE id = $1`
 
@@ -156,6 +166,8 @@ func (r *stockRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mod
 			&stock.Name,
 			&stock.Sector,
 			&stock.Exchange,
+			&stock.MarketCap,
+			&stock.Currency,
 			&stock.CreatedAt,
 			&stock.UpdatedAt,
 		)
@@ -175,7 +187,7 @@ func (r *stockRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*mod
 // GetByTicker retrieves a stock by ticker symbol
 func (r *stockRepository) GetByTicker(ctx context.Context, ticker string) (*models.Stock, error) {
 	query := `
-		SELECT id, ticker, name, sector, exchange, created_at, updated_at
+		SELECT id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at
 		FROM stocks
 		WHERE ticker = $1`
 
@@ -188,6 +200,8 @@ func (r *stockRepository) GetByTicker(ctx context.Context, ticker string) (*mode
 		&stock.Name,
 		&stock.Sector,
 		&stock.Exchange,
+		&stock.MarketCap,
+		&stock.Currency,
 		&stock.CreatedAt,
 		&stock.UpdatedAt,
 	)
@@ -208,7 +222,7 @@ func (r *stockRepository) GetAll(ctx context.Context, search string, limit, offs
 
 	if search != "" {
 		query = `
-			SELECT id, ticker, name, sector, exchange, created_at, updated_at
+			SELECT id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at
 			FROM stocks
 			WHERE ticker ILIKE $1 OR name ILIKE $1 OR sector ILIKE $1
 			ORDER BY ticker
@@ -216,7 +230,7 @@ func (r *stockRepository) GetAll(ctx context.Context, search string, limit, offs
 		args = []interface{}{"%" + search + "%", limit, offset}
 	} else {
 		query = `
-			SELECT id, ticker, name, sector, exchange, created_at, updated_at
+			SELECT id, ticker, name, sector, exchange, market_cap, currency, created_at, updated_at
 			FROM stocks
 			ORDER BY ticker
 			LIMIT $1 OFFSET $2`
@@ -238,6 +252,8 @@ func (r *stockRepository) GetAll(ctx context.Context, search string, limit, offs
 			&stock.Name,
 			&stock.Sector,
 			&stock.Exchange,
+			&stock.MarketCap,
+			&stock.Currency,
 			&stock.CreatedAt,
 			&stock.UpdatedAt,
 		)
@@ -282,13 +298,13 @@ func (r *stockRepository) GetStocksWithCurrentSignals(ctx context.Context, searc
 	var args []interface{}
 
 	baseQuery := `
-		SELECT DISTINCT s.id, s.ticker, s.name, s.sector, s.exchange, s.created_at, s.updated_at,
+		SELECT DISTINCT s.id, s.ticker, s.name, s.sector, s.exchange, s.market_cap, s.currency, s.created_at, s.updated_at,
 		       sig.stock_id, sig.signal, sig.date, sig.created_at as signal_created_at
 		FROM stocks s
 		LEFT JOIN LATERAL (
 			SELECT stock_id, signal, date, created_at
 			FROM signals
-			WHERE stock_id = s.id
+			WHERE stock_id = s.id AND strategy_id IS NULL
 			ORDER BY date DESC, created_at DESC
 			LIMIT 1
 		) sig ON true`
@@ -327,6 +343,8 @@ func (r *stockRepository) GetStocksWithCurrentSignals(ctx context.Context, searc
 			&stock.Name,
 			&stock.Sector,
 			&stock.Exchange,
+			&stock.MarketCap,
+			&stock.Currency,
 			&stock.CreatedAt,
 			&stock.UpdatedAt,
 			&signalStockID,
@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
 	"portfolio-app/internal/models"
 )
 
@@ -20,6 +21,7 @@ type StrategyRepository interface {
 	AddStockToStrategy(ctx context.Context, strategyID, stockID uuid.UUID) error
 	RemoveStockFromStrategy(ctx context.Context, strategyID, stockID uuid.UUID) error
 	UpdateStockEligibility(ctx context.Context, strategyID, stockID uuid.UUID, eligible bool) error
+	UpdateStockWeight(ctx context.Context, strategyID, stockID uuid.UUID, weight *decimal.Decimal) error
 	GetStrategyStocks(ctx context.Context, strategyID uuid.UUID) ([]*models.StrategyStock, error)
 }
 
@@ -36,9 +38,9 @@ func NewStrategyRepository(db *sql.DB) StrategyRepository {
 // Create creates a new strategy in the database
 func (r *strategyRepository) Create(ctx context.Context, strategy *models.Strategy) (*models.Strategy, error) {
 	query := `
-		INSERT INTO strategies (id, user_id, name, weight_mode, weight_value, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, user_id, name, weight_mode, weight_value, created_at, updated_at`
+		INSERT INTO strategies (id, user_id, name, weight_mode, weight_value, stock_weighting, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, user_id, name, weight_mode, weight_value, stock_weighting, created_at, updated_at`
 
 	row := r.db.QueryRowContext(ctx, query,
 		strategy.ID,
@@ -46,6 +48,7 @@ func (r *strategyRepository) Create(ctx context.Context, strategy *models.Strate
 		strategy.Name,
 		strategy.WeightMode,
 		strategy.WeightValue,
+		strategy.StockWeighting,
 		strategy.CreatedAt,
 		strategy.UpdatedAt,
 	)
@@ -57,6 +60,7 @@ func (r *strategyRepository) Create(ctx context.Context, strategy *models.Strate
 		&created.Name,
 		&created.WeightMode,
 		&created.WeightValue,
+		&created.StockWeighting,
 		&created.CreatedAt,
 		&created.UpdatedAt,
 	)
@@ -71,9 +75,9 @@ func (r *strategyRepository) Create(ctx context.Context, strategy *models.Strate
 func (r *strategyRepository) Update(ctx context.Context, strategy *models.Strategy) (*models.Strategy, error) {
 	query := `
 		UPDATE strategies 
-		SET name = $3, weight_mode = $4, weight_value = $5, updated_at = $6
+		SET name = $3, weight_mode = $4, weight_value = $5, stock_weighting = $6, updated_at = $7
 		WHERE id = $1 AND user_id = $2
-		RETURNING id, user_id, name, weight_mode, weight_value, created_at, updated_at`
+		RETURNING id, user_id, name, weight_mode, weight_value, stock_weighting, created_at, updated_at`
 
 	row := r.db.QueryRowContext(ctx, query,
 		strategy.ID,
@@ -81,6 +85,7 @@ func (r *strategyRepository) Update(ctx context.Context, strategy *models.Strate
 		strategy.Name,
 		strategy.WeightMode,
 		strategy.WeightValue,
+		strategy.StockWeighting,
 		strategy.UpdatedAt,
 	)
 
@@ -91,6 +96,7 @@ func (r *strategyRepository) Update(ctx context.Context, strategy *models.Strate
 		&updated.Name,
 		&updated.WeightMode,
 		&updated.WeightValue,
+		&updated.StockWeighting,
 		&updated.CreatedAt,
 		&updated.UpdatedAt,
 	)
@@ -107,7 +113,7 @@ func (r *strategyRepository) Update(ctx context.Context, strategy *models.Strate
 // GetByID retrieves a strategy by ID and user ID
 func (r *strategyRepository) GetByID(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Strategy, error) {
 	query := `
-		SELECT id, user_id, name, weight_mode, weight_value, created_at, updated_at
+		SELECT id, user_id, name, weight_mode, weight_value, stock_weighting, created_at, updated_at
 		FROM strategies
 		WHERE id = $1 AND user_id = $2`
 
@@ -120,6 +126,7 @@ func (r *strategyRepository) GetByID(ctx context.Context, id uuid.UUID, userID u
 		&strategy.Name,
 		&strategy.WeightMode,
 		&strategy.WeightValue,
+		&strategy.StockWeighting,
 		&strategy.CreatedAt,
 		&strategy.UpdatedAt,
 	)
@@ -157,7 +164,7 @@ func (r *strategyRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*
 	for _, id := range ids {
 		// Query without user_id constraint since this is used by allocation engine
 		query := `
-			SELECT id, user_id, name, weight_mode, weight_value, created_at, updated_at
+			SELECT id, user_id, name, weight_mode, weight_value, stock_weighting, created_at, updated_at
 			FROM strategies
 			WHERE id = $1`
 
@@ -170,6 +177,7 @@ func (r *strategyRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*
 			&strategy.Name,
 			&strategy.WeightMode,
 			&strategy.WeightValue,
+			&strategy.StockWeighting,
 			&strategy.CreatedAt,
 			&strategy.UpdatedAt,
 		)
@@ -200,7 +208,7 @@ func (r *strategyRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*
 // GetByUserID retrieves all strategies for a user
 func (r *strategyRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Strategy, error) {
 	query := `
-		SELECT id, user_id, name, weight_mode, weight_value, created_at, updated_at
+		SELECT id, user_id, name, weight_mode, weight_value, stock_weighting, created_at, updated_at
 		FROM strategies
 		WHERE user_id = $1
 		ORDER BY created_at DESC`
@@ -220,6 +228,7 @@ func (r *strategyRepository) GetByUserID(ctx context.Context, userID uuid.UUID)
 			&strategy.Name,
 			&strategy.WeightMode,
 			&strategy.WeightValue,
+			&strategy.StockWeighting,
 			&strategy.CreatedAt,
 			&strategy.UpdatedAt,
 		)
@@ -329,11 +338,35 @@ func (r *strategyRepository) UpdateStockEligibility(ctx context.Context, strateg
 	return nil
 }
 
+// UpdateStockWeight sets or clears the custom weight of a stock within a strategy
+func (r *strategyRepository) UpdateStockWeight(ctx context.Context, strategyID, stockID uuid.UUID, weight *decimal.Decimal) error {
+	query := `
+		UPDATE strategy_stocks 
+		SET custom_weight = $3
+		WHERE strategy_id = $1 AND stock_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, strategyID, stockID, weight)
+	if err != nil {
+		return fmt.Errorf("failed to update stock weight: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "strategy stock"}
+	}
+
+	return nil
+}
+
 // GetStrategyStocks retrieves all stocks associated with a strategy
 func (r *strategyRepository) GetStrategyStocks(ctx context.Context, strategyID uuid.UUID) ([]*models.StrategyStock, error) {
 	query := `
-		SELECT ss.strategy_id, ss.stock_id, ss.eligible, ss.created_at,
-		       s.id, s.ticker, s.name, s.sector, s.exchange, s.created_at, s.updated_at
+		SELECT ss.strategy_id, ss.stock_id, ss.eligible, ss.custom_weight, ss.created_at,
+		       s.id, s.ticker, s.name, s.sector, s.exchange, s.market_cap, s.currency, s.created_at, s.updated_at
 		FROM strategy_stocks ss
 		JOIN stocks s ON ss.stock_id = s.id
 		WHERE ss.strategy_id = $1
@@ -354,12 +387,15 @@ func (r *strategyRepository) GetStrategyStocks(ctx context.Context, strategyID u
 			&ss.StrategyID,
 			&ss.StockID,
 			&ss.Eligible,
+			&ss.CustomWeight,
 			&ss.CreatedAt,
 			&stock.ID,
 			&stock.Ticker,
 			&stock.Name,
 			&stock.Sector,
 			&stock.Exchange,
+			&stock.MarketCap,
+			&stock.Currency,
 			&stock.CreatedAt,
 			&stock.UpdatedAt,
 		)
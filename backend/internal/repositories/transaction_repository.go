@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+)
+
+// TransactionRepository defines the interface for portfolio ledger operations
+type TransactionRepository interface {
+	Create(ctx context.Context, transaction *models.Transaction) error
+	GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error)
+	AppendTransaction(ctx context.Context, portfolioID uuid.UUID, transaction *models.Transaction, apply func(entries []*models.Transaction) (*models.LedgerUpdate, error)) error
+}
+
+// transactionRepository implements the TransactionRepository interface
+type transactionRepository struct {
+	db *sql.DB
+}
+
+// execer is satisfied by both *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// NewTransactionRepository creates a new transaction repository instance
+func NewTransactionRepository(db *sql.DB) TransactionRepository {
+	return &transactionRepository{db: db}
+}
+
+// Create appends a single ledger entry
+func (r *transactionRepository) Create(ctx context.Context, transaction *models.Transaction) error {
+	return insertTransaction(ctx, r.db, transaction)
+}
+
+// GetByPortfolioID retrieves the ledger for a portfolio within a date range in execution order
+func (r *transactionRepository) GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	return queryTransactions(ctx, r.db, portfolioID, from, to)
+}
+
+// queryTransactions reads the ledger for a portfolio within a date range using the given querier
+func queryTransactions(ctx context.Context, q queryer, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	query := `
+		SELECT t.id, t.portfolio_id, t.stock_id, t.type, t.quantity, t.price, t.amount, t.fx_rate,
+		       t.notes, t.lot_selections, t.executed_at, t.created_at,
+		       s.ticker, s.name, s.sector, s.currency
+		FROM transactions t
+		LEFT JOIN stocks s ON t.stock_id = s.id
+		WHERE t.portfolio_id = $1 AND t.executed_at BETWEEN $2 AND $3
+		ORDER BY t.executed_at ASC, t.created_at ASC`
+
+	rows, err := q.QueryContext(ctx, query, portfolioID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []*models.Transaction
+	for rows.Next() {
+		var transaction models.Transaction
+		var ticker, name, sector, currency sql.NullString
+		var lotsJSON []byte
+
+		err := rows.Scan(
+			&transaction.ID,
+			&transaction.PortfolioID,
+			&transaction.StockID,
+			&transaction.Type,
+			&transaction.Quantity,
+			&transaction.Price,
+			&transaction.Amount,
+			&transaction.FXRate,
+			&transaction.Notes,
+			&lotsJSON,
+			&transaction.ExecutedAt,
+			&transaction.CreatedAt,
+			&ticker,
+			&name,
+			&sector,
+			&currency,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		if lotsJSON != nil {
+			if err := json.Unmarshal(lotsJSON, &transaction.LotSelections); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal lot selections: %w", err)
+			}
+		}
+
+		if transaction.StockID != nil && ticker.Valid {
+			transaction.Stock = &models.Stock{
+				ID:       *transaction.StockID,
+				Ticker:   ticker.String,
+				Name:     name.String,
+				Currency: currency.String,
+			}
+			if sector.Valid {
+				transaction.Stock.Sector = &sector.String
+			}
+		}
+
+		transactions = append(transactions, &transaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// AppendTransaction appends a ledger entry while holding a lock on the portfolio row. apply is given
+// the ledger read under the lock and derives the state to write, so entries recorded concurrently are
+// validated one after another. An error from apply is returned as is and nothing is written.
+func (r *transactionRepository) AppendTransaction(ctx context.Context, portfolioID uuid.UUID, transaction *models.Transaction, apply func(entries []*models.Transaction) (*models.LedgerUpdate, error)) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lockedID uuid.UUID
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM portfolios WHERE id = $1 FOR UPDATE`, portfolioID).Scan(&lockedID); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("portfolio not found")
+		}
+		return fmt.Errorf("failed to lock portfolio: %w", err)
+	}
+
+	entries, err := queryTransactions(ctx, tx, portfolioID, time.Time{}, time.Now())
+	if err != nil {
+		return err
+	}
+
+	update, err := apply(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := insertTransaction(ctx, tx, transaction); err != nil {
+		return err
+	}
+
+	if err := replacePositions(ctx, tx, portfolioID, update.Positions); err != nil {
+		return err
+	}
+
+	portfolioQuery := `
+		UPDATE portfolios
+		SET cash_balance = $1, total_investment = GREATEST(total_investment + $2, 0), updated_at = $3
+		WHERE id = $4`
+	if _, err := tx.ExecContext(ctx, portfolioQuery, update.CashBalance, update.InvestmentChange, time.Now(), portfolioID); err != nil {
+		return fmt.Errorf("failed to update portfolio balances: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// insertTransaction writes a ledger entry using the given executor
+func insertTransaction(ctx context.Context, exec execer, transaction *models.Transaction) error {
+	var lotsJSON []byte
+	if len(transaction.LotSelections) > 0 {
+		var err error
+		lotsJSON, err = json.Marshal(transaction.LotSelections)
+		if err != nil {
+			return fmt.Errorf("failed to marshal lot selections: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO transactions (id, portfolio_id, stock_id, type, quantity, price, amount, fx_rate, notes, lot_selections, executed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+
+	_, err := exec.ExecContext(ctx, query,
+		transaction.ID,
+		transaction.PortfolioID,
+		transaction.StockID,
+		transaction.Type,
+		transaction.Quantity,
+		transaction.Price,
+		transaction.Amount,
+		transaction.FXRate,
+		transaction.Notes,
+		lotsJSON,
+		transaction.ExecutedAt,
+		transaction.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create %s transaction: %w", transaction.Type, err)
+	}
+
+	return nil
+}
+
+// replacePositions upserts the given positions and removes any other positions of the portfolio
+func replacePositions(ctx context.Context, exec execer, portfolioID uuid.UUID, positions []*models.Position) error {
+	if err := upsertPositions(ctx, exec, portfolioID, positions); err != nil {
+		return err
+	}
+
+	if len(positions) == 0 {
+		if _, err := exec.ExecContext(ctx, `DELETE FROM positions WHERE portfolio_id = $1`, portfolioID); err != nil {
+			return fmt.Errorf("failed to delete closed positions: %w", err)
+		}
+		return nil
+	}
+
+	// Delete positions that are no longer held
+	placeholders := make([]string, len(positions))
+	args := make([]interface{}, len(positions)+1)
+	args[0] = portfolioID
+	for i, position := range positions {
+		placeholders[i] = fmt.Sprintf("$%d", i+2)
+		args[i+1] = position.StockID
+	}
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM positions WHERE portfolio_id = $1 AND stock_id NOT IN (%s)`,
+		strings.Join(placeholders, ","))
+	if _, err := exec.ExecContext(ctx, deleteQuery, args...); err != nil {
+		return fmt.Errorf("failed to delete closed positions: %w", err)
+	}
+
+	return nil
+}
+
+// upsertPositions creates or updates the given positions, preserving the creation time of existing rows
+func upsertPositions(ctx context.Context, exec execer, portfolioID uuid.UUID, positions []*models.Position) error {
+	upsertQuery := `
+		INSERT INTO positions (portfolio_id, stock_id, quantity, entry_price, allocation_value, entry_fx_rate, strategy_contrib, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (portfolio_id, stock_id)
+		DO UPDATE SET quantity = EXCLUDED.quantity, entry_price = EXCLUDED.entry_price,
+		              allocation_value = EXCLUDED.allocation_value, entry_fx_rate = EXCLUDED.entry_fx_rate,
+		              strategy_contrib = EXCLUDED.strategy_contrib,
+		              updated_at = EXCLUDED.updated_at`
+
+	for _, position := range positions {
+		_, err := exec.ExecContext(ctx, upsertQuery,
+			portfolioID, position.StockID, position.Quantity,
+			position.EntryPrice, position.AllocationValue, position.EntryFXRate, position.StrategyContrib,
+			position.CreatedAt, position.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save position for stock %s: %w", position.StockID, err)
+		}
+	}
+
+	return nil
+}
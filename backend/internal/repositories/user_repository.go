@@ -18,6 +18,8 @@ type UserRepository interface {
 	Update(ctx context.Context, id uuid.UUID, req *models.UpdateUserRequest) (*models.User, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 	List(ctx context.Context, limit, offset int) ([]*models.User, error)
+	GetWebhookSecret(ctx context.Context, id uuid.UUID) (string, error)
+	SetWebhookSecret(ctx context.Context, id uuid.UUID, secret string) error
 }
 
 // userRepository implements UserRepository
@@ -173,6 +175,44 @@ func (r *userRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// GetWebhookSecret retrieves the secret a user's signal webhooks are signed with; it is empty
+// when the user has not generated one
+func (r *userRepository) GetWebhookSecret(ctx context.Context, id uuid.UUID) (string, error) {
+	query := `SELECT webhook_secret FROM users WHERE id = $1`
+
+	var secret sql.NullString
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&secret)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", &models.NotFoundError{Resource: "user"}
+		}
+		return "", fmt.Errorf("failed to get webhook secret: %w", err)
+	}
+
+	return secret.String, nil
+}
+
+// SetWebhookSecret replaces the secret a user's signal webhooks are signed with
+func (r *userRepository) SetWebhookSecret(ctx context.Context, id uuid.UUID, secret string) error {
+	query := `UPDATE users SET webhook_secret = $1, updated_at = NOW() WHERE id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, secret, id)
+	if err != nil {
+		return fmt.Errorf("failed to set webhook secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return &models.NotFoundError{Resource: "user"}
+	}
+
+	return nil
+}
+
 // List retrieves a list of users with pagination
 func (r *userRepository) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
 	query := `
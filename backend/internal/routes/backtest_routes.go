@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"portfolio-app/internal/handlers"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/repositories"
+	"portfolio-app/internal/services"
+)
+
+// SetupBacktestRoutes sets up strategy backtest routes
+func SetupBacktestRoutes(router fiber.Router, backtestHandler *handlers.BacktestHandler, authService *services.AuthService, userRepo repositories.UserRepository) {
+	backtests := router.Group("/backtests", middleware.AuthMiddleware(authService, userRepo), middleware.RateLimitMiddleware())
+
+	backtests.Post("/", backtestHandler.CreateBacktest)
+	backtests.Get("/", backtestHandler.GetBacktests)
+	backtests.Get("/:id", backtestHandler.GetBacktest)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"portfolio-app/internal/handlers"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/repositories"
+	"portfolio-app/internal/services"
+)
+
+// SetupCorporateActionRoutes sets up corporate action administration routes
+func SetupCorporateActionRoutes(router fiber.Router, corporateActionHandler *handlers.CorporateActionHandler, authService *services.AuthService, userRepo repositories.UserRepository) {
+	corporateActions := router.Group("/corporate-actions", middleware.AuthMiddleware(authService, userRepo), middleware.RateLimitMiddleware())
+
+	corporateActions.Post("/", corporateActionHandler.CreateCorporateAction)
+	corporateActions.Get("/", corporateActionHandler.GetCorporateActions)
+	corporateActions.Post("/import", corporateActionHandler.ImportCorporateActions)
+	corporateActions.Post("/:id/apply", corporateActionHandler.ApplyCorporateAction)
+	corporateActions.Get("/:id/adjustments", corporateActionHandler.GetAdjustments)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"portfolio-app/internal/handlers"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/repositories"
+	"portfolio-app/internal/services"
+)
+
+// SetupDividendRoutes sets up dividend administration routes
+func SetupDividendRoutes(router fiber.Router, dividendHandler *handlers.DividendHandler, authService *services.AuthService, userRepo repositories.UserRepository) {
+	dividends := router.Group("/dividends", middleware.AuthMiddleware(authService, userRepo), middleware.RateLimitMiddleware())
+
+	dividends.Post("/", dividendHandler.CreateDividendEvent)
+	dividends.Get("/", dividendHandler.GetDividendEvents)
+	dividends.Post("/process", dividendHandler.ProcessDueDividends)
+	dividends.Post("/:id/process", dividendHandler.ProcessDividendEvent)
+	dividends.Get("/:id/payments", dividendHandler.GetPayments)
+}
@@ -29,9 +29,25 @@ func SetupPortfolioRoutes(router fiber.Router, handler *handlers.PortfolioHandle
 	// Portfolio performance
 	protected.Get("/:id/history", handler.GetPortfolioHistory)
 	protected.Get("/:id/performance", handler.GetPortfolioPerformance)
+	protected.Get("/:id/attribution", handler.GetPortfolioAttribution)
+	protected.Get("/:id/positions/history", handler.GetPositionHistory)
 	protected.Post("/:id/nav/update", handler.UpdatePortfolioNAV)
 	
+	// Portfolio reports
+	protected.Get("/:id/reports/tax", handler.GetTaxReport)
+	
+	// Portfolio ledger
+	protected.Get("/:id/transactions", handler.GetTransactions)
+	protected.Post("/:id/transactions", handler.CreateTransaction)
+	
 	// Portfolio rebalancing
 	protected.Post("/:id/rebalance/preview", handler.GenerateRebalancePreview)
 	protected.Post("/:id/rebalance", handler.RebalancePortfolio)
+	protected.Get("/:id/allocation-config", handler.GetAllocationConfig)
+	protected.Put("/:id/allocation-config", handler.UpdateAllocationConfig)
+	protected.Get("/:id/rebalance-policy", handler.GetRebalancePolicy)
+	protected.Put("/:id/rebalance-policy", handler.UpdateRebalancePolicy)
+	protected.Get("/:id/rebalance-runs", handler.GetRebalanceRuns)
+	protected.Post("/:id/rebalance-runs/:runId/approve", handler.ApproveRebalanceRun)
+	protected.Post("/:id/rebalance-runs/:runId/reject", handler.RejectRebalanceRun)
 }
\ No newline at end of file
@@ -32,4 +32,5 @@ func SetupStockRoutes(app fiber.Router, stockHandler *handlers.StockHandler, aut
 	// Strategy assignment management
 	protected.Post("/:id/strategies/:strategyId", stockHandler.AddStockToStrategy)
 	protected.Delete("/:id/strategies/:strategyId", stockHandler.RemoveStockFromStrategy)
+	protected.Put("/:id/strategies/:strategyId/signal", stockHandler.UpdateStrategyStockSignal)
 }
\ No newline at end of file
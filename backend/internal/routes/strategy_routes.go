@@ -9,7 +9,7 @@ import (
 )
 
 // SetupStrategyRoutes sets up all strategy-related routes
-func SetupStrategyRoutes(app fiber.Router, strategyHandler *handlers.StrategyHandler, authService *services.AuthService, userRepo repositories.UserRepository) {
+func SetupStrategyRoutes(app fiber.Router, strategyHandler *handlers.StrategyHandler, signalRuleHandler *handlers.SignalRuleHandler, authService *services.AuthService, userRepo repositories.UserRepository) {
 	strategies := app.Group("/strategies")
 
 	// Apply authentication middleware and rate limiting to all strategy routes
@@ -27,4 +27,12 @@ func SetupStrategyRoutes(app fiber.Router, strategyHandler *handlers.StrategyHan
 
 	// Stock assignment and eligibility management
 	protected.Put("/:id/stocks/:stockId", strategyHandler.UpdateStockEligibility)
+	protected.Put("/:id/stocks/:stockId/weight", strategyHandler.UpdateStockWeight)
+
+	// Signal rules generating the strategy's signals
+	protected.Post("/:id/signal-rules", signalRuleHandler.CreateRule)
+	protected.Get("/:id/signal-rules", signalRuleHandler.GetRules)
+	protected.Post("/:id/signal-rules/evaluate", signalRuleHandler.EvaluateRules)
+	protected.Put("/:id/signal-rules/:ruleId", signalRuleHandler.UpdateRule)
+	protected.Delete("/:id/signal-rules/:ruleId", signalRuleHandler.DeleteRule)
 }
\ No newline at end of file
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"portfolio-app/internal/handlers"
+	"portfolio-app/internal/middleware"
+	"portfolio-app/internal/repositories"
+	"portfolio-app/internal/services"
+)
+
+// SetupWebhookRoutes sets up signal webhook routes
+func SetupWebhookRoutes(router fiber.Router, webhookHandler *handlers.WebhookHandler, authService *services.AuthService, userRepo repositories.UserRepository) {
+	webhooks := router.Group("/webhooks")
+
+	// Signal ingestion is authenticated by the payload signature instead of a JWT
+	webhooks.Post("/signals/:userId", middleware.RateLimitMiddleware(), webhookHandler.IngestSignals)
+
+	// Secret management requires a logged in user
+	protected := webhooks.Group("", middleware.AuthMiddleware(authService, userRepo), middleware.RateLimitMiddleware())
+	protected.Post("/secret", webhookHandler.RotateSecret)
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
@@ -16,7 +17,10 @@ type AllocationEngine struct {
 	stockRepo           StockRepository
 	signalRepo          SignalRepository
 	marketDataService   MarketDataService
+	fxService           FXService
 	constraintValidator *ConstraintValidator
+	// asOf pins price history lookups to a past date, as when replaying a backtest; zero means now
+	asOf time.Time
 }
 
 // AllocationEngineInterface defines the allocation engine contract
@@ -27,6 +31,7 @@ type AllocationEngineInterface interface {
 	ValidateConstraints(allocations []models.StockAllocation, constraints models.AllocationConstraints) error
 	ValidateConstraintsDetailed(allocations []models.StockAllocation, constraints models.AllocationConstraints, totalInvestment decimal.Decimal) *ValidationResult
 	ValidateConstraintsConfig(constraints models.AllocationConstraints, totalInvestment decimal.Decimal) *ValidationResult
+	HasExitSignals(ctx context.Context, strategyIDs []uuid.UUID, stockIDs []uuid.UUID) (bool, error)
 }
 
 // StrategyRepository interface for strategy data access
@@ -42,21 +47,24 @@ type StockRepository interface {
 
 // SignalRepository interface for signal data access
 type SignalRepository interface {
-	GetLatestSignals(ctx context.Context, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error)
+	GetLatestSignals(ctx context.Context, strategyID *uuid.UUID, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error)
 }
 
-// NewAllocationEngine creates a new allocation engine
+// NewAllocationEngine creates a new allocation engine. A nil fxService prices every stock as if it
+// were quoted in the base currency of the request.
 func NewAllocationEngine(
 	strategyRepo StrategyRepository,
 	stockRepo StockRepository,
 	signalRepo SignalRepository,
 	marketDataService MarketDataService,
+	fxService FXService,
 ) *AllocationEngine {
 	return &AllocationEngine{
 		strategyRepo:        strategyRepo,
 		stockRepo:           stockRepo,
 		signalRepo:          signalRepo,
 		marketDataService:   marketDataService,
+		fxService:           fxService,
 		constraintValidator: NewConstraintValidator(),
 	}
 }
@@ -80,7 +88,7 @@ func (e *AllocationEngine) CalculateAllocations(ctx context.Context, req *models
 	}
 
 	// 3. Get all stocks from strategies and their signals
-	stockAllocations, err := e.distributeToStocks(ctx, strategies, strategyAllocations, req.ExcludedStocks)
+	stockAllocations, exitStocks, err := e.distributeToStocks(ctx, strategies, strategyAllocations, req.ExcludedStocks, req.StrongBuyMultiplier)
 	if err != nil {
 		return nil, fmt.Errorf("failed to distribute to stocks: %w", err)
 	}
@@ -91,30 +99,49 @@ func (e *AllocationEngine) CalculateAllocations(ctx context.Context, req *models
 		return nil, fmt.Errorf("invalid constraints configuration: %s", configValidation.Violations[0].Message)
 	}
 
-	// 5. Apply constraints
-	constrainedAllocations, err := e.applyConstraints(stockAllocations, req.Constraints, req.TotalInvestment)
+	// 5. Apply constraints, redistributing capped excess so allocations add up to the total investment
+	finalAllocations, err := e.applyConstraints(stockAllocations, req.Constraints, req.TotalInvestment)
 	if err != nil {
 		return nil, fmt.Errorf("failed to apply constraints: %w", err)
 	}
 
-	// 6. Normalize allocations to match total investment
-	finalAllocations := e.normalizeAllocations(constrainedAllocations, req.TotalInvestment)
+	// 6. Record which stocks were pinned at the per-stock cap
+	cappedStocks := make([]uuid.UUID, 0)
+	for _, allocation := range finalAllocations {
+		if allocation.Capped {
+			cappedStocks = append(cappedStocks, allocation.StockID)
+		}
+	}
 
 	// 7. Fetch real-time prices and calculate quantities
-	finalAllocationsWithPrices, err := e.addPricesAndQuantities(ctx, finalAllocations)
+	sharePrecision := int32(0)
+	if req.FractionalShares {
+		sharePrecision = req.SharePrecision
+	}
+	finalAllocationsWithPrices, err := e.addPricesAndQuantities(ctx, finalAllocations, sharePrecision, req.BaseCurrency)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch prices and calculate quantities: %w", err)
 	}
 
-	// 8. Perform detailed validation and provide warnings if needed
+	// 8. Optionally spend cash left over by rounding on more shares of the most underweight stocks
+	var cashSweep *models.CashSweepResult
+	if req.SweepResidualCash {
+		cashSweep = e.sweepResidualCash(finalAllocationsWithPrices, req.Constraints, req.TotalInvestment, sharePrecision)
+	}
+
+	// 9. Perform detailed validation; violations fail strict requests, otherwise they are returned with the preview
 	validation := e.ValidateConstraintsDetailed(finalAllocationsWithPrices, req.Constraints, req.TotalInvestment)
+	violations, warnings := validation.Partition()
+	if req.Strict && len(violations) > 0 {
+		return nil, ErrConstraintViolation(violations)
+	}
+
+	var suggestions []string
 	if !validation.IsValid {
-		// Log warnings but don't fail - let the user decide
-		// In a real implementation, you might want to return warnings in the response
-		fmt.Printf("Allocation warnings: %+v\n", validation.Violations)
+		suggestions = e.constraintValidator.SuggestConstraintAdjustments(strategies, req.TotalInvestment, req.Constraints)
 	}
 
-	// 9. Calculate unallocated cash based on actual quantities
+	// 10. Calculate unallocated cash based on actual quantities
 	totalAllocated := decimal.Zero
 	for _, allocation := range finalAllocationsWithPrices {
 		totalAllocated = totalAllocated.Add(allocation.ActualValue)
@@ -127,6 +154,12 @@ func (e *AllocationEngine) CalculateAllocations(ctx context.Context, req *models
 		UnallocatedCash: unallocatedCash,
 		TotalAllocated:  totalAllocated,
 		Constraints:     req.Constraints,
+		CappedStocks:    cappedStocks,
+		Violations:      violations,
+		Warnings:        warnings,
+		Suggestions:     suggestions,
+		CashSweep:       cashSweep,
+		ExitStocks:      exitStocks,
 	}, nil
 }
 
@@ -180,8 +213,11 @@ func (e *AllocationEngine) calculateStrategyWeights(strategies []*models.Strateg
 	return strategyAllocations, nil
 }
 
-// distributeToStocks distributes strategy allocations to eligible stocks with "Buy" signals
-func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []*models.Strategy, strategyAllocations map[uuid.UUID]decimal.Decimal, excludedStocks []uuid.UUID) ([]models.StockAllocation, error) {
+// distributeToStocks distributes strategy allocations to eligible stocks with Buy or StrongBuy signals,
+// using each strategy's own signal for a stock when it has one and the global signal otherwise.
+// StrongBuy weights are scaled by strongBuyMultiplier when it is positive. It also returns the strategy
+// stocks that no strategy buys and whose latest signal calls for an exit.
+func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []*models.Strategy, strategyAllocations map[uuid.UUID]decimal.Decimal, excludedStocks []uuid.UUID, strongBuyMultiplier decimal.Decimal) ([]models.StockAllocation, []uuid.UUID, error) {
 	stockAllocations := make(map[uuid.UUID]*models.StockAllocation)
 	excludedSet := make(map[uuid.UUID]bool)
 	
@@ -197,7 +233,7 @@ func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []
 	for _, strategy := range strategies {
 		stocks, err := e.strategyRepo.GetStrategyStocks(ctx, strategy.ID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get stocks for strategy %s: %w", strategy.ID, err)
+			return nil, nil, fmt.Errorf("failed to get stocks for strategy %s: %w", strategy.ID, err)
 		}
 		
 		strategyStocks[strategy.ID] = stocks
@@ -217,7 +253,7 @@ func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []
 	// Get stock details
 	stocks, err := e.stockRepo.GetByIDs(ctx, stockIDSlice)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get stock details: %w", err)
+		return nil, nil, fmt.Errorf("failed to get stock details: %w", err)
 	}
 	
 	stockMap := make(map[uuid.UUID]*models.Stock)
@@ -225,47 +261,86 @@ func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []
 		stockMap[stock.ID] = stock
 	}
 	
-	// Get latest signals for all stocks
-	signals, err := e.signalRepo.GetLatestSignals(ctx, stockIDSlice)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get stock signals: %w", err)
-	}
-	
 	// Process each strategy
+	exitCandidates := make([]uuid.UUID, 0)
+	boughtStocks := make(map[uuid.UUID]bool)
 	for _, strategy := range strategies {
+		candidateIDs := make([]uuid.UUID, 0, len(strategyStocks[strategy.ID]))
+		for _, strategyStock := range strategyStocks[strategy.ID] {
+			if strategyStock.Eligible && !excludedSet[strategyStock.StockID] {
+				candidateIDs = append(candidateIDs, strategyStock.StockID)
+			}
+		}
+		
+		// Get latest signals for the strategy's stocks, preferring its own signals over global ones
+		signals, err := e.signalRepo.GetLatestSignals(ctx, &strategy.ID, candidateIDs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get stock signals for strategy %s: %w", strategy.ID, err)
+		}
+		
+		for _, stockID := range candidateIDs {
+			if signal, hasSignal := signals[stockID]; hasSignal && signal.Signal.IsExit() {
+				exitCandidates = append(exitCandidates, stockID)
+			}
+		}
+		
 		strategyAllocation := strategyAllocations[strategy.ID]
 		if strategyAllocation.IsZero() {
 			continue
 		}
 		
-		// Get eligible stocks with "Buy" signals for this strategy
-		eligibleStocks := make([]uuid.UUID, 0)
+		// Get eligible stocks with Buy or StrongBuy signals for this strategy
+		eligibleStocks := make([]*models.StrategyStock, 0)
 		for _, strategyStock := range strategyStocks[strategy.ID] {
 			if !strategyStock.Eligible || excludedSet[strategyStock.StockID] {
 				continue
 			}
 			
-			// Check if stock has "Buy" signal
+			// Check if stock has a buy signal
 			signal, hasSignal := signals[strategyStock.StockID]
-			if !hasSignal || signal.Signal != models.SignalBuy {
+			if !hasSignal || !signal.Signal.IsBuy() {
+				continue
+			}
+			
+			if stockMap[strategyStock.StockID] == nil {
 				continue
 			}
 			
-			eligibleStocks = append(eligibleStocks, strategyStock.StockID)
+			eligibleStocks = append(eligibleStocks, strategyStock)
 		}
 		
 		if len(eligibleStocks) == 0 {
 			continue
 		}
 		
-		// Distribute strategy allocation equally among eligible stocks
-		allocationPerStock := strategyAllocation.Div(decimal.NewFromInt(int64(len(eligibleStocks))))
+		// Distribute strategy allocation among eligible stocks according to the weighting scheme
+		weights, err := e.calculateStockWeights(ctx, strategy, eligibleStocks, stockMap, signals)
+		if err != nil {
+			return nil, nil, err
+		}
 		
-		for _, stockID := range eligibleStocks {
-			stock := stockMap[stockID]
-			if stock == nil {
-				continue
+		// Overweight StrongBuy names within the strategy
+		if strongBuyMultiplier.IsPositive() {
+			for _, strategyStock := range eligibleStocks {
+				if signals[strategyStock.StockID].Signal == models.SignalStrongBuy {
+					weights[strategyStock.StockID] = weights[strategyStock.StockID].Mul(strongBuyMultiplier)
+				}
 			}
+		}
+		
+		totalWeight := decimal.Zero
+		for _, weight := range weights {
+			totalWeight = totalWeight.Add(weight)
+		}
+		if !totalWeight.IsPositive() {
+			continue
+		}
+		
+		for _, strategyStock := range eligibleStocks {
+			stockID := strategyStock.StockID
+			stock := stockMap[stockID]
+			boughtStocks[stockID] = true
+			allocationPerStock := strategyAllocation.Mul(weights[stockID]).Div(totalWeight)
 			
 			// Initialize or update stock allocation
 			if existing, exists := stockAllocations[stockID]; exists {
@@ -279,6 +354,7 @@ func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []
 					StockID:         stockID,
 					Ticker:          stock.Ticker,
 					Name:            stock.Name,
+					Currency:        models.CurrencyOrDefault(stock.Currency),
 					AllocationValue: allocationPerStock,
 					StrategyContrib: map[string]decimal.Decimal{
 						strategy.ID.String(): allocationPerStock,
@@ -288,27 +364,33 @@ func (e *AllocationEngine) distributeToStocks(ctx context.Context, strategies []
 		}
 	}
 	
+	// Stocks with an exit signal are only exits when no strategy still buys them
+	exitStocks := make([]uuid.UUID, 0)
+	seenExits := make(map[uuid.UUID]bool)
+	for _, stockID := range exitCandidates {
+		if !boughtStocks[stockID] && !seenExits[stockID] {
+			seenExits[stockID] = true
+			exitStocks = append(exitStocks, stockID)
+		}
+	}
+	
 	// Convert map to slice
 	result := make([]models.StockAllocation, 0, len(stockAllocations))
 	for _, allocation := range stockAllocations {
 		result = append(result, *allocation)
 	}
 	
-	return result, nil
+	return result, exitStocks, nil
 }
 
-// applyConstraints applies min/max allocation constraints
+// applyConstraints applies min/max allocation constraints. Stocks below the minimum amount are
+// dropped, then the total investment is water-filled across the remaining stocks: any stock above
+// the per-stock cap is pinned at the cap and its excess is redistributed proportionally to the
+// uncapped stocks, repeating until no stock exceeds the cap.
 func (e *AllocationEngine) applyConstraints(allocations []models.StockAllocation, constraints models.AllocationConstraints, totalInvestment decimal.Decimal) ([]models.StockAllocation, error) {
 	result := make([]models.StockAllocation, 0, len(allocations))
 	
-	maxAllocationAmount := totalInvestment.Mul(constraints.MaxAllocationPerStock).Div(decimal.NewFromInt(100))
-	
 	for _, allocation := range allocations {
-		// Apply maximum allocation constraint
-		if allocation.AllocationValue.GreaterThan(maxAllocationAmount) {
-			allocation.AllocationValue = maxAllocationAmount
-		}
-		
 		// Apply minimum allocation constraint
 		if allocation.AllocationValue.LessThan(constraints.MinAllocationAmount) {
 			// Skip stocks that don't meet minimum allocation
@@ -318,40 +400,73 @@ func (e *AllocationEngine) applyConstraints(allocations []models.StockAllocation
 		result = append(result, allocation)
 	}
 	
-	return result, nil
-}
-
-// normalizeAllocations normalizes allocations to match total investment amount
-func (e *AllocationEngine) normalizeAllocations(allocations []models.StockAllocation, totalInvestment decimal.Decimal) []models.StockAllocation {
-	if len(allocations) == 0 {
-		return allocations
+	if len(result) == 0 || !totalInvestment.IsPositive() {
+		return result, nil
 	}
 	
-	// Calculate current total
-	currentTotal := decimal.Zero
-	for _, allocation := range allocations {
-		currentTotal = currentTotal.Add(allocation.AllocationValue)
+	// The cap is infeasible when even fully capped stocks cannot absorb the total investment
+	hundred := decimal.NewFromInt(100)
+	if constraints.MaxAllocationPerStock.Mul(decimal.NewFromInt(int64(len(result)))).LessThan(hundred) {
+		return nil, ErrInfeasibleMaxAllocation(constraints.MaxAllocationPerStock, len(result))
 	}
 	
-	if currentTotal.IsZero() {
-		return allocations
+	maxAllocationAmount := totalInvestment.Mul(constraints.MaxAllocationPerStock).Div(hundred)
+	originalValues := make([]decimal.Decimal, len(result))
+	for i := range result {
+		originalValues[i] = result[i].AllocationValue
 	}
 	
-	// Calculate normalization factor
-	normalizationFactor := totalInvestment.Div(currentTotal)
+	capped := make([]bool, len(result))
+	for {
+		// Spread whatever the capped stocks leave over the uncapped stocks in proportion to their original value
+		remaining := totalInvestment
+		uncappedTotal := decimal.Zero
+		for i := range result {
+			if capped[i] {
+				remaining = remaining.Sub(maxAllocationAmount)
+			} else {
+				uncappedTotal = uncappedTotal.Add(originalValues[i])
+			}
+		}
+		
+		newlyCapped := false
+		for i := range result {
+			if capped[i] {
+				result[i].AllocationValue = maxAllocationAmount
+				continue
+			}
+			
+			value := decimal.Zero
+			if uncappedTotal.IsPositive() {
+				value = originalValues[i].Mul(remaining).Div(uncappedTotal)
+			}
+			if value.GreaterThan(maxAllocationAmount) {
+				capped[i] = true
+				newlyCapped = true
+			}
+			result[i].AllocationValue = value
+		}
+		
+		if !newlyCapped {
+			break
+		}
+	}
 	
-	// Apply normalization
-	for i := range allocations {
-		allocations[i].AllocationValue = allocations[i].AllocationValue.Mul(normalizationFactor)
-		allocations[i].Weight = allocations[i].AllocationValue.Div(totalInvestment).Mul(decimal.NewFromInt(100))
+	for i := range result {
+		result[i].Capped = capped[i]
+		result[i].Weight = result[i].AllocationValue.Div(totalInvestment).Mul(hundred)
 		
-		// Normalize strategy contributions
-		for strategyID, contrib := range allocations[i].StrategyContrib {
-			allocations[i].StrategyContrib[strategyID] = contrib.Mul(normalizationFactor)
+		// Scale strategy contributions with the stock's allocation
+		if originalValues[i].IsPositive() {
+			contributions := make(map[string]decimal.Decimal, len(result[i].StrategyContrib))
+			for strategyID, contrib := range result[i].StrategyContrib {
+				contributions[strategyID] = contrib.Mul(result[i].AllocationValue).Div(originalValues[i])
+			}
+			result[i].StrategyContrib = contributions
 		}
 	}
 	
-	return allocations
+	return result, nil
 }
 
 // RebalanceAllocations recalculates allocations for an existing portfolio with new investment amount
@@ -399,8 +514,10 @@ func (e *AllocationEngine) ValidateConstraintsConfig(constraints models.Allocati
 	return e.constraintValidator.ValidateConstraintsConfig(constraints, totalInvestment)
 }
 
-// addPricesAndQuantities fetches real-time prices and calculates quantities for allocations
-func (e *AllocationEngine) addPricesAndQuantities(ctx context.Context, allocations []models.StockAllocation) ([]models.StockAllocation, error) {
+// addPricesAndQuantities fetches real-time prices, converts them to the base currency and calculates
+// quantities for allocations. Quantities are rounded down to sharePrecision decimal places; zero buys
+// whole shares only.
+func (e *AllocationEngine) addPricesAndQuantities(ctx context.Context, allocations []models.StockAllocation, sharePrecision int32, baseCurrency string) ([]models.StockAllocation, error) {
 	if len(allocations) == 0 {
 		return allocations, nil
 	}
@@ -417,6 +534,30 @@ func (e *AllocationEngine) addPricesAndQuantities(ctx context.Context, allocatio
 		return nil, fmt.Errorf("failed to fetch market quotes: %w", err)
 	}
 
+	// Fetch the rates converting each foreign stock's currency to the base currency
+	baseCurrency = models.CurrencyOrDefault(baseCurrency)
+	var currencies []string
+	for _, allocation := range allocations {
+		if currency := models.CurrencyOrDefault(allocation.Currency); currency != baseCurrency {
+			currencies = append(currencies, currency)
+		}
+	}
+	rates := make(map[string]decimal.Decimal)
+	if len(currencies) > 0 {
+		if e.fxService == nil {
+			return nil, fmt.Errorf("no FX service to convert %v to %s", currencies, baseCurrency)
+		}
+
+		asOf := e.asOf
+		if asOf.IsZero() {
+			asOf = time.Now()
+		}
+		rates, err = e.fxService.GetRates(ctx, currencies, baseCurrency, asOf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch FX rates: %w", err)
+		}
+	}
+
 	// Update allocations with prices and calculate quantities
 	result := make([]models.StockAllocation, len(allocations))
 	for i, allocation := range allocations {
@@ -427,18 +568,34 @@ func (e *AllocationEngine) addPricesAndQuantities(ctx context.Context, allocatio
 			return nil, fmt.Errorf("no quote available for symbol %s", allocation.Ticker)
 		}
 
-		// Set the price
-		result[i].Price = quote.Price
-
-		// Calculate quantity using floor logic (can only buy whole shares)
-		if quote.Price.GreaterThan(decimal.Zero) {
-			quantity := allocation.AllocationValue.Div(quote.Price).IntPart()
-			result[i].Quantity = int(quantity)
-
-			// Calculate actual value based on whole shares
-			result[i].ActualValue = quote.Price.Mul(decimal.NewFromInt(quantity))
+		// Set the price in the base currency
+		currency := models.CurrencyOrDefault(allocation.Currency)
+		rate := decimal.NewFromInt(1)
+		if currency != baseCurrency {
+			var hasRate bool
+			rate, hasRate = rates[currency]
+			if !hasRate || !rate.IsPositive() {
+				return nil, fmt.Errorf("no FX rate available to convert %s to %s for %s", currency, baseCurrency, allocation.Ticker)
+			}
+		}
+		price := quote.Price
+		if !rate.Equal(decimal.NewFromInt(1)) {
+			price = quote.Price.Mul(rate).Round(4)
+		}
+		result[i].Currency = currency
+		result[i].LocalPrice = quote.Price
+		result[i].FXRate = rate
+		result[i].Price = price
+
+		// Calculate quantity using floor logic so the allocation is never overspent
+		if price.GreaterThan(decimal.Zero) {
+			quantity := allocation.AllocationValue.Div(price).RoundFloor(sharePrecision)
+			result[i].Quantity = quantity
+
+			// Calculate actual value based on the shares bought
+			result[i].ActualValue = price.Mul(quantity)
 		} else {
-			result[i].Quantity = 0
+			result[i].Quantity = decimal.Zero
 			result[i].ActualValue = decimal.Zero
 		}
 	}
@@ -446,6 +603,30 @@ func (e *AllocationEngine) addPricesAndQuantities(ctx context.Context, allocatio
 	return result, nil
 }
 
+// HasExitSignals reports whether any of the stocks has a Sell or StrongSell signal under one of the
+// strategies. It only reads signals, so it is cheap enough to run before pricing a full allocation.
+func (e *AllocationEngine) HasExitSignals(ctx context.Context, strategyIDs []uuid.UUID, stockIDs []uuid.UUID) (bool, error) {
+	if len(stockIDs) == 0 {
+		return false, nil
+	}
+
+	for _, strategyID := range strategyIDs {
+		strategyID := strategyID
+		signals, err := e.signalRepo.GetLatestSignals(ctx, &strategyID, stockIDs)
+		if err != nil {
+			return false, fmt.Errorf("failed to get stock signals for strategy %s: %w", strategyID, err)
+		}
+
+		for _, signal := range signals {
+			if signal.Signal.IsExit() {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
 // RecalculateWithExclusions recalculates allocations when stocks are removed
 func (e *AllocationEngine) RecalculateWithExclusions(ctx context.Context, originalReq *models.AllocationRequest, excludedStocks []uuid.UUID) (*models.AllocationPreview, error) {
 	// Create a new request with excluded stocks
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -41,8 +42,8 @@ type MockAllocationSignalRepository struct {
 	mock.Mock
 }
 
-func (m *MockAllocationSignalRepository) GetLatestSignals(ctx context.Context, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
-	args := m.Called(ctx, stockIDs)
+func (m *MockAllocationSignalRepository) GetLatestSignals(ctx context.Context, strategyID *uuid.UUID, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
+	args := m.Called(ctx, strategyID, stockIDs)
 	return args.Get(0).(map[uuid.UUID]*models.Signal), args.Error(1)
 }
 
@@ -53,7 +54,7 @@ func TestAllocationEngine_CalculateAllocations(t *testing.T) {
 	mockSignalRepo := new(MockAllocationSignalRepository)
 	mockMarketData := NewMockMarketDataService()
 	
-	engine := NewAllocationEngine(mockStrategyRepo, mockStockRepo, mockSignalRepo, mockMarketData)
+	engine := NewAllocationEngine(mockStrategyRepo, mockStockRepo, mockSignalRepo, mockMarketData, nil)
 	
 	// Test data
 	strategyID1 := uuid.New()
@@ -116,7 +117,7 @@ func TestAllocationEngine_CalculateAllocations(t *testing.T) {
 	mockStrategyRepo.On("GetStrategyStocks", mock.Anything, strategyID1).Return(strategyStocks1, nil)
 	mockStrategyRepo.On("GetStrategyStocks", mock.Anything, strategyID2).Return(strategyStocks2, nil)
 	mockStockRepo.On("GetByIDs", mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(stocks, nil)
-	mockSignalRepo.On("GetLatestSignals", mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(signals, nil)
+	mockSignalRepo.On("GetLatestSignals", mock.Anything, mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(signals, nil)
 	
 	// Test request
 	req := &models.AllocationRequest{
@@ -153,6 +154,64 @@ func TestAllocationEngine_CalculateAllocations(t *testing.T) {
 	mockSignalRepo.AssertExpectations(t)
 }
 
+func TestAllocationEngine_CalculateAllocations_ReturnsWarnings(t *testing.T) {
+	strategyID := uuid.New()
+	stockID1 := uuid.New()
+	stockID2 := uuid.New()
+	
+	strategy := &models.Strategy{
+		ID:          strategyID,
+		Name:        "Focused Strategy",
+		WeightMode:  models.WeightModePercent,
+		WeightValue: decimal.NewFromInt(100),
+	}
+	
+	stocks := []*models.Stock{
+		{ID: stockID1, Ticker: "AAPL", Name: "Apple Inc."},
+		{ID: stockID2, Ticker: "GOOGL", Name: "Alphabet Inc."},
+	}
+	
+	strategyStocks := []*models.StrategyStock{
+		{StrategyID: strategyID, StockID: stockID1, Eligible: true},
+		{StrategyID: strategyID, StockID: stockID2, Eligible: true},
+	}
+	
+	signals := map[uuid.UUID]*models.Signal{
+		stockID1: {StockID: stockID1, Signal: models.SignalBuy, Date: time.Now()},
+		stockID2: {StockID: stockID2, Signal: models.SignalBuy, Date: time.Now()},
+	}
+	
+	for _, strict := range []bool{false, true} {
+		mockStrategyRepo := new(MockAllocationStrategyRepository)
+		mockStockRepo := new(MockAllocationStockRepository)
+		mockSignalRepo := new(MockAllocationSignalRepository)
+		engine := NewAllocationEngine(mockStrategyRepo, mockStockRepo, mockSignalRepo, NewMockMarketDataService(), nil)
+		
+		mockStrategyRepo.On("GetByIDs", mock.Anything, []uuid.UUID{strategyID}).Return([]*models.Strategy{strategy}, nil)
+		mockStrategyRepo.On("GetStrategyStocks", mock.Anything, strategyID).Return(strategyStocks, nil)
+		mockStockRepo.On("GetByIDs", mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(stocks, nil)
+		mockSignalRepo.On("GetLatestSignals", mock.Anything, mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(signals, nil)
+		
+		req := &models.AllocationRequest{
+			StrategyIDs:     []uuid.UUID{strategyID},
+			TotalInvestment: decimal.NewFromInt(10000),
+			Constraints: models.AllocationConstraints{
+				MaxAllocationPerStock: decimal.NewFromInt(50),
+				MinAllocationAmount:   decimal.NewFromInt(100),
+			},
+			Strict: strict,
+		}
+		
+		// Two stocks only raise a concentration warning, which never fails the request
+		result, err := engine.CalculateAllocations(context.Background(), req)
+		
+		assert.NoError(t, err)
+		assert.Empty(t, result.Violations)
+		assert.Len(t, result.Warnings, 1)
+		assert.Equal(t, "CONCENTRATION_RISK", result.Warnings[0].Type)
+	}
+}
+
 func TestAllocationEngine_CalculateStrategyWeights_PercentageMode(t *testing.T) {
 	engine := &AllocationEngine{}
 	
@@ -323,55 +382,6 @@ func TestAllocationEngine_ApplyConstraints(t *testing.T) {
 	assert.True(t, found, "AAPL allocation should be present and capped")
 }
 
-func TestAllocationEngine_NormalizeAllocations(t *testing.T) {
-	engine := &AllocationEngine{}
-	
-	allocations := []models.StockAllocation{
-		{
-			StockID:         uuid.New(),
-			Ticker:          "AAPL",
-			AllocationValue: decimal.NewFromInt(3000),
-			StrategyContrib: map[string]decimal.Decimal{
-				"strategy1": decimal.NewFromInt(3000),
-			},
-		},
-		{
-			StockID:         uuid.New(),
-			Ticker:          "GOOGL",
-			AllocationValue: decimal.NewFromInt(2000),
-			StrategyContrib: map[string]decimal.Decimal{
-				"strategy1": decimal.NewFromInt(2000),
-			},
-		},
-	}
-	
-	totalInvestment := decimal.NewFromInt(10000)
-	
-	result := engine.normalizeAllocations(allocations, totalInvestment)
-	
-	assert.Len(t, result, 2)
-	
-	// Total should be normalized to 10000 from 5000 (2x factor)
-	totalAllocated := decimal.Zero
-	for _, allocation := range result {
-		totalAllocated = totalAllocated.Add(allocation.AllocationValue)
-		assert.True(t, allocation.Weight.GreaterThan(decimal.Zero))
-	}
-	
-	assert.True(t, totalInvestment.Equal(totalAllocated))
-	
-	// Check individual allocations
-	for _, allocation := range result {
-		if allocation.Ticker == "AAPL" {
-			assert.True(t, decimal.NewFromInt(6000).Equal(allocation.AllocationValue))
-			assert.True(t, decimal.NewFromInt(60).Equal(allocation.Weight))
-		} else if allocation.Ticker == "GOOGL" {
-			assert.True(t, decimal.NewFromInt(4000).Equal(allocation.AllocationValue))
-			assert.True(t, decimal.NewFromInt(40).Equal(allocation.Weight))
-		}
-	}
-}
-
 func TestAllocationEngine_ValidateConstraints(t *testing.T) {
 	engine := &AllocationEngine{}
 	
@@ -408,4 +418,496 @@ func TestAllocationEngine_ValidateConstraints(t *testing.T) {
 	err = engine.ValidateConstraints(allocations, constraints)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "below minimum")
-}
\ No newline at end of file
+}
+// ohlcvMarketDataService serves fixed close price series for volatility calculations
+type ohlcvMarketDataService struct {
+	*MockMarketDataService
+	closes map[string][]float64
+}
+
+func (m *ohlcvMarketDataService) GetOHLCV(ctx context.Context, symbol string, from, to time.Time, interval string) ([]*OHLCV, error) {
+	result := make([]*OHLCV, 0, len(m.closes[symbol]))
+	for i, price := range m.closes[symbol] {
+		result = append(result, &OHLCV{
+			Timestamp: from.AddDate(0, 0, i),
+			Close:     decimal.NewFromFloat(price),
+		})
+	}
+	return result, nil
+}
+
+// setupWeightingEngine builds an engine with a single strategy holding two Buy-signal stocks
+func setupWeightingEngine(strategy *models.Strategy, stocks []*models.Stock, strategyStocks []*models.StrategyStock, signals map[uuid.UUID]*models.Signal, marketData MarketDataService) *AllocationEngine {
+	mockStrategyRepo := new(MockAllocationStrategyRepository)
+	mockStockRepo := new(MockAllocationStockRepository)
+	mockSignalRepo := new(MockAllocationSignalRepository)
+
+	mockStrategyRepo.On("GetStrategyStocks", mock.Anything, strategy.ID).Return(strategyStocks, nil)
+	mockStockRepo.On("GetByIDs", mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(stocks, nil)
+	mockSignalRepo.On("GetLatestSignals", mock.Anything, mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return(signals, nil)
+
+	return NewAllocationEngine(mockStrategyRepo, mockStockRepo, mockSignalRepo, marketData, nil)
+}
+
+func TestAllocationEngine_DistributeToStocks_StockWeighting(t *testing.T) {
+	strategyID := uuid.New()
+	stockID1 := uuid.New()
+	stockID2 := uuid.New()
+	strategyAllocations := map[uuid.UUID]decimal.Decimal{strategyID: decimal.NewFromInt(1000)}
+
+	marketCap1 := decimal.NewFromInt(3000)
+	marketCap2 := decimal.NewFromInt(1000)
+	stocks := []*models.Stock{
+		{ID: stockID1, Ticker: "STABLE", Name: "Stable Corp.", MarketCap: &marketCap1},
+		{ID: stockID2, Ticker: "VOLATILE", Name: "Volatile Corp.", MarketCap: &marketCap2},
+	}
+
+	customWeight1 := decimal.NewFromInt(1)
+	customWeight2 := decimal.NewFromInt(4)
+	strategyStocks := []*models.StrategyStock{
+		{StrategyID: strategyID, StockID: stockID1, Eligible: true, CustomWeight: &customWeight1},
+		{StrategyID: strategyID, StockID: stockID2, Eligible: true, CustomWeight: &customWeight2},
+	}
+
+	strength1 := decimal.NewFromInt(90)
+	strength2 := decimal.NewFromInt(30)
+	signals := map[uuid.UUID]*models.Signal{
+		stockID1: {StockID: stockID1, Signal: models.SignalBuy, Date: time.Now(), Strength: &strength1},
+		stockID2: {StockID: stockID2, Signal: models.SignalBuy, Date: time.Now(), Strength: &strength2},
+	}
+
+	allocationsByStock := func(allocations []models.StockAllocation) map[uuid.UUID]decimal.Decimal {
+		result := make(map[uuid.UUID]decimal.Decimal)
+		for _, allocation := range allocations {
+			result[allocation.StockID] = allocation.AllocationValue
+		}
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		weighting models.StockWeighting
+		expected1 decimal.Decimal
+		expected2 decimal.Decimal
+	}{
+		{"equal", models.StockWeightingEqual, decimal.NewFromInt(500), decimal.NewFromInt(500)},
+		{"market cap", models.StockWeightingMarketCap, decimal.NewFromInt(750), decimal.NewFromInt(250)},
+		{"custom", models.StockWeightingCustom, decimal.NewFromInt(200), decimal.NewFromInt(800)},
+		{"signal strength", models.StockWeightingSignalStrength, decimal.NewFromInt(750), decimal.NewFromInt(250)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := &models.Strategy{ID: strategyID, Name: "Weighted Strategy", StockWeighting: tt.weighting}
+			engine := setupWeightingEngine(strategy, stocks, strategyStocks, signals, NewMockMarketDataService())
+
+			result, _, err := engine.distributeToStocks(context.Background(), []*models.Strategy{strategy}, strategyAllocations, nil, decimal.Zero)
+
+			assert.NoError(t, err)
+			allocations := allocationsByStock(result)
+			assert.True(t, tt.expected1.Equal(allocations[stockID1]), "expected %s, got %s", tt.expected1, allocations[stockID1])
+			assert.True(t, tt.expected2.Equal(allocations[stockID2]), "expected %s, got %s", tt.expected2, allocations[stockID2])
+		})
+	}
+
+	t.Run("inverse volatility favours the less volatile stock", func(t *testing.T) {
+		marketData := &ohlcvMarketDataService{
+			MockMarketDataService: NewMockMarketDataService(),
+			closes: map[string][]float64{
+				"STABLE":   {100, 101, 100, 101, 100},
+				"VOLATILE": {100, 110, 95, 115, 90},
+			},
+		}
+
+		strategy := &models.Strategy{ID: strategyID, Name: "Weighted Strategy", StockWeighting: models.StockWeightingInverseVolatility}
+		engine := setupWeightingEngine(strategy, stocks, strategyStocks, signals, marketData)
+
+		result, _, err := engine.distributeToStocks(context.Background(), []*models.Strategy{strategy}, strategyAllocations, nil, decimal.Zero)
+
+		assert.NoError(t, err)
+		allocations := allocationsByStock(result)
+		assert.True(t, allocations[stockID1].GreaterThan(allocations[stockID2]))
+		assert.True(t, allocations[stockID1].Add(allocations[stockID2]).Sub(decimal.NewFromInt(1000)).Abs().LessThan(decimal.NewFromFloat(0.01)))
+	})
+
+	t.Run("missing market cap returns error", func(t *testing.T) {
+		stocksWithoutCap := []*models.Stock{
+			{ID: stockID1, Ticker: "STABLE", Name: "Stable Corp.", MarketCap: &marketCap1},
+			{ID: stockID2, Ticker: "VOLATILE", Name: "Volatile Corp."},
+		}
+
+		strategy := &models.Strategy{ID: strategyID, Name: "Weighted Strategy", StockWeighting: models.StockWeightingMarketCap}
+		engine := setupWeightingEngine(strategy, stocksWithoutCap, strategyStocks, signals, NewMockMarketDataService())
+
+		_, _, err := engine.distributeToStocks(context.Background(), []*models.Strategy{strategy}, strategyAllocations, nil, decimal.Zero)
+
+		assert.Error(t, err)
+		allocationErr, ok := err.(*AllocationError)
+		assert.True(t, ok)
+		assert.Equal(t, "MISSING_WEIGHTING_DATA", allocationErr.Type)
+	})
+}
+
+func TestAllocationEngine_DistributeToStocks_SignalLevels(t *testing.T) {
+	strategyID := uuid.New()
+	strongBuyID := uuid.New()
+	buyID := uuid.New()
+	holdID := uuid.New()
+	sellID := uuid.New()
+	strategyAllocations := map[uuid.UUID]decimal.Decimal{strategyID: decimal.NewFromInt(900)}
+
+	stocks := []*models.Stock{
+		{ID: strongBuyID, Ticker: "STRONG", Name: "Strong Corp."},
+		{ID: buyID, Ticker: "BUY", Name: "Buy Corp."},
+		{ID: holdID, Ticker: "HOLD", Name: "Hold Corp."},
+		{ID: sellID, Ticker: "SELL", Name: "Sell Corp."},
+	}
+	strategyStocks := []*models.StrategyStock{
+		{StrategyID: strategyID, StockID: strongBuyID, Eligible: true},
+		{StrategyID: strategyID, StockID: buyID, Eligible: true},
+		{StrategyID: strategyID, StockID: holdID, Eligible: true},
+		{StrategyID: strategyID, StockID: sellID, Eligible: true},
+	}
+	signals := map[uuid.UUID]*models.Signal{
+		strongBuyID: {StockID: strongBuyID, Signal: models.SignalStrongBuy, Date: time.Now()},
+		buyID:       {StockID: buyID, Signal: models.SignalBuy, Date: time.Now()},
+		holdID:      {StockID: holdID, Signal: models.SignalHold, Date: time.Now()},
+		sellID:      {StockID: sellID, Signal: models.SignalSell, Date: time.Now()},
+	}
+	strategy := &models.Strategy{ID: strategyID, Name: "Signal Strategy", StockWeighting: models.StockWeightingEqual}
+
+	allocationsByStock := func(allocations []models.StockAllocation) map[uuid.UUID]decimal.Decimal {
+		result := make(map[uuid.UUID]decimal.Decimal)
+		for _, allocation := range allocations {
+			result[allocation.StockID] = allocation.AllocationValue
+		}
+		return result
+	}
+
+	t.Run("buy levels are allocated and sell levels are exits", func(t *testing.T) {
+		engine := setupWeightingEngine(strategy, stocks, strategyStocks, signals, NewMockMarketDataService())
+
+		result, exits, err := engine.distributeToStocks(context.Background(), []*models.Strategy{strategy}, strategyAllocations, nil, decimal.Zero)
+
+		assert.NoError(t, err)
+		allocations := allocationsByStock(result)
+		assert.Len(t, allocations, 2)
+		assert.True(t, decimal.NewFromInt(450).Equal(allocations[strongBuyID]))
+		assert.True(t, decimal.NewFromInt(450).Equal(allocations[buyID]))
+		assert.Equal(t, []uuid.UUID{sellID}, exits)
+	})
+
+	t.Run("strong buy multiplier overweights strong buy names", func(t *testing.T) {
+		engine := setupWeightingEngine(strategy, stocks, strategyStocks, signals, NewMockMarketDataService())
+
+		result, _, err := engine.distributeToStocks(context.Background(), []*models.Strategy{strategy}, strategyAllocations, nil, decimal.NewFromInt(2))
+
+		assert.NoError(t, err)
+		allocations := allocationsByStock(result)
+		assert.True(t, decimal.NewFromInt(600).Equal(allocations[strongBuyID]), "got %s", allocations[strongBuyID])
+		assert.True(t, decimal.NewFromInt(300).Equal(allocations[buyID]), "got %s", allocations[buyID])
+	})
+}
+
+func TestAllocationEngine_DistributeToStocks_StrategySignals(t *testing.T) {
+	momentumID := uuid.New()
+	valueID := uuid.New()
+	sharedID := uuid.New()
+	otherID := uuid.New()
+
+	momentum := &models.Strategy{ID: momentumID, Name: "Momentum", StockWeighting: models.StockWeightingEqual}
+	value := &models.Strategy{ID: valueID, Name: "Value", StockWeighting: models.StockWeightingEqual}
+	strategyAllocations := map[uuid.UUID]decimal.Decimal{
+		momentumID: decimal.NewFromInt(1000),
+		valueID:    decimal.NewFromInt(1000),
+	}
+
+	mockStrategyRepo := new(MockAllocationStrategyRepository)
+	mockStockRepo := new(MockAllocationStockRepository)
+	mockSignalRepo := new(MockAllocationSignalRepository)
+
+	mockStrategyRepo.On("GetStrategyStocks", mock.Anything, momentumID).Return([]*models.StrategyStock{
+		{StrategyID: momentumID, StockID: sharedID, Eligible: true},
+		{StrategyID: momentumID, StockID: otherID, Eligible: true},
+	}, nil)
+	mockStrategyRepo.On("GetStrategyStocks", mock.Anything, valueID).Return([]*models.StrategyStock{
+		{StrategyID: valueID, StockID: sharedID, Eligible: true},
+	}, nil)
+	mockStockRepo.On("GetByIDs", mock.Anything, mock.AnythingOfType("[]uuid.UUID")).Return([]*models.Stock{
+		{ID: sharedID, Ticker: "SHARED", Name: "Shared Corp."},
+		{ID: otherID, Ticker: "OTHER", Name: "Other Corp."},
+	}, nil)
+
+	// Momentum has its own Sell signal on the shared stock; value falls back to the global Buy signal
+	mockSignalRepo.On("GetLatestSignals", mock.Anything, &momentumID, mock.AnythingOfType("[]uuid.UUID")).Return(map[uuid.UUID]*models.Signal{
+		sharedID: {StockID: sharedID, StrategyID: &momentumID, Signal: models.SignalSell, Date: time.Now()},
+		otherID:  {StockID: otherID, Signal: models.SignalBuy, Date: time.Now()},
+	}, nil)
+	mockSignalRepo.On("GetLatestSignals", mock.Anything, &valueID, mock.AnythingOfType("[]uuid.UUID")).Return(map[uuid.UUID]*models.Signal{
+		sharedID: {StockID: sharedID, Signal: models.SignalBuy, Date: time.Now()},
+	}, nil)
+
+	engine := NewAllocationEngine(mockStrategyRepo, mockStockRepo, mockSignalRepo, NewMockMarketDataService(), nil)
+
+	result, exits, err := engine.distributeToStocks(context.Background(), []*models.Strategy{momentum, value}, strategyAllocations, nil, decimal.Zero)
+
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	for _, allocation := range result {
+		switch allocation.StockID {
+		case sharedID:
+			assert.True(t, decimal.NewFromInt(1000).Equal(allocation.AllocationValue))
+			assert.Len(t, allocation.StrategyContrib, 1)
+			assert.True(t, decimal.NewFromInt(1000).Equal(allocation.StrategyContrib[valueID.String()]))
+		case otherID:
+			assert.True(t, decimal.NewFromInt(1000).Equal(allocation.AllocationValue))
+		}
+	}
+	// The shared stock is still bought by the value strategy, so it is not an exit
+	assert.Empty(t, exits)
+	mockSignalRepo.AssertExpectations(t)
+}
+
+func TestAllocationEngine_HasExitSignals(t *testing.T) {
+	ctx := context.Background()
+	momentumID := uuid.New()
+	valueID := uuid.New()
+	heldID := uuid.New()
+	otherID := uuid.New()
+	stockIDs := []uuid.UUID{heldID, otherID}
+
+	mockSignalRepo := new(MockAllocationSignalRepository)
+	mockSignalRepo.On("GetLatestSignals", ctx, &momentumID, stockIDs).Return(map[uuid.UUID]*models.Signal{
+		heldID:  {StockID: heldID, StrategyID: &momentumID, Signal: models.SignalHold, Date: time.Now()},
+		otherID: {StockID: otherID, Signal: models.SignalBuy, Date: time.Now()},
+	}, nil)
+	mockSignalRepo.On("GetLatestSignals", ctx, &valueID, stockIDs).Return(map[uuid.UUID]*models.Signal{
+		heldID: {StockID: heldID, StrategyID: &valueID, Signal: models.SignalStrongSell, Date: time.Now()},
+	}, nil)
+
+	engine := NewAllocationEngine(nil, nil, mockSignalRepo, nil, nil)
+
+	hasExits, err := engine.HasExitSignals(ctx, []uuid.UUID{momentumID}, stockIDs)
+	assert.NoError(t, err)
+	assert.False(t, hasExits)
+
+	hasExits, err = engine.HasExitSignals(ctx, []uuid.UUID{momentumID, valueID}, stockIDs)
+	assert.NoError(t, err)
+	assert.True(t, hasExits)
+
+	// Nothing is held, so there is nothing to look up
+	hasExits, err = engine.HasExitSignals(ctx, []uuid.UUID{valueID}, nil)
+	assert.NoError(t, err)
+	assert.False(t, hasExits)
+	mockSignalRepo.AssertNumberOfCalls(t, "GetLatestSignals", 3)
+}
+
+func TestAllocationEngine_ApplyConstraints_RedistributesCappedExcess(t *testing.T) {
+	engine := &AllocationEngine{}
+	
+	allocations := []models.StockAllocation{
+		{StockID: uuid.New(), Ticker: "AAPL", AllocationValue: decimal.NewFromInt(7000), StrategyContrib: map[string]decimal.Decimal{"strategy1": decimal.NewFromInt(7000)}},
+		{StockID: uuid.New(), Ticker: "GOOGL", AllocationValue: decimal.NewFromInt(2500), StrategyContrib: map[string]decimal.Decimal{"strategy1": decimal.NewFromInt(2500)}},
+		{StockID: uuid.New(), Ticker: "MSFT", AllocationValue: decimal.NewFromInt(600), StrategyContrib: map[string]decimal.Decimal{"strategy1": decimal.NewFromInt(600)}},
+		{StockID: uuid.New(), Ticker: "AMZN", AllocationValue: decimal.NewFromInt(400), StrategyContrib: map[string]decimal.Decimal{"strategy1": decimal.NewFromInt(400)}},
+	}
+	
+	constraints := models.AllocationConstraints{
+		MaxAllocationPerStock: decimal.NewFromInt(40), // 40%
+		MinAllocationAmount:   decimal.NewFromInt(100),
+	}
+	
+	totalInvestment := decimal.NewFromInt(10000)
+	
+	result, err := engine.applyConstraints(allocations, constraints, totalInvestment)
+	
+	assert.NoError(t, err)
+	assert.Len(t, result, 4)
+	
+	// AAPL is capped first; redistributing its excess then pushes GOOGL over the cap as well,
+	// leaving 2000 to split 600:400 between MSFT and AMZN
+	expected := map[string]decimal.Decimal{
+		"AAPL":  decimal.NewFromInt(4000),
+		"GOOGL": decimal.NewFromInt(4000),
+		"MSFT":  decimal.NewFromInt(1200),
+		"AMZN":  decimal.NewFromInt(800),
+	}
+	
+	totalAllocated := decimal.Zero
+	for _, allocation := range result {
+		totalAllocated = totalAllocated.Add(allocation.AllocationValue)
+		assert.True(t, expected[allocation.Ticker].Equal(allocation.AllocationValue), "%s: expected %s, got %s", allocation.Ticker, expected[allocation.Ticker], allocation.AllocationValue)
+		assert.True(t, allocation.StrategyContrib["strategy1"].Equal(allocation.AllocationValue))
+		assert.True(t, allocation.Weight.LessThanOrEqual(constraints.MaxAllocationPerStock))
+		assert.Equal(t, allocation.Ticker == "AAPL" || allocation.Ticker == "GOOGL", allocation.Capped)
+	}
+	assert.True(t, totalInvestment.Equal(totalAllocated))
+}
+
+func TestAllocationEngine_ApplyConstraints_InfeasibleCap(t *testing.T) {
+	engine := &AllocationEngine{}
+	
+	allocations := []models.StockAllocation{
+		{StockID: uuid.New(), Ticker: "AAPL", AllocationValue: decimal.NewFromInt(4000)},
+		{StockID: uuid.New(), Ticker: "GOOGL", AllocationValue: decimal.NewFromInt(3000)},
+		{StockID: uuid.New(), Ticker: "MSFT", AllocationValue: decimal.NewFromInt(3000)},
+	}
+	
+	constraints := models.AllocationConstraints{
+		MaxAllocationPerStock: decimal.NewFromInt(20), // 3 x 20% cannot hold 100%
+		MinAllocationAmount:   decimal.NewFromInt(100),
+	}
+	
+	_, err := engine.applyConstraints(allocations, constraints, decimal.NewFromInt(10000))
+	
+	assert.Error(t, err)
+	allocErr := GetAllocationError(err)
+	assert.NotNil(t, allocErr)
+	assert.Equal(t, "INFEASIBLE_MAX_ALLOCATION", allocErr.Type)
+	
+	// The structured error survives wrapping by callers
+	assert.Equal(t, allocErr, GetAllocationError(fmt.Errorf("failed to calculate allocations: %w", err)))
+}
+
+func TestAllocationEngine_AddPricesAndQuantities_FractionalShares(t *testing.T) {
+	marketData := NewMockMarketDataService()
+	marketData.AddQuote("AAPL", 300)
+	engine := &AllocationEngine{marketDataService: marketData}
+	
+	allocations := []models.StockAllocation{
+		{StockID: uuid.New(), Ticker: "AAPL", AllocationValue: decimal.NewFromInt(1000)},
+	}
+	
+	tests := []struct {
+		name             string
+		sharePrecision   int32
+		expectedQuantity decimal.Decimal
+	}{
+		{"whole shares", 0, decimal.NewFromInt(3)},
+		{"two decimal places", 2, decimal.NewFromFloat(3.33)},
+		{"four decimal places", 4, decimal.NewFromFloat(3.3333)},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.addPricesAndQuantities(context.Background(), allocations, tt.sharePrecision, "")
+			
+			assert.NoError(t, err)
+			assert.Len(t, result, 1)
+			assert.True(t, tt.expectedQuantity.Equal(result[0].Quantity), "expected %s, got %s", tt.expectedQuantity, result[0].Quantity)
+			assert.True(t, decimal.NewFromInt(300).Mul(tt.expectedQuantity).Equal(result[0].ActualValue))
+			assert.True(t, result[0].ActualValue.LessThanOrEqual(allocations[0].AllocationValue))
+		})
+	}
+}
+
+func TestAllocationEngine_AddPricesAndQuantities_ConvertsToBaseCurrency(t *testing.T) {
+	marketData := NewMockMarketDataService()
+	marketData.AddQuote("AAPL", 200)
+	marketData.AddQuote("VOD", 80)
+	fxService := new(MockFXService)
+	fxService.On("GetRates", mock.Anything, []string{"GBP"}, "USD", mock.AnythingOfType("time.Time")).
+		Return(map[string]decimal.Decimal{"GBP": decimal.NewFromFloat(1.25)}, nil)
+	engine := &AllocationEngine{marketDataService: marketData, fxService: fxService}
+	
+	allocations := []models.StockAllocation{
+		{StockID: uuid.New(), Ticker: "AAPL", Currency: "USD", AllocationValue: decimal.NewFromInt(1000)},
+		{StockID: uuid.New(), Ticker: "VOD", Currency: "GBP", AllocationValue: decimal.NewFromInt(1000)},
+	}
+	
+	result, err := engine.addPricesAndQuantities(context.Background(), allocations, 0, "USD")
+	
+	assert.NoError(t, err)
+	assert.Len(t, result, 2)
+	assert.True(t, decimal.NewFromInt(200).Equal(result[0].Price))
+	assert.True(t, decimal.NewFromInt(1).Equal(result[0].FXRate))
+	
+	// 80 GBP at 1.25 costs 100 USD a share
+	assert.Equal(t, "GBP", result[1].Currency)
+	assert.True(t, decimal.NewFromInt(80).Equal(result[1].LocalPrice))
+	assert.True(t, decimal.NewFromFloat(1.25).Equal(result[1].FXRate))
+	assert.True(t, decimal.NewFromInt(100).Equal(result[1].Price), "expected 100, got %s", result[1].Price)
+	assert.True(t, decimal.NewFromInt(10).Equal(result[1].Quantity))
+	assert.True(t, decimal.NewFromInt(1000).Equal(result[1].ActualValue))
+	fxService.AssertExpectations(t)
+}
+
+func TestAllocationEngine_AddPricesAndQuantities_RequiresFXRates(t *testing.T) {
+	marketData := NewMockMarketDataService()
+	marketData.AddQuote("AAPL", 200)
+	marketData.AddQuote("VOD", 80)
+	allocations := []models.StockAllocation{
+		{StockID: uuid.New(), Ticker: "AAPL", Currency: "USD", AllocationValue: decimal.NewFromInt(1000)},
+		{StockID: uuid.New(), Ticker: "VOD", Currency: "GBP", AllocationValue: decimal.NewFromInt(1000)},
+	}
+
+	t.Run("without an FX service", func(t *testing.T) {
+		engine := &AllocationEngine{marketDataService: marketData}
+
+		_, err := engine.addPricesAndQuantities(context.Background(), allocations, 0, "USD")
+
+		assert.ErrorContains(t, err, "no FX service to convert [GBP] to USD")
+	})
+
+	t.Run("without a rate for the currency", func(t *testing.T) {
+		fxService := new(MockFXService)
+		fxService.On("GetRates", mock.Anything, []string{"GBP"}, "USD", mock.AnythingOfType("time.Time")).
+			Return(map[string]decimal.Decimal{}, nil)
+		engine := &AllocationEngine{marketDataService: marketData, fxService: fxService}
+
+		_, err := engine.addPricesAndQuantities(context.Background(), allocations, 0, "USD")
+
+		assert.ErrorContains(t, err, "no FX rate available to convert GBP to USD for VOD")
+	})
+
+	t.Run("base currency stocks need no FX service", func(t *testing.T) {
+		engine := &AllocationEngine{marketDataService: marketData}
+
+		result, err := engine.addPricesAndQuantities(context.Background(), allocations[1:], 0, "GBP")
+
+		assert.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(80).Equal(result[0].Price))
+	})
+}
+
+func TestAllocationEngine_SweepResidualCash(t *testing.T) {
+	engine := &AllocationEngine{}
+	
+	// Whole-share rounding of a 50/30/20 split of 10000 leaves 540 uninvested
+	allocations := []models.StockAllocation{
+		{Ticker: "AAPL", Weight: decimal.NewFromInt(50), Price: decimal.NewFromInt(300), Quantity: decimal.NewFromInt(16), ActualValue: decimal.NewFromInt(4800)},
+		{Ticker: "GOOGL", Weight: decimal.NewFromInt(30), Price: decimal.NewFromInt(140), Quantity: decimal.NewFromInt(21), ActualValue: decimal.NewFromInt(2940)},
+		{Ticker: "MSFT", Weight: decimal.NewFromInt(20), Price: decimal.NewFromInt(430), Quantity: decimal.NewFromInt(4), ActualValue: decimal.NewFromInt(1720)},
+	}
+	
+	constraints := models.AllocationConstraints{
+		MaxAllocationPerStock: decimal.NewFromInt(50),
+		MinAllocationAmount:   decimal.NewFromInt(100),
+	}
+	
+	totalInvestment := decimal.NewFromInt(10000)
+	
+	result := engine.sweepResidualCash(allocations, constraints, totalInvestment, 0)
+	
+	assert.True(t, decimal.NewFromInt(540).Equal(result.UnallocatedCashBefore))
+	assert.True(t, result.UnallocatedCashAfter.LessThan(result.UnallocatedCashBefore))
+	assert.True(t, result.TrackingErrorAfter.LessThan(result.TrackingErrorBefore))
+	
+	// MSFT is most underweight and affordable; AAPL cannot grow past the 50% cap
+	quantities := make(map[string]decimal.Decimal)
+	totalActual := decimal.Zero
+	for _, allocation := range allocations {
+		quantities[allocation.Ticker] = allocation.Quantity
+		totalActual = totalActual.Add(allocation.ActualValue)
+		assert.True(t, allocation.ActualValue.LessThanOrEqual(decimal.NewFromInt(5000)))
+	}
+	assert.True(t, decimal.NewFromInt(16).Equal(quantities["AAPL"]))
+	assert.True(t, decimal.NewFromInt(5).Equal(quantities["MSFT"]))
+	assert.True(t, totalInvestment.Sub(totalActual).Equal(result.UnallocatedCashAfter))
+	
+	// Every remaining stock is unaffordable or capped
+	for _, allocation := range allocations {
+		capped := allocation.ActualValue.Add(allocation.Price).GreaterThan(decimal.NewFromInt(5000))
+		assert.True(t, capped || allocation.Price.GreaterThan(result.UnallocatedCashAfter))
+	}
+}
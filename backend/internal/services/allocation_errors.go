@@ -1,9 +1,12 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/shopspring/decimal"
+
+	"portfolio-app/internal/models"
 )
 
 // AllocationError represents an error during allocation calculation
@@ -54,10 +57,35 @@ var (
 	ErrNoEligibleStocks = func(strategyName string) *AllocationError {
 		return NewAllocationError(
 			"NO_ELIGIBLE_STOCKS",
-			fmt.Sprintf("Strategy '%s' has no eligible stocks with 'Buy' signals", strategyName),
+			fmt.Sprintf("Strategy '%s' has no eligible stocks with 'Buy' or 'StrongBuy' signals", strategyName),
 			map[string]interface{}{
 				"strategy_name": strategyName,
-				"suggestion":    "Ensure stocks in this strategy have 'Buy' signals and are marked as eligible",
+				"suggestion":    "Ensure stocks in this strategy have 'Buy' or 'StrongBuy' signals and are marked as eligible",
+			},
+		)
+	}
+
+	ErrMissingWeightingData = func(strategyName string, weighting models.StockWeighting, ticker string) *AllocationError {
+		return NewAllocationError(
+			"MISSING_WEIGHTING_DATA",
+			fmt.Sprintf("Strategy '%s' uses %s weighting but %s has no data for it", strategyName, weighting, ticker),
+			map[string]interface{}{
+				"strategy_name":   strategyName,
+				"stock_weighting": weighting,
+				"ticker":          ticker,
+			},
+		)
+	}
+
+	ErrInfeasibleMaxAllocation = func(maxAllocationPerStock decimal.Decimal, stockCount int) *AllocationError {
+		return NewAllocationError(
+			"INFEASIBLE_MAX_ALLOCATION",
+			fmt.Sprintf("Maximum allocation of %s%% per stock cannot be met with %d stocks", maxAllocationPerStock.String(), stockCount),
+			map[string]interface{}{
+				"max_allocation_per_stock": maxAllocationPerStock,
+				"stock_count":              stockCount,
+				"min_feasible_percentage":  decimal.NewFromInt(100).Div(decimal.NewFromInt(int64(stockCount))).Round(2),
+				"suggestion":               "Raise the per-stock maximum or include more eligible stocks",
 			},
 		)
 	}
@@ -92,13 +120,14 @@ var (
 
 // IsAllocationError checks if an error is an AllocationError
 func IsAllocationError(err error) bool {
-	_, ok := err.(*AllocationError)
-	return ok
+	var allocErr *AllocationError
+	return errors.As(err, &allocErr)
 }
 
 // GetAllocationError extracts AllocationError from error
 func GetAllocationError(err error) *AllocationError {
-	if allocErr, ok := err.(*AllocationError); ok {
+	var allocErr *AllocationError
+	if errors.As(err, &allocErr) {
 		return allocErr
 	}
 	return nil
@@ -65,6 +65,16 @@ func (m *MockUserRepository) List(ctx context.Context, limit, offset int) ([]*mo
 	return args.Get(0).([]*models.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetWebhookSecret(ctx context.Context, id uuid.UUID) (string, error) {
+	args := m.Called(ctx, id)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserRepository) SetWebhookSecret(ctx context.Context, id uuid.UUID, secret string) error {
+	args := m.Called(ctx, id, secret)
+	return args.Error(0)
+}
+
 func setupAuthServiceTest() (*AuthService, *MockUserRepository, *redis.Client, *miniredis.Miniredis) {
 	mockRepo := &MockUserRepository{}
 	
@@ -0,0 +1,557 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/repositories"
+)
+
+// backtestTimeout bounds how long a single backtest job may run
+const backtestTimeout = 10 * time.Minute
+
+// BacktestService defines the interface for replaying strategies over historical data
+type BacktestService interface {
+	StartBacktest(ctx context.Context, req *models.BacktestRequest, userID uuid.UUID) (*models.Backtest, error)
+	GetBacktest(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Backtest, error)
+	GetUserBacktests(ctx context.Context, userID uuid.UUID) ([]*models.Backtest, error)
+	RunBacktest(ctx context.Context, req *models.BacktestRequest) (*models.BacktestResult, error)
+}
+
+// backtestService implements the BacktestService interface
+type backtestService struct {
+	backtestRepo      repositories.BacktestRepository
+	strategyRepo      repositories.StrategyRepository
+	stockRepo         repositories.StockRepository
+	signalRepo        repositories.SignalRepository
+	marketDataService MarketDataService
+}
+
+// NewBacktestService creates a new backtest service instance
+func NewBacktestService(
+	backtestRepo repositories.BacktestRepository,
+	strategyRepo repositories.StrategyRepository,
+	stockRepo repositories.StockRepository,
+	signalRepo repositories.SignalRepository,
+	marketDataService MarketDataService,
+) BacktestService {
+	return &backtestService{
+		backtestRepo:      backtestRepo,
+		strategyRepo:      strategyRepo,
+		stockRepo:         stockRepo,
+		signalRepo:        signalRepo,
+		marketDataService: marketDataService,
+	}
+}
+
+// StartBacktest records a backtest job for the user's strategies and runs it in the background
+func (s *backtestService) StartBacktest(ctx context.Context, req *models.BacktestRequest, userID uuid.UUID) (*models.Backtest, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Verify strategies belong to user
+	for _, strategyID := range req.StrategyIDs {
+		if _, err := s.strategyRepo.GetByID(ctx, strategyID, userID); err != nil {
+			return nil, fmt.Errorf("strategy not found or access denied: %w", err)
+		}
+	}
+
+	backtest := models.NewBacktest(userID, req)
+	if err := s.backtestRepo.Create(ctx, backtest); err != nil {
+		return nil, fmt.Errorf("failed to create backtest: %w", err)
+	}
+
+	go s.runJob(backtest)
+
+	return backtest, nil
+}
+
+// GetBacktest retrieves a backtest job of a user with its result once completed
+func (s *backtestService) GetBacktest(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*models.Backtest, error) {
+	backtest, err := s.backtestRepo.GetByID(ctx, id, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backtest: %w", err)
+	}
+
+	return backtest, nil
+}
+
+// GetUserBacktests retrieves all backtest jobs of a user
+func (s *backtestService) GetUserBacktests(ctx context.Context, userID uuid.UUID) ([]*models.Backtest, error) {
+	backtests, err := s.backtestRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backtests: %w", err)
+	}
+
+	return backtests, nil
+}
+
+// runJob runs a backtest job to completion, saving its status before and after the run
+func (s *backtestService) runJob(backtest *models.Backtest) {
+	ctx, cancel := context.WithTimeout(context.Background(), backtestTimeout)
+	defer cancel()
+
+	backtest.Start()
+	if err := s.backtestRepo.Update(ctx, backtest); err != nil {
+		log.Printf("Backtest %s failed to save running status: %v", backtest.ID, err)
+	}
+
+	result, err := s.RunBacktest(ctx, backtest.Request)
+	backtest.Complete(result, err)
+	if err != nil {
+		log.Printf("Backtest %s failed: %v", backtest.ID, err)
+	}
+
+	if err := s.backtestRepo.Update(ctx, backtest); err != nil {
+		log.Printf("Backtest %s failed to save its result: %v", backtest.ID, err)
+	}
+}
+
+// RunBacktest replays the strategies of a request over its date range. On the first trading day of
+// every rebalance period the allocation engine reallocates the simulated NAV using the signals and
+// closing prices known on that day; in between, holdings are marked to market at each close.
+func (s *backtestService) RunBacktest(ctx context.Context, req *models.BacktestRequest) (*models.BacktestResult, error) {
+	start, end, err := req.DateRange()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := s.loadBacktestData(ctx, req.StrategyIDs, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	tradingDays := data.tradingDays(start, end)
+	if len(tradingDays) == 0 {
+		return nil, fmt.Errorf("no price history between %s and %s", req.StartDate, req.EndDate)
+	}
+
+	result := &models.BacktestResult{
+		Currency:   data.currency,
+		NAVSeries:  make([]models.BacktestNAVPoint, 0, len(tradingDays)),
+		Rebalances: []models.BacktestRebalance{},
+	}
+
+	cash := req.InitialInvestment
+	holdings := make(map[uuid.UUID]decimal.Decimal)
+	highWaterMark := req.InitialInvestment
+	period := -1
+
+	for _, day := range tradingDays {
+		if dayPeriod := rebalancePeriodIndex(day, req.RebalanceFrequency); dayPeriod != period {
+			period = dayPeriod
+			rebalance := s.rebalance(ctx, data, req, day, holdings, cash)
+			result.Rebalances = append(result.Rebalances, rebalance)
+
+			cash = rebalance.Cash
+			holdings = make(map[uuid.UUID]decimal.Decimal, len(rebalance.Holdings))
+			for _, holding := range rebalance.Holdings {
+				holdings[holding.StockID] = holding.Quantity
+			}
+		}
+
+		nav := data.markToMarket(holdings, cash, day)
+		if nav.GreaterThan(highWaterMark) {
+			highWaterMark = nav
+		}
+		drawdown := decimal.Zero
+		if highWaterMark.IsPositive() && nav.LessThan(highWaterMark) {
+			drawdown = nav.Sub(highWaterMark).Div(highWaterMark).Mul(decimal.NewFromInt(100))
+		}
+
+		result.NAVSeries = append(result.NAVSeries, models.BacktestNAVPoint{
+			Date:     day,
+			NAV:      nav,
+			Cash:     cash,
+			Drawdown: &drawdown,
+		})
+	}
+
+	history := make([]models.NAVHistory, len(result.NAVSeries))
+	for i, point := range result.NAVSeries {
+		history[i] = models.NAVHistory{
+			Timestamp: point.Date,
+			NAV:       point.NAV,
+			Drawdown:  point.Drawdown,
+			Cash:      point.Cash,
+		}
+	}
+	result.Metrics = models.CalculatePerformanceMetrics(history, nil, req.InitialInvestment, req.RiskFreeRatePct)
+
+	return result, nil
+}
+
+// rebalance reallocates the simulated portfolio on a rebalance date. When the allocation engine
+// fails, the current holdings are kept and the error is recorded as a warning.
+func (s *backtestService) rebalance(ctx context.Context, data *backtestData, req *models.BacktestRequest, day time.Time, holdings map[uuid.UUID]decimal.Decimal, cash decimal.Decimal) models.BacktestRebalance {
+	nav := data.markToMarket(holdings, cash, day)
+	rebalance := models.BacktestRebalance{
+		Date: day,
+		NAV:  nav,
+		Cash: cash,
+	}
+
+	keep := func(warning string) models.BacktestRebalance {
+		rebalance.Warnings = append(rebalance.Warnings, warning)
+		rebalance.Holdings = data.holdingsAt(holdings, day)
+		return rebalance
+	}
+
+	if !nav.IsPositive() {
+		return keep("portfolio has no value left to allocate")
+	}
+
+	// Stocks without a price yet, such as before their listing, cannot be bought
+	var excluded []uuid.UUID
+	for stockID, stock := range data.stocks {
+		if _, ok := data.closeOn(stock.Ticker, day); !ok {
+			excluded = append(excluded, stockID)
+		}
+	}
+
+	// Every stock is quoted in the backtest currency, so no FX service is needed
+	engine := NewAllocationEngine(
+		&backtestStrategySource{data: data},
+		&backtestStockSource{data: data},
+		&backtestSignalSource{data: data, asOf: day},
+		&backtestPriceSource{data: data, asOf: day},
+		nil,
+	)
+	engine.asOf = day
+
+	preview, err := engine.CalculateAllocations(ctx, &models.AllocationRequest{
+		StrategyIDs:         req.StrategyIDs,
+		TotalInvestment:     nav,
+		Constraints:         req.Constraints,
+		ExcludedStocks:      excluded,
+		FractionalShares:    req.FractionalShares,
+		SharePrecision:      req.SharePrecision,
+		StrongBuyMultiplier: req.StrongBuyMultiplier,
+		BaseCurrency:        data.currency,
+	})
+	if err != nil {
+		return keep(fmt.Sprintf("allocation failed, holdings kept: %v", err))
+	}
+
+	rebalance.Cash = preview.UnallocatedCash
+	rebalance.Holdings = make([]models.BacktestHolding, 0, len(preview.Allocations))
+	for _, allocation := range preview.Allocations {
+		if !allocation.Quantity.IsPositive() {
+			continue
+		}
+		rebalance.Holdings = append(rebalance.Holdings, models.BacktestHolding{
+			StockID:  allocation.StockID,
+			Ticker:   allocation.Ticker,
+			Quantity: allocation.Quantity,
+			Price:    allocation.Price,
+			Value:    allocation.ActualValue,
+		})
+	}
+	sortHoldings(rebalance.Holdings)
+
+	for _, violation := range preview.Violations {
+		rebalance.Warnings = append(rebalance.Warnings, violation.Message)
+	}
+
+	return rebalance
+}
+
+// loadBacktestData fetches everything a backtest replays: the strategies and their stocks, the
+// full signal history of each stock and its daily bars, including enough history before the start
+// date for inverse-volatility weighting
+func (s *backtestService) loadBacktestData(ctx context.Context, strategyIDs []uuid.UUID, start, end time.Time) (*backtestData, error) {
+	strategies, err := s.strategyRepo.GetByIDs(ctx, strategyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategies: %w", err)
+	}
+	if len(strategies) == 0 {
+		return nil, fmt.Errorf("no strategies found")
+	}
+
+	data := &backtestData{
+		strategies:     strategies,
+		strategyStocks: make(map[uuid.UUID][]*models.StrategyStock),
+		stocks:         make(map[uuid.UUID]*models.Stock),
+		bars:           make(map[string][]*OHLCV),
+		signals:        make(map[uuid.UUID][]*models.Signal),
+	}
+
+	var stockIDs []uuid.UUID
+	for _, strategy := range strategies {
+		strategyStocks, err := s.strategyRepo.GetStrategyStocks(ctx, strategy.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get stocks for strategy %s: %w", strategy.ID, err)
+		}
+		data.strategyStocks[strategy.ID] = strategyStocks
+
+		for _, strategyStock := range strategyStocks {
+			if _, seen := data.stocks[strategyStock.StockID]; !seen {
+				data.stocks[strategyStock.StockID] = nil
+				stockIDs = append(stockIDs, strategyStock.StockID)
+			}
+		}
+	}
+
+	stocks, err := s.stockRepo.GetByIDs(ctx, stockIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock details: %w", err)
+	}
+	currencies := make(map[string]bool)
+	for _, stock := range stocks {
+		data.stocks[stock.ID] = stock
+		currencies[models.CurrencyOrDefault(stock.Currency)] = true
+	}
+
+	// Closes are replayed without historical FX rates, so the stocks must share one currency
+	// that the simulated NAV is then kept in
+	if len(currencies) > 1 {
+		names := make([]string, 0, len(currencies))
+		for currency := range currencies {
+			names = append(names, currency)
+		}
+		sort.Strings(names)
+		return nil, &models.ValidationError{
+			Field:   "strategy_ids",
+			Message: fmt.Sprintf("backtests cannot mix stocks quoted in different currencies, the strategies hold stocks in %s", strings.Join(names, ", ")),
+		}
+	}
+	data.currency = models.CurrencyOrDefault("")
+	for currency := range currencies {
+		data.currency = currency
+	}
+
+	historyStart := start.AddDate(0, 0, -volatilityLookbackDays)
+	for stockID, stock := range data.stocks {
+		if stock == nil {
+			delete(data.stocks, stockID)
+			continue
+		}
+
+		bars, err := s.marketDataService.GetOHLCV(ctx, stock.Ticker, historyStart, end, "1day")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price history for %s: %w", stock.Ticker, err)
+		}
+		sort.SliceStable(bars, func(i, j int) bool { return bars[i].Timestamp.Before(bars[j].Timestamp) })
+		data.bars[stock.Ticker] = bars
+
+		signals, err := s.signalRepo.GetSignalHistory(ctx, stockID, time.Time{}, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get signal history for %s: %w", stock.Ticker, err)
+		}
+		data.signals[stockID] = signals
+	}
+
+	return data, nil
+}
+
+// rebalancePeriodIndex numbers rebalance periods so that consecutive periods have consecutive indexes
+func rebalancePeriodIndex(day time.Time, frequency models.RebalanceFrequency) int {
+	months := 1
+	if frequency == models.RebalanceQuarterly {
+		months = 3
+	}
+	return (day.Year()*12 + int(day.Month()) - 1) / months
+}
+
+// sortHoldings orders holdings by ticker so results are deterministic
+func sortHoldings(holdings []models.BacktestHolding) {
+	sort.Slice(holdings, func(i, j int) bool { return holdings[i].Ticker < holdings[j].Ticker })
+}
+
+// backtestData holds the strategies, signals and prices a backtest replays
+type backtestData struct {
+	strategies     []*models.Strategy
+	strategyStocks map[uuid.UUID][]*models.StrategyStock
+	stocks         map[uuid.UUID]*models.Stock
+	bars           map[string][]*OHLCV            // by ticker, oldest first
+	signals        map[uuid.UUID][]*models.Signal // by stock, newest first
+	currency       string                         // the currency every stock is quoted in
+}
+
+// tradingDays returns the dates within the range that have a bar for any stock, in order
+func (d *backtestData) tradingDays(start, end time.Time) []time.Time {
+	seen := make(map[time.Time]bool)
+	var days []time.Time
+	for _, bars := range d.bars {
+		for _, bar := range bars {
+			day := truncateToDate(bar.Timestamp)
+			if day.Before(start) || day.After(end) || seen[day] {
+				continue
+			}
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	return days
+}
+
+// closeOn returns the latest close of a ticker up to and including the given day
+func (d *backtestData) closeOn(ticker string, day time.Time) (decimal.Decimal, bool) {
+	bars := d.barsUntil(ticker, day)
+	if len(bars) == 0 {
+		return decimal.Zero, false
+	}
+	return bars[len(bars)-1].Close, true
+}
+
+// barsUntil returns the bars of a ticker up to and including the given day
+func (d *backtestData) barsUntil(ticker string, day time.Time) []*OHLCV {
+	bars := d.bars[ticker]
+	cutoff := day.AddDate(0, 0, 1)
+	n := sort.Search(len(bars), func(i int) bool { return !bars[i].Timestamp.Before(cutoff) })
+	return bars[:n]
+}
+
+// markToMarket values holdings at the given day's closes plus cash
+func (d *backtestData) markToMarket(holdings map[uuid.UUID]decimal.Decimal, cash decimal.Decimal, day time.Time) decimal.Decimal {
+	nav := cash
+	for _, holding := range d.holdingsAt(holdings, day) {
+		nav = nav.Add(holding.Value)
+	}
+	return nav
+}
+
+// holdingsAt values holdings at the given day's closes
+func (d *backtestData) holdingsAt(holdings map[uuid.UUID]decimal.Decimal, day time.Time) []models.BacktestHolding {
+	result := make([]models.BacktestHolding, 0, len(holdings))
+	for stockID, quantity := range holdings {
+		stock := d.stocks[stockID]
+		price, _ := d.closeOn(stock.Ticker, day)
+		result = append(result, models.BacktestHolding{
+			StockID:  stockID,
+			Ticker:   stock.Ticker,
+			Quantity: quantity,
+			Price:    price,
+			Value:    price.Mul(quantity),
+		})
+	}
+	sortHoldings(result)
+	return result
+}
+
+// truncateToDate drops the time of day, keeping the calendar date in UTC
+func truncateToDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// backtestStrategySource serves the allocation engine the strategies of a backtest
+type backtestStrategySource struct {
+	data *backtestData
+}
+
+func (s *backtestStrategySource) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Strategy, error) {
+	return s.data.strategies, nil
+}
+
+func (s *backtestStrategySource) GetStrategyStocks(ctx context.Context, strategyID uuid.UUID) ([]*models.StrategyStock, error) {
+	return s.data.strategyStocks[strategyID], nil
+}
+
+// backtestStockSource serves the allocation engine the stocks of a backtest
+type backtestStockSource struct {
+	data *backtestData
+}
+
+func (s *backtestStockSource) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Stock, error) {
+	stocks := make([]*models.Stock, 0, len(ids))
+	for _, id := range ids {
+		if stock := s.data.stocks[id]; stock != nil {
+			stocks = append(stocks, stock)
+		}
+	}
+	return stocks, nil
+}
+
+// backtestSignalSource serves the allocation engine the signals known on a past date
+type backtestSignalSource struct {
+	data *backtestData
+	asOf time.Time
+}
+
+// GetLatestSignals mirrors SignalRepository.GetLatestSignals as of the source's date: a
+// strategy's own signal takes precedence over the stock's global signal
+func (s *backtestSignalSource) GetLatestSignals(ctx context.Context, strategyID *uuid.UUID, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
+	result := make(map[uuid.UUID]*models.Signal)
+	for _, stockID := range stockIDs {
+		var global *models.Signal
+		for _, signal := range s.data.signals[stockID] {
+			if signal.Date.After(s.asOf) {
+				continue
+			}
+			if signal.StrategyID == nil {
+				if global == nil {
+					global = signal
+				}
+				continue
+			}
+			if strategyID != nil && *signal.StrategyID == *strategyID {
+				result[stockID] = signal
+				break
+			}
+		}
+		if _, scoped := result[stockID]; !scoped && global != nil {
+			result[stockID] = global
+		}
+	}
+	return result, nil
+}
+
+// backtestPriceSource serves the allocation engine the closing prices known on a past date
+type backtestPriceSource struct {
+	data *backtestData
+	asOf time.Time
+}
+
+func (s *backtestPriceSource) GetQuote(ctx context.Context, symbol string) (*Quote, error) {
+	price, ok := s.data.closeOn(symbol, s.asOf)
+	if !ok {
+		return nil, fmt.Errorf("no price for %s on %s", symbol, s.asOf.Format("2006-01-02"))
+	}
+	return &Quote{Symbol: symbol, Price: price, Timestamp: s.asOf}, nil
+}
+
+func (s *backtestPriceSource) GetMultipleQuotes(ctx context.Context, symbols []string) (map[string]*Quote, error) {
+	quotes := make(map[string]*Quote, len(symbols))
+	for _, symbol := range symbols {
+		if quote, err := s.GetQuote(ctx, symbol); err == nil {
+			quotes[symbol] = quote
+		}
+	}
+	return quotes, nil
+}
+
+func (s *backtestPriceSource) GetQuotesByStockIDs(ctx context.Context, stockIDs []uuid.UUID) (map[uuid.UUID]*Quote, error) {
+	quotes := make(map[uuid.UUID]*Quote, len(stockIDs))
+	for _, stockID := range stockIDs {
+		stock := s.data.stocks[stockID]
+		if stock == nil {
+			continue
+		}
+		if quote, err := s.GetQuote(ctx, stock.Ticker); err == nil {
+			quotes[stockID] = quote
+		}
+	}
+	return quotes, nil
+}
+
+func (s *backtestPriceSource) GetOHLCV(ctx context.Context, symbol string, from, to time.Time, interval string) ([]*OHLCV, error) {
+	if to.After(s.asOf) {
+		to = s.asOf
+	}
+	var result []*OHLCV
+	for _, bar := range s.data.barsUntil(symbol, to) {
+		if !bar.Timestamp.Before(from) {
+			result = append(result, bar)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"portfolio-app/internal/models"
+)
+
+// weekdayBars builds a bar per weekday from start with the given closes
+func weekdayBars(start time.Time, closes []float64) []*OHLCV {
+	bars := make([]*OHLCV, 0, len(closes))
+	day := start
+	for _, close := range closes {
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, 1)
+		}
+		price := decimal.NewFromFloat(close)
+		bars = append(bars, &OHLCV{
+			Timestamp: day,
+			Open:      price,
+			High:      price,
+			Low:       price,
+			Close:     price,
+			Volume:    100000,
+		})
+		day = day.AddDate(0, 0, 1)
+	}
+	return bars
+}
+
+func TestBacktestService_RunBacktest(t *testing.T) {
+	ctx := context.Background()
+	strategyID := uuid.New()
+	aaa := &models.Stock{ID: uuid.New(), Ticker: "AAA", Name: "AAA Corp"}
+	bbb := &models.Stock{ID: uuid.New(), Ticker: "BBB", Name: "BBB Corp"}
+
+	newService := func(stocks ...*models.Stock) BacktestService {
+		strategyRepo := new(MockStrategyRepository)
+		stockRepo := new(MockStockRepository)
+		signalRepo := new(MockSignalRepository)
+
+		strategyRepo.On("GetByIDs", mock.Anything, []uuid.UUID{strategyID}).Return([]*models.Strategy{{
+			ID:             strategyID,
+			Name:           "Growth",
+			WeightMode:     models.WeightModePercent,
+			WeightValue:    decimal.NewFromInt(100),
+			StockWeighting: models.StockWeightingEqual,
+		}}, nil)
+		strategyRepo.On("GetStrategyStocks", mock.Anything, strategyID).Return([]*models.StrategyStock{
+			{StrategyID: strategyID, StockID: aaa.ID, Eligible: true},
+			{StrategyID: strategyID, StockID: bbb.ID, Eligible: true},
+		}, nil)
+		stockRepo.On("GetByIDs", mock.Anything, mock.Anything).Return(stocks, nil)
+
+		// AAA has a global Buy throughout; BBB only gets a strategy Buy in February
+		signalRepo.On("GetSignalHistory", mock.Anything, aaa.ID, mock.Anything, mock.Anything).Return([]*models.Signal{
+			{StockID: aaa.ID, Signal: models.SignalBuy, Date: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil)
+		signalRepo.On("GetSignalHistory", mock.Anything, bbb.ID, mock.Anything, mock.Anything).Return([]*models.Signal{
+			{StockID: bbb.ID, StrategyID: &strategyID, Signal: models.SignalBuy, Date: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+			{StockID: bbb.ID, Signal: models.SignalSell, Date: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)},
+		}, nil)
+
+		marketData := NewMockMarketDataService()
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		marketData.SetOHLCV("AAA", weekdayBars(start, trend(100, 0.5, 60)))
+		marketData.SetOHLCV("BBB", weekdayBars(start, trend(50, -0.1, 60)))
+
+		return NewBacktestService(nil, strategyRepo, stockRepo, signalRepo, marketData)
+	}
+
+	req := &models.BacktestRequest{
+		StrategyIDs:        []uuid.UUID{strategyID},
+		StartDate:          "2024-01-01",
+		EndDate:            "2024-03-22",
+		InitialInvestment:  decimal.NewFromInt(10000),
+		RebalanceFrequency: models.RebalanceMonthly,
+		Constraints: models.AllocationConstraints{
+			MaxAllocationPerStock: decimal.NewFromInt(100),
+		},
+	}
+
+	result, err := newService(aaa, bbb).RunBacktest(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "USD", result.Currency)
+
+	require.Len(t, result.NAVSeries, 60)
+	assert.True(t, result.NAVSeries[0].NAV.Equal(req.InitialInvestment), "NAV starts at the initial investment")
+
+	require.Len(t, result.Rebalances, 3)
+	january := result.Rebalances[0]
+	require.Len(t, january.Holdings, 1)
+	assert.Equal(t, "AAA", january.Holdings[0].Ticker)
+
+	february := result.Rebalances[1]
+	assert.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), february.Date)
+	require.Len(t, february.Holdings, 2)
+	assert.Equal(t, "BBB", february.Holdings[1].Ticker)
+
+	// AAA rises while held alone, so the portfolio ends above its initial value
+	last := result.NAVSeries[len(result.NAVSeries)-1]
+	assert.True(t, last.NAV.GreaterThan(req.InitialInvestment))
+	require.NotNil(t, result.Metrics)
+	assert.True(t, result.Metrics.TotalReturn.Equal(last.NAV.Sub(req.InitialInvestment)))
+
+	t.Run("deterministic", func(t *testing.T) {
+		again, err := newService(aaa, bbb).RunBacktest(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, result, again)
+	})
+
+	t.Run("no price history", func(t *testing.T) {
+		empty := *req
+		empty.StartDate = "2025-01-01"
+		empty.EndDate = "2025-02-01"
+
+		_, err := newService(aaa, bbb).RunBacktest(ctx, &empty)
+		assert.Error(t, err)
+	})
+
+	t.Run("mixed currencies are rejected", func(t *testing.T) {
+		listedInLondon := *bbb
+		listedInLondon.Currency = "GBP"
+
+		_, err := newService(aaa, &listedInLondon).RunBacktest(ctx, req)
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "strategy_ids", validationErr.Field)
+		assert.Contains(t, validationErr.Message, "GBP, USD")
+	})
+}
+
+func TestBacktestService_StartBacktest(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	strategyID := uuid.New()
+
+	t.Run("invalid date range", func(t *testing.T) {
+		service := NewBacktestService(nil, new(MockStrategyRepository), nil, nil, NewMockMarketDataService())
+
+		_, err := service.StartBacktest(ctx, &models.BacktestRequest{
+			StrategyIDs: []uuid.UUID{strategyID},
+			StartDate:   "2024-06-01",
+			EndDate:     "2024-01-01",
+		}, userID)
+
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("strategy of another user", func(t *testing.T) {
+		strategyRepo := new(MockStrategyRepository)
+		strategyRepo.On("GetByID", mock.Anything, strategyID, userID).Return(nil, &models.NotFoundError{Resource: "strategy"})
+		service := NewBacktestService(nil, strategyRepo, nil, nil, NewMockMarketDataService())
+
+		_, err := service.StartBacktest(ctx, &models.BacktestRequest{
+			StrategyIDs: []uuid.UUID{strategyID},
+			StartDate:   "2024-01-01",
+			EndDate:     "2024-06-01",
+		}, userID)
+
+		var notFound *models.NotFoundError
+		assert.ErrorAs(t, err, &notFound)
+	})
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"math"
+
+	"github.com/shopspring/decimal"
+
+	"portfolio-app/internal/models"
+)
+
+// sweepResidualCash spends the cash left over after rounding quantities on additional shares. Each step
+// buys one share unit (a whole share, or the smallest fraction allowed by sharePrecision) of the stock
+// furthest below its ideal weight that is still affordable and stays within the per-stock cap.
+// Allocations are updated in place.
+func (e *AllocationEngine) sweepResidualCash(allocations []models.StockAllocation, constraints models.AllocationConstraints, totalInvestment decimal.Decimal, sharePrecision int32) *models.CashSweepResult {
+	result := &models.CashSweepResult{
+		TrackingErrorBefore: trackingError(allocations, totalInvestment),
+	}
+
+	cash := totalInvestment
+	for _, allocation := range allocations {
+		cash = cash.Sub(allocation.ActualValue)
+	}
+	result.UnallocatedCashBefore = cash
+
+	hundred := decimal.NewFromInt(100)
+	maxAllocationAmount := totalInvestment.Mul(constraints.MaxAllocationPerStock).Div(hundred)
+	unit := decimal.New(1, -sharePrecision)
+
+	for {
+		best := -1
+		bestShortfall := decimal.Zero
+		for i, allocation := range allocations {
+			if !allocation.Price.IsPositive() {
+				continue
+			}
+
+			cost := allocation.Price.Mul(unit)
+			if cost.GreaterThan(cash) || allocation.ActualValue.Add(cost).GreaterThan(maxAllocationAmount) {
+				continue
+			}
+
+			// Shortfall against the ideal weight; negative once a stock is overweight
+			shortfall := allocation.Weight.Sub(allocation.ActualValue.Div(totalInvestment).Mul(hundred))
+			if best == -1 || shortfall.GreaterThan(bestShortfall) {
+				best = i
+				bestShortfall = shortfall
+			}
+		}
+
+		if best == -1 {
+			break
+		}
+
+		cost := allocations[best].Price.Mul(unit)
+		allocations[best].Quantity = allocations[best].Quantity.Add(unit)
+		allocations[best].ActualValue = allocations[best].ActualValue.Add(cost)
+		cash = cash.Sub(cost)
+		result.SharesAdded = result.SharesAdded.Add(unit)
+	}
+
+	result.UnallocatedCashAfter = cash
+	result.TrackingErrorAfter = trackingError(allocations, totalInvestment)
+
+	return result
+}
+
+// trackingError returns the root-sum-square difference, in percentage points, between the weights
+// implied by the actual position values and the ideal allocation weights
+func trackingError(allocations []models.StockAllocation, totalInvestment decimal.Decimal) decimal.Decimal {
+	if !totalInvestment.IsPositive() {
+		return decimal.Zero
+	}
+
+	sumSquares := 0.0
+	for _, allocation := range allocations {
+		actualWeight := allocation.ActualValue.Div(totalInvestment).Mul(decimal.NewFromInt(100))
+		difference := actualWeight.Sub(allocation.Weight).InexactFloat64()
+		sumSquares += difference * difference
+	}
+
+	return decimal.NewFromFloat(math.Sqrt(sumSquares)).Round(4)
+}
@@ -16,13 +16,12 @@ func NewConstraintValidator() *ConstraintValidator {
 }
 
 // ConstraintViolation represents a constraint violation with suggestions
-type ConstraintViolation struct {
-	Type        string          `json:"type"`
-	Message     string          `json:"message"`
-	StockTicker string          `json:"stock_ticker,omitempty"`
-	CurrentValue decimal.Decimal `json:"current_value,omitempty"`
-	LimitValue   decimal.Decimal `json:"limit_value,omitempty"`
-	Suggestions  []string        `json:"suggestions"`
+type ConstraintViolation = models.ConstraintViolation
+
+// advisoryViolationTypes are reported as warnings rather than violations in allocation previews
+var advisoryViolationTypes = map[string]bool{
+	"LOW_ALLOCATION_RATIO": true,
+	"CONCENTRATION_RISK":   true,
 }
 
 // ValidationResult contains the result of constraint validation
@@ -31,6 +30,18 @@ type ValidationResult struct {
 	Violations []ConstraintViolation `json:"violations"`
 }
 
+// Partition splits the result into hard constraint violations and advisory warnings
+func (r *ValidationResult) Partition() (violations, warnings []ConstraintViolation) {
+	for _, violation := range r.Violations {
+		if advisoryViolationTypes[violation.Type] {
+			warnings = append(warnings, violation)
+		} else {
+			violations = append(violations, violation)
+		}
+	}
+	return violations, warnings
+}
+
 // ValidateAllocations validates allocations against constraints and provides detailed feedback
 func (cv *ConstraintValidator) ValidateAllocations(
 	allocations []models.StockAllocation,
@@ -129,7 +140,7 @@ func (cv *ConstraintValidator) validateTotalConstraints(
 		
 		suggestions := []string{
 			"Consider lowering the minimum allocation amount constraint",
-			"Add more stocks with 'Buy' signals to your strategies",
+			"Add more stocks with 'Buy' or 'StrongBuy' signals to your strategies",
 			"Review your strategy stock eligibility settings",
 			"Consider adjusting your maximum allocation percentage to allow larger positions",
 		}
@@ -147,7 +158,7 @@ func (cv *ConstraintValidator) validateTotalConstraints(
 	if len(allocations) > 0 && len(allocations) < 3 {
 		suggestions := []string{
 			"Consider adding more stocks to your strategies for better diversification",
-			"Review your stock signals - ensure more stocks have 'Buy' signals",
+			"Review your stock signals - ensure more stocks have 'Buy' or 'StrongBuy' signals",
 			"Check strategy stock eligibility settings",
 		}
 
@@ -280,6 +280,27 @@ func TestConstraintValidator_SuggestConstraintAdjustments(t *testing.T) {
 	assert.Contains(t, suggestions[0], "reducing minimum allocation")
 }
 
+func TestValidationResult_Partition(t *testing.T) {
+	result := &ValidationResult{
+		IsValid: false,
+		Violations: []ConstraintViolation{
+			{Type: "MAX_ALLOCATION_VIOLATION", StockTicker: "AAPL"},
+			{Type: "CONCENTRATION_RISK"},
+			{Type: "MIN_ALLOCATION_VIOLATION", StockTicker: "GOOGL"},
+			{Type: "LOW_ALLOCATION_RATIO"},
+		},
+	}
+	
+	violations, warnings := result.Partition()
+	
+	assert.Len(t, violations, 2)
+	assert.Equal(t, "MAX_ALLOCATION_VIOLATION", violations[0].Type)
+	assert.Equal(t, "MIN_ALLOCATION_VIOLATION", violations[1].Type)
+	assert.Len(t, warnings, 2)
+	assert.Equal(t, "CONCENTRATION_RISK", warnings[0].Type)
+	assert.Equal(t, "LOW_ALLOCATION_RATIO", warnings[1].Type)
+}
+
 func TestConstraintValidator_EdgeCases(t *testing.T) {
 	validator := NewConstraintValidator()
 	
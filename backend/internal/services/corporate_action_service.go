@@ -0,0 +1,264 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/repositories"
+)
+
+// CorporateActionService defines the interface for recording stock splits and applying them to portfolios
+type CorporateActionService interface {
+	CreateCorporateAction(ctx context.Context, req *models.CreateCorporateActionRequest) (*models.CorporateAction, error)
+	ImportCorporateActions(ctx context.Context, req *models.ImportCorporateActionsRequest) ([]*models.CorporateAction, error)
+	GetCorporateActions(ctx context.Context, stockID *uuid.UUID) ([]*models.CorporateAction, error)
+	ApplyCorporateAction(ctx context.Context, id uuid.UUID) (*models.CorporateActionResult, error)
+	GetAdjustments(ctx context.Context, id uuid.UUID) ([]*models.CorporateActionAdjustment, error)
+}
+
+// corporateActionService implements the CorporateActionService interface
+type corporateActionService struct {
+	corporateActionRepo repositories.CorporateActionRepository
+	stockRepo           repositories.StockRepository
+	portfolioRepo       PortfolioRepository
+	transactionRepo     TransactionRepository
+	marketDataService   MarketDataService
+}
+
+// NewCorporateActionService creates a new corporate action service instance
+func NewCorporateActionService(
+	corporateActionRepo repositories.CorporateActionRepository,
+	stockRepo repositories.StockRepository,
+	portfolioRepo PortfolioRepository,
+	transactionRepo TransactionRepository,
+	marketDataService MarketDataService,
+) CorporateActionService {
+	return &corporateActionService{
+		corporateActionRepo: corporateActionRepo,
+		stockRepo:           stockRepo,
+		portfolioRepo:       portfolioRepo,
+		transactionRepo:     transactionRepo,
+		marketDataService:   marketDataService,
+	}
+}
+
+// CreateCorporateAction records a stock split to be applied to portfolios
+func (s *corporateActionService) CreateCorporateAction(ctx context.Context, req *models.CreateCorporateActionRequest) (*models.CorporateAction, error) {
+	action, err := models.NewCorporateAction(req, models.CorporateActionSourceManual)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := s.stockRepo.GetByID(ctx, req.StockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	created, err := s.corporateActionRepo.Create(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, &models.ValidationError{
+			Field:   "effective_date",
+			Tag:     "unique",
+			Message: fmt.Sprintf("a corporate action for %s is already recorded on %s", stock.Ticker, req.EffectiveDate),
+		}
+	}
+
+	action.Stock = stock
+	return action, nil
+}
+
+// ImportCorporateActions records the splits of a stock reported by the market data provider within a
+// date range, skipping splits already recorded, and returns the ones recorded
+func (s *corporateActionService) ImportCorporateActions(ctx context.Context, req *models.ImportCorporateActionsRequest) ([]*models.CorporateAction, error) {
+	provider, ok := s.marketDataService.(SplitProvider)
+	if !ok {
+		return nil, fmt.Errorf("the market data provider does not report splits")
+	}
+
+	from, err := time.Parse("2006-01-02", req.From)
+	if err != nil {
+		return nil, &models.ValidationError{Field: "from", Tag: "datetime", Message: "from must be a date in YYYY-MM-DD format"}
+	}
+	to := time.Now()
+	if req.To != "" {
+		to, err = time.Parse("2006-01-02", req.To)
+		if err != nil {
+			return nil, &models.ValidationError{Field: "to", Tag: "datetime", Message: "to must be a date in YYYY-MM-DD format"}
+		}
+	}
+	if !from.Before(to) {
+		return nil, &models.ValidationError{Field: "from", Tag: "ltfield", Message: "from must be before to"}
+	}
+
+	stock, err := s.stockRepo.GetByID(ctx, req.StockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	splits, err := provider.GetSplits(ctx, stock.Ticker, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	imported := []*models.CorporateAction{}
+	for _, split := range splits {
+		action, err := models.NewCorporateAction(&models.CreateCorporateActionRequest{
+			StockID:       stock.ID,
+			Type:          models.CorporateActionSplit,
+			NewShares:     split.NewShares,
+			OldShares:     split.OldShares,
+			EffectiveDate: split.Date.Format("2006-01-02"),
+		}, models.CorporateActionSourceProvider)
+		if err != nil {
+			// Skip events that are not a change in share count
+			continue
+		}
+
+		created, err := s.corporateActionRepo.Create(ctx, action)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			action.Stock = stock
+			imported = append(imported, action)
+		}
+	}
+
+	return imported, nil
+}
+
+// GetCorporateActions retrieves recorded corporate actions, optionally for a single stock
+func (s *corporateActionService) GetCorporateActions(ctx context.Context, stockID *uuid.UUID) ([]*models.CorporateAction, error) {
+	return s.corporateActionRepo.GetAll(ctx, stockID)
+}
+
+// ApplyCorporateAction applies a split to every portfolio that held the stock on its effective date.
+// Each portfolio gets a split ledger entry that scales its shares and tax lots while keeping their
+// cost basis, so entry prices fall by the split ratio, and an audit entry of the change. Portfolios
+// already adjusted are skipped, so a partially applied action can be applied again.
+func (s *corporateActionService) ApplyCorporateAction(ctx context.Context, id uuid.UUID) (*models.CorporateActionResult, error) {
+	action, err := s.corporateActionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if action.EffectiveDate.After(time.Now()) {
+		return nil, &models.ValidationError{
+			Field:   "effective_date",
+			Message: fmt.Sprintf("the split is not effective until %s", action.EffectiveDate.Format("2006-01-02")),
+		}
+	}
+
+	portfolioIDs, err := s.corporateActionRepo.GetUnadjustedPortfolioIDs(ctx, action)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.CorporateActionResult{Action: action, Adjustments: []*models.CorporateActionAdjustment{}}
+	for _, portfolioID := range portfolioIDs {
+		adjustment, err := s.applyToPortfolio(ctx, action, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s to portfolio %s: %w", action.Description(), portfolioID, err)
+		}
+		if adjustment != nil {
+			result.Adjustments = append(result.Adjustments, adjustment)
+		}
+	}
+
+	now := time.Now()
+	if err := s.corporateActionRepo.MarkApplied(ctx, action.ID, now); err != nil {
+		return nil, err
+	}
+	action.Status = models.CorporateActionApplied
+	action.AppliedAt = &now
+
+	return result, nil
+}
+
+// applyToPortfolio records the split of the shares a portfolio held on the effective date and
+// re-derives its positions, returning nil when the portfolio held none
+func (s *corporateActionService) applyToPortfolio(ctx context.Context, action *models.CorporateAction, portfolioID uuid.UUID) (*models.CorporateActionAdjustment, error) {
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, time.Time{}, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
+	}
+
+	var before []*models.Transaction
+	for _, t := range transactions {
+		if t.ExecutedAt.Before(action.EffectiveDate) {
+			before = append(before, t)
+		}
+	}
+	heldLedger, err := models.BuildLedger(before, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+
+	split := action.SplitTransaction(portfolioID, heldLedger.Quantity(action.StockID), portfolio.ShareDecimals())
+	if split == nil {
+		return nil, nil
+	}
+
+	currentLedger, err := models.BuildLedger(transactions, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+	splitLedger, err := models.BuildLedger(append(transactions, split), portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+
+	positions, err := positionsFromLedger(portfolioID, splitLedger, strategyContribsByStock(portfolio.Positions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive positions: %w", err)
+	}
+
+	adjustment := &models.CorporateActionAdjustment{
+		ID:                uuid.New(),
+		CorporateActionID: action.ID,
+		PortfolioID:       portfolioID,
+		StockID:           action.StockID,
+		TransactionID:     split.ID,
+		QuantityBefore:    currentLedger.Quantity(action.StockID),
+		QuantityAfter:     splitLedger.Quantity(action.StockID),
+		EntryPriceBefore:  averageCost(currentLedger, action.StockID),
+		EntryPriceAfter:   averageCost(splitLedger, action.StockID),
+		CreatedAt:         time.Now(),
+	}
+
+	if err := s.corporateActionRepo.ApplyToPortfolio(ctx, adjustment, split, positions); err != nil {
+		return nil, err
+	}
+
+	return adjustment, nil
+}
+
+// GetAdjustments retrieves the portfolio adjustments made for a corporate action
+func (s *corporateActionService) GetAdjustments(ctx context.Context, id uuid.UUID) ([]*models.CorporateActionAdjustment, error) {
+	if _, err := s.corporateActionRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.corporateActionRepo.GetAdjustments(ctx, id)
+}
+
+// averageCost returns the entry price of a holding in a ledger, zero once it is closed
+func averageCost(ledger *models.Ledger, stockID uuid.UUID) decimal.Decimal {
+	holding, exists := ledger.Holdings[stockID]
+	if !exists {
+		return decimal.Zero
+	}
+	return holding.AverageCost().Round(4)
+}
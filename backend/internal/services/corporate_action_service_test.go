@@ -0,0 +1,288 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"portfolio-app/internal/models"
+)
+
+// MockCorporateActionRepository is a mock implementation of repositories.CorporateActionRepository
+type MockCorporateActionRepository struct {
+	mock.Mock
+}
+
+func (m *MockCorporateActionRepository) Create(ctx context.Context, action *models.CorporateAction) (bool, error) {
+	args := m.Called(ctx, action)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockCorporateActionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.CorporateAction, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.CorporateAction), args.Error(1)
+}
+
+func (m *MockCorporateActionRepository) GetAll(ctx context.Context, stockID *uuid.UUID) ([]*models.CorporateAction, error) {
+	args := m.Called(ctx, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CorporateAction), args.Error(1)
+}
+
+func (m *MockCorporateActionRepository) GetByStockID(ctx context.Context, stockID uuid.UUID) ([]*models.CorporateAction, error) {
+	args := m.Called(ctx, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CorporateAction), args.Error(1)
+}
+
+func (m *MockCorporateActionRepository) MarkApplied(ctx context.Context, id uuid.UUID, appliedAt time.Time) error {
+	args := m.Called(ctx, id, appliedAt)
+	return args.Error(0)
+}
+
+func (m *MockCorporateActionRepository) GetUnadjustedPortfolioIDs(ctx context.Context, action *models.CorporateAction) ([]uuid.UUID, error) {
+	args := m.Called(ctx, action)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockCorporateActionRepository) ApplyToPortfolio(ctx context.Context, adjustment *models.CorporateActionAdjustment, transaction *models.Transaction, positions []*models.Position) error {
+	args := m.Called(ctx, adjustment, transaction, positions)
+	return args.Error(0)
+}
+
+func (m *MockCorporateActionRepository) GetAdjustments(ctx context.Context, actionID uuid.UUID) ([]*models.CorporateActionAdjustment, error) {
+	args := m.Called(ctx, actionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.CorporateActionAdjustment), args.Error(1)
+}
+
+// newSplitAction builds a split of a stock effective on a date
+func newSplitAction(t *testing.T, stockID uuid.UUID, newShares, oldShares int64, effectiveDate time.Time) *models.CorporateAction {
+	action, err := models.NewCorporateAction(&models.CreateCorporateActionRequest{
+		StockID:       stockID,
+		NewShares:     decimal.NewFromInt(newShares),
+		OldShares:     decimal.NewFromInt(oldShares),
+		EffectiveDate: effectiveDate.Format("2006-01-02"),
+	}, models.CorporateActionSourceManual)
+	require.NoError(t, err)
+	return action
+}
+
+func TestCorporateActionService_ApplyCorporateAction(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+	effectiveDate := time.Now().AddDate(0, 0, -10).UTC().Truncate(24 * time.Hour)
+	boughtAt := effectiveDate.AddDate(0, -2, 0)
+
+	portfolio := &models.Portfolio{ID: portfolioID, LotReliefMethod: models.LotReliefFIFO}
+	transactions := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(50000.00), boughtAt),
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(400.00), boughtAt),
+		// Bought after the split at the split-adjusted price, so not split again
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(20), decimal.NewFromFloat(100.00), effectiveDate.AddDate(0, 0, 2)),
+	}
+
+	t.Run("splits the shares held on the effective date and audits the change", func(t *testing.T) {
+		action := newSplitAction(t, stockID, 4, 1, effectiveDate)
+
+		corporateActionRepo := new(MockCorporateActionRepository)
+		portfolioRepo := new(MockPortfolioRepository)
+		transactionRepo := new(MockTransactionRepository)
+		service := NewCorporateActionService(corporateActionRepo, new(MockStockRepository), portfolioRepo, transactionRepo, nil)
+
+		corporateActionRepo.On("GetByID", ctx, action.ID).Return(action, nil)
+		corporateActionRepo.On("GetUnadjustedPortfolioIDs", ctx, action).Return([]uuid.UUID{portfolioID}, nil)
+		portfolioRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		transactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(transactions, nil)
+
+		var split *models.Transaction
+		var positions []*models.Position
+		corporateActionRepo.On("ApplyToPortfolio", ctx, mock.AnythingOfType("*models.CorporateActionAdjustment"), mock.AnythingOfType("*models.Transaction"), mock.AnythingOfType("[]*models.Position")).
+			Run(func(args mock.Arguments) {
+				split = args.Get(2).(*models.Transaction)
+				positions = args.Get(3).([]*models.Position)
+			}).Return(nil)
+		corporateActionRepo.On("MarkApplied", ctx, action.ID, mock.AnythingOfType("time.Time")).Return(nil)
+
+		result, err := service.ApplyCorporateAction(ctx, action.ID)
+		require.NoError(t, err)
+
+		require.NotNil(t, split)
+		assert.Equal(t, models.TransactionSplit, split.Type)
+		assert.True(t, split.Quantity.Equal(decimal.NewFromInt(300)))
+		assert.True(t, split.ExecutedAt.Equal(effectiveDate))
+
+		require.Len(t, positions, 1)
+		assert.True(t, positions[0].Quantity.Equal(decimal.NewFromInt(420)))
+		// (100 x 400 + 20 x 100) / 420 shares
+		assert.True(t, positions[0].EntryPrice.Equal(decimal.NewFromFloat(100.00)))
+
+		require.Len(t, result.Adjustments, 1)
+		adjustment := result.Adjustments[0]
+		assert.Equal(t, split.ID, adjustment.TransactionID)
+		assert.True(t, adjustment.QuantityBefore.Equal(decimal.NewFromInt(120)))
+		assert.True(t, adjustment.QuantityAfter.Equal(decimal.NewFromInt(420)))
+		assert.True(t, adjustment.EntryPriceBefore.Equal(decimal.NewFromFloat(350.00)))
+		assert.True(t, adjustment.EntryPriceAfter.Equal(decimal.NewFromFloat(100.00)))
+		assert.Equal(t, models.CorporateActionApplied, result.Action.Status)
+		assert.NotNil(t, result.Action.AppliedAt)
+	})
+
+	t.Run("drops fractional shares lost to a reverse split", func(t *testing.T) {
+		action := newSplitAction(t, stockID, 1, 3, effectiveDate)
+		held := decimal.NewFromInt(100)
+
+		split := action.SplitTransaction(portfolioID, held, portfolio.ShareDecimals())
+		require.NotNil(t, split)
+		assert.True(t, split.Quantity.Equal(decimal.NewFromInt(-67)))
+		assert.Equal(t, "1-for-3 reverse split", *split.Notes)
+	})
+
+	t.Run("rejects a split that is not yet effective", func(t *testing.T) {
+		action := newSplitAction(t, stockID, 2, 1, time.Now().AddDate(0, 0, 5))
+
+		corporateActionRepo := new(MockCorporateActionRepository)
+		service := NewCorporateActionService(corporateActionRepo, new(MockStockRepository), new(MockPortfolioRepository), new(MockTransactionRepository), nil)
+		corporateActionRepo.On("GetByID", ctx, action.ID).Return(action, nil)
+
+		_, err := service.ApplyCorporateAction(ctx, action.ID)
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		corporateActionRepo.AssertNotCalled(t, "GetUnadjustedPortfolioIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCorporateActionService_CreateCorporateAction(t *testing.T) {
+	ctx := context.Background()
+	stock := &models.Stock{ID: uuid.New(), Ticker: "AAPL"}
+	req := &models.CreateCorporateActionRequest{
+		StockID:       stock.ID,
+		NewShares:     decimal.NewFromInt(4),
+		OldShares:     decimal.NewFromInt(1),
+		EffectiveDate: "2020-08-31",
+	}
+
+	t.Run("records a pending split", func(t *testing.T) {
+		corporateActionRepo := new(MockCorporateActionRepository)
+		stockRepo := new(MockStockRepository)
+		service := NewCorporateActionService(corporateActionRepo, stockRepo, nil, nil, nil)
+
+		stockRepo.On("GetByID", ctx, stock.ID).Return(stock, nil)
+		corporateActionRepo.On("Create", ctx, mock.AnythingOfType("*models.CorporateAction")).Return(true, nil)
+
+		action, err := service.CreateCorporateAction(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, models.CorporateActionSplit, action.Type)
+		assert.Equal(t, models.CorporateActionPending, action.Status)
+		assert.Equal(t, models.CorporateActionSourceManual, action.Source)
+		assert.True(t, action.Ratio().Equal(decimal.NewFromInt(4)))
+		assert.Equal(t, "4-for-1 split", action.Description())
+	})
+
+	t.Run("rejects a split already recorded on the date", func(t *testing.T) {
+		corporateActionRepo := new(MockCorporateActionRepository)
+		stockRepo := new(MockStockRepository)
+		service := NewCorporateActionService(corporateActionRepo, stockRepo, nil, nil, nil)
+
+		stockRepo.On("GetByID", ctx, stock.ID).Return(stock, nil)
+		corporateActionRepo.On("Create", ctx, mock.AnythingOfType("*models.CorporateAction")).Return(false, nil)
+
+		_, err := service.CreateCorporateAction(ctx, req)
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "effective_date", validationErr.Field)
+	})
+
+	t.Run("rejects a split that does not change the share count", func(t *testing.T) {
+		service := NewCorporateActionService(nil, nil, nil, nil, nil)
+
+		_, err := service.CreateCorporateAction(ctx, &models.CreateCorporateActionRequest{
+			StockID:       stock.ID,
+			NewShares:     decimal.NewFromInt(1),
+			OldShares:     decimal.NewFromInt(1),
+			EffectiveDate: "2020-08-31",
+		})
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
+
+func TestCorporateActionService_ImportCorporateActions(t *testing.T) {
+	ctx := context.Background()
+	stock := &models.Stock{ID: uuid.New(), Ticker: "NVDA"}
+
+	marketData := NewMockMarketDataService()
+	marketData.SetSplits("NVDA", []*Split{
+		{Date: time.Date(2021, 7, 20, 0, 0, 0, 0, time.UTC), NewShares: decimal.NewFromInt(4), OldShares: decimal.NewFromInt(1)},
+		{Date: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), NewShares: decimal.NewFromInt(10), OldShares: decimal.NewFromInt(1)},
+	})
+
+	corporateActionRepo := new(MockCorporateActionRepository)
+	stockRepo := new(MockStockRepository)
+	service := NewCorporateActionService(corporateActionRepo, stockRepo, nil, nil, marketData)
+
+	stockRepo.On("GetByID", ctx, stock.ID).Return(stock, nil)
+	// The 2021 split is already recorded
+	corporateActionRepo.On("Create", ctx, mock.MatchedBy(func(action *models.CorporateAction) bool {
+		return action.EffectiveDate.Year() == 2021
+	})).Return(false, nil)
+	corporateActionRepo.On("Create", ctx, mock.MatchedBy(func(action *models.CorporateAction) bool {
+		return action.EffectiveDate.Year() == 2024
+	})).Return(true, nil)
+
+	actions, err := service.ImportCorporateActions(ctx, &models.ImportCorporateActionsRequest{
+		StockID: stock.ID,
+		From:    "2020-01-01",
+		To:      "2025-01-01",
+	})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, models.CorporateActionSourceProvider, actions[0].Source)
+	assert.True(t, actions[0].Ratio().Equal(decimal.NewFromInt(10)))
+}
+
+func TestPortfolioService_GetTradedBars(t *testing.T) {
+	ctx := context.Background()
+	stock := &models.Stock{ID: uuid.New(), Ticker: "AAPL"}
+	splitDate := time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC)
+	from := time.Date(2020, 8, 27, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 9, 2, 0, 0, 0, 0, time.UTC)
+
+	marketData := new(MockTestMarketDataService)
+	corporateActionRepo := new(MockCorporateActionRepository)
+	service := NewPortfolioService(nil, nil, nil, nil, marketData, corporateActionRepo, nil, nil)
+
+	// Provider history is already adjusted for the split
+	bars := weekdayBars(from, []float64{125.00, 124.00, 129.00, 134.00, 131.00})
+	marketData.On("GetOHLCV", ctx, "AAPL", from, to, "1day").Return(bars, nil)
+	corporateActionRepo.On("GetByStockID", ctx, stock.ID).Return([]*models.CorporateAction{newSplitAction(t, stock.ID, 4, 1, splitDate)}, nil)
+
+	traded, err := service.getTradedBars(ctx, stock, from, to)
+	require.NoError(t, err)
+	require.Len(t, traded, 5)
+
+	// Bars before the split are at four times the adjusted price and a quarter of the volume
+	assert.True(t, traded[0].Close.Equal(decimal.NewFromFloat(500.00)))
+	assert.Equal(t, int64(25000), traded[0].Volume)
+	assert.True(t, traded[1].Close.Equal(decimal.NewFromFloat(496.00)))
+	assert.True(t, traded[2].Close.Equal(decimal.NewFromFloat(129.00)))
+	assert.Equal(t, int64(100000), traded[2].Volume)
+}
@@ -0,0 +1,280 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/repositories"
+)
+
+// DividendService defines the interface for recording dividends and paying them to portfolios
+type DividendService interface {
+	CreateDividendEvent(ctx context.Context, req *models.CreateDividendEventRequest) (*models.DividendEvent, error)
+	GetDividendEvents(ctx context.Context, stockID *uuid.UUID) ([]*models.DividendEvent, error)
+	ProcessDividendEvent(ctx context.Context, id uuid.UUID) (*models.DividendEventResult, error)
+	ProcessDueDividends(ctx context.Context) ([]*models.DividendEventResult, error)
+	GetPayments(ctx context.Context, id uuid.UUID) ([]*models.DividendPayment, error)
+}
+
+// dividendService implements the DividendService interface
+type dividendService struct {
+	dividendRepo      repositories.DividendRepository
+	stockRepo         repositories.StockRepository
+	portfolioRepo     PortfolioRepository
+	transactionRepo   TransactionRepository
+	marketDataService MarketDataService
+	fxService         FXService
+}
+
+// NewDividendService creates a new dividend service instance
+func NewDividendService(
+	dividendRepo repositories.DividendRepository,
+	stockRepo repositories.StockRepository,
+	portfolioRepo PortfolioRepository,
+	transactionRepo TransactionRepository,
+	marketDataService MarketDataService,
+	fxService FXService,
+) DividendService {
+	return &dividendService{
+		dividendRepo:      dividendRepo,
+		stockRepo:         stockRepo,
+		portfolioRepo:     portfolioRepo,
+		transactionRepo:   transactionRepo,
+		marketDataService: marketDataService,
+		fxService:         fxService,
+	}
+}
+
+// CreateDividendEvent records a dividend to be paid to portfolios
+func (s *dividendService) CreateDividendEvent(ctx context.Context, req *models.CreateDividendEventRequest) (*models.DividendEvent, error) {
+	event, err := models.NewDividendEvent(req)
+	if err != nil {
+		return nil, err
+	}
+
+	stock, err := s.stockRepo.GetByID(ctx, req.StockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stock: %w", err)
+	}
+
+	created, err := s.dividendRepo.Create(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		return nil, &models.ValidationError{
+			Field:   "ex_date",
+			Tag:     "unique",
+			Message: fmt.Sprintf("a dividend for %s is already recorded with ex-date %s", stock.Ticker, req.ExDate),
+		}
+	}
+
+	event.Stock = stock
+	return event, nil
+}
+
+// GetDividendEvents retrieves recorded dividends, optionally for a single stock
+func (s *dividendService) GetDividendEvents(ctx context.Context, stockID *uuid.UUID) ([]*models.DividendEvent, error) {
+	return s.dividendRepo.GetAll(ctx, stockID)
+}
+
+// ProcessDividendEvent pays a dividend to every portfolio that held the stock before its ex-date.
+// The dividend is credited to cash, then reinvested in the stock at its pay-date close in portfolios
+// with DRIP enabled. Portfolios already paid are skipped, so a partially processed dividend can be
+// processed again.
+func (s *dividendService) ProcessDividendEvent(ctx context.Context, id uuid.UUID) (*models.DividendEventResult, error) {
+	event, err := s.dividendRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.PayDate.After(time.Now()) {
+		return nil, &models.ValidationError{
+			Field:   "pay_date",
+			Message: fmt.Sprintf("the dividend is not paid until %s", event.PayDate.Format("2006-01-02")),
+		}
+	}
+
+	return s.processEvent(ctx, event)
+}
+
+// ProcessDueDividends pays every pending dividend whose pay date has passed
+func (s *dividendService) ProcessDueDividends(ctx context.Context) ([]*models.DividendEventResult, error) {
+	events, err := s.dividendRepo.GetDue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*models.DividendEventResult, 0, len(events))
+	for _, event := range events {
+		result, err := s.processEvent(ctx, event)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// processEvent pays a dividend to the entitled portfolios not yet paid and marks it paid
+func (s *dividendService) processEvent(ctx context.Context, event *models.DividendEvent) (*models.DividendEventResult, error) {
+	portfolioIDs, err := s.dividendRepo.GetUnpaidPortfolioIDs(ctx, event)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.DividendEventResult{Event: event, Payments: []*models.DividendPayment{}}
+	for _, portfolioID := range portfolioIDs {
+		payment, err := s.payPortfolio(ctx, event, portfolioID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to pay dividend to portfolio %s: %w", portfolioID, err)
+		}
+		if payment != nil {
+			result.Payments = append(result.Payments, payment)
+		}
+	}
+
+	now := time.Now()
+	if err := s.dividendRepo.MarkPaid(ctx, event.ID, now); err != nil {
+		return nil, err
+	}
+	event.Status = models.DividendPaid
+	event.PaidAt = &now
+
+	return result, nil
+}
+
+// payPortfolio credits the dividend on the shares a portfolio held before the ex-date, reinvesting it
+// when DRIP is enabled, and returns nil when the portfolio held none
+func (s *dividendService) payPortfolio(ctx context.Context, event *models.DividendEvent, portfolioID uuid.UUID) (*models.DividendPayment, error) {
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, time.Time{}, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
+	}
+
+	var before []*models.Transaction
+	for _, t := range transactions {
+		if t.ExecutedAt.Before(event.ExDate) {
+			before = append(before, t)
+		}
+	}
+	heldLedger, err := models.BuildLedger(before, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+
+	fxRate, err := s.fxRate(ctx, event, portfolio)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := heldLedger.Quantity(event.StockID)
+	dividend := event.DividendTransaction(portfolioID, shares, fxRate)
+	if dividend == nil {
+		return nil, nil
+	}
+
+	payment := &models.DividendPayment{
+		ID:               uuid.New(),
+		DividendEventID:  event.ID,
+		PortfolioID:      portfolioID,
+		StockID:          event.StockID,
+		Shares:           shares,
+		Amount:           dividend.Amount,
+		TransactionID:    dividend.ID,
+		ReinvestedShares: decimal.Zero,
+		CreatedAt:        time.Now(),
+	}
+	entries := []*models.Transaction{dividend}
+
+	if portfolio.DRIPEnabled {
+		if reinvestment := s.reinvestmentTransaction(ctx, event, portfolio, dividend.Amount, fxRate); reinvestment != nil {
+			entries = append(entries, reinvestment)
+			payment.ReinvestmentTransactionID = &reinvestment.ID
+			payment.ReinvestedShares = reinvestment.Quantity
+		}
+	}
+
+	ledger, err := models.BuildLedger(append(transactions, entries...), portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+
+	positions, err := positionsFromLedger(portfolioID, ledger, strategyContribsByStock(portfolio.Positions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive positions: %w", err)
+	}
+
+	if err := s.dividendRepo.RecordPayment(ctx, payment, entries, positions, ledger.Cash); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+// fxRate returns the rate on the pay date converting the currency of the dividend's stock to the
+// portfolio base currency
+func (s *dividendService) fxRate(ctx context.Context, event *models.DividendEvent, portfolio *models.Portfolio) (decimal.Decimal, error) {
+	currency := models.DefaultCurrency
+	if event.Stock != nil {
+		currency = models.CurrencyOrDefault(event.Stock.Currency)
+	}
+	baseCurrency := models.CurrencyOrDefault(portfolio.BaseCurrency)
+	if currency == baseCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+	if s.fxService == nil {
+		return decimal.Zero, fmt.Errorf("no FX service to convert %s to %s", currency, baseCurrency)
+	}
+
+	rate, err := s.fxService.GetRate(ctx, currency, baseCurrency, event.PayDate)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get FX rate: %w", err)
+	}
+	return rate, nil
+}
+
+// reinvestmentTransaction returns the buy reinvesting a dividend at the stock's close on the pay date,
+// converted to the portfolio base currency at fxRate. The dividend is kept as cash when no price is
+// available or it buys no shares.
+func (s *dividendService) reinvestmentTransaction(ctx context.Context, event *models.DividendEvent, portfolio *models.Portfolio, amount, fxRate decimal.Decimal) *models.Transaction {
+	ticker := event.StockID.String()
+	if event.Stock != nil {
+		ticker = event.Stock.Ticker
+	}
+
+	bars, err := s.marketDataService.GetOHLCV(ctx, ticker, event.PayDate.AddDate(0, 0, -7), event.PayDate.AddDate(0, 0, 1), "1day")
+	if err != nil {
+		fmt.Printf("Warning: failed to get price of %s to reinvest dividend for portfolio %s: %v\n", ticker, portfolio.ID, err)
+		return nil
+	}
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Timestamp.Before(bars[j].Timestamp) })
+
+	price, ok := closeOnOrBefore(bars, event.PayDate)
+	if !ok {
+		fmt.Printf("Warning: no price for %s on or before %s to reinvest dividend for portfolio %s\n", ticker, event.PayDate.Format("2006-01-02"), portfolio.ID)
+		return nil
+	}
+
+	return event.ReinvestmentTransaction(portfolio.ID, amount, price, fxRate, portfolio.ShareDecimals())
+}
+
+// GetPayments retrieves the portfolio payments made for a dividend event
+func (s *dividendService) GetPayments(ctx context.Context, id uuid.UUID) ([]*models.DividendPayment, error) {
+	if _, err := s.dividendRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.dividendRepo.GetPayments(ctx, id)
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"portfolio-app/internal/models"
+)
+
+// MockDividendRepository is a mock implementation of repositories.DividendRepository
+type MockDividendRepository struct {
+	mock.Mock
+}
+
+func (m *MockDividendRepository) Create(ctx context.Context, event *models.DividendEvent) (bool, error) {
+	args := m.Called(ctx, event)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDividendRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DividendEvent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.DividendEvent), args.Error(1)
+}
+
+func (m *MockDividendRepository) GetAll(ctx context.Context, stockID *uuid.UUID) ([]*models.DividendEvent, error) {
+	args := m.Called(ctx, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DividendEvent), args.Error(1)
+}
+
+func (m *MockDividendRepository) GetDue(ctx context.Context, asOf time.Time) ([]*models.DividendEvent, error) {
+	args := m.Called(ctx, asOf)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DividendEvent), args.Error(1)
+}
+
+func (m *MockDividendRepository) MarkPaid(ctx context.Context, id uuid.UUID, paidAt time.Time) error {
+	args := m.Called(ctx, id, paidAt)
+	return args.Error(0)
+}
+
+func (m *MockDividendRepository) GetUnpaidPortfolioIDs(ctx context.Context, event *models.DividendEvent) ([]uuid.UUID, error) {
+	args := m.Called(ctx, event)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockDividendRepository) RecordPayment(ctx context.Context, payment *models.DividendPayment, transactions []*models.Transaction, positions []*models.Position, cashBalance decimal.Decimal) error {
+	args := m.Called(ctx, payment, transactions, positions, cashBalance)
+	return args.Error(0)
+}
+
+func (m *MockDividendRepository) GetPayments(ctx context.Context, eventID uuid.UUID) ([]*models.DividendPayment, error) {
+	args := m.Called(ctx, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.DividendPayment), args.Error(1)
+}
+
+// newDividendEvent builds a dividend of a stock with the given ex- and pay dates
+func newDividendEvent(t *testing.T, stock *models.Stock, amountPerShare float64, exDate, payDate time.Time) *models.DividendEvent {
+	event, err := models.NewDividendEvent(&models.CreateDividendEventRequest{
+		StockID:        stock.ID,
+		ExDate:         exDate.Format("2006-01-02"),
+		PayDate:        payDate.Format("2006-01-02"),
+		AmountPerShare: decimal.NewFromFloat(amountPerShare),
+	})
+	require.NoError(t, err)
+	event.Stock = stock
+	return event
+}
+
+func TestDividendService_ProcessDividendEvent(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stock := &models.Stock{ID: uuid.New(), Ticker: "KO"}
+	exDate := time.Now().AddDate(0, 0, -20).UTC().Truncate(24 * time.Hour)
+	payDate := exDate.AddDate(0, 0, 14)
+	boughtAt := exDate.AddDate(0, -3, 0)
+
+	transactions := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(8000.00), boughtAt),
+		models.NewTradeTransaction(portfolioID, stock.ID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(60.00), boughtAt),
+		// Bought on the ex-date, so not entitled to the dividend
+		models.NewTradeTransaction(portfolioID, stock.ID, models.TransactionBuy, decimal.NewFromInt(20), decimal.NewFromFloat(58.00), exDate),
+	}
+
+	// process pays the dividend to the portfolio and returns the entries and cash balance recorded
+	process := func(t *testing.T, portfolio *models.Portfolio, marketData *MockTestMarketDataService) (*models.DividendEventResult, []*models.Transaction, []*models.Position, decimal.Decimal) {
+		event := newDividendEvent(t, stock, 0.485, exDate, payDate)
+
+		dividendRepo := new(MockDividendRepository)
+		portfolioRepo := new(MockPortfolioRepository)
+		transactionRepo := new(MockTransactionRepository)
+		service := NewDividendService(dividendRepo, new(MockStockRepository), portfolioRepo, transactionRepo, marketData, nil)
+
+		dividendRepo.On("GetByID", ctx, event.ID).Return(event, nil)
+		dividendRepo.On("GetUnpaidPortfolioIDs", ctx, event).Return([]uuid.UUID{portfolioID}, nil)
+		portfolioRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		transactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(transactions, nil)
+
+		var entries []*models.Transaction
+		var positions []*models.Position
+		var cash decimal.Decimal
+		dividendRepo.On("RecordPayment", ctx, mock.AnythingOfType("*models.DividendPayment"), mock.AnythingOfType("[]*models.Transaction"), mock.AnythingOfType("[]*models.Position"), mock.AnythingOfType("decimal.Decimal")).
+			Run(func(args mock.Arguments) {
+				entries = args.Get(2).([]*models.Transaction)
+				positions = args.Get(3).([]*models.Position)
+				cash = args.Get(4).(decimal.Decimal)
+			}).Return(nil)
+		dividendRepo.On("MarkPaid", ctx, event.ID, mock.AnythingOfType("time.Time")).Return(nil)
+
+		result, err := service.ProcessDividendEvent(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DividendPaid, result.Event.Status)
+		return result, entries, positions, cash
+	}
+
+	t.Run("credits cash on the shares held before the ex-date", func(t *testing.T) {
+		portfolio := &models.Portfolio{ID: portfolioID, LotReliefMethod: models.LotReliefFIFO}
+
+		result, entries, positions, cash := process(t, portfolio, nil)
+
+		require.Len(t, result.Payments, 1)
+		payment := result.Payments[0]
+		assert.True(t, payment.Shares.Equal(decimal.NewFromInt(100)))
+		// 100 shares x $0.485
+		assert.True(t, payment.Amount.Equal(decimal.NewFromFloat(48.50)))
+		assert.Nil(t, payment.ReinvestmentTransactionID)
+
+		require.Len(t, entries, 1)
+		assert.Equal(t, models.TransactionDividend, entries[0].Type)
+		assert.Equal(t, stock.ID, *entries[0].StockID)
+		assert.True(t, entries[0].ExecutedAt.Equal(payDate))
+		// $8000 - $6000 - $1160 + $48.50
+		assert.True(t, cash.Equal(decimal.NewFromFloat(888.50)))
+		require.Len(t, positions, 1)
+		assert.True(t, positions[0].Quantity.Equal(decimal.NewFromInt(120)))
+	})
+
+	t.Run("reinvests the dividend at the pay-date close with drip enabled", func(t *testing.T) {
+		portfolio := &models.Portfolio{ID: portfolioID, LotReliefMethod: models.LotReliefFIFO, DRIPEnabled: true, FractionalShares: true, SharePrecision: 4}
+		marketData := new(MockTestMarketDataService)
+		marketData.On("GetOHLCV", ctx, "KO", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1day").
+			Return(weekdayBars(payDate.AddDate(0, 0, -7), []float64{61.00, 61.50, 62.00, 62.50, 63.00, 64.00}), nil)
+
+		result, entries, positions, cash := process(t, portfolio, marketData)
+
+		require.Len(t, entries, 2)
+		reinvestment := entries[1]
+		assert.Equal(t, models.TransactionBuy, reinvestment.Type)
+		price := reinvestment.Price
+		assert.True(t, price.GreaterThanOrEqual(decimal.NewFromFloat(61.00)))
+		// $48.50 buys whole ten-thousandths of a share without exceeding the dividend
+		assert.True(t, reinvestment.Quantity.Equal(decimal.NewFromFloat(48.50).Div(price).RoundFloor(4)))
+		assert.True(t, reinvestment.Amount.LessThanOrEqual(decimal.NewFromFloat(48.50)))
+
+		payment := result.Payments[0]
+		require.NotNil(t, payment.ReinvestmentTransactionID)
+		assert.Equal(t, reinvestment.ID, *payment.ReinvestmentTransactionID)
+		assert.True(t, payment.ReinvestedShares.Equal(reinvestment.Quantity))
+
+		assert.True(t, cash.Equal(decimal.NewFromFloat(888.50).Sub(reinvestment.Amount)))
+		require.Len(t, positions, 1)
+		assert.True(t, positions[0].Quantity.Equal(decimal.NewFromInt(120).Add(reinvestment.Quantity)))
+	})
+
+	t.Run("keeps the dividend as cash when it buys no whole share", func(t *testing.T) {
+		portfolio := &models.Portfolio{ID: portfolioID, LotReliefMethod: models.LotReliefFIFO, DRIPEnabled: true}
+		marketData := new(MockTestMarketDataService)
+		marketData.On("GetOHLCV", ctx, "KO", mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time"), "1day").
+			Return(weekdayBars(payDate.AddDate(0, 0, -7), []float64{61.00, 61.50, 62.00, 62.50, 63.00, 64.00}), nil)
+
+		result, entries, _, cash := process(t, portfolio, marketData)
+
+		require.Len(t, entries, 1)
+		assert.Nil(t, result.Payments[0].ReinvestmentTransactionID)
+		assert.True(t, cash.Equal(decimal.NewFromFloat(888.50)))
+	})
+
+	t.Run("rejects a dividend that is not yet paid", func(t *testing.T) {
+		event := newDividendEvent(t, stock, 0.485, time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 10))
+
+		dividendRepo := new(MockDividendRepository)
+		service := NewDividendService(dividendRepo, nil, nil, nil, nil, nil)
+		dividendRepo.On("GetByID", ctx, event.ID).Return(event, nil)
+
+		_, err := service.ProcessDividendEvent(ctx, event.ID)
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		dividendRepo.AssertNotCalled(t, "GetUnpaidPortfolioIDs", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDividendService_CreateDividendEvent(t *testing.T) {
+	ctx := context.Background()
+	stock := &models.Stock{ID: uuid.New(), Ticker: "KO"}
+	req := &models.CreateDividendEventRequest{
+		StockID:        stock.ID,
+		ExDate:         "2024-06-14",
+		PayDate:        "2024-07-01",
+		AmountPerShare: decimal.NewFromFloat(0.485),
+	}
+
+	t.Run("records a pending dividend", func(t *testing.T) {
+		dividendRepo := new(MockDividendRepository)
+		stockRepo := new(MockStockRepository)
+		service := NewDividendService(dividendRepo, stockRepo, nil, nil, nil, nil)
+
+		stockRepo.On("GetByID", ctx, stock.ID).Return(stock, nil)
+		dividendRepo.On("Create", ctx, mock.AnythingOfType("*models.DividendEvent")).Return(true, nil)
+
+		event, err := service.CreateDividendEvent(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, models.DividendPending, event.Status)
+		assert.Equal(t, stock, event.Stock)
+	})
+
+	t.Run("rejects a dividend already recorded on the ex-date", func(t *testing.T) {
+		dividendRepo := new(MockDividendRepository)
+		stockRepo := new(MockStockRepository)
+		service := NewDividendService(dividendRepo, stockRepo, nil, nil, nil, nil)
+
+		stockRepo.On("GetByID", ctx, stock.ID).Return(stock, nil)
+		dividendRepo.On("Create", ctx, mock.AnythingOfType("*models.DividendEvent")).Return(false, nil)
+
+		_, err := service.CreateDividendEvent(ctx, req)
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "ex_date", validationErr.Field)
+	})
+
+	t.Run("rejects a pay date before the ex-date", func(t *testing.T) {
+		service := NewDividendService(nil, nil, nil, nil, nil, nil)
+
+		_, err := service.CreateDividendEvent(ctx, &models.CreateDividendEventRequest{
+			StockID:        stock.ID,
+			ExDate:         "2024-07-01",
+			PayDate:        "2024-06-14",
+			AmountPerShare: decimal.NewFromFloat(0.485),
+		})
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "pay_date", validationErr.Field)
+	})
+}
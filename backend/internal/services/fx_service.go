@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/repositories"
+)
+
+// FXRateSource provides exchange rates from a market data provider
+type FXRateSource interface {
+	// GetRate returns what one unit of the from currency was worth in the to currency on a date
+	GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error)
+	// Name identifies the source rates are stored with
+	Name() string
+}
+
+// FXService defines the interface for converting between currencies at historical exchange rates
+type FXService interface {
+	GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error)
+	GetRates(ctx context.Context, currencies []string, to string, date time.Time) (map[string]decimal.Decimal, error)
+}
+
+// fxService implements the FXService interface, storing the rates fetched from its source so
+// each rate is fetched once
+type fxService struct {
+	fxRateRepo repositories.FXRateRepository
+	source     FXRateSource
+}
+
+// NewFXService creates a new FX service instance
+func NewFXService(fxRateRepo repositories.FXRateRepository, source FXRateSource) FXService {
+	return &fxService{
+		fxRateRepo: fxRateRepo,
+		source:     source,
+	}
+}
+
+// GetRate returns what one unit of the from currency was worth in the to currency on a date. A rate
+// stored for the date is used when there is one; otherwise the rate is fetched from the source and
+// stored. When the source fails, the latest rate stored before the date is used instead.
+func (s *fxService) GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error) {
+	from, to = models.CurrencyOrDefault(from), models.CurrencyOrDefault(to)
+	if from == to {
+		return decimal.NewFromInt(1), nil
+	}
+	day := truncateToDate(date)
+
+	stored, err := s.fxRateRepo.GetOnOrBefore(ctx, from, to, day)
+	if err != nil {
+		var notFound *models.NotFoundError
+		if !errors.As(err, &notFound) {
+			return decimal.Zero, err
+		}
+		stored = nil
+	}
+	if stored != nil && truncateToDate(stored.RateDate).Equal(day) {
+		return stored.Rate, nil
+	}
+
+	rate, err := s.source.GetRate(ctx, from, to, day)
+	if err == nil && rate.IsPositive() {
+		fetched := &models.FXRate{
+			BaseCurrency:  from,
+			QuoteCurrency: to,
+			RateDate:      day,
+			Rate:          rate,
+			Source:        s.source.Name(),
+			CreatedAt:     time.Now(),
+		}
+		if err := s.fxRateRepo.Save(ctx, fetched); err != nil {
+			fmt.Printf("Warning: failed to store %s/%s rate for %s: %v\n", from, to, day.Format("2006-01-02"), err)
+		}
+		return rate, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("source returned rate %s", rate)
+	}
+
+	if stored != nil {
+		fmt.Printf("Warning: failed to get %s/%s rate for %s, using rate from %s: %v\n",
+			from, to, day.Format("2006-01-02"), stored.RateDate.Format("2006-01-02"), err)
+		return stored.Rate, nil
+	}
+
+	return decimal.Zero, fmt.Errorf("no %s/%s rate available for %s: %w", from, to, day.Format("2006-01-02"), err)
+}
+
+// GetRates returns the rate converting each of the currencies to the to currency on a date
+func (s *fxService) GetRates(ctx context.Context, currencies []string, to string, date time.Time) (map[string]decimal.Decimal, error) {
+	rates := make(map[string]decimal.Decimal, len(currencies))
+	for _, currency := range currencies {
+		currency = models.CurrencyOrDefault(currency)
+		if _, exists := rates[currency]; exists {
+			continue
+		}
+
+		rate, err := s.GetRate(ctx, currency, to, date)
+		if err != nil {
+			return nil, err
+		}
+		rates[currency] = rate
+	}
+
+	return rates, nil
+}
+
+// MarketDataFXRateSource reads exchange rates from the daily closes of currency pairs quoted by a
+// market data provider, using Yahoo Finance style symbols such as EURUSD=X
+type MarketDataFXRateSource struct {
+	marketDataService MarketDataService
+}
+
+// NewMarketDataFXRateSource creates an FX rate source backed by a market data service
+func NewMarketDataFXRateSource(marketDataService MarketDataService) *MarketDataFXRateSource {
+	return &MarketDataFXRateSource{marketDataService: marketDataService}
+}
+
+// GetRate returns the close of the currency pair on or before a date
+func (s *MarketDataFXRateSource) GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error) {
+	symbol := fmt.Sprintf("%s%s=X", from, to)
+
+	bars, err := s.marketDataService.GetOHLCV(ctx, symbol, date.AddDate(0, 0, -7), date.AddDate(0, 0, 1), "1day")
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to get %s closes: %w", symbol, err)
+	}
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Timestamp.Before(bars[j].Timestamp) })
+
+	rate, ok := closeOnOrBefore(bars, date)
+	if !ok {
+		return decimal.Zero, fmt.Errorf("no %s close on or before %s", symbol, date.Format("2006-01-02"))
+	}
+
+	return rate, nil
+}
+
+// Name identifies the source rates are stored with
+func (s *MarketDataFXRateSource) Name() string {
+	return "market_data"
+}
+
+// MockFXRateSource provides mock exchange rates for testing and development
+type MockFXRateSource struct {
+	// usdRates is what one unit of each currency is worth in US dollars
+	usdRates map[string]decimal.Decimal
+}
+
+// NewMockFXRateSource creates a new mock FX rate source
+func NewMockFXRateSource() *MockFXRateSource {
+	// Initialize with some sample rates
+	return &MockFXRateSource{
+		usdRates: map[string]decimal.Decimal{
+			"USD": decimal.NewFromInt(1),
+			"EUR": decimal.NewFromFloat(1.08),
+			"GBP": decimal.NewFromFloat(1.27),
+			"CAD": decimal.NewFromFloat(0.74),
+			"CHF": decimal.NewFromFloat(1.12),
+			"JPY": decimal.NewFromFloat(0.0067),
+		},
+	}
+}
+
+// GetRate converts between currencies through their US dollar rates, whatever the date
+func (m *MockFXRateSource) GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error) {
+	fromUSD, exists := m.usdRates[strings.ToUpper(from)]
+	if !exists {
+		return decimal.Zero, fmt.Errorf("no mock rate for %s", from)
+	}
+	toUSD, exists := m.usdRates[strings.ToUpper(to)]
+	if !exists {
+		return decimal.Zero, fmt.Errorf("no mock rate for %s", to)
+	}
+
+	return fromUSD.Div(toUSD).Round(8), nil
+}
+
+// Name identifies the source rates are stored with
+func (m *MockFXRateSource) Name() string {
+	return "mock"
+}
+
+// SetRate allows setting what one unit of a currency is worth in US dollars for testing
+func (m *MockFXRateSource) SetRate(currency string, usdRate float64) {
+	m.usdRates[strings.ToUpper(currency)] = decimal.NewFromFloat(usdRate)
+}
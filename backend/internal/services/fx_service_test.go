@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"portfolio-app/internal/models"
+)
+
+// MockFXRateRepository is a mock implementation of repositories.FXRateRepository
+type MockFXRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockFXRateRepository) Save(ctx context.Context, rate *models.FXRate) error {
+	args := m.Called(ctx, rate)
+	return args.Error(0)
+}
+
+func (m *MockFXRateRepository) GetOnOrBefore(ctx context.Context, baseCurrency, quoteCurrency string, date time.Time) (*models.FXRate, error) {
+	args := m.Called(ctx, baseCurrency, quoteCurrency, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.FXRate), args.Error(1)
+}
+
+func (m *MockFXRateRepository) GetHistory(ctx context.Context, baseCurrency, quoteCurrency string, from, to time.Time) ([]*models.FXRate, error) {
+	args := m.Called(ctx, baseCurrency, quoteCurrency, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.FXRate), args.Error(1)
+}
+
+// MockFXService is a mock implementation of FXService
+type MockFXService struct {
+	mock.Mock
+}
+
+func (m *MockFXService) GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error) {
+	args := m.Called(ctx, from, to, date)
+	return args.Get(0).(decimal.Decimal), args.Error(1)
+}
+
+func (m *MockFXService) GetRates(ctx context.Context, currencies []string, to string, date time.Time) (map[string]decimal.Decimal, error) {
+	args := m.Called(ctx, currencies, to, date)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]decimal.Decimal), args.Error(1)
+}
+
+// failingFXRateSource is an FX rate source whose provider is unavailable
+type failingFXRateSource struct{}
+
+func (failingFXRateSource) GetRate(ctx context.Context, from, to string, date time.Time) (decimal.Decimal, error) {
+	return decimal.Zero, errors.New("provider unavailable")
+}
+
+func (failingFXRateSource) Name() string {
+	return "failing"
+}
+
+func TestFXService_GetRate(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+	day := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	notFound := &models.NotFoundError{Resource: "FX rate"}
+
+	t.Run("same currency converts at 1 without a lookup", func(t *testing.T) {
+		repo := new(MockFXRateRepository)
+		service := NewFXService(repo, NewMockFXRateSource())
+
+		rate, err := service.GetRate(ctx, "USD", "", date)
+
+		require.NoError(t, err)
+		assert.True(t, rate.Equal(decimal.NewFromInt(1)))
+		repo.AssertNotCalled(t, "GetOnOrBefore", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("uses the rate stored for the day", func(t *testing.T) {
+		repo := new(MockFXRateRepository)
+		repo.On("GetOnOrBefore", ctx, "EUR", "USD", day).Return(&models.FXRate{
+			BaseCurrency: "EUR", QuoteCurrency: "USD", RateDate: day, Rate: decimal.NewFromFloat(1.1), Source: "mock",
+		}, nil)
+		service := NewFXService(repo, failingFXRateSource{})
+
+		rate, err := service.GetRate(ctx, "EUR", "USD", date)
+
+		require.NoError(t, err)
+		assert.True(t, rate.Equal(decimal.NewFromFloat(1.1)))
+		repo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fetches and stores a rate not stored for the day", func(t *testing.T) {
+		repo := new(MockFXRateRepository)
+		repo.On("GetOnOrBefore", ctx, "GBP", "USD", day).Return(nil, notFound)
+		repo.On("Save", ctx, mock.MatchedBy(func(rate *models.FXRate) bool {
+			return rate.BaseCurrency == "GBP" && rate.QuoteCurrency == "USD" && rate.RateDate.Equal(day) &&
+				rate.Rate.Equal(decimal.NewFromFloat(1.27)) && rate.Source == "mock"
+		})).Return(nil)
+		service := NewFXService(repo, NewMockFXRateSource())
+
+		rate, err := service.GetRate(ctx, "GBP", "USD", date)
+
+		require.NoError(t, err)
+		assert.True(t, rate.Equal(decimal.NewFromFloat(1.27)))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("falls back to the latest stored rate when the source fails", func(t *testing.T) {
+		repo := new(MockFXRateRepository)
+		repo.On("GetOnOrBefore", ctx, "EUR", "USD", day).Return(&models.FXRate{
+			BaseCurrency: "EUR", QuoteCurrency: "USD", RateDate: day.AddDate(0, 0, -3), Rate: decimal.NewFromFloat(1.09),
+		}, nil)
+		service := NewFXService(repo, failingFXRateSource{})
+
+		rate, err := service.GetRate(ctx, "EUR", "USD", date)
+
+		require.NoError(t, err)
+		assert.True(t, rate.Equal(decimal.NewFromFloat(1.09)))
+	})
+
+	t.Run("fails without a stored or fetched rate", func(t *testing.T) {
+		repo := new(MockFXRateRepository)
+		repo.On("GetOnOrBefore", ctx, "EUR", "USD", day).Return(nil, notFound)
+		service := NewFXService(repo, failingFXRateSource{})
+
+		_, err := service.GetRate(ctx, "EUR", "USD", date)
+
+		assert.ErrorContains(t, err, "no EUR/USD rate available for 2024-03-15")
+	})
+}
+
+func TestFXService_GetRates(t *testing.T) {
+	ctx := context.Background()
+	date := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	repo := new(MockFXRateRepository)
+	repo.On("GetOnOrBefore", ctx, "EUR", "GBP", date).Return(nil, &models.NotFoundError{Resource: "FX rate"}).Once()
+	repo.On("Save", ctx, mock.Anything).Return(nil).Once()
+	service := NewFXService(repo, NewMockFXRateSource())
+
+	rates, err := service.GetRates(ctx, []string{"EUR", "GBP", "EUR"}, "GBP", date)
+
+	require.NoError(t, err)
+	assert.Len(t, rates, 2)
+	assert.True(t, rates["GBP"].Equal(decimal.NewFromInt(1)))
+	assert.True(t, rates["EUR"].Equal(decimal.NewFromFloat(1.08).Div(decimal.NewFromFloat(1.27)).Round(8)))
+	repo.AssertExpectations(t)
+}
+
+func TestMockFXRateSource_GetRate(t *testing.T) {
+	ctx := context.Background()
+	source := NewMockFXRateSource()
+
+	rate, err := source.GetRate(ctx, "usd", "EUR", time.Now())
+	require.NoError(t, err)
+	assert.True(t, rate.Equal(decimal.NewFromInt(1).Div(decimal.NewFromFloat(1.08)).Round(8)))
+
+	source.SetRate("AUD", 0.66)
+	rate, err = source.GetRate(ctx, "AUD", "USD", time.Now())
+	require.NoError(t, err)
+	assert.True(t, rate.Equal(decimal.NewFromFloat(0.66)))
+
+	_, err = source.GetRate(ctx, "XYZ", "USD", time.Now())
+	assert.Error(t, err)
+}
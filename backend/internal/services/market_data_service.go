@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -39,6 +40,13 @@ type OHLCV struct {
 	Volume    int64           `json:"volume"`
 }
 
+// Split represents a stock split reported by a market data provider: every OldShares became NewShares
+type Split struct {
+	Date      time.Time       `json:"date"`
+	NewShares decimal.Decimal `json:"new_shares"`
+	OldShares decimal.Decimal `json:"old_shares"`
+}
+
 // CircuitState represents the state of a circuit breaker
 type CircuitState int
 
@@ -113,6 +121,11 @@ type MarketDataService interface {
 	GetOHLCV(ctx context.Context, symbol string, from, to time.Time, interval string) ([]*OHLCV, error)
 }
 
+// SplitProvider is implemented by market data services that can report the stock splits of a symbol
+type SplitProvider interface {
+	GetSplits(ctx context.Context, symbol string, from, to time.Time) ([]*Split, error)
+}
+
 // ExternalMarketDataService implements MarketDataService with external API integration
 type ExternalMarketDataService struct {
 	redisClient    *redis.Client
@@ -259,6 +272,18 @@ type YahooResult struct {
 	Meta       YahooMeta       `json:"meta"`
 	Timestamp  []int64         `json:"timestamp"`
 	Indicators YahooIndicators `json:"indicators"`
+	Events     *YahooEvents    `json:"events,omitempty"`
+}
+
+type YahooEvents struct {
+	Splits map[string]YahooSplit `json:"splits"`
+}
+
+type YahooSplit struct {
+	Date        int64   `json:"date"`
+	Numerator   float64 `json:"numerator"`
+	Denominator float64 `json:"denominator"`
+	SplitRatio  string  `json:"splitRatio"`
 }
 
 type YahooMeta struct {
@@ -447,6 +472,60 @@ func (s *ExternalMarketDataService) GetOHLCV(ctx context.Context, symbol string,
 	return ohlcv, nil
 }
 
+// GetSplits retrieves the stock splits of a symbol within a date range, oldest first. Only Yahoo
+// Finance reports splits.
+func (s *ExternalMarketDataService) GetSplits(ctx context.Context, symbol string, from, to time.Time) ([]*Split, error) {
+	if s.provider != ProviderYahooFinance {
+		return nil, fmt.Errorf("split data is not available from provider %s", s.provider)
+	}
+
+	var splits []*Split
+	err := s.circuitBreaker.Call(func() error {
+		var fetchErr error
+		splits, fetchErr = s.fetchYahooFinanceSplits(ctx, symbol, from, to)
+		return fetchErr
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch splits for %s: %w", symbol, err)
+	}
+
+	return splits, nil
+}
+
+// fetchYahooFinanceSplits fetches split events from Yahoo Finance API
+func (s *ExternalMarketDataService) fetchYahooFinanceSplits(ctx context.Context, symbol string, from, to time.Time) ([]*Split, error) {
+	url := fmt.Sprintf("%s/%s?interval=1d&period1=%d&period2=%d&events=split",
+		s.baseURL, symbol, from.Unix(), to.Unix())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; PortfolioApp/1.0)")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var apiResp YahooFinanceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, err
+	}
+
+	if len(apiResp.Chart.Result) == 0 {
+		return nil, fmt.Errorf("no data found for symbol %s", symbol)
+	}
+
+	return s.convertYahooFinanceToSplits(&apiResp.Chart.Result[0]), nil
+}
+
 // fetchQuoteFromAPI fetches a quote from the external API
 func (s *ExternalMarketDataService) fetchQuoteFromAPI(ctx context.Context, symbol string) (*Quote, error) {
 	switch s.provider {
@@ -925,9 +1004,33 @@ func (s *ExternalMarketDataService) convertYahooFinanceToOHLCV(result *YahooResu
 	return ohlcvData, nil
 }
 
+// convertYahooFinanceToSplits converts Yahoo Finance split events to splits ordered by date
+func (s *ExternalMarketDataService) convertYahooFinanceToSplits(result *YahooResult) []*Split {
+	var splits []*Split
+	if result.Events == nil {
+		return splits
+	}
+
+	for _, event := range result.Events.Splits {
+		if event.Numerator <= 0 || event.Denominator <= 0 {
+			continue
+		}
+		splits = append(splits, &Split{
+			Date:      time.Unix(event.Date, 0).UTC(),
+			NewShares: decimal.NewFromFloat(event.Numerator),
+			OldShares: decimal.NewFromFloat(event.Denominator),
+		})
+	}
+
+	sort.Slice(splits, func(i, j int) bool { return splits[i].Date.Before(splits[j].Date) })
+	return splits
+}
+
 // MockMarketDataService provides mock market data for testing and development
 type MockMarketDataService struct {
 	quotes map[string]*Quote
+	ohlcv  map[string][]*OHLCV
+	splits map[string][]*Split
 }
 
 // NewMockMarketDataService creates a new mock market data service
@@ -998,6 +1101,8 @@ func NewMockMarketDataService() *MockMarketDataService {
 
 	return &MockMarketDataService{
 		quotes: quotes,
+		ohlcv:  make(map[string][]*OHLCV),
+		splits: make(map[string][]*Split),
 	}
 }
 
@@ -1050,6 +1155,17 @@ func (m *MockMarketDataService) GetQuotesByStockIDs(ctx context.Context, stockID
 
 // GetOHLCV retrieves historical OHLCV data (mock implementation)
 func (m *MockMarketDataService) GetOHLCV(ctx context.Context, symbol string, from, to time.Time, interval string) ([]*OHLCV, error) {
+	// Return the bars set for testing when there are any
+	if bars, exists := m.ohlcv[symbol]; exists {
+		var result []*OHLCV
+		for _, bar := range bars {
+			if !bar.Timestamp.Before(from) && !bar.Timestamp.After(to) {
+				result = append(result, bar)
+			}
+		}
+		return result, nil
+	}
+	
 	// Generate mock historical data
 	var result []*OHLCV
 	current := from
@@ -1123,6 +1239,27 @@ func (m *MockMarketDataService) SetQuote(symbol string, quote *Quote) {
 	m.quotes[symbol] = quote
 }
 
+// GetSplits retrieves the splits set for a symbol within a date range
+func (m *MockMarketDataService) GetSplits(ctx context.Context, symbol string, from, to time.Time) ([]*Split, error) {
+	var splits []*Split
+	for _, split := range m.splits[symbol] {
+		if !split.Date.Before(from) && !split.Date.After(to) {
+			splits = append(splits, split)
+		}
+	}
+	return splits, nil
+}
+
+// SetSplits allows setting custom splits for testing, ordered from oldest to newest
+func (m *MockMarketDataService) SetSplits(symbol string, splits []*Split) {
+	m.splits[symbol] = splits
+}
+
+// SetOHLCV allows setting custom historical bars for testing, ordered from oldest to newest
+func (m *MockMarketDataService) SetOHLCV(symbol string, bars []*OHLCV) {
+	m.ohlcv[symbol] = bars
+}
+
 // AddQuote adds a new quote to the mock service
 func (m *MockMarketDataService) AddQuote(symbol string, price float64) {
 	m.quotes[symbol] = &Quote{
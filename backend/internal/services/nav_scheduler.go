@@ -27,6 +27,8 @@ type NAVScheduler struct {
 	maxRetries       int
 	retryDelay       time.Duration
 	batchSize        int
+	snapshotFullResolution time.Duration
+	snapshotRetention      time.Duration
 	
 	// Metrics
 	lastUpdateTime   time.Time
@@ -42,6 +44,11 @@ type NAVSchedulerConfig struct {
 	RetryDelay      time.Duration // Delay between retries (default: 30 seconds)
 	BatchSize       int           // Number of portfolios to process in parallel (default: 10)
 	CronExpression  string        // Cron expression for scheduling (default: "*/15 * * * *")
+	
+	// Position snapshot retention
+	SnapshotFullResolution time.Duration // Keep every snapshot this long, then one per day (default: 7 days)
+	SnapshotRetention      time.Duration // Delete snapshots older than this (default: 2 years)
+	SnapshotPruneCron      string        // Cron expression for pruning snapshots (default: daily at 03:30)
 }
 
 // DefaultNAVSchedulerConfig returns default configuration
@@ -52,6 +59,9 @@ func DefaultNAVSchedulerConfig() *NAVSchedulerConfig {
 		RetryDelay:     30 * time.Second,
 		BatchSize:      10,
 		CronExpression: "0 */15 * * * *", // Every 15 minutes (with seconds field)
+		SnapshotFullResolution: 7 * 24 * time.Hour,
+		SnapshotRetention:      730 * 24 * time.Hour,
+		SnapshotPruneCron:      "0 30 3 * * *", // Daily at 03:30
 	}
 }
 
@@ -73,6 +83,8 @@ func NewNAVScheduler(portfolioService PortfolioServiceInterface, portfolioRepo P
 		maxRetries:       config.MaxRetries,
 		retryDelay:       config.RetryDelay,
 		batchSize:        config.BatchSize,
+		snapshotFullResolution: config.SnapshotFullResolution,
+		snapshotRetention:      config.SnapshotRetention,
 	}
 	
 	// Add cron job for NAV updates
@@ -81,6 +93,13 @@ func NewNAVScheduler(portfolioService PortfolioServiceInterface, portfolioRepo P
 		log.Printf("Failed to add NAV update cron job: %v", err)
 	}
 	
+	// Add cron job for position snapshot retention
+	if config.SnapshotPruneCron != "" {
+		if _, err := scheduler.cron.AddFunc(config.SnapshotPruneCron, scheduler.schedulePositionSnapshotPrune); err != nil {
+			log.Printf("Failed to add position snapshot prune cron job: %v", err)
+		}
+	}
+	
 	return scheduler
 }
 
@@ -279,6 +298,9 @@ func (s *NAVScheduler) processBatch(portfolioIDs []uuid.UUID) []error {
 			defer wg.Done()
 			
 			err := s.updatePortfolioNAVWithRetry(id)
+			if err == nil {
+				s.evaluateRebalancePolicy(id)
+			}
 			
 			mu.Lock()
 			if err != nil {
@@ -336,6 +358,62 @@ func (s *NAVScheduler) updatePortfolioNAVWithRetry(portfolioID uuid.UUID) error
 	return fmt.Errorf("failed after %d attempts: %w", s.maxRetries+1, lastErr)
 }
 
+// evaluateRebalancePolicy checks a portfolio's rebalance policy against its freshly updated NAV.
+// Failures are logged only, so they never count as NAV update errors.
+func (s *NAVScheduler) evaluateRebalancePolicy(portfolioID uuid.UUID) {
+	run, err := s.portfolioService.EvaluateRebalancePolicy(s.ctx, portfolioID)
+	if err != nil {
+		log.Printf("Portfolio %s rebalance policy evaluation failed: %v", portfolioID, err)
+		return
+	}
+	
+	if run != nil {
+		log.Printf("Portfolio %s rebalance triggered by %s policy (max drift %s pp): %s",
+			portfolioID, run.TriggerType, run.MaxDrift.String(), run.Status)
+	}
+}
+
+// schedulePositionSnapshotPrune is called by the cron scheduler to apply position snapshot retention
+func (s *NAVScheduler) schedulePositionSnapshotPrune() {
+	s.mu.RLock()
+	running := s.running
+	s.mu.RUnlock()
+	if !running {
+		return
+	}
+	
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		
+		if err := s.PrunePositionSnapshots(time.Now()); err != nil {
+			log.Printf("Position snapshot prune failed: %v", err)
+		}
+	}()
+}
+
+// PrunePositionSnapshots downsamples position snapshots older than the full resolution window to one
+// per day and deletes those past the retention period
+func (s *NAVScheduler) PrunePositionSnapshots(now time.Time) error {
+	// A zero duration disables that step
+	downsampleBefore := time.Time{}
+	if s.snapshotFullResolution > 0 {
+		downsampleBefore = now.Add(-s.snapshotFullResolution)
+	}
+	deleteBefore := time.Time{}
+	if s.snapshotRetention > 0 {
+		deleteBefore = now.Add(-s.snapshotRetention)
+	}
+	
+	deleted, err := s.portfolioRepo.PrunePositionSnapshots(s.ctx, downsampleBefore, deleteBefore)
+	if err != nil {
+		return fmt.Errorf("failed to prune position snapshots: %w", err)
+	}
+	
+	log.Printf("Pruned %d position snapshots", deleted)
+	return nil
+}
+
 // ForceUpdate triggers an immediate NAV update for all portfolios
 func (s *NAVScheduler) ForceUpdate() error {
 	s.mu.RLock()
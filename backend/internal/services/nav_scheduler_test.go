@@ -93,20 +93,52 @@ func (m *MockPortfolioServiceInterface) GetPortfolioHistory(ctx context.Context,
 	return args.Get(0).([]*models.NAVHistory), args.Error(1)
 }
 
-func (m *MockPortfolioServiceInterface) GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID) (*models.PerformanceMetrics, error) {
-	args := m.Called(ctx, portfolioID)
+func (m *MockPortfolioServiceInterface) GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID, riskFreeRatePct decimal.Decimal, period models.ReturnPeriod) (*models.PerformanceMetrics, error) {
+	args := m.Called(ctx, portfolioID, riskFreeRatePct, period)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*models.PerformanceMetrics), args.Error(1)
 }
 
-func (m *MockPortfolioServiceInterface) GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.AllocationPreview, error) {
+func (m *MockPortfolioServiceInterface) GetBenchmarkHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) (*models.BenchmarkHistory, error) {
+	args := m.Called(ctx, portfolioID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BenchmarkHistory), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetPositionHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]models.PositionHistory, error) {
+	args := m.Called(ctx, portfolioID, from, to, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PositionHistory), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetPortfolioAttribution(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, benchmark []models.BenchmarkSectorWeight) (*models.PortfolioAttribution, error) {
+	args := m.Called(ctx, portfolioID, from, to, benchmark)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAttribution), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetTaxReport(ctx context.Context, portfolioID uuid.UUID, year int) (*models.TaxReport, error) {
+	args := m.Called(ctx, portfolioID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.TaxReport), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.RebalancePlan, error) {
 	args := m.Called(ctx, portfolioID, newTotalInvestment)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(*models.AllocationPreview), args.Error(1)
+	return args.Get(0).(*models.RebalancePlan), args.Error(1)
 }
 
 func (m *MockPortfolioServiceInterface) RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.Portfolio, error) {
@@ -117,6 +149,86 @@ func (m *MockPortfolioServiceInterface) RebalancePortfolio(ctx context.Context,
 	return args.Get(0).(*models.Portfolio), args.Error(1)
 }
 
+func (m *MockPortfolioServiceInterface) RecordTransaction(ctx context.Context, portfolioID uuid.UUID, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	args := m.Called(ctx, portfolioID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Transaction), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetTransactions(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	args := m.Called(ctx, portfolioID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAllocationConfig), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) UpdateAllocationConfig(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateAllocationConfigRequest) (*models.PortfolioAllocationConfig, error) {
+	args := m.Called(ctx, portfolioID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAllocationConfig), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePolicy), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) UpdateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateRebalancePolicyRequest) (*models.RebalancePolicy, error) {
+	args := m.Called(ctx, portfolioID, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePolicy), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) EvaluateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) ApproveRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioServiceInterface) RejectRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID, runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
 func TestNewNAVScheduler(t *testing.T) {
 	mockPortfolioService := &MockPortfolioServiceInterface{}
 	mockPortfolioRepo := &MockPortfolioRepository{}
@@ -329,6 +441,7 @@ func TestNAVScheduler_ForceUpdate_Success(t *testing.T) {
 
 	for _, portfolioID := range portfolioIDs {
 		mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioID).Return(expectedNAV, nil)
+		mockPortfolioService.On("EvaluateRebalancePolicy", mock.AnythingOfType("*context.cancelCtx"), portfolioID).Return(nil, nil)
 	}
 
 	// Execute
@@ -372,6 +485,7 @@ func TestNAVScheduler_ProcessBatches(t *testing.T) {
 	// Setup expectations for all portfolios
 	for _, portfolioID := range portfolioIDs {
 		mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioID).Return(expectedNAV, nil)
+		mockPortfolioService.On("EvaluateRebalancePolicy", mock.AnythingOfType("*context.cancelCtx"), portfolioID).Return(nil, nil)
 	}
 
 	// Execute
@@ -416,6 +530,8 @@ func TestNAVScheduler_ProcessBatches_WithErrors(t *testing.T) {
 	mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[0]).Return(expectedNAV, nil)
 	mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[1]).Return(nil, assert.AnError)
 	mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[2]).Return(expectedNAV, nil)
+	mockPortfolioService.On("EvaluateRebalancePolicy", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[0]).Return(nil, nil)
+	mockPortfolioService.On("EvaluateRebalancePolicy", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[2]).Return(nil, nil)
 
 	// Execute
 	err := scheduler.processBatches(portfolioIDs)
@@ -431,6 +547,56 @@ func TestNAVScheduler_ProcessBatches_WithErrors(t *testing.T) {
 	assert.Equal(t, int64(1), metrics["error_count"])
 }
 
+func TestNAVScheduler_ProcessBatches_RebalanceEvaluation(t *testing.T) {
+	mockPortfolioService := &MockPortfolioServiceInterface{}
+	mockPortfolioRepo := &MockPortfolioRepository{}
+
+	config := &NAVSchedulerConfig{
+		UpdateInterval: 15 * time.Minute,
+		MaxRetries:     0,
+		RetryDelay:     10 * time.Millisecond,
+		BatchSize:      2,
+		CronExpression: "0 */15 * * * *",
+	}
+
+	scheduler := NewNAVScheduler(mockPortfolioService, mockPortfolioRepo, config)
+
+	portfolioIDs := []uuid.UUID{uuid.New(), uuid.New()}
+
+	expectedNAV := &models.NAVHistory{
+		PortfolioID: uuid.New(),
+		Timestamp:   time.Now(),
+		NAV:         decimal.NewFromFloat(15000.00),
+		PnL:         decimal.NewFromFloat(5000.00),
+		CreatedAt:   time.Now(),
+	}
+
+	triggeredRun := &models.RebalanceRun{
+		ID:          uuid.New(),
+		PortfolioID: portfolioIDs[0],
+		TriggerType: models.RebalanceTriggerThreshold,
+		Status:      models.RebalanceRunPending,
+		MaxDrift:    decimal.NewFromFloat(7.5),
+	}
+
+	// Setup expectations - one policy triggers, the other fails to evaluate
+	mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[0]).Return(expectedNAV, nil)
+	mockPortfolioService.On("UpdatePortfolioNAV", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[1]).Return(expectedNAV, nil)
+	mockPortfolioService.On("EvaluateRebalancePolicy", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[0]).Return(triggeredRun, nil)
+	mockPortfolioService.On("EvaluateRebalancePolicy", mock.AnythingOfType("*context.cancelCtx"), portfolioIDs[1]).Return(nil, assert.AnError)
+
+	// Execute
+	err := scheduler.processBatches(portfolioIDs)
+
+	// Assert - policy evaluation failures do not count as NAV update errors
+	require.NoError(t, err)
+	mockPortfolioService.AssertExpectations(t)
+
+	metrics := scheduler.GetMetrics()
+	assert.Equal(t, int64(2), metrics["success_count"])
+	assert.Equal(t, int64(0), metrics["error_count"])
+}
+
 func TestDefaultNAVSchedulerConfig(t *testing.T) {
 	config := DefaultNAVSchedulerConfig()
 
@@ -440,6 +606,23 @@ func TestDefaultNAVSchedulerConfig(t *testing.T) {
 	assert.Equal(t, 30*time.Second, config.RetryDelay)
 	assert.Equal(t, 10, config.BatchSize)
 	assert.Equal(t, "0 */15 * * * *", config.CronExpression)
+	assert.Equal(t, 7*24*time.Hour, config.SnapshotFullResolution)
+	assert.Equal(t, 730*24*time.Hour, config.SnapshotRetention)
+}
+
+func TestNAVScheduler_PrunePositionSnapshots(t *testing.T) {
+	mockPortfolioService := &MockPortfolioServiceInterface{}
+	mockPortfolioRepo := &MockPortfolioRepository{}
+
+	scheduler := NewNAVScheduler(mockPortfolioService, mockPortfolioRepo, nil)
+	now := time.Date(2024, 6, 30, 3, 30, 0, 0, time.UTC)
+
+	mockPortfolioRepo.On("PrunePositionSnapshots", mock.Anything, now.AddDate(0, 0, -7), now.AddDate(0, 0, -730)).Return(int64(12), nil)
+
+	err := scheduler.PrunePositionSnapshots(now)
+
+	require.NoError(t, err)
+	mockPortfolioRepo.AssertExpectations(t)
 }
 
 func TestNAVScheduler_GetAllPortfolioIDs_Error(t *testing.T) {
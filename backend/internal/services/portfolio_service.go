@@ -3,7 +3,9 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -30,7 +32,33 @@ type PortfolioRepository interface {
 	GetNAVHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.NAVHistory, error)
 	GetLatestNAV(ctx context.Context, portfolioID uuid.UUID) (*models.NAVHistory, error)
 	
+	GetPositionSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]*models.PositionSnapshot, error)
+	PrunePositionSnapshots(ctx context.Context, downsampleBefore, deleteBefore time.Time) (int64, error)
+	
+	GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error)
+	SaveAllocationConfig(ctx context.Context, config *models.PortfolioAllocationConfig) error
+	
 	CreatePortfolioWithPositions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position) error
+	CreatePortfolioWithTransactions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position, transactions []*models.Transaction) error
+	ExecuteRebalance(ctx context.Context, portfolio *models.Portfolio, transactions []*models.Transaction, positions []*models.Position, closedStockIDs []uuid.UUID) error
+	
+	GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error)
+	SaveRebalancePolicy(ctx context.Context, policy *models.RebalancePolicy) error
+	CreateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error
+	GetRebalanceRun(ctx context.Context, id uuid.UUID) (*models.RebalanceRun, error)
+	GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error)
+	UpdateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error
+}
+
+// TransactionRepository interface for portfolio ledger access
+type TransactionRepository interface {
+	GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error)
+	AppendTransaction(ctx context.Context, portfolioID uuid.UUID, transaction *models.Transaction, apply func(entries []*models.Transaction) (*models.LedgerUpdate, error)) error
+}
+
+// CorporateActionRepository interface for the stock splits used to price historical holdings
+type CorporateActionRepository interface {
+	GetByStockID(ctx context.Context, stockID uuid.UUID) ([]*models.CorporateAction, error)
 }
 
 // PortfolioService handles portfolio-related operations
@@ -38,7 +66,11 @@ type PortfolioService struct {
 	allocationEngine AllocationEngineInterface
 	strategyRepo     StrategyRepository
 	portfolioRepo    PortfolioRepository
+	transactionRepo  TransactionRepository
 	marketDataService MarketDataService
+	corporateActionRepo CorporateActionRepository
+	fxService        FXService
+	stockRepo        StockRepository
 }
 
 // PortfolioServiceInterface defines the portfolio service contract
@@ -58,11 +90,31 @@ type PortfolioServiceInterface interface {
 	// Portfolio performance operations
 	UpdatePortfolioNAV(ctx context.Context, portfolioID uuid.UUID) (*models.NAVHistory, error)
 	GetPortfolioHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.NAVHistory, error)
-	GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID) (*models.PerformanceMetrics, error)
+	GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID, riskFreeRatePct decimal.Decimal, period models.ReturnPeriod) (*models.PerformanceMetrics, error)
+	GetBenchmarkHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) (*models.BenchmarkHistory, error)
+	GetPositionHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]models.PositionHistory, error)
+	GetPortfolioAttribution(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, benchmark []models.BenchmarkSectorWeight) (*models.PortfolioAttribution, error)
+	GetTaxReport(ctx context.Context, portfolioID uuid.UUID, year int) (*models.TaxReport, error)
 	
 	// Portfolio rebalancing operations
-	GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.AllocationPreview, error)
+	GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.RebalancePlan, error)
 	RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.Portfolio, error)
+	
+	// Portfolio ledger operations
+	RecordTransaction(ctx context.Context, portfolioID uuid.UUID, req *models.CreateTransactionRequest) (*models.Transaction, error)
+	GetTransactions(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error)
+	
+	// Stored allocation configuration operations
+	GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error)
+	UpdateAllocationConfig(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateAllocationConfigRequest) (*models.PortfolioAllocationConfig, error)
+	
+	// Rebalance policy operations
+	GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error)
+	UpdateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateRebalancePolicyRequest) (*models.RebalancePolicy, error)
+	EvaluateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalanceRun, error)
+	GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error)
+	ApproveRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error)
+	RejectRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error)
 }
 
 // NewPortfolioService creates a new portfolio service
@@ -70,13 +122,21 @@ func NewPortfolioService(
 	allocationEngine AllocationEngineInterface,
 	strategyRepo StrategyRepository,
 	portfolioRepo PortfolioRepository,
+	transactionRepo TransactionRepository,
 	marketDataService MarketDataService,
+	corporateActionRepo CorporateActionRepository,
+	fxService FXService,
+	stockRepo StockRepository,
 ) *PortfolioService {
 	return &PortfolioService{
-		allocationEngine:  allocationEngine,
-		strategyRepo:      strategyRepo,
-		portfolioRepo:     portfolioRepo,
-		marketDataService: marketDataService,
+		allocationEngine:    allocationEngine,
+		strategyRepo:        strategyRepo,
+		portfolioRepo:       portfolioRepo,
+		transactionRepo:     transactionRepo,
+		marketDataService:   marketDataService,
+		corporateActionRepo: corporateActionRepo,
+		fxService:           fxService,
+		stockRepo:           stockRepo,
 	}
 }
 
@@ -149,6 +209,10 @@ func (s *PortfolioService) ValidateAllocationRequest(req *models.AllocationReque
 		return fmt.Errorf("minimum allocation amount cannot be negative")
 	}
 
+	if !req.StrongBuyMultiplier.IsZero() && req.StrongBuyMultiplier.LessThan(decimal.NewFromInt(1)) {
+		return fmt.Errorf("strong buy multiplier must be at least 1")
+	}
+
 	return nil
 }
 
@@ -175,18 +239,53 @@ func (s *PortfolioService) CreatePortfolio(ctx context.Context, req *models.Crea
 	portfolio := &models.Portfolio{}
 	portfolio.FromCreateRequest(req, userID)
 	
-	// Create position entities
-	positions := make([]*models.Position, len(req.Positions))
+	// Keep the allocation request for faithful rebalancing
+	if req.AllocationRequest != nil {
+		allocationReq := *req.AllocationRequest
+		allocationReq.TotalInvestment = req.TotalInvestment
+		allocationReq.BaseCurrency = portfolio.BaseCurrency
+		if err := s.ValidateAllocationRequest(&allocationReq); err != nil {
+			return nil, fmt.Errorf("invalid allocation request: %w", err)
+		}
+		portfolio.AllocationConfig = models.NewPortfolioAllocationConfig(portfolio.ID, &allocationReq)
+	}
+	
+	// Opening ledger: the initial deposit followed by a buy for each position
+	transactions := make([]*models.Transaction, 0, len(req.Positions)+1)
+	transactions = append(transactions, models.NewCashTransaction(portfolio.ID, models.TransactionDeposit, req.TotalInvestment, portfolio.CreatedAt))
+	
+	contribs := make(map[uuid.UUID]map[string]decimal.Decimal)
 	for i, posReq := range req.Positions {
-		position := &models.Position{}
-		if err := position.FromCreateRequest(&posReq, portfolio.ID); err != nil {
-			return nil, fmt.Errorf("failed to create position %d: %w", i, err)
+		if !posReq.Quantity.IsPositive() || posReq.EntryPrice.LessThanOrEqual(decimal.Zero) {
+			return nil, fmt.Errorf("position %d must have a positive quantity and entry price", i)
 		}
-		positions[i] = position
+		if !posReq.Quantity.Equal(posReq.Quantity.RoundFloor(portfolio.ShareDecimals())) {
+			return nil, fmt.Errorf("position %d quantity %s exceeds the portfolio's share precision of %d decimal places", i, posReq.Quantity, portfolio.ShareDecimals())
+		}
+		buy := models.NewTradeTransaction(portfolio.ID, posReq.StockID, models.TransactionBuy, posReq.Quantity, posReq.EntryPrice, portfolio.CreatedAt)
+		if posReq.FXRate != nil {
+			buy.SetFXRate(*posReq.FXRate)
+		}
+		transactions = append(transactions, buy)
+		contribs[posReq.StockID] = posReq.StrategyContrib
+	}
+	
+	// Derive positions from the ledger
+	ledger, err := models.BuildLedger(transactions, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+	
+	positions, err := positionsFromLedger(portfolio.ID, ledger, contribs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive positions: %w", err)
 	}
 	
-	// Create portfolio with positions in transaction
-	if err := s.portfolioRepo.CreatePortfolioWithPositions(ctx, portfolio, positions); err != nil {
+	// Whatever was not invested in positions stays in the portfolio as cash
+	portfolio.CashBalance = ledger.Cash
+	
+	// Create portfolio with ledger and positions in transaction
+	if err := s.portfolioRepo.CreatePortfolioWithTransactions(ctx, portfolio, positions, transactions); err != nil {
 		return nil, fmt.Errorf("failed to create portfolio: %w", err)
 	}
 	
@@ -207,11 +306,17 @@ func (s *PortfolioService) GetPortfolio(ctx context.Context, id uuid.UUID) (*mod
 	}
 	
 	// Enrich positions with current market data
-	if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions); err != nil {
+	if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions, portfolio.BaseCurrency); err != nil {
 		// Log error but don't fail - return portfolio with stale data
 		fmt.Printf("Warning: failed to enrich positions with market data: %v\n", err)
 	}
 	
+	// Break positions down into tax lots with realized and unrealized gains
+	if err := s.enrichPositionsWithLots(ctx, portfolio); err != nil {
+		// Log error but don't fail - return portfolio without lots
+		fmt.Printf("Warning: failed to enrich positions with tax lots: %v\n", err)
+	}
+	
 	return portfolio, nil
 }
 
@@ -224,7 +329,7 @@ func (s *PortfolioService) GetUserPortfolios(ctx context.Context, userID uuid.UU
 	
 	// Enrich all portfolios with current market data
 	for _, portfolio := range portfolios {
-		if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions); err != nil {
+		if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions, portfolio.BaseCurrency); err != nil {
 			// Log error but continue with other portfolios
 			fmt.Printf("Warning: failed to enrich portfolio %s with market data: %v\n", portfolio.ID, err)
 		}
@@ -241,6 +346,25 @@ func (s *PortfolioService) UpdatePortfolio(ctx context.Context, id uuid.UUID, re
 		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
 	
+	// A changed total investment is a deposit or withdrawal, recorded in the ledger so returns can account for it
+	if req.TotalInvestment != nil && !req.TotalInvestment.Equal(portfolio.TotalInvestment) {
+		change := req.TotalInvestment.Sub(portfolio.TotalInvestment)
+		flow := &models.CreateTransactionRequest{Type: models.TransactionDeposit, Amount: change}
+		if change.IsNegative() {
+			flow = &models.CreateTransactionRequest{Type: models.TransactionWithdrawal, Amount: change.Abs()}
+		}
+		
+		if _, err := s.RecordTransaction(ctx, id, flow); err != nil {
+			return nil, fmt.Errorf("failed to record capital change: %w", err)
+		}
+		
+		// Recording the flow updated the total investment and cash balance
+		portfolio, err = s.portfolioRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get portfolio: %w", err)
+		}
+	}
+	
 	// Apply updates
 	portfolio.ApplyUpdate(req)
 	
@@ -270,14 +394,31 @@ func (s *PortfolioService) UpdatePortfolioNAV(ctx context.Context, portfolioID u
 		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
 	
+	// Gains realized by sells and dividends received to date
+	ledger, err := s.buildPortfolioLedger(ctx, portfolio)
+	if err != nil {
+		return nil, err
+	}
+	realizedPnL := decimal.Zero
+	for _, gain := range ledger.Realized {
+		realizedPnL = realizedPnL.Add(gain.Gain)
+	}
+	realizedPnL = realizedPnL.Round(2)
+	dividendIncome := ledger.Dividends.Round(2)
+	
 	if len(portfolio.Positions) == 0 {
-		// Portfolio has no positions, NAV equals cash (total investment)
+		// Portfolio has no positions, NAV equals cash balance
 		navHistory := &models.NAVHistory{
-			PortfolioID: portfolioID,
-			Timestamp:   time.Now(),
-			NAV:         portfolio.TotalInvestment,
-			PnL:         decimal.Zero,
-			CreatedAt:   time.Now(),
+			PortfolioID:   portfolioID,
+			Timestamp:     time.Now(),
+			NAV:           portfolio.CashBalance,
+			PnL:           decimal.Zero,
+			Cash:          portfolio.CashBalance,
+			RealizedPnL:   realizedPnL,
+			UnrealizedPnL: decimal.Zero,
+			DividendIncome: dividendIncome,
+			FXPnL:         decimal.Zero,
+			CreatedAt:     time.Now(),
 		}
 		
 		// Calculate drawdown from high water mark
@@ -293,13 +434,14 @@ func (s *PortfolioService) UpdatePortfolioNAV(ctx context.Context, portfolioID u
 	}
 	
 	// Get current market prices for all positions
-	if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions); err != nil {
+	if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions, portfolio.BaseCurrency); err != nil {
 		return nil, fmt.Errorf("failed to get current market prices: %w", err)
 	}
 	
-	// Calculate current NAV
-	currentNAV := decimal.Zero
+	// Calculate current NAV, starting from the uninvested cash
+	currentNAV := portfolio.CashBalance
 	totalPnL := decimal.Zero
+	fxPnL := decimal.Zero
 	
 	for _, position := range portfolio.Positions {
 		if position.CurrentValue != nil {
@@ -312,17 +454,29 @@ func (s *PortfolioService) UpdatePortfolioNAV(ctx context.Context, portfolioID u
 		if position.PnL != nil {
 			totalPnL = totalPnL.Add(*position.PnL)
 		}
+		// Part of the P&L of foreign holdings due to exchange rate moves
+		if position.FXPnL != nil {
+			fxPnL = fxPnL.Add(*position.FXPnL)
+		}
 	}
 	
 	// Create NAV history entry
 	navHistory := &models.NAVHistory{
-		PortfolioID: portfolioID,
-		Timestamp:   time.Now(),
-		NAV:         currentNAV,
-		PnL:         totalPnL,
-		CreatedAt:   time.Now(),
+		PortfolioID:   portfolioID,
+		Timestamp:     time.Now(),
+		NAV:           currentNAV,
+		PnL:           totalPnL,
+		Cash:          portfolio.CashBalance,
+		RealizedPnL:   realizedPnL,
+		UnrealizedPnL: totalPnL.Round(2),
+		DividendIncome: dividendIncome,
+		FXPnL:         fxPnL.Round(2),
+		CreatedAt:     time.Now(),
 	}
 	
+	// Snapshot the holdings behind the NAV
+	navHistory.Positions = models.NewPositionSnapshots(navHistory, portfolio.Positions)
+	
 	// Calculate drawdown from high water mark
 	if err := s.calculateDrawdown(ctx, navHistory); err != nil {
 		return nil, fmt.Errorf("failed to calculate drawdown: %w", err)
@@ -346,8 +500,21 @@ func (s *PortfolioService) GetPortfolioHistory(ctx context.Context, portfolioID
 	return history, nil
 }
 
-// GetPortfolioPerformanceMetrics calculates performance metrics for a portfolio
-func (s *PortfolioService) GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID) (*models.PerformanceMetrics, error) {
+// GetPositionHistory retrieves the value of each holding of a portfolio over a date range from the
+// position snapshots taken with NAV updates, optionally for a single stock
+func (s *PortfolioService) GetPositionHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]models.PositionHistory, error) {
+	snapshots, err := s.portfolioRepo.GetPositionSnapshots(ctx, portfolioID, from, to, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position history: %w", err)
+	}
+	
+	return models.GroupPositionSnapshots(snapshots), nil
+}
+
+// GetPortfolioPerformanceMetrics calculates performance metrics for a portfolio, using the annual
+// risk-free rate in percent for risk-adjusted returns. Time- and money-weighted returns over the
+// period account for the deposits and withdrawals recorded in the ledger.
+func (s *PortfolioService) GetPortfolioPerformanceMetrics(ctx context.Context, portfolioID uuid.UUID, riskFreeRatePct decimal.Decimal, period models.ReturnPeriod) (*models.PerformanceMetrics, error) {
 	// Get portfolio for initial investment
 	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
 	if err != nil {
@@ -361,7 +528,7 @@ func (s *PortfolioService) GetPortfolioPerformanceMetrics(ctx context.Context, p
 	}
 	
 	if len(history) == 0 {
-		return &models.PerformanceMetrics{}, nil
+		return &models.PerformanceMetrics{RiskFreeRatePct: riskFreeRatePct}, nil
 	}
 	
 	// Convert to slice of values for calculation
@@ -370,250 +537,1233 @@ func (s *PortfolioService) GetPortfolioPerformanceMetrics(ctx context.Context, p
 		historyValues[i] = *h
 	}
 	
+	// Deposits and withdrawals are needed to separate investment performance from capital changes
+	now := time.Now()
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, time.Time{}, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
+	}
+	flows := models.ExternalCashFlows(transactions)
+	
 	// Calculate performance metrics
-	metrics := models.CalculatePerformanceMetrics(historyValues, portfolio.TotalInvestment)
+	metrics := models.CalculatePerformanceMetrics(historyValues, flows, portfolio.TotalInvestment, riskFreeRatePct)
+	metrics.Returns = models.CalculatePeriodReturns(period, historyValues, flows, period.Start(now), now)
+	
+	// Dividend income is reported separately from the price return it is part of
+	ledger, err := models.BuildLedger(transactions, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+	metrics.DividendIncome = ledger.Dividends.Round(2)
+	if portfolio.TotalInvestment.IsPositive() {
+		metrics.DividendReturnPct = metrics.DividendIncome.Div(portfolio.TotalInvestment).Mul(decimal.NewFromInt(100)).Round(4)
+	}
+	
+	// Compare against the benchmark when one is set
+	if portfolio.BenchmarkSymbol != nil {
+		bars, err := s.getDailyBars(ctx, *portfolio.BenchmarkSymbol, history[0].Timestamp, now)
+		if err != nil {
+			// Log error but don't fail - return metrics without the comparison
+			fmt.Printf("Warning: failed to get benchmark %s for portfolio %s: %v\n", *portfolio.BenchmarkSymbol, portfolioID, err)
+		} else {
+			closes := make(map[time.Time]decimal.Decimal, len(bars))
+			for _, bar := range bars {
+				closes[truncateToDate(bar.Timestamp)] = bar.Close
+			}
+			metrics.Benchmark = models.CalculateBenchmarkMetrics(*portfolio.BenchmarkSymbol, historyValues, flows, closes, riskFreeRatePct)
+		}
+	}
 	
 	return metrics, nil
 }
 
-// GenerateRebalancePreview generates a preview of how a portfolio would be rebalanced
-func (s *PortfolioService) GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.AllocationPreview, error) {
-	// Get portfolio to extract original strategy configuration
+// GetBenchmarkHistory retrieves the daily closes of a portfolio's benchmark over a date range, scaled to
+// the portfolio's first NAV in the range. It returns nil when the portfolio has no benchmark.
+func (s *PortfolioService) GetBenchmarkHistory(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) (*models.BenchmarkHistory, error) {
 	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
 	
-	if len(portfolio.Positions) == 0 {
-		return nil, fmt.Errorf("portfolio has no positions to rebalance")
+	if portfolio.BenchmarkSymbol == nil {
+		return nil, nil
 	}
+	symbol := *portfolio.BenchmarkSymbol
 	
-	// Extract strategy IDs from positions (this is a simplified approach)
-	// In a real implementation, you might want to store the original allocation request
-	strategyIDs := make(map[uuid.UUID]bool)
-	for _, position := range portfolio.Positions {
-		if position.StrategyContribMap != nil {
-			for strategyIDStr := range position.StrategyContribMap {
-				if strategyID, err := uuid.Parse(strategyIDStr); err == nil {
-					strategyIDs[strategyID] = true
-				}
-			}
-		}
+	history, err := s.portfolioRepo.GetNAVHistory(ctx, portfolioID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NAV history: %w", err)
 	}
 	
-	// Convert to slice
-	strategyIDSlice := make([]uuid.UUID, 0, len(strategyIDs))
-	for strategyID := range strategyIDs {
-		strategyIDSlice = append(strategyIDSlice, strategyID)
+	if len(history) == 0 {
+		return models.NormalizeBenchmark(symbol, nil, nil, decimal.Zero), nil
 	}
 	
-	if len(strategyIDSlice) == 0 {
-		return nil, fmt.Errorf("could not determine original strategies for rebalancing")
+	bars, err := s.getDailyBars(ctx, symbol, history[0].Timestamp, to)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Create allocation request with new total investment
-	allocationReq := &models.AllocationRequest{
-		StrategyIDs:     strategyIDSlice,
-		TotalInvestment: newTotalInvestment,
-		Constraints: models.AllocationConstraints{
-			MaxAllocationPerStock: decimal.NewFromInt(20), // Default 20% max per stock
-			MinAllocationAmount:   decimal.NewFromInt(100), // Default $100 minimum
-		},
+	timestamps := make([]time.Time, len(bars))
+	closes := make([]decimal.Decimal, len(bars))
+	for i, bar := range bars {
+		timestamps[i] = bar.Timestamp
+		closes[i] = bar.Close
 	}
 	
-	// Generate new allocation preview
-	preview, err := s.allocationEngine.CalculateAllocations(ctx, allocationReq)
+	return models.NormalizeBenchmark(symbol, timestamps, closes, history[0].NAV), nil
+}
+
+// getDailyBars fetches the daily bars of a symbol from the start of from's day, oldest first
+func (s *PortfolioService) getDailyBars(ctx context.Context, symbol string, from, to time.Time) ([]*OHLCV, error) {
+	bars, err := s.marketDataService.GetOHLCV(ctx, symbol, truncateToDate(from), to, "1day")
 	if err != nil {
-		return nil, fmt.Errorf("failed to calculate rebalance allocations: %w", err)
+		return nil, fmt.Errorf("failed to get price history for %s: %w", symbol, err)
 	}
 	
-	return preview, nil
+	sort.SliceStable(bars, func(i, j int) bool { return bars[i].Timestamp.Before(bars[j].Timestamp) })
+	return bars, nil
 }
 
-// RebalancePortfolio rebalances a portfolio to match new allocation targets
-func (s *PortfolioService) RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.Portfolio, error) {
-	// Generate rebalance preview
-	preview, err := s.GenerateRebalancePreview(ctx, portfolioID, newTotalInvestment)
+// getTradedBars fetches the daily bars of a stock at the prices it traded at. Providers adjust price
+// history for later splits, which would misprice the share counts the ledger held before a split, so
+// bars before each recorded split are scaled back by its ratio.
+func (s *PortfolioService) getTradedBars(ctx context.Context, stock *models.Stock, from, to time.Time) ([]*OHLCV, error) {
+	bars, err := s.getDailyBars(ctx, stock.Ticker, from, to)
+	if err != nil || s.corporateActionRepo == nil {
+		return bars, err
+	}
+	
+	actions, err := s.corporateActionRepo.GetByStockID(ctx, stock.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate rebalance preview: %w", err)
+		return nil, fmt.Errorf("failed to get corporate actions for %s: %w", stock.Ticker, err)
+	}
+	if len(actions) == 0 {
+		return bars, nil
+	}
+	
+	traded := make([]*OHLCV, 0, len(bars))
+	for _, bar := range bars {
+		factor := models.SplitFactor(actions, bar.Timestamp)
+		traded = append(traded, &OHLCV{
+			Timestamp: bar.Timestamp,
+			Open:      bar.Open.Mul(factor),
+			High:      bar.High.Mul(factor),
+			Low:       bar.Low.Mul(factor),
+			Close:     bar.Close.Mul(factor),
+			Volume:    decimal.NewFromInt(bar.Volume).Div(factor).IntPart(),
+		})
+	}
+	return traded, nil
+}
+
+// GetPortfolioAttribution attributes a portfolio's P&L over a date range to its strategies and sectors.
+// Holdings are valued from the ledger at daily closes on or before from and to, and each position's P&L
+// is pro-rated across strategies by its strategy contribution map. Brinson effects are calculated when
+// a benchmark weight set is given.
+func (s *PortfolioService) GetPortfolioAttribution(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, benchmark []models.BenchmarkSectorWeight) (*models.PortfolioAttribution, error) {
+	if !from.Before(to) {
+		return nil, &models.ValidationError{
+			Field:   "from",
+			Tag:     "ltfield",
+			Message: "from must be before to",
+		}
 	}
 	
-	// Get current portfolio
 	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
 	
-	// Update portfolio total investment
-	portfolio.TotalInvestment = newTotalInvestment
-	portfolio.UpdatedAt = time.Now()
-	
-	if err := s.portfolioRepo.Update(ctx, portfolio); err != nil {
-		return nil, fmt.Errorf("failed to update portfolio: %w", err)
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, time.Time{}, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
 	
-	// Update positions based on new allocations
-	for _, allocation := range preview.Allocations {
-		// Find existing position
-		var existingPosition *models.Position
-		for i := range portfolio.Positions {
-			if portfolio.Positions[i].StockID == allocation.StockID {
-				existingPosition = &portfolio.Positions[i]
-				break
+	// Split the ledger at the start of the period and total the trading within it
+	var before []*models.Transaction
+	holdings := make(map[uuid.UUID]*models.HoldingPerformance)
+	stocks := make(map[uuid.UUID]*models.Stock)
+	for _, t := range transactions {
+		if t.StockID == nil {
+			if !t.ExecutedAt.After(from) {
+				before = append(before, t)
 			}
+			continue
 		}
 		
-		if existingPosition != nil {
-			// Update existing position
-			existingPosition.Quantity = allocation.Quantity
-			existingPosition.AllocationValue = allocation.ActualValue
-			existingPosition.UpdatedAt = time.Now()
-			
-			if err := s.portfolioRepo.UpdatePosition(ctx, existingPosition); err != nil {
-				return nil, fmt.Errorf("failed to update position for stock %s: %w", allocation.Ticker, err)
-			}
-		} else {
-			// Create new position
-			newPosition := &models.Position{
-				PortfolioID:     portfolioID,
-				StockID:         allocation.StockID,
-				Quantity:        allocation.Quantity,
-				EntryPrice:      allocation.Price,
-				AllocationValue: allocation.ActualValue,
-				CreatedAt:       time.Now(),
-				UpdatedAt:       time.Now(),
-			}
-			
-			// Convert strategy contribution to JSON
-			if allocation.StrategyContrib != nil {
-				contribJSON, err := json.Marshal(allocation.StrategyContrib)
-				if err == nil {
-					newPosition.StrategyContrib = contribJSON
-					newPosition.StrategyContribMap = allocation.StrategyContrib
-				}
-			}
-			
-			if err := s.portfolioRepo.CreatePosition(ctx, newPosition); err != nil {
-				return nil, fmt.Errorf("failed to create position for stock %s: %w", allocation.Ticker, err)
-			}
+		if t.Stock != nil {
+			stocks[*t.StockID] = t.Stock
+		}
+		holding, exists := holdings[*t.StockID]
+		if !exists {
+			holding = &models.HoldingPerformance{StockID: *t.StockID}
+			holdings[*t.StockID] = holding
+		}
+		
+		if !t.ExecutedAt.After(from) {
+			before = append(before, t)
+			continue
+		}
+		switch t.Type {
+		case models.TransactionBuy:
+			holding.Purchases = holding.Purchases.Add(t.Amount)
+		case models.TransactionSell:
+			holding.Sales = holding.Sales.Add(t.Amount)
+		case models.TransactionDividend:
+			holding.Dividends = holding.Dividends.Add(t.Amount)
 		}
 	}
 	
-	// Update NAV after rebalancing
-	if _, err := s.UpdatePortfolioNAV(ctx, portfolioID); err != nil {
-		// Log error but don't fail the rebalancing
-		fmt.Printf("Warning: failed to update NAV after rebalancing: %v\n", err)
+	startLedger, err := models.BuildLedger(before, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ledger: %w", err)
 	}
-	
-	// Return updated portfolio
-	return s.GetPortfolio(ctx, portfolioID)
-}
-
-// enrichPositionsWithMarketData fetches current market prices and calculates position metrics
-func (s *PortfolioService) enrichPositionsWithMarketData(ctx context.Context, positions []models.Position) error {
-	if len(positions) == 0 {
-		return nil
+	endLedger, err := models.BuildLedger(transactions, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ledger: %w", err)
 	}
 	
-	// Extract unique tickers
-	tickers := make([]string, 0, len(positions))
-	tickerSet := make(map[string]bool)
-	
-	for _, position := range positions {
-		if position.Stock != nil && !tickerSet[position.Stock.Ticker] {
-			tickers = append(tickers, position.Stock.Ticker)
-			tickerSet[position.Stock.Ticker] = true
+	// Current positions carry the strategy contributions and the stock details
+	for _, position := range portfolio.Positions {
+		if holding, exists := holdings[position.StockID]; exists {
+			holding.StrategyContrib = position.StrategyContribMap
+		}
+		if position.Stock != nil {
+			stocks[position.StockID] = position.Stock
 		}
 	}
 	
-	if len(tickers) == 0 {
-		return nil
+	startValue := startLedger.Cash
+	performances := make([]models.HoldingPerformance, 0, len(holdings))
+	strategyIDs := make(map[string]bool)
+	for stockID, holding := range holdings {
+		startQuantity := startLedger.Quantity(stockID)
+		endQuantity := endLedger.Quantity(stockID)
+		if startQuantity.IsZero() && endQuantity.IsZero() && holding.Purchases.IsZero() && holding.Sales.IsZero() && holding.Dividends.IsZero() {
+			// Closed before the period started
+			continue
+		}
+		
+		stock, exists := stocks[stockID]
+		if !exists {
+			return nil, fmt.Errorf("stock %s not found", stockID)
+		}
+		holding.Ticker = stock.Ticker
+		if stock.Sector != nil {
+			holding.Sector = *stock.Sector
+		}
+		
+		if startQuantity.IsPositive() || endQuantity.IsPositive() {
+			bars, err := s.getTradedBars(ctx, stock, from.AddDate(0, 0, -7), to)
+			if err != nil {
+				return nil, err
+			}
+			if startQuantity.IsPositive() {
+				price, ok := closeOnOrBefore(bars, from)
+				if !ok {
+					return nil, fmt.Errorf("no price for %s on or before %s", stock.Ticker, from.Format("2006-01-02"))
+				}
+				holding.StartValue = startQuantity.Mul(price)
+			}
+			if endQuantity.IsPositive() {
+				price, ok := closeOnOrBefore(bars, to)
+				if !ok {
+					return nil, fmt.Errorf("no price for %s on or before %s", stock.Ticker, to.Format("2006-01-02"))
+				}
+				holding.EndValue = endQuantity.Mul(price)
+			}
+		}
+		
+		startValue = startValue.Add(holding.StartValue)
+		for strategyID := range holding.StrategyContrib {
+			strategyIDs[strategyID] = true
+		}
+		performances = append(performances, *holding)
 	}
 	
-	// Fetch current quotes
-	quotes, err := s.marketDataService.GetMultipleQuotes(ctx, tickers)
+	strategyNames, err := s.getStrategyNames(ctx, strategyIDs)
 	if err != nil {
-		return fmt.Errorf("failed to fetch market quotes: %w", err)
+		return nil, err
 	}
 	
-	// Update positions with current market data
-	for i := range positions {
-		if positions[i].Stock == nil {
-			continue
+	// The return of each benchmark sector is the return of its symbol over the period
+	benchmarkReturns := make(map[string]decimal.Decimal, len(benchmark))
+	for _, weight := range benchmark {
+		bars, err := s.getDailyBars(ctx, weight.Symbol, from.AddDate(0, 0, -7), to)
+		if err != nil {
+			return nil, err
 		}
-		
-		quote, hasQuote := quotes[positions[i].Stock.Ticker]
-		if hasQuote {
-			positions[i].CalculateMetrics(quote.Price)
+		startClose, okStart := closeOnOrBefore(bars, from)
+		endClose, okEnd := closeOnOrBefore(bars, to)
+		if !okStart || !okEnd || !startClose.IsPositive() {
+			return nil, fmt.Errorf("no benchmark prices for %s over the period", weight.Symbol)
 		}
+		benchmarkReturns[weight.Sector] = endClose.Div(startClose).Sub(decimal.NewFromInt(1)).Mul(decimal.NewFromInt(100))
 	}
 	
-	return nil
+	attribution := models.CalculateAttribution(performances, startValue, strategyNames, benchmark, benchmarkReturns)
+	attribution.PortfolioID = portfolioID
+	attribution.From = from
+	attribution.To = to
+	
+	return attribution, nil
 }
 
-// calculateDrawdown calculates drawdown from high water mark
-func (s *PortfolioService) calculateDrawdown(ctx context.Context, navHistory *models.NAVHistory) error {
-	// Get all previous NAV history to find high water mark
-	allHistory, err := s.portfolioRepo.GetNAVHistory(ctx, navHistory.PortfolioID, time.Time{}, navHistory.Timestamp)
+// GetTaxReport lists the gains a portfolio realized in a calendar year, one row per tax lot sold,
+// flagging losses with repurchases of the same stock within the wash-sale window as wash sales
+func (s *PortfolioService) GetTaxReport(ctx context.Context, portfolioID uuid.UUID, year int) (*models.TaxReport, error) {
+	if year < 1900 || year > time.Now().Year() {
+		return nil, &models.ValidationError{
+			Field:   "year",
+			Message: fmt.Sprintf("year %d is out of range", year),
+		}
+	}
+	
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
 	if err != nil {
-		return fmt.Errorf("failed to get NAV history for drawdown calculation: %w", err)
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
 	
-	// Find high water mark
-	highWaterMark := navHistory.NAV
-	for _, entry := range allHistory {
-		if entry.NAV.GreaterThan(highWaterMark) {
-			highWaterMark = entry.NAV
-		}
+	// Replay past the end of the year to find repurchases that make a December loss a wash sale
+	to := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, models.WashSaleWindowDays)
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, time.Time{}, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
 	}
 	
-	// Calculate drawdown
-	navHistory.CalculateDrawdown(highWaterMark)
+	ledger, err := models.BuildLedger(transactions, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
 	
-	return nil
-}
-
-// AllocationPreviewCache provides caching for allocation previews
-type AllocationPreviewCache struct {
-	cache map[string]*CachedPreview
+	return models.NewTaxReport(portfolioID, year, ledger.Realized, transactions), nil
 }
 
-// CachedPreview represents a cached allocation preview
-type CachedPreview struct {
-	Preview   *models.AllocationPreview
-	Timestamp time.Time
-	TTL       time.Duration
+// getStrategyNames resolves strategy IDs from position contribution maps to strategy names
+func (s *PortfolioService) getStrategyNames(ctx context.Context, strategyIDs map[string]bool) (map[string]string, error) {
+	names := make(map[string]string, len(strategyIDs))
+	ids := make([]uuid.UUID, 0, len(strategyIDs))
+	for strategyID := range strategyIDs {
+		if id, err := uuid.Parse(strategyID); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return names, nil
+	}
+	
+	strategies, err := s.strategyRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategies: %w", err)
+	}
+	for _, strategy := range strategies {
+		names[strategy.ID.String()] = strategy.Name
+	}
+	return names, nil
 }
 
-// NewAllocationPreviewCache creates a new allocation preview cache
-func NewAllocationPreviewCache() *AllocationPreviewCache {
-	return &AllocationPreviewCache{
-		cache: make(map[string]*CachedPreview),
+// closeOnOrBefore returns the close of the last bar on or before t's date from bars ordered by time
+func closeOnOrBefore(bars []*OHLCV, t time.Time) (decimal.Decimal, bool) {
+	day := truncateToDate(t)
+	close, found := decimal.Zero, false
+	for _, bar := range bars {
+		if truncateToDate(bar.Timestamp).After(day) {
+			break
+		}
+		close, found = bar.Close, true
 	}
+	return close, found
 }
 
-// Get retrieves a cached preview if it exists and is not expired
-func (c *AllocationPreviewCache) Get(key string) (*models.AllocationPreview, bool) {
-	cached, exists := c.cache[key]
-	if !exists {
-		return nil, false
+// GenerateRebalancePreview generates the trade list that would rebalance a portfolio to its target allocation
+func (s *PortfolioService) GenerateRebalancePreview(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.RebalancePlan, error) {
+	// Get portfolio to extract original strategy configuration
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
 	}
-
-	// Check if expired
-	if time.Since(cached.Timestamp) > cached.TTL {
-		delete(c.cache, key)
-		return nil, false
+	
+	if len(portfolio.Positions) == 0 {
+		return nil, fmt.Errorf("portfolio has no positions to rebalance")
 	}
-
-	return cached.Preview, true
-}
-
-// Set stores a preview in the cache
-func (c *AllocationPreviewCache) Set(key string, preview *models.AllocationPreview, ttl time.Duration) {
-	c.cache[key] = &CachedPreview{
-		Preview:   preview,
-		Timestamp: time.Now(),
-		TTL:       ttl,
+	
+	// Reuse the stored allocation request with the new total investment
+	var allocationReq *models.AllocationRequest
+	if portfolio.AllocationConfig != nil {
+		allocationReq = portfolio.AllocationConfig.ToAllocationRequest(newTotalInvestment)
+	} else {
+		allocationReq, err = inferAllocationRequest(portfolio, newTotalInvestment)
+		if err != nil {
+			return nil, err
+		}
+	}
+	allocationReq.FractionalShares = portfolio.FractionalShares
+	allocationReq.SharePrecision = portfolio.SharePrecision
+	allocationReq.BaseCurrency = portfolio.BaseCurrency
+	
+	// Generate new allocation preview
+	preview, err := s.allocationEngine.CalculateAllocations(ctx, allocationReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate rebalance allocations: %w", err)
+	}
+	
+	// Price positions that may be closed at current market prices
+	if err := s.enrichPositionsWithMarketData(ctx, portfolio.Positions, portfolio.BaseCurrency); err != nil {
+		// Log error but don't fail - exits fall back to entry prices
+		fmt.Printf("Warning: failed to enrich positions with market data: %v\n", err)
+	}
+	
+	return models.NewRebalancePlan(portfolio, preview, newTotalInvestment), nil
+}
+
+// RebalancePortfolio executes the rebalance plan for a portfolio: the capital change and the
+// planned trades are recorded in the ledger and positions are updated or closed in one transaction
+func (s *PortfolioService) RebalancePortfolio(ctx context.Context, portfolioID uuid.UUID, newTotalInvestment decimal.Decimal) (*models.Portfolio, error) {
+	// Generate rebalance plan
+	plan, err := s.GenerateRebalancePreview(ctx, portfolioID, newTotalInvestment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate rebalance preview: %w", err)
+	}
+	
+	return s.executeRebalancePlan(ctx, plan)
+}
+
+// executeRebalancePlan applies a rebalance plan to the portfolio it was generated for
+func (s *PortfolioService) executeRebalancePlan(ctx context.Context, plan *models.RebalancePlan) (*models.Portfolio, error) {
+	portfolioID := plan.PortfolioID
+	
+	// Get current portfolio
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	// Replay the existing ledger to get current holdings
+	entries, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, time.Time{}, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
+	}
+	
+	// Trades are deltas from the holdings the plan was generated for, so they only apply to those
+	current, err := models.BuildLedger(entries, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+	for _, trade := range plan.Trades {
+		if held := current.Quantity(trade.StockID); !held.Equal(trade.CurrentQuantity) {
+			return nil, fmt.Errorf("rebalance plan is out of date: %s holding is %s shares, the plan expected %s", trade.Ticker, held, trade.CurrentQuantity)
+		}
+	}
+	
+	now := time.Now()
+	transactions := make([]*models.Transaction, 0, len(plan.Trades)+1)
+	
+	// Record added or withdrawn capital
+	if plan.CapitalChange.GreaterThan(decimal.Zero) {
+		transactions = append(transactions, models.NewCashTransaction(portfolioID, models.TransactionDeposit, plan.CapitalChange, now))
+	} else if plan.CapitalChange.LessThan(decimal.Zero) {
+		transactions = append(transactions, models.NewCashTransaction(portfolioID, models.TransactionWithdrawal, plan.CapitalChange.Abs(), now))
+	}
+	
+	contribs := strategyContribsByStock(portfolio.Positions)
+	
+	// Sells are recorded before buys so their proceeds fund the purchases
+	var buys []*models.Transaction
+	for _, trade := range plan.Trades {
+		if trade.SharesToSell.IsPositive() {
+			sell := models.NewTradeTransaction(portfolioID, trade.StockID, models.TransactionSell, trade.SharesToSell, trade.Price, now)
+			sell.SetFXRate(trade.FXRate)
+			transactions = append(transactions, sell)
+		}
+		if trade.SharesToBuy.IsPositive() {
+			buy := models.NewTradeTransaction(portfolioID, trade.StockID, models.TransactionBuy, trade.SharesToBuy, trade.Price, now)
+			buy.SetFXRate(trade.FXRate)
+			buys = append(buys, buy)
+		}
+		
+		if trade.StrategyContrib != nil {
+			contribs[trade.StockID] = trade.StrategyContrib
+		}
+	}
+	transactions = append(transactions, buys...)
+	
+	// Derive the new positions from the full ledger
+	ledger, err := models.BuildLedger(append(entries, transactions...), portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply rebalance trades: %w", err)
+	}
+	
+	for _, stockID := range plan.PositionsToClose {
+		if !ledger.Quantity(stockID).IsZero() {
+			return nil, fmt.Errorf("rebalance plan is out of date: position %s would remain open", stockID)
+		}
+	}
+	
+	for _, transaction := range transactions {
+		if transaction.Type == models.TransactionSell {
+			transaction.LotSelections = ledger.LotSelections(transaction.ID)
+		}
+	}
+	
+	positions, err := positionsFromLedger(portfolioID, ledger, contribs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive positions: %w", err)
+	}
+	
+	// Update portfolio totals
+	portfolio.TotalInvestment = plan.NewTotalInvestment
+	portfolio.CashBalance = ledger.Cash
+	portfolio.UpdatedAt = now
+	
+	if err := s.portfolioRepo.ExecuteRebalance(ctx, portfolio, transactions, positions, plan.PositionsToClose); err != nil {
+		return nil, fmt.Errorf("failed to execute rebalance: %w", err)
+	}
+	
+	// Update NAV after rebalancing
+	if _, err := s.UpdatePortfolioNAV(ctx, portfolioID); err != nil {
+		// Log error but don't fail the rebalancing
+		fmt.Printf("Warning: failed to update NAV after rebalancing: %v\n", err)
+	}
+	
+	// Return updated portfolio
+	return s.GetPortfolio(ctx, portfolioID)
+}
+
+// RecordTransaction appends a manual ledger entry and re-derives the portfolio positions
+func (s *PortfolioService) RecordTransaction(ctx context.Context, portfolioID uuid.UUID, req *models.CreateTransactionRequest) (*models.Transaction, error) {
+	if req == nil {
+		return nil, fmt.Errorf("create transaction request cannot be nil")
+	}
+	
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	transaction := &models.Transaction{}
+	transaction.FromCreateRequest(req, portfolioID)
+	
+	if err := transaction.Validate(); err != nil {
+		return nil, err
+	}
+	
+	isTrade := transaction.Type == models.TransactionBuy || transaction.Type == models.TransactionSell
+	if isTrade && !transaction.Quantity.Equal(transaction.Quantity.RoundFloor(portfolio.ShareDecimals())) {
+		return nil, &models.ValidationError{
+			Field:   "quantity",
+			Message: fmt.Sprintf("quantity cannot have more than %d decimal places in this portfolio", portfolio.ShareDecimals()),
+		}
+	}
+	
+	// Trades in stocks quoted in another currency are converted at the rate on the trade date
+	if isTrade && req.FXRate == nil {
+		rate, err := s.stockFXRate(ctx, portfolio, *transaction.StockID, transaction.ExecutedAt)
+		if err != nil {
+			return nil, err
+		}
+		transaction.SetFXRate(rate)
+	}
+	
+	if transaction.ExecutedAt.After(time.Now()) {
+		return nil, &models.ValidationError{
+			Field:   "executed_at",
+			Message: "executed_at cannot be in the future",
+		}
+	}
+	
+	if len(transaction.LotSelections) > 0 && portfolio.LotReliefMethod != models.LotReliefSpecificID {
+		return nil, &models.ValidationError{
+			Field:   "lots",
+			Message: fmt.Sprintf("lots can only be named in portfolios using specific identification, this portfolio uses %s", portfolio.LotReliefMethod),
+		}
+	}
+	
+	// The ledger is replayed and validated under a lock on the portfolio, so concurrent entries
+	// cannot each pass the checks against the same balance
+	contribs := strategyContribsByStock(portfolio.Positions)
+	err = s.transactionRepo.AppendTransaction(ctx, portfolioID, transaction, func(entries []*models.Transaction) (*models.LedgerUpdate, error) {
+		ledger, err := models.BuildLedger(append(entries, transaction), portfolio.LotReliefMethod)
+		if err != nil {
+			field := "quantity"
+			if len(transaction.LotSelections) > 0 {
+				field = "lots"
+			}
+			return nil, &models.ValidationError{
+				Field:   field,
+				Message: err.Error(),
+			}
+		}
+		
+		// Withdrawals, fees and buys are paid from cash, which cannot go below zero at any point,
+		// including after a backdated entry
+		if overdraft := ledger.Overdraft; overdraft != nil {
+			field := "amount"
+			if transaction.Type == models.TransactionBuy {
+				field = "quantity"
+			}
+			message := fmt.Sprintf("insufficient cash: the transaction needs %s but the portfolio holds %s", transaction.Amount.StringFixed(2), overdraft.Cash.Sub(transaction.CashEffect()).StringFixed(2))
+			if overdraft.TransactionID != transaction.ID {
+				message = fmt.Sprintf("insufficient cash: the transaction would leave cash at %s on %s", overdraft.Cash.StringFixed(2), overdraft.ExecutedAt.UTC().Format("2006-01-02"))
+			}
+			return nil, &models.ValidationError{
+				Field:   field,
+				Message: message,
+			}
+		}
+		
+		// Record the lots a sell relieved so replaying the ledger realizes the same gains
+		if transaction.Type == models.TransactionSell {
+			transaction.LotSelections = ledger.LotSelections(transaction.ID)
+		}
+		
+		positions, err := positionsFromLedger(portfolioID, ledger, contribs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive positions: %w", err)
+		}
+		
+		// Capital contributions keep the total investment in line with net deposits
+		update := &models.LedgerUpdate{Positions: positions, CashBalance: ledger.Cash}
+		if transaction.Type == models.TransactionDeposit || transaction.Type == models.TransactionWithdrawal {
+			update.InvestmentChange = transaction.CashEffect()
+		}
+		return update, nil
+	})
+	if err != nil {
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			return nil, validationErr
+		}
+		return nil, fmt.Errorf("failed to record transaction: %w", err)
+	}
+	
+	return transaction, nil
+}
+
+// GetTransactions retrieves the ledger entries of a portfolio within a date range
+func (s *PortfolioService) GetTransactions(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolioID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
+	}
+	
+	return transactions, nil
+}
+
+// GetAllocationConfig retrieves the stored allocation configuration of a portfolio
+func (s *PortfolioService) GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error) {
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	if portfolio.AllocationConfig == nil {
+		return nil, &models.NotFoundError{Resource: "allocation config"}
+	}
+	
+	return portfolio.AllocationConfig, nil
+}
+
+// UpdateAllocationConfig edits the stored allocation configuration used when rebalancing a portfolio
+func (s *PortfolioService) UpdateAllocationConfig(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateAllocationConfigRequest) (*models.PortfolioAllocationConfig, error) {
+	if req == nil {
+		return nil, fmt.Errorf("update allocation config request cannot be nil")
+	}
+	
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	config := portfolio.AllocationConfig
+	if config == nil {
+		// Portfolios created without a stored configuration start from an empty one
+		config = &models.PortfolioAllocationConfig{
+			PortfolioID: portfolioID,
+			CreatedAt:   time.Now(),
+		}
+	}
+	config.ApplyUpdate(req)
+	
+	if err := s.ValidateAllocationRequest(config.ToAllocationRequest(portfolio.TotalInvestment)); err != nil {
+		return nil, &models.ValidationError{
+			Field:   "allocation_config",
+			Message: err.Error(),
+		}
+	}
+	
+	if err := s.portfolioRepo.SaveAllocationConfig(ctx, config); err != nil {
+		return nil, fmt.Errorf("failed to save allocation config: %w", err)
+	}
+	
+	return config, nil
+}
+
+// GetRebalancePolicy retrieves the rebalance policy of a portfolio
+func (s *PortfolioService) GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error) {
+	if _, err := s.portfolioRepo.GetByID(ctx, portfolioID); err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	policy, err := s.portfolioRepo.GetRebalancePolicy(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rebalance policy: %w", err)
+	}
+	
+	if policy == nil {
+		return nil, &models.NotFoundError{Resource: "rebalance policy"}
+	}
+	
+	return policy, nil
+}
+
+// UpdateRebalancePolicy creates or replaces the rebalance policy of a portfolio
+func (s *PortfolioService) UpdateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID, req *models.UpdateRebalancePolicyRequest) (*models.RebalancePolicy, error) {
+	if req == nil {
+		return nil, fmt.Errorf("update rebalance policy request cannot be nil")
+	}
+	
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	
+	if _, err := s.portfolioRepo.GetByID(ctx, portfolioID); err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	policy, err := s.portfolioRepo.GetRebalancePolicy(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rebalance policy: %w", err)
+	}
+	
+	if policy == nil {
+		policy = models.NewRebalancePolicy(portfolioID, req)
+	} else {
+		policy.ApplyUpdate(req)
+	}
+	
+	if err := s.portfolioRepo.SaveRebalancePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save rebalance policy: %w", err)
+	}
+	
+	return policy, nil
+}
+
+// EvaluateRebalancePolicy checks whether a portfolio's rebalance policy is triggered. A triggered
+// policy records a rebalance run that either waits for approval or is executed straight away.
+// It returns nil when the policy did not trigger.
+func (s *PortfolioService) EvaluateRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalanceRun, error) {
+	policy, err := s.portfolioRepo.GetRebalancePolicy(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rebalance policy: %w", err)
+	}
+	
+	if policy == nil || policy.TriggerType == models.RebalanceTriggerNone {
+		return nil, nil
+	}
+	
+	// Wait for a pending plan to be approved or rejected before proposing another one
+	runs, err := s.portfolioRepo.GetRebalanceRuns(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rebalance runs: %w", err)
+	}
+	for _, run := range runs {
+		if run.Status == models.RebalanceRunPending {
+			return nil, nil
+		}
+	}
+	
+	portfolio, err := s.portfolioRepo.GetByID(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio: %w", err)
+	}
+	
+	if len(portfolio.Positions) == 0 {
+		return nil, nil
+	}
+	
+	now := time.Now()
+	policy.LastEvaluatedAt = &now
+	
+	// Between scheduled dates a calendar policy only reacts to exit signals, which can be checked
+	// without pricing a full plan
+	if policy.TriggerType == models.RebalanceTriggerCalendar && !policy.IsCalendarDue(now) {
+		hasExits, err := s.hasExitSignals(ctx, portfolio)
+		if err != nil {
+			return nil, err
+		}
+		if !hasExits {
+			if err := s.portfolioRepo.SaveRebalancePolicy(ctx, policy); err != nil {
+				return nil, fmt.Errorf("failed to save rebalance policy: %w", err)
+			}
+			return nil, nil
+		}
+	}
+	
+	plan, err := s.GenerateRebalancePreview(ctx, portfolioID, portfolio.TotalInvestment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate rebalance preview: %w", err)
+	}
+	
+	// Sell and StrongSell signals on held stocks trigger an exit regardless of the schedule or drift
+	triggered := policy.IsCalendarDue(now) || policy.IsDriftExceeded(plan.MaxDrift) || len(plan.SignalExits) > 0
+	if !triggered || plan.TotalTurnover.IsZero() {
+		if err := s.portfolioRepo.SaveRebalancePolicy(ctx, policy); err != nil {
+			return nil, fmt.Errorf("failed to save rebalance policy: %w", err)
+		}
+		return nil, nil
+	}
+	
+	policy.LastTriggeredAt = &now
+	run := models.NewRebalanceRun(portfolioID, policy.TriggerType, plan, models.RebalanceRunPending)
+	
+	if policy.ExecutionMode == models.RebalanceModeAuto {
+		if _, err := s.executeRebalancePlan(ctx, plan); err != nil {
+			run.Resolve(models.RebalanceRunFailed, err)
+		} else {
+			run.Resolve(models.RebalanceRunExecuted, nil)
+		}
+	}
+	
+	if err := s.portfolioRepo.CreateRebalanceRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to record rebalance run: %w", err)
+	}
+	
+	if err := s.portfolioRepo.SaveRebalancePolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("failed to save rebalance policy: %w", err)
+	}
+	
+	return run, nil
+}
+
+// hasExitSignals checks whether any held stock has an exit signal under the portfolio's strategies
+func (s *PortfolioService) hasExitSignals(ctx context.Context, portfolio *models.Portfolio) (bool, error) {
+	var strategyIDs []uuid.UUID
+	if portfolio.AllocationConfig != nil {
+		strategyIDs = portfolio.AllocationConfig.StrategyIDs
+	} else {
+		allocationReq, err := inferAllocationRequest(portfolio, portfolio.TotalInvestment)
+		if err != nil {
+			return false, err
+		}
+		strategyIDs = allocationReq.StrategyIDs
+	}
+	
+	stockIDs := make([]uuid.UUID, 0, len(portfolio.Positions))
+	for _, position := range portfolio.Positions {
+		stockIDs = append(stockIDs, position.StockID)
+	}
+	
+	hasExits, err := s.allocationEngine.HasExitSignals(ctx, strategyIDs, stockIDs)
+	if err != nil {
+		return false, fmt.Errorf("failed to check exit signals: %w", err)
+	}
+	return hasExits, nil
+}
+
+// GetRebalanceRuns retrieves the rebalance runs triggered for a portfolio, newest first
+func (s *PortfolioService) GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error) {
+	runs, err := s.portfolioRepo.GetRebalanceRuns(ctx, portfolioID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rebalance runs: %w", err)
+	}
+	
+	return runs, nil
+}
+
+// ApproveRebalanceRun executes a pending rebalance run. The plan is regenerated for the run's total
+// investment so the trades are sized from the current holdings and recorded at current prices rather
+// than those the run was proposed with.
+func (s *PortfolioService) ApproveRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	run, err := s.getPendingRebalanceRun(ctx, portfolioID, runID)
+	if err != nil {
+		return nil, err
+	}
+	
+	newTotalInvestment := decimal.Zero
+	if run.Plan != nil {
+		newTotalInvestment = run.Plan.NewTotalInvestment
+	}
+	
+	plan, execErr := s.GenerateRebalancePreview(ctx, portfolioID, newTotalInvestment)
+	if execErr == nil {
+		run.Plan = plan
+		run.MaxDrift = plan.MaxDrift
+		_, execErr = s.executeRebalancePlan(ctx, plan)
+	}
+	if execErr != nil {
+		run.Resolve(models.RebalanceRunFailed, execErr)
+	} else {
+		run.Resolve(models.RebalanceRunExecuted, nil)
+	}
+	
+	if err := s.portfolioRepo.UpdateRebalanceRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to update rebalance run: %w", err)
+	}
+	
+	if execErr != nil {
+		return nil, fmt.Errorf("failed to execute rebalance: %w", execErr)
+	}
+	
+	return run, nil
+}
+
+// RejectRebalanceRun discards the plan of a pending rebalance run
+func (s *PortfolioService) RejectRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	run, err := s.getPendingRebalanceRun(ctx, portfolioID, runID)
+	if err != nil {
+		return nil, err
+	}
+	
+	run.Resolve(models.RebalanceRunRejected, nil)
+	
+	if err := s.portfolioRepo.UpdateRebalanceRun(ctx, run); err != nil {
+		return nil, fmt.Errorf("failed to update rebalance run: %w", err)
+	}
+	
+	return run, nil
+}
+
+// getPendingRebalanceRun retrieves a rebalance run of a portfolio that is still waiting for approval
+func (s *PortfolioService) getPendingRebalanceRun(ctx context.Context, portfolioID, runID uuid.UUID) (*models.RebalanceRun, error) {
+	run, err := s.portfolioRepo.GetRebalanceRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	
+	if run.PortfolioID != portfolioID {
+		return nil, &models.NotFoundError{Resource: "rebalance run"}
+	}
+	
+	if run.Status != models.RebalanceRunPending {
+		return nil, &models.ValidationError{
+			Field:   "status",
+			Message: fmt.Sprintf("rebalance run is already %s", run.Status),
+		}
+	}
+	
+	return run, nil
+}
+
+// inferAllocationRequest rebuilds an allocation request for portfolios created without a stored
+// configuration, using the strategies recorded on their positions and default constraints
+func inferAllocationRequest(portfolio *models.Portfolio, totalInvestment decimal.Decimal) (*models.AllocationRequest, error) {
+	strategyIDs := make(map[uuid.UUID]bool)
+	for _, position := range portfolio.Positions {
+		if position.StrategyContribMap != nil {
+			for strategyIDStr := range position.StrategyContribMap {
+				if strategyID, err := uuid.Parse(strategyIDStr); err == nil {
+					strategyIDs[strategyID] = true
+				}
+			}
+		}
+	}
+	
+	// Convert to slice
+	strategyIDSlice := make([]uuid.UUID, 0, len(strategyIDs))
+	for strategyID := range strategyIDs {
+		strategyIDSlice = append(strategyIDSlice, strategyID)
+	}
+	
+	if len(strategyIDSlice) == 0 {
+		return nil, fmt.Errorf("could not determine original strategies for rebalancing")
+	}
+	
+	return &models.AllocationRequest{
+		StrategyIDs:     strategyIDSlice,
+		TotalInvestment: totalInvestment,
+		Constraints: models.AllocationConstraints{
+			MaxAllocationPerStock: decimal.NewFromInt(20), // Default 20% max per stock
+			MinAllocationAmount:   decimal.NewFromInt(100), // Default $100 minimum
+		},
+	}, nil
+}
+
+// stockFXRate returns the rate on a date converting the currency a stock is quoted in to the portfolio
+// base currency. Held stocks are already loaded with the portfolio; others are looked up.
+func (s *PortfolioService) stockFXRate(ctx context.Context, portfolio *models.Portfolio, stockID uuid.UUID, date time.Time) (decimal.Decimal, error) {
+	var stock *models.Stock
+	for i := range portfolio.Positions {
+		if portfolio.Positions[i].StockID == stockID && portfolio.Positions[i].Stock != nil {
+			stock = portfolio.Positions[i].Stock
+			break
+		}
+	}
+	
+	if stock == nil {
+		if s.stockRepo == nil {
+			return decimal.Zero, fmt.Errorf("no stock repository to look up the currency of stock %s", stockID)
+		}
+		stocks, err := s.stockRepo.GetByIDs(ctx, []uuid.UUID{stockID})
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to get stock: %w", err)
+		}
+		if len(stocks) == 0 {
+			return decimal.Zero, &models.ValidationError{
+				Field:   "stock_id",
+				Message: fmt.Sprintf("stock %s not found", stockID),
+			}
+		}
+		stock = stocks[0]
+	}
+	
+	currency := models.CurrencyOrDefault(stock.Currency)
+	baseCurrency := models.CurrencyOrDefault(portfolio.BaseCurrency)
+	if currency == baseCurrency {
+		return decimal.NewFromInt(1), nil
+	}
+	if s.fxService == nil {
+		return decimal.Zero, fmt.Errorf("no FX service to convert %s to %s", currency, baseCurrency)
+	}
+	
+	rate, err := s.fxService.GetRate(ctx, currency, baseCurrency, date)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to fetch FX rate: %w", err)
+	}
+	
+	return rate, nil
+}
+
+// positionsFromLedger builds position rows for every open holding in the ledger
+func positionsFromLedger(portfolioID uuid.UUID, ledger *models.Ledger, contribs map[uuid.UUID]map[string]decimal.Decimal) ([]*models.Position, error) {
+	holdings := ledger.OpenHoldings()
+	positions := make([]*models.Position, 0, len(holdings))
+	now := time.Now()
+	
+	for _, holding := range holdings {
+		position := &models.Position{
+			PortfolioID:     portfolioID,
+			StockID:         holding.StockID,
+			Quantity:        holding.Quantity,
+			EntryPrice:      holding.AverageCost().Round(4),
+			AllocationValue: holding.CostBasis.Round(2),
+			EntryFXRate:     holding.EntryFXRate().Round(8),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		
+		if contrib, exists := contribs[holding.StockID]; exists && contrib != nil {
+			contribJSON, err := json.Marshal(contrib)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode strategy contribution for stock %s: %w", holding.StockID, err)
+			}
+			position.StrategyContrib = contribJSON
+			position.StrategyContribMap = contrib
+		}
+		
+		positions = append(positions, position)
+	}
+	
+	return positions, nil
+}
+
+// strategyContribsByStock indexes the strategy contributions of existing positions by stock
+func strategyContribsByStock(positions []models.Position) map[uuid.UUID]map[string]decimal.Decimal {
+	contribs := make(map[uuid.UUID]map[string]decimal.Decimal, len(positions))
+	for _, position := range positions {
+		if position.StrategyContribMap != nil {
+			contribs[position.StockID] = position.StrategyContribMap
+		}
+	}
+	return contribs
+}
+
+// enrichPositionsWithMarketData fetches current market prices and calculates position metrics in the
+// portfolio base currency, converting the prices of stocks quoted in other currencies at today's rate
+func (s *PortfolioService) enrichPositionsWithMarketData(ctx context.Context, positions []models.Position, baseCurrency string) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	
+	// Extract unique tickers
+	tickers := make([]string, 0, len(positions))
+	tickerSet := make(map[string]bool)
+	
+	for _, position := range positions {
+		if position.Stock != nil && !tickerSet[position.Stock.Ticker] {
+			tickers = append(tickers, position.Stock.Ticker)
+			tickerSet[position.Stock.Ticker] = true
+		}
+	}
+	
+	if len(tickers) == 0 {
+		return nil
+	}
+	
+	// Fetch current quotes
+	quotes, err := s.marketDataService.GetMultipleQuotes(ctx, tickers)
+	if err != nil {
+		return fmt.Errorf("failed to fetch market quotes: %w", err)
+	}
+	
+	// Fetch the rates converting foreign quotes to the base currency
+	fxRates, err := s.foreignFXRates(ctx, positions, baseCurrency, time.Now())
+	if err != nil {
+		return err
+	}
+	
+	// Update positions with current market data
+	for i := range positions {
+		if positions[i].Stock == nil {
+			continue
+		}
+		
+		quote, hasQuote := quotes[positions[i].Stock.Ticker]
+		if !hasQuote {
+			continue
+		}
+		if rate, foreign := fxRates[models.CurrencyOrDefault(positions[i].Stock.Currency)]; foreign {
+			positions[i].CalculateMetricsInBaseCurrency(quote.Price, rate)
+		} else {
+			positions[i].CalculateMetrics(quote.Price)
+		}
+	}
+	
+	return nil
+}
+
+// foreignFXRates returns the rates on a date converting the currencies of positions quoted in another
+// currency than the base currency, keyed by currency
+func (s *PortfolioService) foreignFXRates(ctx context.Context, positions []models.Position, baseCurrency string, date time.Time) (map[string]decimal.Decimal, error) {
+	baseCurrency = models.CurrencyOrDefault(baseCurrency)
+	
+	var currencies []string
+	for _, position := range positions {
+		if position.Stock == nil {
+			continue
+		}
+		if currency := models.CurrencyOrDefault(position.Stock.Currency); currency != baseCurrency {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) == 0 {
+		return map[string]decimal.Decimal{}, nil
+	}
+	if s.fxService == nil {
+		return nil, fmt.Errorf("no FX service to convert %v to %s", currencies, baseCurrency)
+	}
+	
+	rates, err := s.fxService.GetRates(ctx, currencies, baseCurrency, date)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch FX rates: %w", err)
+	}
+	
+	return rates, nil
+}
+
+// buildPortfolioLedger replays the full ledger of a portfolio using its lot relief method
+func (s *PortfolioService) buildPortfolioLedger(ctx context.Context, portfolio *models.Portfolio) (*models.Ledger, error) {
+	transactions, err := s.transactionRepo.GetByPortfolioID(ctx, portfolio.ID, time.Time{}, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get portfolio transactions: %w", err)
+	}
+	
+	ledger, err := models.BuildLedger(transactions, portfolio.LotReliefMethod)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio ledger: %w", err)
+	}
+	
+	return ledger, nil
+}
+
+// enrichPositionsWithLots attaches the open tax lots of each position, valued at its current price
+// when one is available, and summarizes realized and unrealized gains by holding term
+func (s *PortfolioService) enrichPositionsWithLots(ctx context.Context, portfolio *models.Portfolio) error {
+	ledger, err := s.buildPortfolioLedger(ctx, portfolio)
+	if err != nil {
+		return err
+	}
+	
+	now := time.Now()
+	var allLots []models.TaxLot
+	for i := range portfolio.Positions {
+		position := &portfolio.Positions[i]
+		
+		var lots []*models.TaxLot
+		if holding, exists := ledger.Holdings[position.StockID]; exists {
+			lots = holding.Lots
+		}
+		
+		if position.CurrentPrice != nil {
+			position.Lots = models.ValueLots(lots, *position.CurrentPrice, now)
+		} else {
+			position.Lots = make([]models.TaxLot, 0, len(lots))
+			for _, lot := range lots {
+				position.Lots = append(position.Lots, *lot)
+			}
+		}
+		
+		position.Gains = models.SummarizeGains(ledger.RealizedFor(position.StockID), position.Lots)
+		allLots = append(allLots, position.Lots...)
+	}
+	
+	portfolio.Gains = models.SummarizeGains(ledger.Realized, allLots)
+	return nil
+}
+
+// calculateDrawdown calculates drawdown from high water mark
+func (s *PortfolioService) calculateDrawdown(ctx context.Context, navHistory *models.NAVHistory) error {
+	// Get all previous NAV history to find high water mark
+	allHistory, err := s.portfolioRepo.GetNAVHistory(ctx, navHistory.PortfolioID, time.Time{}, navHistory.Timestamp)
+	if err != nil {
+		return fmt.Errorf("failed to get NAV history for drawdown calculation: %w", err)
+	}
+	
+	// Find high water mark
+	highWaterMark := navHistory.NAV
+	for _, entry := range allHistory {
+		if entry.NAV.GreaterThan(highWaterMark) {
+			highWaterMark = entry.NAV
+		}
+	}
+	
+	// Calculate drawdown
+	navHistory.CalculateDrawdown(highWaterMark)
+	
+	return nil
+}
+
+// AllocationPreviewCache provides caching for allocation previews
+type AllocationPreviewCache struct {
+	cache map[string]*CachedPreview
+}
+
+// CachedPreview represents a cached allocation preview
+type CachedPreview struct {
+	Preview   *models.AllocationPreview
+	Timestamp time.Time
+	TTL       time.Duration
+}
+
+// NewAllocationPreviewCache creates a new allocation preview cache
+func NewAllocationPreviewCache() *AllocationPreviewCache {
+	return &AllocationPreviewCache{
+		cache: make(map[string]*CachedPreview),
+	}
+}
+
+// Get retrieves a cached preview if it exists and is not expired
+func (c *AllocationPreviewCache) Get(key string) (*models.AllocationPreview, bool) {
+	cached, exists := c.cache[key]
+	if !exists {
+		return nil, false
+	}
+
+	// Check if expired
+	if time.Since(cached.Timestamp) > cached.TTL {
+		delete(c.cache, key)
+		return nil, false
+	}
+
+	return cached.Preview, true
+}
+
+// Set stores a preview in the cache
+func (c *AllocationPreviewCache) Set(key string, preview *models.AllocationPreview, ttl time.Duration) {
+	c.cache[key] = &CachedPreview{
+		Preview:   preview,
+		Timestamp: time.Now(),
+		TTL:       ttl,
 	}
 }
 
@@ -632,6 +1782,22 @@ func GenerateCacheKey(req *models.AllocationRequest) string {
 		req.Constraints.MinAllocationAmount.String(),
 		fmt.Sprintf("%v", req.StrategyIDs))
 	
+	if req.Strict {
+		key += "_strict"
+	}
+	
+	if req.FractionalShares {
+		key += fmt.Sprintf("_frac_%d", req.SharePrecision)
+	}
+	
+	if req.SweepResidualCash {
+		key += "_sweep"
+	}
+	
+	if req.StrongBuyMultiplier.IsPositive() {
+		key += fmt.Sprintf("_sbm_%s", req.StrongBuyMultiplier.String())
+	}
+	
 	if len(req.ExcludedStocks) > 0 {
 		key += fmt.Sprintf("_excl_%v", req.ExcludedStocks)
 	}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"math"
 	"testing"
 	"time"
 
@@ -102,11 +103,111 @@ func (m *MockPortfolioRepository) GetLatestNAV(ctx context.Context, portfolioID
 	return args.Get(0).(*models.NAVHistory), args.Error(1)
 }
 
+func (m *MockPortfolioRepository) GetPositionSnapshots(ctx context.Context, portfolioID uuid.UUID, from, to time.Time, stockID *uuid.UUID) ([]*models.PositionSnapshot, error) {
+	args := m.Called(ctx, portfolioID, from, to, stockID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.PositionSnapshot), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) PrunePositionSnapshots(ctx context.Context, downsampleBefore, deleteBefore time.Time) (int64, error) {
+	args := m.Called(ctx, downsampleBefore, deleteBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) GetAllocationConfig(ctx context.Context, portfolioID uuid.UUID) (*models.PortfolioAllocationConfig, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PortfolioAllocationConfig), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) SaveAllocationConfig(ctx context.Context, config *models.PortfolioAllocationConfig) error {
+	args := m.Called(ctx, config)
+	return args.Error(0)
+}
+
 func (m *MockPortfolioRepository) CreatePortfolioWithPositions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position) error {
 	args := m.Called(ctx, portfolio, positions)
 	return args.Error(0)
 }
 
+func (m *MockPortfolioRepository) CreatePortfolioWithTransactions(ctx context.Context, portfolio *models.Portfolio, positions []*models.Position, transactions []*models.Transaction) error {
+	args := m.Called(ctx, portfolio, positions, transactions)
+	return args.Error(0)
+}
+
+func (m *MockPortfolioRepository) ExecuteRebalance(ctx context.Context, portfolio *models.Portfolio, transactions []*models.Transaction, positions []*models.Position, closedStockIDs []uuid.UUID) error {
+	args := m.Called(ctx, portfolio, transactions, positions, closedStockIDs)
+	return args.Error(0)
+}
+
+func (m *MockPortfolioRepository) GetRebalancePolicy(ctx context.Context, portfolioID uuid.UUID) (*models.RebalancePolicy, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalancePolicy), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) SaveRebalancePolicy(ctx context.Context, policy *models.RebalancePolicy) error {
+	args := m.Called(ctx, policy)
+	return args.Error(0)
+}
+
+func (m *MockPortfolioRepository) CreateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+func (m *MockPortfolioRepository) GetRebalanceRun(ctx context.Context, id uuid.UUID) (*models.RebalanceRun, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) GetRebalanceRuns(ctx context.Context, portfolioID uuid.UUID) ([]*models.RebalanceRun, error) {
+	args := m.Called(ctx, portfolioID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.RebalanceRun), args.Error(1)
+}
+
+func (m *MockPortfolioRepository) UpdateRebalanceRun(ctx context.Context, run *models.RebalanceRun) error {
+	args := m.Called(ctx, run)
+	return args.Error(0)
+}
+
+type MockTransactionRepository struct {
+	mock.Mock
+}
+
+func (m *MockTransactionRepository) GetByPortfolioID(ctx context.Context, portfolioID uuid.UUID, from, to time.Time) ([]*models.Transaction, error) {
+	args := m.Called(ctx, portfolioID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Transaction), args.Error(1)
+}
+
+// AppendTransaction hands the stubbed ledger to apply and records the update it derives as ApplyLedgerUpdate
+func (m *MockTransactionRepository) AppendTransaction(ctx context.Context, portfolioID uuid.UUID, transaction *models.Transaction, apply func(entries []*models.Transaction) (*models.LedgerUpdate, error)) error {
+	args := m.Called(ctx, portfolioID, transaction)
+	if args.Get(0) == nil {
+		return args.Error(1)
+	}
+	update, err := apply(args.Get(0).([]*models.Transaction))
+	if err != nil {
+		return err
+	}
+	return m.MethodCalled("ApplyLedgerUpdate", ctx, portfolioID, update).Error(0)
+}
+
 type MockAllocationEngine struct {
 	mock.Mock
 }
@@ -156,6 +257,11 @@ func (m *MockAllocationEngine) ValidateConstraintsConfig(constraints models.Allo
 	return args.Get(0).(*ValidationResult)
 }
 
+func (m *MockAllocationEngine) HasExitSignals(ctx context.Context, strategyIDs []uuid.UUID, stockIDs []uuid.UUID) (bool, error) {
+	args := m.Called(ctx, strategyIDs, stockIDs)
+	return args.Bool(0), args.Error(1)
+}
+
 type MockTestStrategyRepository struct {
 	mock.Mock
 }
@@ -219,7 +325,7 @@ func TestPortfolioService_CreatePortfolio(t *testing.T) {
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, nil, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	userID := uuid.New()
@@ -232,7 +338,7 @@ func TestPortfolioService_CreatePortfolio(t *testing.T) {
 		Positions: []models.CreatePositionRequest{
 			{
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				StrategyContrib: map[string]decimal.Decimal{
@@ -252,7 +358,17 @@ func TestPortfolioService_CreatePortfolio(t *testing.T) {
 	}
 
 	// Setup expectations
-	mockRepo.On("CreatePortfolioWithPositions", ctx, mock.AnythingOfType("*models.Portfolio"), mock.AnythingOfType("[]*models.Position")).Return(nil)
+	mockRepo.On("CreatePortfolioWithTransactions", ctx, mock.MatchedBy(func(p *models.Portfolio) bool {
+		return p.CashBalance.IsZero() // Fully invested
+	}),
+		mock.MatchedBy(func(positions []*models.Position) bool {
+			return len(positions) == 1 && positions[0].Quantity.Equal(decimal.NewFromInt(100)) && positions[0].EntryPrice.Equal(decimal.NewFromFloat(100.00))
+		}),
+		mock.MatchedBy(func(transactions []*models.Transaction) bool {
+			return len(transactions) == 2 &&
+				transactions[0].Type == models.TransactionDeposit && transactions[0].Amount.Equal(decimal.NewFromFloat(10000.00)) &&
+				transactions[1].Type == models.TransactionBuy && transactions[1].Quantity.Equal(decimal.NewFromInt(100))
+		})).Return(nil)
 	mockRepo.On("GetByID", ctx, mock.AnythingOfType("uuid.UUID")).Return(expectedPortfolio, nil)
 
 	// Execute
@@ -268,7 +384,7 @@ func TestPortfolioService_CreatePortfolio(t *testing.T) {
 }
 
 func TestPortfolioService_CreatePortfolio_ValidationErrors(t *testing.T) {
-	service := NewPortfolioService(nil, nil, nil, nil)
+	service := NewPortfolioService(nil, nil, nil, nil, nil, nil, nil, nil)
 	ctx := context.Background()
 	userID := uuid.New()
 
@@ -309,6 +425,30 @@ func TestPortfolioService_CreatePortfolio_ValidationErrors(t *testing.T) {
 			},
 			wantErr: "at least one position is required",
 		},
+		{
+			name: "fractional quantity in whole-share portfolio",
+			req: &models.CreatePortfolioRequest{
+				Name:            "Test",
+				TotalInvestment: decimal.NewFromFloat(10000.00),
+				Positions: []models.CreatePositionRequest{
+					{StockID: uuid.New(), Quantity: decimal.NewFromFloat(2.5), EntryPrice: decimal.NewFromFloat(100.00), AllocationValue: decimal.NewFromFloat(250.00)},
+				},
+			},
+			wantErr: "exceeds the portfolio's share precision",
+		},
+		{
+			name: "quantity beyond fractional share precision",
+			req: &models.CreatePortfolioRequest{
+				Name:             "Test",
+				TotalInvestment:  decimal.NewFromFloat(10000.00),
+				FractionalShares: true,
+				SharePrecision:   2,
+				Positions: []models.CreatePositionRequest{
+					{StockID: uuid.New(), Quantity: decimal.NewFromFloat(2.125), EntryPrice: decimal.NewFromFloat(100.00), AllocationValue: decimal.NewFromFloat(212.50)},
+				},
+			},
+			wantErr: "exceeds the portfolio's share precision",
+		},
 	}
 
 	for _, tt := range tests {
@@ -327,7 +467,8 @@ func TestPortfolioService_GetPortfolio(t *testing.T) {
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -344,7 +485,7 @@ func TestPortfolioService_GetPortfolio(t *testing.T) {
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				Stock: &models.Stock{
@@ -365,6 +506,9 @@ func TestPortfolioService_GetPortfolio(t *testing.T) {
 
 	// Setup expectations
 	mockRepo.On("GetByID", ctx, portfolioID).Return(expectedPortfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(100.00), expectedPortfolio.CreatedAt),
+	}, nil)
 	mockMarketDataService.On("GetMultipleQuotes", ctx, []string{"AAPL"}).Return(quotes, nil)
 
 	// Execute
@@ -381,6 +525,13 @@ func TestPortfolioService_GetPortfolio(t *testing.T) {
 	assert.NotNil(t, position.CurrentPrice)
 	assert.True(t, decimal.NewFromFloat(150.00).Equal(*position.CurrentPrice))
 
+	// Verify the position was broken down into valued tax lots
+	require.Len(t, position.Lots, 1)
+	assert.True(t, decimal.NewFromFloat(5000.00).Equal(*position.Lots[0].UnrealizedPnL))
+	require.NotNil(t, result.Gains)
+	require.NotNil(t, result.Gains.UnrealizedPnL)
+	assert.True(t, decimal.NewFromFloat(5000.00).Equal(*result.Gains.UnrealizedPnL))
+
 	mockRepo.AssertExpectations(t)
 	mockMarketDataService.AssertExpectations(t)
 }
@@ -392,7 +543,8 @@ func TestPortfolioService_UpdatePortfolioNAV(t *testing.T) {
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -409,7 +561,7 @@ func TestPortfolioService_UpdatePortfolioNAV(t *testing.T) {
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				Stock: &models.Stock{
@@ -430,6 +582,7 @@ func TestPortfolioService_UpdatePortfolioNAV(t *testing.T) {
 
 	// Setup expectations
 	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{}, nil)
 	mockMarketDataService.On("GetMultipleQuotes", ctx, []string{"AAPL"}).Return(quotes, nil)
 	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.NAVHistory{}, nil)
 	mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
@@ -444,10 +597,179 @@ func TestPortfolioService_UpdatePortfolioNAV(t *testing.T) {
 	assert.True(t, decimal.NewFromFloat(15000.00).Equal(result.NAV)) // 100 shares * $150
 	assert.True(t, decimal.NewFromFloat(5000.00).Equal(result.PnL))  // $15000 - $10000
 
+	// Holdings are snapshotted with the NAV
+	require.Len(t, result.Positions, 1)
+	assert.Equal(t, stockID, result.Positions[0].StockID)
+	assert.Equal(t, result.Timestamp, result.Positions[0].Timestamp)
+	assert.True(t, decimal.NewFromFloat(150.00).Equal(result.Positions[0].Price))
+	assert.True(t, decimal.NewFromFloat(15000.00).Equal(result.Positions[0].Value))
+	assert.True(t, decimal.NewFromInt(100).Equal(result.Positions[0].Weight))
+
 	mockRepo.AssertExpectations(t)
 	mockMarketDataService.AssertExpectations(t)
 }
 
+func TestPortfolioService_GetPositionHistory(t *testing.T) {
+	mockRepo := &MockPortfolioRepository{}
+	service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	apple := &models.Stock{ID: uuid.New(), Ticker: "AAPL"}
+	microsoft := &models.Stock{ID: uuid.New(), Ticker: "MSFT"}
+
+	mockRepo.On("GetPositionSnapshots", ctx, portfolioID, from, to, (*uuid.UUID)(nil)).Return([]*models.PositionSnapshot{
+		{StockID: apple.ID, Stock: apple, Timestamp: from, Value: decimal.NewFromInt(6000), Weight: decimal.NewFromInt(60)},
+		{StockID: microsoft.ID, Stock: microsoft, Timestamp: from, Value: decimal.NewFromInt(4000), Weight: decimal.NewFromInt(40)},
+		{StockID: apple.ID, Stock: apple, Timestamp: from.AddDate(0, 0, 1), Value: decimal.NewFromInt(6600), Weight: decimal.NewFromInt(62)},
+	}, nil)
+
+	history, err := service.GetPositionHistory(ctx, portfolioID, from, to, nil)
+
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, apple.ID, history[0].StockID)
+	assert.Equal(t, "AAPL", history[0].Stock.Ticker)
+	require.Len(t, history[0].Points, 2)
+	assert.True(t, decimal.NewFromInt(6600).Equal(history[0].Points[1].Value))
+	assert.Equal(t, microsoft.ID, history[1].StockID)
+	assert.Len(t, history[1].Points, 1)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPortfolioService_UpdatePortfolioNAV_IncludesCash(t *testing.T) {
+	mockRepo := &MockPortfolioRepository{}
+	mockMarketDataService := &MockTestMarketDataService{}
+
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
+
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		TotalInvestment: decimal.NewFromFloat(10000.00),
+		CashBalance:     decimal.NewFromFloat(250.00),
+		Positions: []models.Position{
+			{
+				PortfolioID:     portfolioID,
+				StockID:         stockID,
+				Quantity:        decimal.NewFromInt(65),
+				EntryPrice:      decimal.NewFromFloat(150.00),
+				AllocationValue: decimal.NewFromFloat(9750.00),
+				Stock:           &models.Stock{ID: stockID, Ticker: "AAPL"},
+			},
+		},
+	}
+
+	quotes := map[string]*Quote{
+		"AAPL": {Symbol: "AAPL", Price: decimal.NewFromFloat(150.00)},
+	}
+
+	// The cash is a dividend kept uninvested
+	boughtAt := time.Now().AddDate(0, -6, 0)
+	dividend := models.NewCashTransaction(portfolioID, models.TransactionDividend, decimal.NewFromFloat(250.00), time.Now().AddDate(0, -1, 0))
+	dividend.StockID = &stockID
+	transactions := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(9750.00), boughtAt),
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(65), decimal.NewFromFloat(150.00), boughtAt),
+		dividend,
+	}
+
+	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(transactions, nil)
+	mockMarketDataService.On("GetMultipleQuotes", ctx, []string{"AAPL"}).Return(quotes, nil)
+	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.NAVHistory{}, nil)
+	mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
+
+	result, err := service.UpdatePortfolioNAV(ctx, portfolioID)
+
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(10000.00).Equal(result.NAV)) // $9750 in stock + $250 cash
+	assert.True(t, decimal.NewFromFloat(250.00).Equal(result.Cash))
+	assert.True(t, decimal.NewFromFloat(250.00).Equal(result.DividendIncome))
+
+	metrics := models.CalculatePerformanceMetrics([]models.NAVHistory{*result}, nil, portfolio.TotalInvestment, decimal.Zero)
+	assert.True(t, metrics.TotalReturnPct.IsZero())
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPortfolioService_UpdatePortfolioNAV_ConvertsForeignHoldings(t *testing.T) {
+	mockRepo := &MockPortfolioRepository{}
+	mockMarketDataService := &MockTestMarketDataService{}
+	mockTransactionRepo := &MockTransactionRepository{}
+	mockFXService := &MockFXService{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, mockMarketDataService, nil, mockFXService, nil)
+
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+
+	// 100 shares bought at 80 GBP when a pound was worth 1.25 USD
+	boughtAt := time.Now().AddDate(0, -6, 0)
+	buy := models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromInt(100), boughtAt)
+	buy.SetFXRate(decimal.NewFromFloat(1.25))
+	transactions := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromInt(10000), boughtAt),
+		buy,
+	}
+
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		TotalInvestment: decimal.NewFromInt(10000),
+		BaseCurrency:    "USD",
+		Positions: []models.Position{
+			{
+				PortfolioID:     portfolioID,
+				StockID:         stockID,
+				Quantity:        decimal.NewFromInt(100),
+				EntryPrice:      decimal.NewFromInt(100),
+				AllocationValue: decimal.NewFromInt(10000),
+				EntryFXRate:     decimal.NewFromFloat(1.25),
+				Stock:           &models.Stock{ID: stockID, Ticker: "VOD", Currency: "GBP"},
+			},
+		},
+	}
+
+	// The stock is up 10% in pounds and the pound is up to 1.30 USD
+	quotes := map[string]*Quote{
+		"VOD": {Symbol: "VOD", Price: decimal.NewFromInt(88)},
+	}
+
+	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(transactions, nil)
+	mockMarketDataService.On("GetMultipleQuotes", ctx, []string{"VOD"}).Return(quotes, nil)
+	mockFXService.On("GetRates", ctx, []string{"GBP"}, "USD", mock.AnythingOfType("time.Time")).
+		Return(map[string]decimal.Decimal{"GBP": decimal.NewFromFloat(1.30)}, nil)
+	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.NAVHistory{}, nil)
+	mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
+
+	result, err := service.UpdatePortfolioNAV(ctx, portfolioID)
+
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(11440).Equal(result.NAV), "expected 11440, got %s", result.NAV) // 8800 GBP at 1.30
+	assert.True(t, decimal.NewFromInt(1440).Equal(result.UnrealizedPnL))
+	assert.True(t, decimal.NewFromInt(440).Equal(result.FXPnL), "expected 440, got %s", result.FXPnL)
+
+	position := portfolio.Positions[0]
+	require.NotNil(t, position.LocalPnL)
+	assert.True(t, decimal.NewFromInt(1000).Equal(*position.LocalPnL))
+	assert.True(t, decimal.NewFromInt(88).Equal(*position.LocalPrice))
+
+	metrics := models.CalculatePerformanceMetrics([]models.NAVHistory{*result}, nil, portfolio.TotalInvestment, decimal.Zero)
+	assert.True(t, decimal.NewFromFloat(14.4).Equal(metrics.TotalReturnPct))
+	assert.True(t, decimal.NewFromFloat(4.4).Equal(metrics.FXReturnPct))
+	assert.True(t, decimal.NewFromInt(10).Equal(metrics.LocalReturnPct))
+
+	mockRepo.AssertExpectations(t)
+	mockFXService.AssertExpectations(t)
+}
+
 func TestPortfolioService_GenerateRebalancePreview(t *testing.T) {
 	// Setup mocks
 	mockRepo := &MockPortfolioRepository{}
@@ -455,7 +777,7 @@ func TestPortfolioService_GenerateRebalancePreview(t *testing.T) {
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, nil, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -473,7 +795,7 @@ func TestPortfolioService_GenerateRebalancePreview(t *testing.T) {
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				StrategyContribMap: map[string]decimal.Decimal{
@@ -491,7 +813,7 @@ func TestPortfolioService_GenerateRebalancePreview(t *testing.T) {
 				Ticker:          "AAPL",
 				AllocationValue: decimal.NewFromFloat(20000.00),
 				Price:           decimal.NewFromFloat(150.00),
-				Quantity:        133,
+				Quantity:        decimal.NewFromInt(133),
 				ActualValue:     decimal.NewFromFloat(19950.00),
 			},
 		},
@@ -509,21 +831,131 @@ func TestPortfolioService_GenerateRebalancePreview(t *testing.T) {
 	// Assert
 	require.NoError(t, err)
 	assert.NotNil(t, result)
-	assert.True(t, decimal.NewFromFloat(20000.00).Equal(result.TotalInvestment))
-	assert.Len(t, result.Allocations, 1)
+	assert.True(t, decimal.NewFromFloat(20000.00).Equal(result.NewTotalInvestment))
+	require.Len(t, result.Trades, 1)
+	assert.Equal(t, models.RebalanceBuy, result.Trades[0].Action)
+	assert.True(t, decimal.NewFromInt(100).Equal(result.Trades[0].CurrentQuantity))
+	assert.True(t, decimal.NewFromInt(133).Equal(result.Trades[0].TargetQuantity))
+	assert.True(t, decimal.NewFromInt(33).Equal(result.Trades[0].SharesToBuy))
+	assert.True(t, decimal.NewFromFloat(4950.00).Equal(result.TotalTurnover))
+	assert.Empty(t, result.PositionsToClose)
 
 	mockRepo.AssertExpectations(t)
 	mockAllocationEngine.AssertExpectations(t)
 }
 
+func TestPortfolioService_GenerateRebalancePreview_UsesStoredConfig(t *testing.T) {
+	mockRepo := &MockPortfolioRepository{}
+	mockAllocationEngine := &MockAllocationEngine{}
+
+	service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	strategyID := uuid.New()
+	excludedID := uuid.New()
+
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		TotalInvestment: decimal.NewFromFloat(10000.00),
+		Positions: []models.Position{
+			{PortfolioID: portfolioID, StockID: uuid.New(), Quantity: decimal.NewFromInt(10), EntryPrice: decimal.NewFromFloat(100.00)},
+		},
+		AllocationConfig: &models.PortfolioAllocationConfig{
+			PortfolioID: portfolioID,
+			StrategyIDs: []uuid.UUID{strategyID},
+			Constraints: models.AllocationConstraints{
+				MaxAllocationPerStock: decimal.NewFromInt(35),
+				MinAllocationAmount:   decimal.NewFromInt(500),
+			},
+			ExcludedStocks: []uuid.UUID{excludedID},
+		},
+	}
+
+	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockAllocationEngine.On("CalculateAllocations", ctx, mock.MatchedBy(func(req *models.AllocationRequest) bool {
+		return len(req.StrategyIDs) == 1 && req.StrategyIDs[0] == strategyID &&
+			req.TotalInvestment.Equal(decimal.NewFromFloat(20000.00)) &&
+			req.Constraints.MaxAllocationPerStock.Equal(decimal.NewFromInt(35)) &&
+			req.Constraints.MinAllocationAmount.Equal(decimal.NewFromInt(500)) &&
+			len(req.ExcludedStocks) == 1 && req.ExcludedStocks[0] == excludedID
+	})).Return(&models.AllocationPreview{TotalInvestment: decimal.NewFromFloat(20000.00)}, nil)
+
+	_, err := service.GenerateRebalancePreview(ctx, portfolioID, decimal.NewFromFloat(20000.00))
+
+	require.NoError(t, err)
+	mockAllocationEngine.AssertExpectations(t)
+}
+
+func TestPortfolioService_UpdateAllocationConfig(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	strategyID := uuid.New()
+
+	newPortfolio := func() *models.Portfolio {
+		return &models.Portfolio{
+			ID:              portfolioID,
+			TotalInvestment: decimal.NewFromFloat(10000.00),
+			AllocationConfig: &models.PortfolioAllocationConfig{
+				PortfolioID: portfolioID,
+				StrategyIDs: []uuid.UUID{strategyID},
+				Constraints: models.AllocationConstraints{
+					MaxAllocationPerStock: decimal.NewFromInt(20),
+					MinAllocationAmount:   decimal.NewFromInt(100),
+				},
+			},
+		}
+	}
+
+	t.Run("updates constraints and keeps strategies", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil)
+		mockRepo.On("SaveAllocationConfig", ctx, mock.MatchedBy(func(config *models.PortfolioAllocationConfig) bool {
+			return config.StrategyIDs[0] == strategyID && config.Constraints.MaxAllocationPerStock.Equal(decimal.NewFromInt(30))
+		})).Return(nil)
+
+		config, err := service.UpdateAllocationConfig(ctx, portfolioID, &models.UpdateAllocationConfigRequest{
+			Constraints: &models.AllocationConstraints{
+				MaxAllocationPerStock: decimal.NewFromInt(30),
+				MinAllocationAmount:   decimal.NewFromInt(100),
+			},
+		})
+
+		require.NoError(t, err)
+		assert.True(t, decimal.NewFromInt(30).Equal(config.Constraints.MaxAllocationPerStock))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects invalid constraints", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil)
+
+		_, err := service.UpdateAllocationConfig(ctx, portfolioID, &models.UpdateAllocationConfigRequest{
+			Constraints: &models.AllocationConstraints{
+				MaxAllocationPerStock: decimal.NewFromInt(150),
+			},
+		})
+
+		require.Error(t, err)
+		_, ok := err.(*models.ValidationError)
+		assert.True(t, ok)
+		mockRepo.AssertNotCalled(t, "SaveAllocationConfig", mock.Anything, mock.Anything)
+	})
+}
+
 func TestPortfolioService_GetPortfolioPerformanceMetrics(t *testing.T) {
 	// Setup mocks
 	mockRepo := &MockPortfolioRepository{}
 	mockAllocationEngine := &MockAllocationEngine{}
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
+	mockTransactionRepo := &MockTransactionRepository{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -566,9 +998,13 @@ func TestPortfolioService_GetPortfolioPerformanceMetrics(t *testing.T) {
 	// Setup expectations
 	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
 	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(navHistory, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(10000.00), time.Now().Add(-31*24*time.Hour)),
+		models.NewCashTransaction(portfolioID, models.TransactionDividend, decimal.NewFromFloat(250.00), time.Now().Add(-7*24*time.Hour)),
+	}, nil)
 
 	// Execute
-	result, err := service.GetPortfolioPerformanceMetrics(ctx, portfolioID)
+	result, err := service.GetPortfolioPerformanceMetrics(ctx, portfolioID, decimal.Zero, models.ReturnPeriodInception)
 
 	// Assert
 	require.NoError(t, err)
@@ -580,9 +1016,540 @@ func TestPortfolioService_GetPortfolioPerformanceMetrics(t *testing.T) {
 	assert.NotNil(t, result.MaxDrawdown)
 	assert.True(t, decimal.NewFromFloat(-4.17).Equal(*result.MaxDrawdown))
 
+	// The initial deposit is part of the starting value, so both returns match the NAV change
+	require.NotNil(t, result.Returns)
+	require.NotNil(t, result.Returns.TimeWeightedReturnPct)
+	assert.True(t, decimal.NewFromInt(15).Equal(*result.Returns.TimeWeightedReturnPct))
+	assert.True(t, result.Returns.NetCashFlow.IsZero())
+	require.NotNil(t, result.Returns.MoneyWeightedReturnPct)
+
+	// Dividends are reported as a separate line
+	assert.True(t, decimal.NewFromFloat(250.00).Equal(result.DividendIncome))
+	assert.True(t, decimal.NewFromFloat(2.5).Equal(result.DividendReturnPct))
+
 	mockRepo.AssertExpectations(t)
 }
 
+func TestCalculatePerformanceMetrics(t *testing.T) {
+	monday := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(day int, hour int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour)
+	}
+	entry := func(ts time.Time, nav float64) models.NAVHistory {
+		return models.NAVHistory{Timestamp: ts, NAV: decimal.NewFromFloat(nav)}
+	}
+
+	t.Run("annualized return is compounded", func(t *testing.T) {
+		history := []models.NAVHistory{
+			entry(monday, 10000),
+			entry(monday.AddDate(0, 0, 1461), 14641), // four years at 10% a year
+		}
+
+		metrics := models.CalculatePerformanceMetrics(history, nil, decimal.NewFromInt(10000), decimal.Zero)
+
+		require.NotNil(t, metrics.AnnualizedReturn)
+		assert.True(t, decimal.NewFromInt(10).Equal(*metrics.AnnualizedReturn), "got %s", metrics.AnnualizedReturn)
+	})
+
+	t.Run("statistics use daily closes", func(t *testing.T) {
+		drawdown := decimal.NewFromInt(-10)
+		wednesday := entry(at(2, 21), 99)
+		wednesday.Drawdown = &drawdown
+		history := []models.NAVHistory{
+			entry(at(0, 21), 100),
+			entry(at(1, 14), 150), // intraday swings are ignored
+			entry(at(1, 21), 110),
+			entry(at(2, 14), 50),
+			wednesday,
+			entry(at(3, 21), 108.9),
+			entry(at(5, 12), 108.9), // weekend entries are ignored
+		}
+
+		metrics := models.CalculatePerformanceMetrics(history, nil, decimal.NewFromInt(100), decimal.Zero)
+
+		// Daily returns are +10%, -10%, +10%
+		returns := []float64{0.1, -0.1, 0.1}
+		mean := (returns[0] + returns[1] + returns[2]) / 3
+		var squares float64
+		for _, r := range returns {
+			squares += (r - mean) * (r - mean)
+		}
+		stdDev := math.Sqrt(squares / 2)
+		downsideDev := math.Sqrt(0.01 / 3)
+
+		require.NotNil(t, metrics.VolatilityPct)
+		assert.InDelta(t, stdDev*math.Sqrt(252)*100, metrics.VolatilityPct.InexactFloat64(), 0.001)
+		require.NotNil(t, metrics.SharpeRatio)
+		assert.InDelta(t, mean/stdDev*math.Sqrt(252), metrics.SharpeRatio.InexactFloat64(), 0.001)
+		require.NotNil(t, metrics.SortinoRatio)
+		assert.InDelta(t, mean/downsideDev*math.Sqrt(252), metrics.SortinoRatio.InexactFloat64(), 0.001)
+
+		// Still below Tuesday's close at Thursday's close
+		assert.Equal(t, 2, metrics.MaxDrawdownDays)
+		require.NotNil(t, metrics.CalmarRatio)
+		assert.True(t, metrics.AnnualizedReturn.Div(decimal.NewFromInt(10)).Round(4).Equal(*metrics.CalmarRatio))
+	})
+
+	t.Run("cash flows are removed from returns", func(t *testing.T) {
+		// Every close is 10% above the last once the deposits made after Monday's and Tuesday's closes are taken out
+		history := []models.NAVHistory{
+			entry(at(0, 21), 100),
+			entry(at(1, 21), 220),
+			entry(at(2, 21), 352),
+			entry(at(3, 21), 387.2),
+		}
+		flows := []models.CashFlow{
+			{Date: at(0, 21).Add(time.Minute), Amount: decimal.NewFromInt(100)},
+			{Date: at(1, 21).Add(time.Minute), Amount: decimal.NewFromInt(100)},
+		}
+
+		metrics := models.CalculatePerformanceMetrics(history, flows, decimal.NewFromInt(100), decimal.Zero)
+
+		require.NotNil(t, metrics.VolatilityPct)
+		assert.InDelta(t, 0, metrics.VolatilityPct.InexactFloat64(), 0.1)
+		assert.Equal(t, 0, metrics.MaxDrawdownDays)
+	})
+
+	t.Run("annualized return compounds the time-weighted return", func(t *testing.T) {
+		// 10% a year for two years, then the doubled portfolio grows another 21% over the next two
+		history := []models.NAVHistory{
+			entry(monday, 10000),
+			entry(monday.AddDate(0, 0, 730), 12100),
+			entry(monday.AddDate(0, 0, 1461), 26741),
+		}
+		flows := []models.CashFlow{
+			{Date: monday.AddDate(0, 0, 730).Add(time.Minute), Amount: decimal.NewFromInt(10000)},
+		}
+
+		metrics := models.CalculatePerformanceMetrics(history, flows, decimal.NewFromInt(20000), decimal.Zero)
+
+		require.NotNil(t, metrics.AnnualizedReturn)
+		assert.InDelta(t, 10, metrics.AnnualizedReturn.InexactFloat64(), 0.01)
+	})
+
+	t.Run("risk-free rate lowers sharpe ratio", func(t *testing.T) {
+		history := []models.NAVHistory{
+			entry(at(0, 21), 100),
+			entry(at(1, 21), 101),
+			entry(at(2, 21), 100.5),
+			entry(at(3, 21), 102),
+		}
+
+		withoutRate := models.CalculatePerformanceMetrics(history, nil, decimal.NewFromInt(100), decimal.Zero)
+		withRate := models.CalculatePerformanceMetrics(history, nil, decimal.NewFromInt(100), decimal.NewFromInt(5))
+
+		require.NotNil(t, withoutRate.SharpeRatio)
+		require.NotNil(t, withRate.SharpeRatio)
+		assert.True(t, withRate.SharpeRatio.LessThan(*withoutRate.SharpeRatio))
+		assert.True(t, withRate.VolatilityPct.Equal(*withoutRate.VolatilityPct))
+		assert.Equal(t, 2, withoutRate.MaxDrawdownDays)
+	})
+}
+
+func TestCalculatePeriodReturns(t *testing.T) {
+	start := time.Date(2024, 3, 1, 21, 0, 0, 0, time.UTC)
+	entry := func(day int, nav float64) models.NAVHistory {
+		return models.NAVHistory{Timestamp: start.AddDate(0, 0, day), NAV: decimal.NewFromFloat(nav)}
+	}
+
+	// 10% gain, then 1000 is added and the larger portfolio gains another 10%
+	history := []models.NAVHistory{
+		entry(0, 1000),
+		entry(10, 1100),
+		entry(20, 2310),
+	}
+	flows := []models.CashFlow{
+		{Date: start.Add(-time.Hour), Amount: decimal.NewFromInt(1000)},
+		{Date: start.AddDate(0, 0, 10).Add(time.Hour), Amount: decimal.NewFromInt(1000)},
+	}
+
+	t.Run("since inception", func(t *testing.T) {
+		returns := models.CalculatePeriodReturns(models.ReturnPeriodInception, history, flows, time.Time{}, start.AddDate(0, 0, 30))
+
+		require.NotNil(t, returns)
+		assert.True(t, decimal.NewFromInt(1000).Equal(returns.StartValue))
+		assert.True(t, decimal.NewFromInt(1000).Equal(returns.NetCashFlow))
+		require.NotNil(t, returns.TimeWeightedReturnPct)
+		assert.InDelta(t, 21, returns.TimeWeightedReturnPct.InexactFloat64(), 0.05)
+
+		// The money-weighted rate is the annual rate at which the flows break even
+		require.NotNil(t, returns.MoneyWeightedReturnPct)
+		rate := returns.MoneyWeightedReturnPct.InexactFloat64() / 100
+		years := func(d time.Duration) float64 { return d.Hours() / 24 / 365 }
+		npv := -1000 - 1000/math.Pow(1+rate, years(10*24*time.Hour+time.Hour)) + 2310/math.Pow(1+rate, years(20*24*time.Hour))
+		assert.InDelta(t, 0, npv, 0.01)
+	})
+
+	t.Run("period starts at the last valuation before it", func(t *testing.T) {
+		returns := models.CalculatePeriodReturns(models.ReturnPeriodMTD, history, flows, start.AddDate(0, 0, 15), start.AddDate(0, 0, 30))
+
+		require.NotNil(t, returns)
+		assert.True(t, decimal.NewFromInt(1100).Equal(returns.StartValue))
+		assert.True(t, decimal.NewFromInt(1000).Equal(returns.NetCashFlow))
+		require.NotNil(t, returns.TimeWeightedReturnPct)
+		assert.InDelta(t, 10, returns.TimeWeightedReturnPct.InexactFloat64(), 0.05)
+	})
+
+	t.Run("single valuation", func(t *testing.T) {
+		assert.Nil(t, models.CalculatePeriodReturns(models.ReturnPeriodInception, history[:1], flows, time.Time{}, start))
+	})
+}
+
+func TestReturnPeriod(t *testing.T) {
+	now := time.Date(2024, 8, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected time.Time
+	}{
+		{"mtd", time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"qtd", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"ytd", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"1y", time.Date(2023, 8, 14, 15, 30, 0, 0, time.UTC)},
+		{"", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		period, err := models.ParseReturnPeriod(tt.input)
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, period.Start(now), tt.input)
+	}
+
+	_, err := models.ParseReturnPeriod("5y")
+	var validationErr *models.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestPortfolioService_UpdatePortfolio_RecordsCapitalChange(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	mockRepo := &MockPortfolioRepository{}
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		Name:            "Core",
+		TotalInvestment: decimal.NewFromFloat(10000.00),
+		CashBalance:     decimal.NewFromFloat(10000.00),
+	}
+	ledger := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(10000.00), time.Now().Add(-24*time.Hour)),
+	}
+
+	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(ledger, nil)
+	mockTransactionRepo.On("AppendTransaction", ctx, portfolioID,
+		mock.MatchedBy(func(transaction *models.Transaction) bool {
+			return transaction.Type == models.TransactionDeposit && transaction.Amount.Equal(decimal.NewFromFloat(2500.00))
+		})).Return(ledger, nil)
+	mockTransactionRepo.On("ApplyLedgerUpdate", ctx, portfolioID, mock.MatchedBy(func(update *models.LedgerUpdate) bool {
+		return update.CashBalance.Equal(decimal.NewFromFloat(12500.00)) && update.InvestmentChange.Equal(decimal.NewFromFloat(2500.00))
+	})).Return(nil)
+	mockRepo.On("Update", ctx, mock.AnythingOfType("*models.Portfolio")).Return(nil)
+
+	newName := "Core plus"
+	newTotal := decimal.NewFromFloat(12500.00)
+	result, err := service.UpdatePortfolio(ctx, portfolioID, &models.UpdatePortfolioRequest{
+		Name:            &newName,
+		TotalInvestment: &newTotal,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Core plus", result.Name)
+	assert.True(t, newTotal.Equal(result.TotalInvestment))
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestCalculateBenchmarkMetrics(t *testing.T) {
+	monday := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	benchmarkReturns := []float64{0.01, -0.005, 0.02, -0.01, 0.004, -0.002, 0.015}
+
+	// The portfolio moves exactly twice as much as the benchmark
+	var history []models.NAVHistory
+	closes := make(map[time.Time]decimal.Decimal)
+	nav, close := 10000.0, 400.0
+	day := monday
+	for i := 0; i <= len(benchmarkReturns); i++ {
+		if i > 0 {
+			nav *= 1 + 2*benchmarkReturns[i-1]
+			close *= 1 + benchmarkReturns[i-1]
+		}
+		for day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			day = day.AddDate(0, 0, 1)
+		}
+		history = append(history, models.NAVHistory{Timestamp: day.Add(21 * time.Hour), NAV: decimal.NewFromFloat(nav)})
+		closes[day] = decimal.NewFromFloat(close)
+		day = day.AddDate(0, 0, 1)
+	}
+
+	metrics := models.CalculateBenchmarkMetrics("SPY", history, nil, closes, decimal.Zero)
+
+	assert.Equal(t, "SPY", metrics.Symbol)
+	assert.Equal(t, len(benchmarkReturns), metrics.ObservationCount)
+	require.NotNil(t, metrics.Beta)
+	assert.InDelta(t, 2, metrics.Beta.InexactFloat64(), 0.0001)
+	require.NotNil(t, metrics.AlphaPct)
+	assert.InDelta(t, 0, metrics.AlphaPct.InexactFloat64(), 0.001)
+	require.NotNil(t, metrics.UpCapturePct)
+	assert.InDelta(t, 200, metrics.UpCapturePct.InexactFloat64(), 0.001)
+	require.NotNil(t, metrics.DownCapturePct)
+	assert.InDelta(t, 200, metrics.DownCapturePct.InexactFloat64(), 0.001)
+
+	// The active return equals the benchmark return, so tracking error is the benchmark's volatility
+	var sum, squares float64
+	for _, r := range benchmarkReturns {
+		sum += r
+	}
+	mean := sum / float64(len(benchmarkReturns))
+	for _, r := range benchmarkReturns {
+		squares += (r - mean) * (r - mean)
+	}
+	trackingError := math.Sqrt(squares/float64(len(benchmarkReturns)-1)) * math.Sqrt(252)
+	require.NotNil(t, metrics.TrackingErrorPct)
+	assert.InDelta(t, trackingError*100, metrics.TrackingErrorPct.InexactFloat64(), 0.001)
+	require.NotNil(t, metrics.InformationRatio)
+	assert.InDelta(t, mean*252/trackingError, metrics.InformationRatio.InexactFloat64(), 0.001)
+
+	t.Run("deposits are not counted as returns", func(t *testing.T) {
+		// 5000 is deposited a minute after the third close
+		deposit := history[2].Timestamp.Add(time.Minute)
+		withDeposit := make([]models.NAVHistory, len(history))
+		for i, entry := range history {
+			withDeposit[i] = entry
+			if entry.Timestamp.After(deposit) {
+				withDeposit[i].NAV = entry.NAV.Add(decimal.NewFromInt(5000).Mul(entry.NAV).Div(history[2].NAV))
+			}
+		}
+		flows := []models.CashFlow{{Date: deposit, Amount: decimal.NewFromInt(5000)}}
+
+		metrics := models.CalculateBenchmarkMetrics("SPY", withDeposit, flows, closes, decimal.Zero)
+
+		require.NotNil(t, metrics.Beta)
+		assert.InDelta(t, 2, metrics.Beta.InexactFloat64(), 0.001)
+		require.NotNil(t, metrics.AlphaPct)
+		assert.InDelta(t, 0, metrics.AlphaPct.InexactFloat64(), 0.1)
+	})
+
+	t.Run("no common dates", func(t *testing.T) {
+		metrics := models.CalculateBenchmarkMetrics("QQQ", history, nil, map[time.Time]decimal.Decimal{}, decimal.Zero)
+
+		assert.Zero(t, metrics.ObservationCount)
+		assert.Nil(t, metrics.Beta)
+		assert.Nil(t, metrics.BenchmarkReturnPct)
+	})
+}
+
+func TestPortfolioService_GetBenchmarkHistory(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("scaled to the first NAV", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockMarketDataService := &MockTestMarketDataService{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, mockMarketDataService, nil, nil, nil)
+
+		symbol := "SPY"
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&models.Portfolio{ID: portfolioID, BenchmarkSymbol: &symbol}, nil)
+		mockRepo.On("GetNAVHistory", ctx, portfolioID, from, to).Return([]*models.NAVHistory{
+			{PortfolioID: portfolioID, Timestamp: from.Add(15 * time.Hour), NAV: decimal.NewFromFloat(10000.00)},
+			{PortfolioID: portfolioID, Timestamp: from.Add(39 * time.Hour), NAV: decimal.NewFromFloat(10100.00)},
+		}, nil)
+		mockMarketDataService.On("GetOHLCV", ctx, "SPY", from, to, "1day").Return([]*OHLCV{
+			{Timestamp: from.AddDate(0, 0, 1), Close: decimal.NewFromFloat(410.00)},
+			{Timestamp: from, Close: decimal.NewFromFloat(400.00)},
+		}, nil)
+
+		benchmark, err := service.GetBenchmarkHistory(ctx, portfolioID, from, to)
+
+		require.NoError(t, err)
+		assert.Equal(t, "SPY", benchmark.Symbol)
+		require.Len(t, benchmark.Points, 2)
+		assert.True(t, decimal.NewFromFloat(10000.00).Equal(benchmark.Points[0].Value))
+		assert.True(t, decimal.NewFromFloat(10250.00).Equal(benchmark.Points[1].Value))
+		mockMarketDataService.AssertExpectations(t)
+	})
+
+	t.Run("no benchmark set", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&models.Portfolio{ID: portfolioID}, nil)
+
+		benchmark, err := service.GetBenchmarkHistory(ctx, portfolioID, from, to)
+
+		require.NoError(t, err)
+		assert.Nil(t, benchmark)
+	})
+}
+
+func TestCalculateAttribution(t *testing.T) {
+	strategyA := uuid.New().String()
+	strategyB := uuid.New().String()
+
+	holdings := []models.HoldingPerformance{
+		{
+			Ticker:          "AAA",
+			Sector:          "Technology",
+			StartValue:      decimal.NewFromInt(1000),
+			EndValue:        decimal.NewFromInt(1200),
+			StrategyContrib: map[string]decimal.Decimal{strategyA: decimal.NewFromInt(600), strategyB: decimal.NewFromInt(400)},
+		},
+		{
+			Ticker:          "BBB",
+			Sector:          "Health Care",
+			StartValue:      decimal.NewFromInt(1000),
+			EndValue:        decimal.NewFromInt(900),
+			Dividends:       decimal.NewFromInt(20),
+			StrategyContrib: map[string]decimal.Decimal{strategyA: decimal.NewFromInt(500)},
+		},
+	}
+	names := map[string]string{strategyA: "Momentum", strategyB: "Value"}
+	benchmark := []models.BenchmarkSectorWeight{
+		{Sector: "Technology", WeightPct: decimal.NewFromInt(60), Symbol: "XLK"},
+		{Sector: "Health Care", WeightPct: decimal.NewFromInt(40), Symbol: "XLV"},
+	}
+	benchmarkReturns := map[string]decimal.Decimal{"Technology": decimal.NewFromInt(10), "Health Care": decimal.NewFromInt(5)}
+
+	attribution := models.CalculateAttribution(holdings, decimal.NewFromInt(2000), names, benchmark, benchmarkReturns)
+
+	assert.True(t, decimal.NewFromInt(120).Equal(attribution.TotalPnL))
+
+	// Strategy P&L is pro-rated by contribution; ordered by P&L
+	require.Len(t, attribution.Strategies, 2)
+	assert.Equal(t, strategyB, attribution.Strategies[0].Key)
+	assert.Equal(t, "Value", attribution.Strategies[0].Name)
+	assert.True(t, decimal.NewFromInt(80).Equal(attribution.Strategies[0].PnL))
+	assert.True(t, decimal.NewFromInt(4).Equal(*attribution.Strategies[0].ContributionPct))
+	assert.True(t, decimal.NewFromInt(20).Equal(*attribution.Strategies[0].ReturnPct))
+	assert.True(t, decimal.NewFromInt(40).Equal(attribution.Strategies[1].PnL))
+	assert.True(t, decimal.NewFromInt(2).Equal(*attribution.Strategies[1].ContributionPct))
+
+	require.Len(t, attribution.Sectors, 2)
+	assert.Equal(t, "Technology", attribution.Sectors[0].Key)
+	assert.True(t, decimal.NewFromInt(20).Equal(*attribution.Sectors[0].ReturnPct))
+	assert.True(t, decimal.NewFromInt(50).Equal(*attribution.Sectors[0].WeightPct))
+	assert.True(t, decimal.NewFromInt(-80).Equal(attribution.Sectors[1].PnL))
+	assert.True(t, decimal.NewFromInt(-4).Equal(*attribution.Sectors[1].ContributionPct))
+
+	// Brinson effects sum to the active return
+	brinson := attribution.Brinson
+	require.NotNil(t, brinson)
+	assert.True(t, decimal.NewFromInt(6).Equal(brinson.PortfolioReturnPct))
+	assert.True(t, decimal.NewFromInt(8).Equal(brinson.BenchmarkReturnPct))
+	assert.True(t, decimal.NewFromInt(-2).Equal(brinson.ActiveReturnPct))
+	assert.True(t, decimal.NewFromFloat(-0.5).Equal(brinson.AllocationEffectPct))
+	assert.True(t, decimal.NewFromFloat(0.8).Equal(brinson.SelectionEffectPct))
+	assert.True(t, decimal.NewFromFloat(-2.3).Equal(brinson.InteractionEffectPct))
+	require.Len(t, brinson.Sectors, 2)
+	assert.Equal(t, "Health Care", brinson.Sectors[0].Sector)
+	assert.True(t, decimal.NewFromFloat(-5.2).Equal(brinson.Sectors[0].SelectionEffectPct))
+	assert.True(t, decimal.NewFromFloat(-0.2).Equal(brinson.Sectors[1].AllocationEffectPct))
+
+	t.Run("no benchmark", func(t *testing.T) {
+		attribution := models.CalculateAttribution(holdings, decimal.NewFromInt(2000), names, nil, nil)
+		assert.Nil(t, attribution.Brinson)
+	})
+}
+
+func TestParseBenchmarkWeights(t *testing.T) {
+	weights, err := models.ParseBenchmarkWeights("Technology:60:xlk, Health Care:40:XLV")
+	require.NoError(t, err)
+	require.Len(t, weights, 2)
+	assert.Equal(t, "Health Care", weights[1].Sector)
+	assert.Equal(t, "XLK", weights[0].Symbol)
+	assert.True(t, decimal.NewFromInt(40).Equal(weights[1].WeightPct))
+
+	weights, err = models.ParseBenchmarkWeights("")
+	require.NoError(t, err)
+	assert.Nil(t, weights)
+
+	for _, invalid := range []string{"Technology:60", "Technology:abc:XLK", "Technology:-5:XLK", "Technology:0:XLK", "Technology:50:XLK,Technology:50:QQQ"} {
+		_, err := models.ParseBenchmarkWeights(invalid)
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr, invalid)
+	}
+}
+
+func TestPortfolioService_GetPortfolioAttribution(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	t.Run("by strategy and sector", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		mockStrategyRepo := &MockTestStrategyRepository{}
+		mockMarketDataService := &MockTestMarketDataService{}
+		service := NewPortfolioService(nil, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
+
+		strategyID := uuid.New()
+		sector := "Technology"
+		stockA := &models.Stock{ID: uuid.New(), Ticker: "AAA", Sector: &sector}
+		stockB := &models.Stock{ID: uuid.New(), Ticker: "BBB"}
+
+		buyA := models.NewTradeTransaction(portfolioID, stockA.ID, models.TransactionBuy, decimal.NewFromInt(10), decimal.NewFromInt(100), from.AddDate(0, -1, 0))
+		buyA.Stock = stockA
+		buyB := models.NewTradeTransaction(portfolioID, stockB.ID, models.TransactionBuy, decimal.NewFromInt(5), decimal.NewFromInt(200), from.AddDate(0, 0, 14))
+		buyB.Stock = stockB
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&models.Portfolio{
+			ID: portfolioID,
+			Positions: []models.Position{
+				{StockID: stockA.ID, Stock: stockA, StrategyContribMap: map[string]decimal.Decimal{strategyID.String(): decimal.NewFromInt(1000)}},
+				{StockID: stockB.ID, Stock: stockB},
+			},
+		}, nil)
+		mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, time.Time{}, to).Return([]*models.Transaction{
+			models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromInt(10000), from.AddDate(0, -1, 0)),
+			buyA,
+			buyB,
+		}, nil)
+		mockMarketDataService.On("GetOHLCV", ctx, "AAA", from.AddDate(0, 0, -7), to, "1day").Return([]*OHLCV{
+			{Timestamp: from.AddDate(0, 0, -1), Close: decimal.NewFromInt(110)},
+			{Timestamp: to.AddDate(0, 0, -2), Close: decimal.NewFromInt(120)},
+		}, nil)
+		mockMarketDataService.On("GetOHLCV", ctx, "BBB", from.AddDate(0, 0, -7), to, "1day").Return([]*OHLCV{
+			{Timestamp: from.AddDate(0, 0, 14), Close: decimal.NewFromInt(200)},
+			{Timestamp: to.AddDate(0, 0, -3), Close: decimal.NewFromInt(210)},
+		}, nil)
+		mockStrategyRepo.On("GetByIDs", ctx, []uuid.UUID{strategyID}).Return([]*models.Strategy{{ID: strategyID, Name: "Momentum"}}, nil)
+
+		attribution, err := service.GetPortfolioAttribution(ctx, portfolioID, from, to, nil)
+
+		require.NoError(t, err)
+		// Start value is the cash left after buying AAA plus AAA at its close before the period
+		assert.True(t, decimal.NewFromInt(10100).Equal(attribution.StartValue))
+		assert.True(t, decimal.NewFromInt(150).Equal(attribution.TotalPnL))
+
+		require.Len(t, attribution.Strategies, 2)
+		assert.Equal(t, strategyID.String(), attribution.Strategies[0].Key)
+		assert.Equal(t, "Momentum", attribution.Strategies[0].Name)
+		assert.True(t, decimal.NewFromInt(100).Equal(attribution.Strategies[0].PnL))
+		assert.Equal(t, models.UnattributedStrategy, attribution.Strategies[1].Key)
+		assert.True(t, decimal.NewFromInt(50).Equal(attribution.Strategies[1].PnL))
+		assert.True(t, decimal.NewFromInt(1000).Equal(attribution.Strategies[1].NetInvestment))
+
+		require.Len(t, attribution.Sectors, 2)
+		assert.Equal(t, "Technology", attribution.Sectors[0].Key)
+		assert.Equal(t, models.UnclassifiedSector, attribution.Sectors[1].Key)
+		assert.Nil(t, attribution.Brinson)
+		mockMarketDataService.AssertExpectations(t)
+	})
+
+	t.Run("from after to", func(t *testing.T) {
+		service := NewPortfolioService(nil, nil, nil, nil, nil, nil, nil, nil)
+
+		_, err := service.GetPortfolioAttribution(ctx, portfolioID, to, from, nil)
+
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+}
+
 func TestPortfolioService_UpdatePortfolioNAV_EmptyPortfolio(t *testing.T) {
 	// Setup mocks
 	mockRepo := &MockPortfolioRepository{}
@@ -590,7 +1557,8 @@ func TestPortfolioService_UpdatePortfolioNAV_EmptyPortfolio(t *testing.T) {
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -601,6 +1569,7 @@ func TestPortfolioService_UpdatePortfolioNAV_EmptyPortfolio(t *testing.T) {
 		UserID:          uuid.New(),
 		Name:            "Empty Portfolio",
 		TotalInvestment: decimal.NewFromFloat(10000.00),
+		CashBalance:     decimal.NewFromFloat(10000.00),
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 		Positions:       []models.Position{}, // Empty positions
@@ -608,6 +1577,7 @@ func TestPortfolioService_UpdatePortfolioNAV_EmptyPortfolio(t *testing.T) {
 
 	// Setup expectations
 	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{}, nil)
 	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.NAVHistory{}, nil)
 	mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
 
@@ -618,7 +1588,8 @@ func TestPortfolioService_UpdatePortfolioNAV_EmptyPortfolio(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, result)
 	assert.Equal(t, portfolioID, result.PortfolioID)
-	assert.True(t, portfolio.TotalInvestment.Equal(result.NAV)) // NAV equals cash for empty portfolio
+	assert.True(t, portfolio.CashBalance.Equal(result.NAV)) // NAV equals cash for empty portfolio
+	assert.True(t, portfolio.CashBalance.Equal(result.Cash))
 	assert.True(t, decimal.Zero.Equal(result.PnL))
 
 	mockRepo.AssertExpectations(t)
@@ -631,7 +1602,8 @@ func TestPortfolioService_UpdatePortfolioNAV_WithDrawdownCalculation(t *testing.
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -648,7 +1620,7 @@ func TestPortfolioService_UpdatePortfolioNAV_WithDrawdownCalculation(t *testing.
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				Stock: &models.Stock{
@@ -680,6 +1652,7 @@ func TestPortfolioService_UpdatePortfolioNAV_WithDrawdownCalculation(t *testing.
 
 	// Setup expectations
 	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{}, nil)
 	mockMarketDataService.On("GetMultipleQuotes", ctx, []string{"AAPL"}).Return(quotes, nil)
 	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(previousNAVHistory, nil)
 	mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
@@ -709,13 +1682,15 @@ func TestPortfolioService_RebalancePortfolio_Success(t *testing.T) {
 	mockAllocationEngine := &MockAllocationEngine{}
 	mockStrategyRepo := &MockTestStrategyRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
+	mockTransactionRepo := &MockTransactionRepository{}
 
-	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockMarketDataService)
+	service := NewPortfolioService(mockAllocationEngine, mockStrategyRepo, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
 	strategyID := uuid.New()
 	stockID := uuid.New()
+	exitStockID := uuid.New()
 
 	portfolio := &models.Portfolio{
 		ID:              portfolioID,
@@ -728,13 +1703,21 @@ func TestPortfolioService_RebalancePortfolio_Success(t *testing.T) {
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				StrategyContribMap: map[string]decimal.Decimal{
 					strategyID.String(): decimal.NewFromFloat(10000.00),
 				},
 			},
+			{
+				// No longer part of the target allocation
+				PortfolioID:     portfolioID,
+				StockID:         exitStockID,
+				Quantity:        decimal.NewFromInt(50),
+				EntryPrice:      decimal.NewFromFloat(40.00),
+				AllocationValue: decimal.NewFromFloat(2000.00),
+			},
 		},
 	}
 
@@ -746,7 +1729,7 @@ func TestPortfolioService_RebalancePortfolio_Success(t *testing.T) {
 				Ticker:          "AAPL",
 				AllocationValue: decimal.NewFromFloat(20000.00),
 				Price:           decimal.NewFromFloat(150.00),
-				Quantity:        133,
+				Quantity:        decimal.NewFromInt(133),
 				ActualValue:     decimal.NewFromFloat(19950.00),
 			},
 		},
@@ -759,8 +1742,27 @@ func TestPortfolioService_RebalancePortfolio_Success(t *testing.T) {
 	// Setup expectations
 	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil).Times(4) // Called multiple times: GenerateRebalancePreview, RebalancePortfolio, UpdatePortfolioNAV, GetPortfolio
 	mockAllocationEngine.On("CalculateAllocations", ctx, mock.AnythingOfType("*models.AllocationRequest")).Return(rebalancePreview, nil)
-	mockRepo.On("Update", ctx, mock.AnythingOfType("*models.Portfolio")).Return(nil)
-	mockRepo.On("UpdatePosition", ctx, mock.AnythingOfType("*models.Position")).Return(nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(12000.00), portfolio.CreatedAt),
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(100.00), portfolio.CreatedAt),
+		models.NewTradeTransaction(portfolioID, exitStockID, models.TransactionBuy, decimal.NewFromInt(50), decimal.NewFromFloat(40.00), portfolio.CreatedAt),
+	}, nil)
+	mockRepo.On("ExecuteRebalance", ctx,
+		mock.MatchedBy(func(p *models.Portfolio) bool {
+			// $12000 deposited - $12000 opening buys + $10000 added + $2000 exit - 33 * $150
+			return p.TotalInvestment.Equal(decimal.NewFromFloat(20000.00)) && p.CashBalance.Equal(decimal.NewFromFloat(7050.00))
+		}),
+		mock.MatchedBy(func(transactions []*models.Transaction) bool {
+			// Deposit of the added capital, the exit sale, then the buy of the missing shares
+			return len(transactions) == 3 &&
+				transactions[0].Type == models.TransactionDeposit && transactions[0].Amount.Equal(decimal.NewFromFloat(10000.00)) &&
+				transactions[1].Type == models.TransactionSell && *transactions[1].StockID == exitStockID && transactions[1].Quantity.Equal(decimal.NewFromInt(50)) &&
+				transactions[2].Type == models.TransactionBuy && transactions[2].Quantity.Equal(decimal.NewFromInt(33))
+		}),
+		mock.MatchedBy(func(positions []*models.Position) bool {
+			return len(positions) == 1 && positions[0].StockID == stockID && positions[0].Quantity.Equal(decimal.NewFromInt(133))
+		}),
+		[]uuid.UUID{exitStockID}).Return(nil)
 	mockMarketDataService.On("GetMultipleQuotes", ctx, mock.AnythingOfType("[]string")).Return(map[string]*Quote{}, nil)
 	mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.NAVHistory{}, nil)
 	mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
@@ -775,10 +1777,476 @@ func TestPortfolioService_RebalancePortfolio_Success(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 	mockAllocationEngine.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestPortfolioService_RecordTransaction(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+	openedAt := time.Now().Add(-24 * time.Hour)
+
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		TotalInvestment: decimal.NewFromFloat(10000.00),
+		Positions: []models.Position{
+			{PortfolioID: portfolioID, StockID: stockID, Quantity: decimal.NewFromInt(100), EntryPrice: decimal.NewFromFloat(100.00), Stock: &models.Stock{ID: stockID, Ticker: "AAPL", Currency: "USD"}},
+		},
+	}
+
+	ledger := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(10000.00), openedAt),
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(100.00), openedAt),
+	}
+
+	t.Run("partial sell keeps average cost", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(ledger, nil)
+		mockTransactionRepo.On("ApplyLedgerUpdate", ctx, portfolioID, mock.MatchedBy(func(update *models.LedgerUpdate) bool {
+			positions := update.Positions
+			return len(positions) == 1 && positions[0].Quantity.Equal(decimal.NewFromInt(60)) &&
+				positions[0].EntryPrice.Equal(decimal.NewFromFloat(100.00)) &&
+				positions[0].AllocationValue.Equal(decimal.NewFromFloat(6000.00)) &&
+				update.CashBalance.Equal(decimal.NewFromFloat(4800.00)) && update.InvestmentChange.IsZero()
+		})).Return(nil)
+
+		result, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			StockID:  &stockID,
+			Type:     models.TransactionSell,
+			Quantity: decimal.NewFromInt(40),
+			Price:    decimal.NewFromFloat(120.00),
+		})
+
+		require.NoError(t, err)
+		assert.True(t, result.Amount.Equal(decimal.NewFromFloat(4800.00)))
+		require.Len(t, result.LotSelections, 1)
+		assert.Equal(t, ledger[1].ID, result.LotSelections[0].LotID)
+		mockRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("selling more than held is rejected", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(ledger, nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			StockID:  &stockID,
+			Type:     models.TransactionSell,
+			Quantity: decimal.NewFromInt(150),
+			Price:    decimal.NewFromFloat(120.00),
+		})
+
+		require.Error(t, err)
+		_, ok := err.(*models.ValidationError)
+		assert.True(t, ok)
+		mockTransactionRepo.AssertNotCalled(t, "ApplyLedgerUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("naming lots requires specific identification", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		fifoPortfolio := *portfolio
+		fifoPortfolio.LotReliefMethod = models.LotReliefFIFO
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&fifoPortfolio, nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			StockID:  &stockID,
+			Type:     models.TransactionSell,
+			Quantity: decimal.NewFromInt(40),
+			Price:    decimal.NewFromFloat(120.00),
+			Lots:     []models.LotSelection{{LotID: ledger[1].ID, Quantity: decimal.NewFromInt(40)}},
+		})
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "lots", validationErr.Field)
+		mockTransactionRepo.AssertNotCalled(t, "AppendTransaction", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("deposit raises total investment", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		depositPortfolio := *portfolio
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&depositPortfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(ledger, nil)
+		mockTransactionRepo.On("ApplyLedgerUpdate", ctx, portfolioID, mock.MatchedBy(func(update *models.LedgerUpdate) bool {
+			return update.CashBalance.Equal(decimal.NewFromFloat(2500.00)) && update.InvestmentChange.Equal(decimal.NewFromFloat(2500.00))
+		})).Return(nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			Type:   models.TransactionDeposit,
+			Amount: decimal.NewFromFloat(2500.00),
+		})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("withdrawing the whole cash balance is allowed", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		cashPortfolio := *portfolio
+		cashPortfolio.TotalInvestment = decimal.NewFromFloat(12500.00)
+		cashLedger := append(append([]*models.Transaction{}, ledger...),
+			models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(2500.00), openedAt))
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&cashPortfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(cashLedger, nil)
+		mockTransactionRepo.On("ApplyLedgerUpdate", ctx, portfolioID, mock.MatchedBy(func(update *models.LedgerUpdate) bool {
+			return update.CashBalance.IsZero() && update.InvestmentChange.Equal(decimal.NewFromFloat(-2500.00))
+		})).Return(nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			Type:   models.TransactionWithdrawal,
+			Amount: decimal.NewFromFloat(2500.00),
+		})
+
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockTransactionRepo.AssertExpectations(t)
+	})
+
+	t.Run("buying with too little cash is rejected", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(ledger, nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			StockID:  &stockID,
+			Type:     models.TransactionBuy,
+			Quantity: decimal.NewFromInt(10),
+			Price:    decimal.NewFromFloat(100.00),
+		})
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "quantity", validationErr.Field)
+		assert.Contains(t, validationErr.Message, "insufficient cash")
+		mockTransactionRepo.AssertNotCalled(t, "ApplyLedgerUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("splitting a stock that is not held is rejected", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		otherStockID := uuid.New()
+		mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(ledger, nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			StockID:  &otherStockID,
+			Type:     models.TransactionSplit,
+			Quantity: decimal.NewFromInt(100),
+		})
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Message, "no shares are held")
+		mockTransactionRepo.AssertNotCalled(t, "ApplyLedgerUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("backdated withdrawal that overdraws earlier cash is rejected", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		// A later deposit leaves enough cash in the end, but none is held on the withdrawal date
+		laterLedger := append(append([]*models.Transaction{}, ledger...),
+			models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(5000.00), openedAt.Add(12*time.Hour)))
+		withdrawnAt := openedAt.Add(6 * time.Hour)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(laterLedger, nil)
+
+		_, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+			Type:       models.TransactionWithdrawal,
+			Amount:     decimal.NewFromFloat(1000.00),
+			ExecutedAt: &withdrawnAt,
+		})
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "amount", validationErr.Field)
+		assert.Contains(t, validationErr.Message, "insufficient cash")
+		mockTransactionRepo.AssertNotCalled(t, "ApplyLedgerUpdate", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPortfolioService_RecordTransaction_FirstForeignBuy(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+	executedAt := time.Date(2024, 3, 15, 14, 30, 0, 0, time.UTC)
+
+	// The portfolio holds only cash, so the stock's currency is looked up
+	portfolio := &models.Portfolio{ID: portfolioID, BaseCurrency: "USD", TotalInvestment: decimal.NewFromFloat(10000.00)}
+	ledger := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(10000.00), executedAt.AddDate(0, 0, -1)),
+	}
+
+	mockRepo := &MockPortfolioRepository{}
+	mockTransactionRepo := &MockTransactionRepository{}
+	mockStockRepo := &MockAllocationStockRepository{}
+	mockFXService := &MockFXService{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, mockFXService, mockStockRepo)
+
+	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockStockRepo.On("GetByIDs", ctx, []uuid.UUID{stockID}).Return([]*models.Stock{{ID: stockID, Ticker: "VOD.L", Currency: "GBP"}}, nil)
+	mockFXService.On("GetRate", ctx, "GBP", "USD", executedAt).Return(decimal.NewFromFloat(1.27), nil)
+	mockTransactionRepo.On("AppendTransaction", ctx, portfolioID, mock.AnythingOfType("*models.Transaction")).Return(ledger, nil)
+	mockTransactionRepo.On("ApplyLedgerUpdate", ctx, portfolioID, mock.MatchedBy(func(update *models.LedgerUpdate) bool {
+		return len(update.Positions) == 1 && update.Positions[0].EntryFXRate.Equal(decimal.NewFromFloat(1.27))
+	})).Return(nil)
+
+	result, err := service.RecordTransaction(ctx, portfolioID, &models.CreateTransactionRequest{
+		StockID:    &stockID,
+		Type:       models.TransactionBuy,
+		Quantity:   decimal.NewFromInt(10),
+		Price:      decimal.NewFromFloat(101.60),
+		ExecutedAt: &executedAt,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, result.FXRate.Equal(decimal.NewFromFloat(1.27)))
+	mockStockRepo.AssertExpectations(t)
+	mockFXService.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
+}
+
+func TestBuildLedger_TaxLots(t *testing.T) {
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+	now := time.Now()
+	firstBuyAt := now.AddDate(-2, 0, 0)
+	secondBuyAt := now.AddDate(0, -3, 0)
+
+	firstBuy := models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(50.00), firstBuyAt)
+	secondBuy := models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(80.00), secondBuyAt)
+	opening := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(13000.00), firstBuyAt),
+		firstBuy,
+		secondBuy,
+	}
+	sell := func(lots ...models.LotSelection) *models.Transaction {
+		transaction := models.NewTradeTransaction(portfolioID, stockID, models.TransactionSell, decimal.NewFromInt(150), decimal.NewFromFloat(100.00), now)
+		transaction.LotSelections = lots
+		return transaction
+	}
+
+	t.Run("fifo relieves the oldest lots first", func(t *testing.T) {
+		ledger, err := models.BuildLedger(append(opening, sell()), models.LotReliefFIFO)
+		require.NoError(t, err)
+
+		require.Len(t, ledger.Realized, 2)
+		assert.Equal(t, firstBuy.ID, ledger.Realized[0].LotID)
+		assert.True(t, ledger.Realized[0].Gain.Equal(decimal.NewFromFloat(5000.00)))
+		assert.Equal(t, models.HoldingLongTerm, ledger.Realized[0].Term)
+		assert.Equal(t, secondBuy.ID, ledger.Realized[1].LotID)
+		assert.True(t, ledger.Realized[1].Gain.Equal(decimal.NewFromFloat(1000.00)))
+		assert.Equal(t, models.HoldingShortTerm, ledger.Realized[1].Term)
+
+		holding := ledger.Holdings[stockID]
+		require.Len(t, holding.Lots, 1)
+		assert.True(t, holding.Lots[0].Quantity.Equal(decimal.NewFromInt(50)))
+		assert.True(t, holding.CostBasis.Equal(decimal.NewFromFloat(4000.00)))
+	})
+
+	t.Run("lifo relieves the newest lots first", func(t *testing.T) {
+		ledger, err := models.BuildLedger(append(opening, sell()), models.LotReliefLIFO)
+		require.NoError(t, err)
+
+		require.Len(t, ledger.Realized, 2)
+		assert.Equal(t, secondBuy.ID, ledger.Realized[0].LotID)
+		assert.Equal(t, firstBuy.ID, ledger.Realized[1].LotID)
+
+		summary := models.SummarizeGains(ledger.Realized, nil)
+		assert.True(t, summary.RealizedShortTerm.Equal(decimal.NewFromFloat(2000.00)))
+		assert.True(t, summary.RealizedLongTerm.Equal(decimal.NewFromFloat(2500.00)))
+		assert.True(t, summary.RealizedPnL.Equal(decimal.NewFromFloat(4500.00)))
+		assert.True(t, ledger.Holdings[stockID].CostBasis.Equal(decimal.NewFromFloat(2500.00)))
+	})
+
+	t.Run("specific identification relieves the named lots", func(t *testing.T) {
+		ledger, err := models.BuildLedger(append(opening, sell(
+			models.LotSelection{LotID: secondBuy.ID, Quantity: decimal.NewFromInt(30)},
+			models.LotSelection{LotID: firstBuy.ID, Quantity: decimal.NewFromInt(120)},
+		)), models.LotReliefSpecificID)
+
+		require.Error(t, err)
+		assert.Nil(t, ledger)
+
+		ledger, err = models.BuildLedger(append(opening, sell(
+			models.LotSelection{LotID: secondBuy.ID, Quantity: decimal.NewFromInt(70)},
+			models.LotSelection{LotID: firstBuy.ID, Quantity: decimal.NewFromInt(80)},
+		)), models.LotReliefSpecificID)
+		require.NoError(t, err)
+
+		require.Len(t, ledger.Realized, 2)
+		assert.True(t, ledger.Realized[0].Gain.Equal(decimal.NewFromFloat(1400.00)))
+		assert.True(t, ledger.Realized[1].Gain.Equal(decimal.NewFromFloat(4000.00)))
+
+		lots := ledger.Holdings[stockID].Lots
+		require.Len(t, lots, 2)
+		assert.True(t, lots[0].Quantity.Equal(decimal.NewFromInt(20)))
+		assert.True(t, lots[1].Quantity.Equal(decimal.NewFromInt(30)))
+	})
+
+	t.Run("splits scale lots and keep their cost", func(t *testing.T) {
+		split := models.NewTradeTransaction(portfolioID, stockID, models.TransactionSplit, decimal.NewFromInt(200), decimal.Zero, now)
+		ledger, err := models.BuildLedger(append(opening, split), models.LotReliefFIFO)
+		require.NoError(t, err)
+
+		lots := ledger.Holdings[stockID].Lots
+		require.Len(t, lots, 2)
+		assert.True(t, lots[0].Quantity.Equal(decimal.NewFromInt(200)))
+		assert.True(t, lots[0].CostBasis.Equal(decimal.NewFromFloat(5000.00)))
+		assert.True(t, lots[1].Quantity.Equal(decimal.NewFromInt(200)))
+		assert.True(t, lots[1].UnitCost().Equal(decimal.NewFromFloat(40.00)))
+	})
+
+	t.Run("splitting a stock that is not held fails", func(t *testing.T) {
+		split := models.NewTradeTransaction(portfolioID, uuid.New(), models.TransactionSplit, decimal.NewFromInt(100), decimal.Zero, now)
+		_, err := models.BuildLedger(append(opening, split), models.LotReliefFIFO)
+		assert.Error(t, err)
+	})
+
+	t.Run("valued lots split unrealized gains by term", func(t *testing.T) {
+		ledger, err := models.BuildLedger(opening, models.LotReliefFIFO)
+		require.NoError(t, err)
+
+		lots := models.ValueLots(ledger.Holdings[stockID].Lots, decimal.NewFromFloat(90.00), now)
+		require.Len(t, lots, 2)
+		assert.Equal(t, models.HoldingLongTerm, lots[0].Term)
+		assert.True(t, lots[0].UnrealizedPnL.Equal(decimal.NewFromFloat(4000.00)))
+
+		summary := models.SummarizeGains(nil, lots)
+		require.NotNil(t, summary.UnrealizedPnL)
+		assert.True(t, summary.UnrealizedPnL.Equal(decimal.NewFromFloat(5000.00)))
+		assert.True(t, summary.UnrealizedLongTerm.Equal(decimal.NewFromFloat(4000.00)))
+		assert.True(t, summary.UnrealizedShortTerm.Equal(decimal.NewFromFloat(1000.00)))
+		assert.True(t, summary.RealizedPnL.IsZero())
+	})
+}
+
+func TestPortfolioService_GetTaxReport(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockID := uuid.New()
+	otherStockID := uuid.New()
+	at := func(month time.Month, day int) time.Time { return time.Date(2025, month, day, 15, 0, 0, 0, time.UTC) }
+
+	firstBuy := models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(100), decimal.NewFromFloat(50.00), time.Date(2023, time.June, 1, 15, 0, 0, 0, time.UTC))
+	firstBuy.Stock = &models.Stock{ID: stockID, Ticker: "AAPL"}
+	otherBuy := models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionBuy, decimal.NewFromInt(40), decimal.NewFromFloat(100.00), at(time.February, 1))
+	otherBuy.Stock = &models.Stock{ID: otherStockID, Ticker: "MSFT"}
+	transactions := []*models.Transaction{
+		models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(20000.00), firstBuy.ExecutedAt),
+		firstBuy,
+		otherBuy,
+		// A long-term gain
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionSell, decimal.NewFromInt(50), decimal.NewFromFloat(70.00), at(time.March, 3)),
+		// A loss on 40 shares, 20 of them bought back within 30 days
+		models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionSell, decimal.NewFromInt(40), decimal.NewFromFloat(90.00), at(time.December, 20)),
+		models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionBuy, decimal.NewFromInt(20), decimal.NewFromFloat(85.00), time.Date(2026, time.January, 10, 15, 0, 0, 0, time.UTC)),
+		// Sold the next year
+		models.NewTradeTransaction(portfolioID, stockID, models.TransactionSell, decimal.NewFromInt(10), decimal.NewFromFloat(80.00), time.Date(2026, time.January, 5, 15, 0, 0, 0, time.UTC)),
+	}
+
+	mockRepo := &MockPortfolioRepository{}
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+	mockRepo.On("GetByID", ctx, portfolioID).Return(&models.Portfolio{ID: portfolioID, LotReliefMethod: models.LotReliefFIFO}, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, time.Time{}, time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)).Return(transactions, nil)
+
+	report, err := service.GetTaxReport(ctx, portfolioID, 2025)
+
+	require.NoError(t, err)
+	require.Len(t, report.Rows, 2)
+
+	gain := report.Rows[0]
+	assert.Equal(t, "AAPL", gain.Ticker)
+	assert.Equal(t, models.HoldingLongTerm, gain.Term)
+	assert.True(t, gain.Gain.Equal(decimal.NewFromFloat(1000.00)))
+	assert.False(t, gain.WashSale)
+
+	loss := report.Rows[1]
+	assert.Equal(t, "MSFT", loss.Ticker)
+	assert.Equal(t, models.HoldingShortTerm, loss.Term)
+	assert.True(t, loss.Gain.Equal(decimal.NewFromFloat(-400.00)))
+	assert.True(t, loss.WashSale)
+	assert.True(t, loss.DisallowedLoss.Equal(decimal.NewFromFloat(200.00)))
+
+	assert.True(t, report.Totals.Proceeds.Equal(decimal.NewFromFloat(7100.00)))
+	assert.True(t, report.Totals.Gain.Equal(decimal.NewFromFloat(600.00)))
+	assert.True(t, report.Totals.LongTermGain.Equal(decimal.NewFromFloat(1000.00)))
+	assert.True(t, report.Totals.ShortTermGain.Equal(decimal.NewFromFloat(-400.00)))
+	assert.True(t, report.Totals.DisallowedLoss.Equal(decimal.NewFromFloat(200.00)))
+
+	t.Run("a repurchase covers one loss sale only", func(t *testing.T) {
+		// Two loss sales of 30 shares each share a single repurchase of 40 shares
+		buy := models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionBuy, decimal.NewFromInt(60), decimal.NewFromFloat(100.00), at(time.January, 6))
+		buy.Stock = &models.Stock{ID: otherStockID, Ticker: "MSFT"}
+		transactions := []*models.Transaction{
+			models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(10000.00), buy.ExecutedAt),
+			buy,
+			models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionSell, decimal.NewFromInt(30), decimal.NewFromFloat(90.00), at(time.March, 3)),
+			models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionSell, decimal.NewFromInt(30), decimal.NewFromFloat(80.00), at(time.March, 10)),
+			models.NewTradeTransaction(portfolioID, otherStockID, models.TransactionBuy, decimal.NewFromInt(40), decimal.NewFromFloat(85.00), at(time.March, 20)),
+		}
+
+		mockRepo := &MockPortfolioRepository{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		mockRepo.On("GetByID", ctx, portfolioID).Return(&models.Portfolio{ID: portfolioID, LotReliefMethod: models.LotReliefFIFO}, nil)
+		mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, time.Time{}, time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)).Return(transactions, nil)
+
+		report, err := service.GetTaxReport(ctx, portfolioID, 2025)
+
+		require.NoError(t, err)
+		require.Len(t, report.Rows, 2)
+
+		// The first sale is fully replaced, the second only by the 10 shares left over
+		first, second := report.Rows[0], report.Rows[1]
+		assert.True(t, first.WashSale)
+		assert.True(t, first.DisallowedLoss.Equal(decimal.NewFromFloat(300.00)))
+		assert.True(t, second.WashSale)
+		assert.True(t, second.DisallowedLoss.Equal(decimal.NewFromFloat(200.00)))
+		assert.True(t, report.Totals.DisallowedLoss.Equal(decimal.NewFromFloat(500.00)))
+	})
+
+	t.Run("future years are rejected", func(t *testing.T) {
+		_, err := service.GetTaxReport(ctx, portfolioID, time.Now().Year()+1)
+
+		var validationErr *models.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "year", validationErr.Field)
+	})
+
+	mockRepo.AssertExpectations(t)
+	mockTransactionRepo.AssertExpectations(t)
 }
 
 func TestPortfolioService_ValidateAllocationRequest(t *testing.T) {
-	service := NewPortfolioService(nil, nil, nil, nil)
+	service := NewPortfolioService(nil, nil, nil, nil, nil, nil, nil, nil)
 
 	tests := []struct {
 		name    string
@@ -868,7 +2336,7 @@ func TestPortfolioService_ValidateAllocationRequest(t *testing.T) {
 func TestPortfolioService_GetPortfolioHistory(t *testing.T) {
 	// Setup mocks
 	mockRepo := &MockPortfolioRepository{}
-	service := NewPortfolioService(nil, nil, mockRepo, nil)
+	service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -905,7 +2373,7 @@ func TestPortfolioService_GetPortfolioHistory(t *testing.T) {
 func TestPortfolioService_GetUserPortfolios(t *testing.T) {
 	// Setup mocks
 	mockRepo := &MockPortfolioRepository{}
-	service := NewPortfolioService(nil, nil, mockRepo, nil)
+	service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	userID := uuid.New()
@@ -941,7 +2409,8 @@ func TestPortfolioService_MarketDataFailure(t *testing.T) {
 	// Test graceful handling of market data failures
 	mockRepo := &MockPortfolioRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
-	service := NewPortfolioService(nil, nil, mockRepo, mockMarketDataService)
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -956,7 +2425,7 @@ func TestPortfolioService_MarketDataFailure(t *testing.T) {
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				Stock: &models.Stock{
@@ -970,6 +2439,7 @@ func TestPortfolioService_MarketDataFailure(t *testing.T) {
 
 	// Setup expectations - market data service fails
 	mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{}, nil)
 	mockMarketDataService.On("GetMultipleQuotes", ctx, []string{"AAPL"}).Return(nil, assert.AnError)
 
 	// Execute
@@ -990,7 +2460,8 @@ func TestPortfolioService_MarketDataFailure(t *testing.T) {
 func BenchmarkPortfolioService_GetPortfolio(b *testing.B) {
 	mockRepo := &MockPortfolioRepository{}
 	mockMarketDataService := &MockTestMarketDataService{}
-	service := NewPortfolioService(nil, nil, mockRepo, mockMarketDataService)
+	mockTransactionRepo := &MockTransactionRepository{}
+	service := NewPortfolioService(nil, nil, mockRepo, mockTransactionRepo, mockMarketDataService, nil, nil, nil)
 
 	ctx := context.Background()
 	portfolioID := uuid.New()
@@ -1005,7 +2476,7 @@ func BenchmarkPortfolioService_GetPortfolio(b *testing.B) {
 			{
 				PortfolioID:     portfolioID,
 				StockID:         stockID,
-				Quantity:        100,
+				Quantity:        decimal.NewFromInt(100),
 				EntryPrice:      decimal.NewFromFloat(100.00),
 				AllocationValue: decimal.NewFromFloat(10000.00),
 				Stock: &models.Stock{
@@ -1025,10 +2496,381 @@ func BenchmarkPortfolioService_GetPortfolio(b *testing.B) {
 	}
 
 	mockRepo.On("GetByID", mock.Anything, portfolioID).Return(portfolio, nil)
+	mockTransactionRepo.On("GetByPortfolioID", mock.Anything, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.Transaction{}, nil)
 	mockMarketDataService.On("GetMultipleQuotes", mock.Anything, []string{"AAPL"}).Return(quotes, nil)
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		service.GetPortfolio(ctx, portfolioID)
 	}
+}
+func TestNewRebalancePlan_DriftIncludesCash(t *testing.T) {
+	portfolioID := uuid.New()
+	stockA := uuid.New()
+	stockB := uuid.New()
+	valueA := decimal.NewFromFloat(1000.00)
+	valueB := decimal.NewFromFloat(1000.00)
+
+	// Holds its 50/50 target of the 2000 invested and 500 of dividends as cash
+	portfolio := &models.Portfolio{
+		ID:              portfolioID,
+		TotalInvestment: decimal.NewFromFloat(2000.00),
+		CashBalance:     decimal.NewFromFloat(500.00),
+		Positions: []models.Position{
+			{PortfolioID: portfolioID, StockID: stockA, Quantity: decimal.NewFromInt(10), AllocationValue: valueA, CurrentValue: &valueA},
+			{PortfolioID: portfolioID, StockID: stockB, Quantity: decimal.NewFromInt(20), AllocationValue: valueB, CurrentValue: &valueB},
+		},
+	}
+	target := &models.AllocationPreview{
+		TotalInvestment: decimal.NewFromFloat(2000.00),
+		Allocations: []models.StockAllocation{
+			{StockID: stockA, Ticker: "AAA", Weight: decimal.NewFromInt(50), Price: decimal.NewFromFloat(100.00), Quantity: decimal.NewFromInt(10)},
+			{StockID: stockB, Ticker: "BBB", Weight: decimal.NewFromInt(50), Price: decimal.NewFromFloat(50.00), Quantity: decimal.NewFromInt(20)},
+		},
+	}
+
+	plan := models.NewRebalancePlan(portfolio, target, portfolio.TotalInvestment)
+
+	assert.True(t, plan.MaxDrift.IsZero(), "expected no drift, got %s", plan.MaxDrift)
+	require.Len(t, plan.Trades, 2)
+	for _, trade := range plan.Trades {
+		assert.True(t, decimal.NewFromInt(40).Equal(trade.CurrentWeight))
+		assert.True(t, decimal.NewFromInt(40).Equal(trade.TargetWeight))
+		assert.Equal(t, models.RebalanceHold, trade.Action)
+	}
+}
+
+func TestPortfolioService_EvaluateRebalancePolicy(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	stockA := uuid.New()
+	stockB := uuid.New()
+	strategyID := uuid.New()
+
+	// Current weights are 75% / 25% while the target is 50% / 50%
+	newPortfolio := func() *models.Portfolio {
+		return &models.Portfolio{
+			ID:              portfolioID,
+			TotalInvestment: decimal.NewFromFloat(2000.00),
+			Positions: []models.Position{
+				{PortfolioID: portfolioID, StockID: stockA, Quantity: decimal.NewFromInt(10), EntryPrice: decimal.NewFromFloat(150.00), AllocationValue: decimal.NewFromFloat(1500.00)},
+				{PortfolioID: portfolioID, StockID: stockB, Quantity: decimal.NewFromInt(5), EntryPrice: decimal.NewFromFloat(100.00), AllocationValue: decimal.NewFromFloat(500.00)},
+			},
+			AllocationConfig: &models.PortfolioAllocationConfig{
+				PortfolioID: portfolioID,
+				StrategyIDs: []uuid.UUID{strategyID},
+				Constraints: models.AllocationConstraints{
+					MaxAllocationPerStock: decimal.NewFromInt(50),
+					MinAllocationAmount:   decimal.NewFromInt(100),
+				},
+			},
+		}
+	}
+	target := &models.AllocationPreview{
+		TotalInvestment: decimal.NewFromFloat(2000.00),
+		Allocations: []models.StockAllocation{
+			{StockID: stockA, Ticker: "AAA", Weight: decimal.NewFromInt(50), Price: decimal.NewFromFloat(100.00), Quantity: decimal.NewFromInt(10), ActualValue: decimal.NewFromFloat(1000.00)},
+			{StockID: stockB, Ticker: "BBB", Weight: decimal.NewFromInt(50), Price: decimal.NewFromFloat(100.00), Quantity: decimal.NewFromInt(10), ActualValue: decimal.NewFromFloat(1000.00)},
+		},
+	}
+	thresholdPolicy := func(threshold float64) *models.RebalancePolicy {
+		drift := decimal.NewFromFloat(threshold)
+		return &models.RebalancePolicy{
+			PortfolioID:    portfolioID,
+			TriggerType:    models.RebalanceTriggerThreshold,
+			DriftThreshold: &drift,
+			ExecutionMode:  models.RebalanceModeApproval,
+			CreatedAt:      time.Now(),
+		}
+	}
+
+	t.Run("drift above threshold creates pending run", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockAllocationEngine := &MockAllocationEngine{}
+		service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(thresholdPolicy(5), nil)
+		mockRepo.On("GetRebalanceRuns", ctx, portfolioID).Return([]*models.RebalanceRun{}, nil)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil)
+		mockAllocationEngine.On("CalculateAllocations", ctx, mock.AnythingOfType("*models.AllocationRequest")).Return(target, nil)
+		mockRepo.On("CreateRebalanceRun", ctx, mock.MatchedBy(func(run *models.RebalanceRun) bool {
+			return run.Status == models.RebalanceRunPending && run.TriggerType == models.RebalanceTriggerThreshold
+		})).Return(nil)
+		mockRepo.On("SaveRebalancePolicy", ctx, mock.MatchedBy(func(policy *models.RebalancePolicy) bool {
+			return policy.LastTriggeredAt != nil && policy.LastEvaluatedAt != nil
+		})).Return(nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		require.NotNil(t, run)
+		assert.True(t, decimal.NewFromInt(25).Equal(run.MaxDrift))
+		assert.Nil(t, run.ResolvedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("drift within threshold only records evaluation", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockAllocationEngine := &MockAllocationEngine{}
+		service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(thresholdPolicy(30), nil)
+		mockRepo.On("GetRebalanceRuns", ctx, portfolioID).Return([]*models.RebalanceRun{}, nil)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil)
+		mockAllocationEngine.On("CalculateAllocations", ctx, mock.AnythingOfType("*models.AllocationRequest")).Return(target, nil)
+		mockRepo.On("SaveRebalancePolicy", ctx, mock.MatchedBy(func(policy *models.RebalancePolicy) bool {
+			return policy.LastTriggeredAt == nil && policy.LastEvaluatedAt != nil
+		})).Return(nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		assert.Nil(t, run)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateRebalanceRun", mock.Anything, mock.Anything)
+	})
+
+	t.Run("sell signal triggers exit within threshold", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockAllocationEngine := &MockAllocationEngine{}
+		service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		exitTarget := &models.AllocationPreview{
+			TotalInvestment: decimal.NewFromFloat(2000.00),
+			Allocations: []models.StockAllocation{
+				{StockID: stockA, Ticker: "AAA", Weight: decimal.NewFromInt(100), Price: decimal.NewFromFloat(100.00), Quantity: decimal.NewFromInt(20), ActualValue: decimal.NewFromFloat(2000.00)},
+			},
+			ExitStocks: []uuid.UUID{stockB},
+		}
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(thresholdPolicy(30), nil)
+		mockRepo.On("GetRebalanceRuns", ctx, portfolioID).Return([]*models.RebalanceRun{}, nil)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil)
+		mockAllocationEngine.On("CalculateAllocations", ctx, mock.AnythingOfType("*models.AllocationRequest")).Return(exitTarget, nil)
+		mockRepo.On("CreateRebalanceRun", ctx, mock.AnythingOfType("*models.RebalanceRun")).Return(nil)
+		mockRepo.On("SaveRebalancePolicy", ctx, mock.AnythingOfType("*models.RebalancePolicy")).Return(nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		require.NotNil(t, run)
+		assert.Equal(t, []uuid.UUID{stockB}, run.Plan.SignalExits)
+		assert.Equal(t, []uuid.UUID{stockB}, run.Plan.PositionsToClose)
+		for _, trade := range run.Plan.Trades {
+			assert.Equal(t, trade.StockID == stockB, trade.SignalExit)
+		}
+	})
+
+	// A monthly policy that triggered moments ago is not due again until next month
+	calendarPolicy := func() *models.RebalancePolicy {
+		frequency := models.RebalanceMonthly
+		triggeredAt := time.Now()
+		return &models.RebalancePolicy{
+			PortfolioID:     portfolioID,
+			TriggerType:     models.RebalanceTriggerCalendar,
+			Frequency:       &frequency,
+			ExecutionMode:   models.RebalanceModeApproval,
+			CreatedAt:       triggeredAt,
+			LastTriggeredAt: &triggeredAt,
+		}
+	}
+
+	t.Run("calendar policy not due skips plan generation", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockAllocationEngine := &MockAllocationEngine{}
+		service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(calendarPolicy(), nil)
+		mockRepo.On("GetRebalanceRuns", ctx, portfolioID).Return([]*models.RebalanceRun{}, nil)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil).Once()
+		mockAllocationEngine.On("HasExitSignals", ctx, []uuid.UUID{strategyID}, []uuid.UUID{stockA, stockB}).Return(false, nil)
+		mockRepo.On("SaveRebalancePolicy", ctx, mock.MatchedBy(func(policy *models.RebalancePolicy) bool {
+			return policy.LastEvaluatedAt != nil
+		})).Return(nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		assert.Nil(t, run)
+		mockRepo.AssertExpectations(t)
+		mockAllocationEngine.AssertNotCalled(t, "CalculateAllocations", mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "CreateRebalanceRun", mock.Anything, mock.Anything)
+	})
+
+	t.Run("calendar policy not due still exits on sell signal", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockAllocationEngine := &MockAllocationEngine{}
+		service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		exitTarget := &models.AllocationPreview{
+			TotalInvestment: decimal.NewFromFloat(2000.00),
+			Allocations: []models.StockAllocation{
+				{StockID: stockA, Ticker: "AAA", Weight: decimal.NewFromInt(100), Price: decimal.NewFromFloat(100.00), Quantity: decimal.NewFromInt(20), ActualValue: decimal.NewFromFloat(2000.00)},
+			},
+			ExitStocks: []uuid.UUID{stockB},
+		}
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(calendarPolicy(), nil)
+		mockRepo.On("GetRebalanceRuns", ctx, portfolioID).Return([]*models.RebalanceRun{}, nil)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(newPortfolio(), nil)
+		mockAllocationEngine.On("HasExitSignals", ctx, []uuid.UUID{strategyID}, []uuid.UUID{stockA, stockB}).Return(true, nil)
+		mockAllocationEngine.On("CalculateAllocations", ctx, mock.AnythingOfType("*models.AllocationRequest")).Return(exitTarget, nil)
+		mockRepo.On("CreateRebalanceRun", ctx, mock.AnythingOfType("*models.RebalanceRun")).Return(nil)
+		mockRepo.On("SaveRebalancePolicy", ctx, mock.AnythingOfType("*models.RebalancePolicy")).Return(nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		require.NotNil(t, run)
+		assert.Equal(t, models.RebalanceTriggerCalendar, run.TriggerType)
+		assert.Equal(t, []uuid.UUID{stockB}, run.Plan.SignalExits)
+	})
+
+	t.Run("pending run blocks new runs", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(thresholdPolicy(5), nil)
+		mockRepo.On("GetRebalanceRuns", ctx, portfolioID).Return([]*models.RebalanceRun{
+			{ID: uuid.New(), PortfolioID: portfolioID, Status: models.RebalanceRunPending},
+		}, nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		assert.Nil(t, run)
+		mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("no policy", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalancePolicy", ctx, portfolioID).Return(nil, nil)
+
+		run, err := service.EvaluateRebalancePolicy(ctx, portfolioID)
+
+		require.NoError(t, err)
+		assert.Nil(t, run)
+	})
+}
+
+func TestPortfolioService_ResolveRebalanceRun(t *testing.T) {
+	ctx := context.Background()
+	portfolioID := uuid.New()
+	runID := uuid.New()
+
+	t.Run("reject pending run", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalanceRun", ctx, runID).Return(&models.RebalanceRun{
+			ID: runID, PortfolioID: portfolioID, Status: models.RebalanceRunPending,
+		}, nil)
+		mockRepo.On("UpdateRebalanceRun", ctx, mock.MatchedBy(func(run *models.RebalanceRun) bool {
+			return run.Status == models.RebalanceRunRejected && run.ResolvedAt != nil
+		})).Return(nil)
+
+		run, err := service.RejectRebalanceRun(ctx, portfolioID, runID)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.RebalanceRunRejected, run.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("approve regenerates a stale plan", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		mockAllocationEngine := &MockAllocationEngine{}
+		mockTransactionRepo := &MockTransactionRepository{}
+		service := NewPortfolioService(mockAllocationEngine, nil, mockRepo, mockTransactionRepo, nil, nil, nil, nil)
+
+		stockID := uuid.New()
+		openedAt := time.Now().AddDate(0, -1, 0)
+		portfolio := &models.Portfolio{
+			ID:              portfolioID,
+			TotalInvestment: decimal.NewFromFloat(2000.00),
+			CashBalance:     decimal.NewFromFloat(1000.00),
+			Positions: []models.Position{
+				{PortfolioID: portfolioID, StockID: stockID, Quantity: decimal.NewFromInt(10), EntryPrice: decimal.NewFromFloat(100.00), AllocationValue: decimal.NewFromFloat(1000.00)},
+			},
+			AllocationConfig: &models.PortfolioAllocationConfig{
+				PortfolioID: portfolioID,
+				StrategyIDs: []uuid.UUID{uuid.New()},
+				Constraints: models.AllocationConstraints{MaxAllocationPerStock: decimal.NewFromInt(100)},
+			},
+		}
+
+		// Proposed when 5 shares were held at 50, since then 5 more were bought and the price rose to 120
+		stalePlan := &models.RebalancePlan{
+			PortfolioID:        portfolioID,
+			NewTotalInvestment: decimal.NewFromFloat(2000.00),
+			Trades: []models.RebalanceTrade{
+				{StockID: stockID, Action: models.RebalanceBuy, CurrentQuantity: decimal.NewFromInt(5), TargetQuantity: decimal.NewFromInt(15), SharesToBuy: decimal.NewFromInt(10), Price: decimal.NewFromFloat(50.00)},
+			},
+		}
+		target := &models.AllocationPreview{
+			TotalInvestment: decimal.NewFromFloat(2000.00),
+			Allocations: []models.StockAllocation{
+				{StockID: stockID, Ticker: "AAA", Weight: decimal.NewFromInt(100), Price: decimal.NewFromFloat(120.00), Quantity: decimal.NewFromInt(15), ActualValue: decimal.NewFromFloat(1800.00)},
+			},
+		}
+		ledger := []*models.Transaction{
+			models.NewCashTransaction(portfolioID, models.TransactionDeposit, decimal.NewFromFloat(2000.00), openedAt),
+			models.NewTradeTransaction(portfolioID, stockID, models.TransactionBuy, decimal.NewFromInt(10), decimal.NewFromFloat(100.00), openedAt),
+		}
+
+		mockRepo.On("GetRebalanceRun", ctx, runID).Return(&models.RebalanceRun{
+			ID: runID, PortfolioID: portfolioID, Status: models.RebalanceRunPending, Plan: stalePlan,
+		}, nil)
+		mockRepo.On("GetByID", ctx, portfolioID).Return(portfolio, nil)
+		mockAllocationEngine.On("CalculateAllocations", ctx, mock.AnythingOfType("*models.AllocationRequest")).Return(target, nil)
+		mockTransactionRepo.On("GetByPortfolioID", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return(ledger, nil)
+		mockRepo.On("ExecuteRebalance", ctx, mock.AnythingOfType("*models.Portfolio"),
+			mock.MatchedBy(func(transactions []*models.Transaction) bool {
+				return len(transactions) == 1 && transactions[0].Type == models.TransactionBuy &&
+					transactions[0].Quantity.Equal(decimal.NewFromInt(5)) && transactions[0].Price.Equal(decimal.NewFromFloat(120.00))
+			}),
+			mock.AnythingOfType("[]*models.Position"), []uuid.UUID{}).Return(nil)
+		mockRepo.On("GetNAVHistory", ctx, portfolioID, mock.AnythingOfType("time.Time"), mock.AnythingOfType("time.Time")).Return([]*models.NAVHistory{}, nil)
+		mockRepo.On("CreateNAVHistory", ctx, mock.AnythingOfType("*models.NAVHistory")).Return(nil)
+		mockRepo.On("UpdateRebalanceRun", ctx, mock.MatchedBy(func(run *models.RebalanceRun) bool {
+			return run.Status == models.RebalanceRunExecuted && run.Plan != stalePlan &&
+				run.Plan.Trades[0].CurrentQuantity.Equal(decimal.NewFromInt(10))
+		})).Return(nil)
+
+		run, err := service.ApproveRebalanceRun(ctx, portfolioID, runID)
+
+		require.NoError(t, err)
+		assert.Equal(t, models.RebalanceRunExecuted, run.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("approve already resolved run", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalanceRun", ctx, runID).Return(&models.RebalanceRun{
+			ID: runID, PortfolioID: portfolioID, Status: models.RebalanceRunRejected,
+		}, nil)
+
+		run, err := service.ApproveRebalanceRun(ctx, portfolioID, runID)
+
+		assert.Nil(t, run)
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+	})
+
+	t.Run("run of another portfolio", func(t *testing.T) {
+		mockRepo := &MockPortfolioRepository{}
+		service := NewPortfolioService(nil, nil, mockRepo, nil, nil, nil, nil, nil)
+
+		mockRepo.On("GetRebalanceRun", ctx, runID).Return(&models.RebalanceRun{
+			ID: runID, PortfolioID: uuid.New(), Status: models.RebalanceRunPending,
+		}, nil)
+
+		_, err := service.ApproveRebalanceRun(ctx, portfolioID, runID)
+
+		var notFoundErr *models.NotFoundError
+		assert.ErrorAs(t, err, &notFoundErr)
+	})
 }
\ No newline at end of file
@@ -0,0 +1,255 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/repositories"
+)
+
+// SignalEngine defines the interface for managing signal rules and generating signals from them
+type SignalEngine interface {
+	CreateRule(ctx context.Context, strategyID uuid.UUID, req *models.CreateSignalRuleRequest, userID uuid.UUID) (*models.SignalRule, error)
+	UpdateRule(ctx context.Context, strategyID, ruleID uuid.UUID, req *models.UpdateSignalRuleRequest, userID uuid.UUID) (*models.SignalRule, error)
+	GetRules(ctx context.Context, strategyID uuid.UUID, userID uuid.UUID) ([]*models.SignalRule, error)
+	DeleteRule(ctx context.Context, strategyID, ruleID uuid.UUID, userID uuid.UUID) error
+	EvaluateStrategy(ctx context.Context, strategyID uuid.UUID, userID uuid.UUID) (*models.SignalRuleEvaluation, error)
+	EvaluateAll(ctx context.Context) ([]*models.SignalRuleEvaluation, error)
+}
+
+// signalEngine implements the SignalEngine interface
+type signalEngine struct {
+	ruleRepo          repositories.SignalRuleRepository
+	strategyRepo      repositories.StrategyRepository
+	stockService      StockService
+	marketDataService MarketDataService
+	now               func() time.Time
+}
+
+// NewSignalEngine creates a new signal engine instance
+func NewSignalEngine(
+	ruleRepo repositories.SignalRuleRepository,
+	strategyRepo repositories.StrategyRepository,
+	stockService StockService,
+	marketDataService MarketDataService,
+) SignalEngine {
+	return &signalEngine{
+		ruleRepo:          ruleRepo,
+		strategyRepo:      strategyRepo,
+		stockService:      stockService,
+		marketDataService: marketDataService,
+		now:               time.Now,
+	}
+}
+
+// CreateRule adds a signal rule to a strategy with user authorization
+func (e *signalEngine) CreateRule(ctx context.Context, strategyID uuid.UUID, req *models.CreateSignalRuleRequest, userID uuid.UUID) (*models.SignalRule, error) {
+	// Verify strategy belongs to user
+	_, err := e.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	rule := models.NewSignalRule(strategyID, req)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	createdRule, err := e.ruleRepo.Create(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create signal rule: %w", err)
+	}
+
+	return createdRule, nil
+}
+
+// UpdateRule updates a signal rule of a strategy with user authorization
+func (e *signalEngine) UpdateRule(ctx context.Context, strategyID, ruleID uuid.UUID, req *models.UpdateSignalRuleRequest, userID uuid.UUID) (*models.SignalRule, error) {
+	// Verify strategy belongs to user
+	_, err := e.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	rule, err := e.ruleRepo.GetByID(ctx, ruleID, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal rule: %w", err)
+	}
+
+	rule.ApplyUpdate(req)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	updatedRule, err := e.ruleRepo.Update(ctx, rule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update signal rule: %w", err)
+	}
+
+	return updatedRule, nil
+}
+
+// GetRules retrieves the signal rules of a strategy with user authorization
+func (e *signalEngine) GetRules(ctx context.Context, strategyID uuid.UUID, userID uuid.UUID) ([]*models.SignalRule, error) {
+	// Verify strategy belongs to user
+	_, err := e.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	rules, err := e.ruleRepo.GetByStrategyID(ctx, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// DeleteRule removes a signal rule from a strategy with user authorization
+func (e *signalEngine) DeleteRule(ctx context.Context, strategyID, ruleID uuid.UUID, userID uuid.UUID) error {
+	// Verify strategy belongs to user
+	_, err := e.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	if err := e.ruleRepo.Delete(ctx, ruleID, strategyID); err != nil {
+		return fmt.Errorf("failed to delete signal rule: %w", err)
+	}
+
+	return nil
+}
+
+// EvaluateStrategy runs a strategy's enabled rules right away with user authorization
+func (e *signalEngine) EvaluateStrategy(ctx context.Context, strategyID uuid.UUID, userID uuid.UUID) (*models.SignalRuleEvaluation, error) {
+	strategy, err := e.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	return e.evaluateStrategy(ctx, strategy)
+}
+
+// EvaluateAll runs the enabled rules of every strategy that has any. A failing strategy is logged
+// and does not stop the others from being evaluated.
+func (e *signalEngine) EvaluateAll(ctx context.Context) ([]*models.SignalRuleEvaluation, error) {
+	strategyIDs, err := e.ruleRepo.GetStrategyIDsWithEnabledRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategies with signal rules: %w", err)
+	}
+	if len(strategyIDs) == 0 {
+		return nil, nil
+	}
+
+	strategies, err := e.strategyRepo.GetByIDs(ctx, strategyIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategies: %w", err)
+	}
+
+	var evaluations []*models.SignalRuleEvaluation
+	var failed int
+	for _, strategy := range strategies {
+		evaluation, err := e.evaluateStrategy(ctx, strategy)
+		if err != nil {
+			log.Printf("Signal rule evaluation failed for strategy %s: %v", strategy.ID, err)
+			failed++
+			continue
+		}
+		evaluations = append(evaluations, evaluation)
+	}
+
+	if failed > 0 {
+		return evaluations, fmt.Errorf("signal rule evaluation failed for %d of %d strategies", failed, len(strategies))
+	}
+
+	return evaluations, nil
+}
+
+// evaluateStrategy evaluates the enabled rules of a strategy for each of its eligible stocks and
+// records the combined outcome as the stock's signal within the strategy. Stocks whose price
+// history cannot be evaluated keep their current signal.
+func (e *signalEngine) evaluateStrategy(ctx context.Context, strategy *models.Strategy) (*models.SignalRuleEvaluation, error) {
+	rules, err := e.ruleRepo.GetByStrategyID(ctx, strategy.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signal rules: %w", err)
+	}
+
+	var enabled []*models.SignalRule
+	requiredBars := 0
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		enabled = append(enabled, rule)
+		if rule.RequiredBars() > requiredBars {
+			requiredBars = rule.RequiredBars()
+		}
+	}
+
+	evaluation := &models.SignalRuleEvaluation{
+		StrategyID:  strategy.ID,
+		RuleCount:   len(enabled),
+		Results:     []models.StockSignalEvaluation{},
+		EvaluatedAt: e.now(),
+	}
+	if len(enabled) == 0 {
+		return evaluation, nil
+	}
+
+	strategyStocks, err := e.strategyRepo.GetStrategyStocks(ctx, strategy.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy stocks: %w", err)
+	}
+
+	// Daily bars only cover trading days, so look back far enough to span weekends and holidays
+	to := evaluation.EvaluatedAt
+	from := to.AddDate(0, 0, -(requiredBars*7/5 + 10))
+
+	for _, strategyStock := range strategyStocks {
+		if !strategyStock.Eligible || strategyStock.Stock == nil {
+			continue
+		}
+
+		result := models.StockSignalEvaluation{
+			StockID: strategyStock.StockID,
+			Ticker:  strategyStock.Stock.Ticker,
+		}
+
+		signal, err := e.evaluateStock(ctx, enabled, strategyStock.Stock.Ticker, from, to)
+		if err == nil {
+			_, err = e.stockService.UpdateStrategyStockSignal(ctx, strategy.ID, strategyStock.StockID, signal, strategy.UserID)
+		}
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Signal = &signal
+		}
+
+		evaluation.Results = append(evaluation.Results, result)
+	}
+
+	return evaluation, nil
+}
+
+// evaluateStock evaluates rules over a stock's daily bars and combines their outcomes
+func (e *signalEngine) evaluateStock(ctx context.Context, rules []*models.SignalRule, ticker string, from, to time.Time) (models.SignalType, error) {
+	bars, err := e.marketDataService.GetOHLCV(ctx, ticker, from, to, "1day")
+	if err != nil {
+		return "", fmt.Errorf("failed to get price history for %s: %w", ticker, err)
+	}
+
+	signals := make([]models.SignalType, 0, len(rules))
+	for _, rule := range rules {
+		signal, err := evaluateSignalRule(rule, bars)
+		if err != nil {
+			return "", err
+		}
+		signals = append(signals, signal)
+	}
+
+	return combineSignals(signals), nil
+}
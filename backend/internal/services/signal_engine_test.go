@@ -0,0 +1,321 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"portfolio-app/internal/models"
+)
+
+// MockSignalRuleRepository is a mock implementation of SignalRuleRepository
+type MockSignalRuleRepository struct {
+	mock.Mock
+}
+
+func (m *MockSignalRuleRepository) Create(ctx context.Context, rule *models.SignalRule) (*models.SignalRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalRuleRepository) Update(ctx context.Context, rule *models.SignalRule) (*models.SignalRule, error) {
+	args := m.Called(ctx, rule)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalRuleRepository) GetByID(ctx context.Context, id, strategyID uuid.UUID) (*models.SignalRule, error) {
+	args := m.Called(ctx, id, strategyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalRuleRepository) GetByStrategyID(ctx context.Context, strategyID uuid.UUID) ([]*models.SignalRule, error) {
+	args := m.Called(ctx, strategyID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.SignalRule), args.Error(1)
+}
+
+func (m *MockSignalRuleRepository) GetStrategyIDsWithEnabledRules(ctx context.Context) ([]uuid.UUID, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]uuid.UUID), args.Error(1)
+}
+
+func (m *MockSignalRuleRepository) Delete(ctx context.Context, id, strategyID uuid.UUID) error {
+	args := m.Called(ctx, id, strategyID)
+	return args.Error(0)
+}
+
+// dailyBars builds one bar per day ending today from closing prices, with a one point range around each close
+func dailyBars(closes []float64) []*OHLCV {
+	end := time.Now().Truncate(24 * time.Hour)
+	bars := make([]*OHLCV, len(closes))
+	for i, close := range closes {
+		price := decimal.NewFromFloat(close)
+		bars[i] = &OHLCV{
+			Timestamp: end.AddDate(0, 0, i-len(closes)+1),
+			Open:      price,
+			High:      price.Add(decimal.NewFromFloat(0.5)),
+			Low:       price.Sub(decimal.NewFromFloat(0.5)),
+			Close:     price,
+			Volume:    100000,
+		}
+	}
+	return bars
+}
+
+// trend returns n closes moving by step from start
+func trend(start, step float64, n int) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = start + step*float64(i)
+	}
+	return closes
+}
+
+func TestEvaluateSignalRule(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	newRule := func(ruleType models.SignalRuleType, period int, slowPeriod *int) *models.SignalRule {
+		return models.NewSignalRule(uuid.New(), &models.CreateSignalRuleRequest{
+			RuleType:   ruleType,
+			Period:     period,
+			SlowPeriod: slowPeriod,
+		})
+	}
+
+	flatThenJump := append(trend(10, 0, 30), 20)
+	flatThenDrop := append(trend(10, 0, 30), 5)
+
+	tests := []struct {
+		name   string
+		rule   *models.SignalRule
+		closes []float64
+		want   models.SignalType
+	}{
+		{"sma fresh golden cross", newRule(models.SignalRuleSMACross, 5, intPtr(20)), flatThenJump, models.SignalStrongBuy},
+		{"sma fresh death cross", newRule(models.SignalRuleSMACross, 5, intPtr(20)), flatThenDrop, models.SignalStrongSell},
+		{"sma uptrend", newRule(models.SignalRuleSMACross, 5, intPtr(20)), trend(10, 1, 30), models.SignalBuy},
+		{"sma downtrend", newRule(models.SignalRuleSMACross, 5, intPtr(20)), trend(40, -1, 30), models.SignalSell},
+		{"sma flat", newRule(models.SignalRuleSMACross, 5, intPtr(20)), trend(10, 0, 30), models.SignalHold},
+		{"ema uptrend", newRule(models.SignalRuleEMACross, 3, intPtr(10)), trend(10, 1, 40), models.SignalBuy},
+		{"ema downtrend", newRule(models.SignalRuleEMACross, 3, intPtr(10)), trend(50, -1, 40), models.SignalSell},
+		{"rsi overbought", newRule(models.SignalRuleRSI, 14, nil), trend(10, 1, 30), models.SignalSell},
+		{"rsi oversold", newRule(models.SignalRuleRSI, 14, nil), trend(40, -1, 30), models.SignalBuy},
+		{"rsi neutral", newRule(models.SignalRuleRSI, 14, nil), trend(10, 0, 30), models.SignalHold},
+		{"breakout above range", newRule(models.SignalRuleBreakout, 10, nil), flatThenJump, models.SignalBuy},
+		{"breakout below range", newRule(models.SignalRuleBreakout, 10, nil), flatThenDrop, models.SignalSell},
+		{"breakout within range", newRule(models.SignalRuleBreakout, 10, nil), trend(10, 0, 30), models.SignalHold},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal, err := evaluateSignalRule(tt.rule, dailyBars(tt.closes))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, signal)
+		})
+	}
+
+	t.Run("insufficient history", func(t *testing.T) {
+		_, err := evaluateSignalRule(newRule(models.SignalRuleSMACross, 5, intPtr(20)), dailyBars(trend(10, 1, 15)))
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "insufficient price history")
+	})
+}
+
+func TestCombineSignals(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals []models.SignalType
+		want    models.SignalType
+	}{
+		{"no rules", nil, models.SignalHold},
+		{"single rule", []models.SignalType{models.SignalStrongSell}, models.SignalStrongSell},
+		{"agreeing rules", []models.SignalType{models.SignalBuy, models.SignalStrongBuy, models.SignalBuy}, models.SignalBuy},
+		{"conflicting rules cancel out", []models.SignalType{models.SignalBuy, models.SignalSell}, models.SignalHold},
+		{"mostly strong signals", []models.SignalType{models.SignalStrongBuy, models.SignalStrongBuy, models.SignalBuy}, models.SignalStrongBuy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, combineSignals(tt.signals))
+		})
+	}
+}
+
+func TestSignalEngine_CreateRule(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	strategyID := uuid.New()
+	slowPeriod := 10
+
+	setup := func() (SignalEngine, *MockSignalRuleRepository, *MockStrategyRepository) {
+		mockRuleRepo := new(MockSignalRuleRepository)
+		mockStrategyRepo := new(MockStrategyRepository)
+		engine := NewSignalEngine(mockRuleRepo, mockStrategyRepo, nil, NewMockMarketDataService())
+		return engine, mockRuleRepo, mockStrategyRepo
+	}
+
+	t.Run("rsi rule gets default thresholds", func(t *testing.T) {
+		engine, mockRuleRepo, mockStrategyRepo := setup()
+		mockStrategyRepo.On("GetByID", ctx, strategyID, userID).Return(&models.Strategy{ID: strategyID, UserID: userID}, nil)
+		mockRuleRepo.On("Create", ctx, mock.AnythingOfType("*models.SignalRule")).Return(&models.SignalRule{ID: uuid.New()}, nil)
+
+		_, err := engine.CreateRule(ctx, strategyID, &models.CreateSignalRuleRequest{
+			RuleType:   models.SignalRuleRSI,
+			Period:     14,
+			SlowPeriod: &slowPeriod,
+		}, userID)
+
+		require.NoError(t, err)
+		created := mockRuleRepo.Calls[0].Arguments.Get(1).(*models.SignalRule)
+		assert.Equal(t, strategyID, created.StrategyID)
+		assert.True(t, created.Enabled)
+		assert.Nil(t, created.SlowPeriod)
+		assert.True(t, created.LowerThreshold.Equal(models.DefaultRSIOversold))
+		assert.True(t, created.UpperThreshold.Equal(models.DefaultRSIOverbought))
+	})
+
+	t.Run("crossover requires a slower second average", func(t *testing.T) {
+		engine, mockRuleRepo, mockStrategyRepo := setup()
+		mockStrategyRepo.On("GetByID", ctx, strategyID, userID).Return(&models.Strategy{ID: strategyID, UserID: userID}, nil)
+
+		_, err := engine.CreateRule(ctx, strategyID, &models.CreateSignalRuleRequest{
+			RuleType:   models.SignalRuleSMACross,
+			Period:     20,
+			SlowPeriod: &slowPeriod,
+		}, userID)
+
+		var validationErr *models.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "slow_period", validationErr.Field)
+		mockRuleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("strategy of another user", func(t *testing.T) {
+		engine, mockRuleRepo, mockStrategyRepo := setup()
+		mockStrategyRepo.On("GetByID", ctx, strategyID, userID).Return(nil, &models.NotFoundError{Resource: "strategy"})
+
+		_, err := engine.CreateRule(ctx, strategyID, &models.CreateSignalRuleRequest{
+			RuleType: models.SignalRuleBreakout,
+			Period:   20,
+		}, userID)
+
+		var notFound *models.NotFoundError
+		assert.ErrorAs(t, err, &notFound)
+		mockRuleRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSignalEngine_EvaluateStrategy(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	strategyID := uuid.New()
+	slowPeriod := 20
+
+	mockRuleRepo := new(MockSignalRuleRepository)
+	mockStrategyRepo := new(MockStrategyRepository)
+	mockStockRepo := new(MockStockRepository)
+	mockSignalRepo := new(MockSignalRepository)
+	marketData := NewMockMarketDataService()
+	stockService := NewStockService(mockStockRepo, mockSignalRepo, mockStrategyRepo, nil)
+	engine := NewSignalEngine(mockRuleRepo, mockStrategyRepo, stockService, marketData)
+
+	rising := &models.Stock{ID: uuid.New(), Ticker: "RISE"}
+	falling := &models.Stock{ID: uuid.New(), Ticker: "FALL"}
+	listed := &models.Stock{ID: uuid.New(), Ticker: "NEW"}
+	ineligible := &models.Stock{ID: uuid.New(), Ticker: "SKIP"}
+
+	marketData.SetOHLCV("RISE", dailyBars(trend(10, 1, 40)))
+	marketData.SetOHLCV("FALL", dailyBars(trend(60, -1, 40)))
+	marketData.SetOHLCV("NEW", dailyBars(trend(10, 1, 5)))
+
+	strategy := &models.Strategy{ID: strategyID, UserID: userID}
+	mockStrategyRepo.On("GetByID", ctx, strategyID, userID).Return(strategy, nil)
+	mockStrategyRepo.On("GetStrategyStocks", ctx, strategyID).Return([]*models.StrategyStock{
+		{StrategyID: strategyID, StockID: rising.ID, Eligible: true, Stock: rising},
+		{StrategyID: strategyID, StockID: falling.ID, Eligible: true, Stock: falling},
+		{StrategyID: strategyID, StockID: listed.ID, Eligible: true, Stock: listed},
+		{StrategyID: strategyID, StockID: ineligible.ID, Eligible: false, Stock: ineligible},
+	}, nil)
+
+	smaRule := models.NewSignalRule(strategyID, &models.CreateSignalRuleRequest{RuleType: models.SignalRuleSMACross, Period: 5, SlowPeriod: &slowPeriod})
+	breakoutRule := models.NewSignalRule(strategyID, &models.CreateSignalRuleRequest{RuleType: models.SignalRuleBreakout, Period: 10})
+	disabled := false
+	rsiRule := models.NewSignalRule(strategyID, &models.CreateSignalRuleRequest{RuleType: models.SignalRuleRSI, Period: 14, Enabled: &disabled})
+	mockRuleRepo.On("GetByStrategyID", ctx, strategyID).Return([]*models.SignalRule{smaRule, breakoutRule, rsiRule}, nil)
+
+	for _, stock := range []*models.Stock{rising, falling} {
+		mockStockRepo.On("GetByID", ctx, stock.ID).Return(stock, nil)
+	}
+	mockSignalRepo.On("UpdateForStrategy", ctx, strategyID, rising.ID, models.SignalBuy).Return(&models.Signal{StockID: rising.ID, Signal: models.SignalBuy}, nil)
+	mockSignalRepo.On("UpdateForStrategy", ctx, strategyID, falling.ID, models.SignalSell).Return(&models.Signal{StockID: falling.ID, Signal: models.SignalSell}, nil)
+
+	evaluation, err := engine.EvaluateStrategy(ctx, strategyID, userID)
+
+	require.NoError(t, err)
+	assert.Equal(t, strategyID, evaluation.StrategyID)
+	assert.Equal(t, 2, evaluation.RuleCount)
+	require.Len(t, evaluation.Results, 3)
+
+	// Uptrend: fast average above slow and a close above the 10 day range
+	assert.Equal(t, "RISE", evaluation.Results[0].Ticker)
+	require.NotNil(t, evaluation.Results[0].Signal)
+	assert.Equal(t, models.SignalBuy, *evaluation.Results[0].Signal)
+
+	assert.Equal(t, "FALL", evaluation.Results[1].Ticker)
+	require.NotNil(t, evaluation.Results[1].Signal)
+	assert.Equal(t, models.SignalSell, *evaluation.Results[1].Signal)
+
+	// Too little history keeps the current signal
+	assert.Equal(t, "NEW", evaluation.Results[2].Ticker)
+	assert.Nil(t, evaluation.Results[2].Signal)
+	assert.Contains(t, evaluation.Results[2].Error, "insufficient price history")
+
+	mockSignalRepo.AssertNumberOfCalls(t, "UpdateForStrategy", 2)
+	mockSignalRepo.AssertExpectations(t)
+}
+
+func TestSignalEngine_EvaluateAll(t *testing.T) {
+	ctx := context.Background()
+	strategyID := uuid.New()
+	otherStrategyID := uuid.New()
+
+	mockRuleRepo := new(MockSignalRuleRepository)
+	mockStrategyRepo := new(MockStrategyRepository)
+	engine := NewSignalEngine(mockRuleRepo, mockStrategyRepo, nil, NewMockMarketDataService())
+
+	mockRuleRepo.On("GetStrategyIDsWithEnabledRules", ctx).Return([]uuid.UUID{strategyID, otherStrategyID}, nil)
+	mockStrategyRepo.On("GetByIDs", ctx, []uuid.UUID{strategyID, otherStrategyID}).Return([]*models.Strategy{
+		{ID: strategyID, UserID: uuid.New()},
+		{ID: otherStrategyID, UserID: uuid.New()},
+	}, nil)
+	mockRuleRepo.On("GetByStrategyID", ctx, strategyID).Return([]*models.SignalRule{}, nil)
+	mockRuleRepo.On("GetByStrategyID", ctx, otherStrategyID).Return(nil, assert.AnError)
+
+	evaluations, err := engine.EvaluateAll(ctx)
+
+	// A failing strategy is reported without dropping the others
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2 strategies")
+	require.Len(t, evaluations, 1)
+	assert.Equal(t, strategyID, evaluations[0].StrategyID)
+}
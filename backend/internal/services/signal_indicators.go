@@ -0,0 +1,189 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"portfolio-app/internal/models"
+)
+
+// signalScores maps signal levels onto a symmetric scale so several rule outcomes can be averaged
+var signalScores = map[models.SignalType]float64{
+	models.SignalStrongSell: -2,
+	models.SignalSell:       -1,
+	models.SignalHold:       0,
+	models.SignalBuy:        1,
+	models.SignalStrongBuy:  2,
+}
+
+// evaluateSignalRule evaluates a single rule over daily bars ordered from oldest to newest
+func evaluateSignalRule(rule *models.SignalRule, bars []*OHLCV) (models.SignalType, error) {
+	if len(bars) < rule.RequiredBars() {
+		return "", fmt.Errorf("insufficient price history for %s rule: need %d bars, got %d",
+			rule.RuleType, rule.RequiredBars(), len(bars))
+	}
+
+	closes := make([]float64, len(bars))
+	for i, bar := range bars {
+		closes[i] = bar.Close.InexactFloat64()
+	}
+
+	switch rule.RuleType {
+	case models.SignalRuleSMACross:
+		return crossoverSignal(smaSeries(closes, rule.Period), smaSeries(closes, *rule.SlowPeriod)), nil
+	case models.SignalRuleEMACross:
+		return crossoverSignal(emaSeries(closes, rule.Period), emaSeries(closes, *rule.SlowPeriod)), nil
+	case models.SignalRuleRSI:
+		rsi := relativeStrengthIndex(closes, rule.Period)
+		switch {
+		case rsi <= rule.LowerThreshold.InexactFloat64():
+			return models.SignalBuy, nil
+		case rsi >= rule.UpperThreshold.InexactFloat64():
+			return models.SignalSell, nil
+		}
+		return models.SignalHold, nil
+	case models.SignalRuleBreakout:
+		return breakoutSignal(bars, rule.Period), nil
+	}
+
+	return "", fmt.Errorf("unsupported signal rule type %s", rule.RuleType)
+}
+
+// combineSignals averages the outcomes of several rules and rounds to the nearest signal level
+func combineSignals(signals []models.SignalType) models.SignalType {
+	if len(signals) == 0 {
+		return models.SignalHold
+	}
+
+	total := 0.0
+	for _, signal := range signals {
+		total += signalScores[signal]
+	}
+
+	switch score := math.Round(total / float64(len(signals))); {
+	case score >= 2:
+		return models.SignalStrongBuy
+	case score >= 1:
+		return models.SignalBuy
+	case score <= -2:
+		return models.SignalStrongSell
+	case score <= -1:
+		return models.SignalSell
+	}
+	return models.SignalHold
+}
+
+// crossoverSignal compares the latest values of a fast and a slow moving average. A cross on the
+// latest bar is a strong signal; otherwise the side the fast average is on gives a regular one.
+func crossoverSignal(fast, slow []float64) models.SignalType {
+	// Align both series on their newest values
+	fast = fast[len(fast)-len(slow):]
+	last := len(slow) - 1
+
+	above := fast[last] > slow[last]
+	below := fast[last] < slow[last]
+	wasAbove := last > 0 && fast[last-1] > slow[last-1]
+	wasBelow := last > 0 && fast[last-1] < slow[last-1]
+
+	switch {
+	case above && last > 0 && !wasAbove:
+		return models.SignalStrongBuy
+	case below && last > 0 && !wasBelow:
+		return models.SignalStrongSell
+	case above:
+		return models.SignalBuy
+	case below:
+		return models.SignalSell
+	}
+	return models.SignalHold
+}
+
+// breakoutSignal compares the latest close with the high and low of the preceding period bars
+func breakoutSignal(bars []*OHLCV, period int) models.SignalType {
+	last := bars[len(bars)-1]
+	window := bars[len(bars)-1-period : len(bars)-1]
+
+	high, low := window[0].High, window[0].Low
+	for _, bar := range window[1:] {
+		if bar.High.GreaterThan(high) {
+			high = bar.High
+		}
+		if bar.Low.LessThan(low) {
+			low = bar.Low
+		}
+	}
+
+	switch {
+	case last.Close.GreaterThan(high):
+		return models.SignalBuy
+	case last.Close.LessThan(low):
+		return models.SignalSell
+	}
+	return models.SignalHold
+}
+
+// smaSeries returns the simple moving average of values for every complete window
+func smaSeries(values []float64, period int) []float64 {
+	if len(values) < period {
+		return nil
+	}
+
+	series := make([]float64, 0, len(values)-period+1)
+	sum := 0.0
+	for i, value := range values {
+		sum += value
+		if i >= period {
+			sum -= values[i-period]
+		}
+		if i >= period-1 {
+			series = append(series, sum/float64(period))
+		}
+	}
+	return series
+}
+
+// emaSeries returns the exponential moving average of values, seeded with the SMA of the first window
+func emaSeries(values []float64, period int) []float64 {
+	if len(values) < period {
+		return nil
+	}
+
+	multiplier := 2 / float64(period+1)
+	series := make([]float64, 0, len(values)-period+1)
+	series = append(series, smaSeries(values[:period], period)[0])
+	for _, value := range values[period:] {
+		previous := series[len(series)-1]
+		series = append(series, (value-previous)*multiplier+previous)
+	}
+	return series
+}
+
+// relativeStrengthIndex returns the RSI of the latest close using Wilder's smoothing
+func relativeStrengthIndex(closes []float64, period int) float64 {
+	var avgGain, avgLoss float64
+	for i := 1; i <= period; i++ {
+		change := closes[i] - closes[i-1]
+		if change > 0 {
+			avgGain += change
+		} else {
+			avgLoss -= change
+		}
+	}
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+
+	for i := period + 1; i < len(closes); i++ {
+		change := closes[i] - closes[i-1]
+		gain, loss := math.Max(change, 0), math.Max(-change, 0)
+		avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+	}
+
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50
+		}
+		return 100
+	}
+	return 100 - 100/(1+avgGain/avgLoss)
+}
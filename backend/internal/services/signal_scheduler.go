@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// SignalScheduler handles background signal generation from strategy signal rules
+type SignalScheduler struct {
+	signalEngine SignalEngine
+	cron         *cron.Cron
+	ctx          context.Context
+	cancel       context.CancelFunc
+	wg           sync.WaitGroup
+	running      bool
+	mu           sync.RWMutex
+
+	// Configuration
+	cronExpression string
+
+	// Metrics
+	lastRunTime     time.Time
+	successCount    int64
+	errorCount      int64
+	evaluatedStocks int
+}
+
+// SignalSchedulerConfig holds configuration for the signal scheduler
+type SignalSchedulerConfig struct {
+	CronExpression string // Cron expression for scheduling (default: weekdays after the US close)
+}
+
+// DefaultSignalSchedulerConfig returns default configuration
+func DefaultSignalSchedulerConfig() *SignalSchedulerConfig {
+	return &SignalSchedulerConfig{
+		CronExpression: "0 30 21 * * 1-5", // 21:30 on weekdays (with seconds field), once daily bars are final
+	}
+}
+
+// NewSignalScheduler creates a new signal scheduler
+func NewSignalScheduler(signalEngine SignalEngine, config *SignalSchedulerConfig) *SignalScheduler {
+	if config == nil {
+		config = DefaultSignalSchedulerConfig()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	scheduler := &SignalScheduler{
+		signalEngine:   signalEngine,
+		cron:           cron.New(cron.WithSeconds()),
+		ctx:            ctx,
+		cancel:         cancel,
+		cronExpression: config.CronExpression,
+	}
+
+	// Add cron job for signal generation
+	_, err := scheduler.cron.AddFunc(config.CronExpression, scheduler.scheduleSignalRun)
+	if err != nil {
+		log.Printf("Failed to add signal generation cron job: %v", err)
+	}
+
+	return scheduler
+}
+
+// Start begins the signal scheduler
+func (s *SignalScheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return fmt.Errorf("signal scheduler is already running")
+	}
+
+	log.Println("Starting signal scheduler...")
+	s.cron.Start()
+	s.running = true
+
+	log.Printf("Signal scheduler started with schedule %q", s.cronExpression)
+	return nil
+}
+
+// Stop gracefully stops the signal scheduler
+func (s *SignalScheduler) Stop() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.running {
+		return fmt.Errorf("signal scheduler is not running")
+	}
+
+	log.Println("Stopping signal scheduler...")
+
+	// Stop cron scheduler
+	s.cron.Stop()
+
+	// Cancel context to stop background operations
+	s.cancel()
+
+	// Wait for all goroutines to finish
+	s.wg.Wait()
+
+	s.running = false
+	log.Println("Signal scheduler stopped")
+
+	return nil
+}
+
+// IsRunning returns whether the scheduler is currently running
+func (s *SignalScheduler) IsRunning() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.running
+}
+
+// GetMetrics returns scheduler metrics
+func (s *SignalScheduler) GetMetrics() map[string]interface{} {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return map[string]interface{}{
+		"running":          s.running,
+		"last_run_time":    s.lastRunTime,
+		"success_count":    s.successCount,
+		"error_count":      s.errorCount,
+		"evaluated_stocks": s.evaluatedStocks,
+		"schedule":         s.cronExpression,
+	}
+}
+
+// scheduleSignalRun is called by the cron scheduler to evaluate the signal rules of all strategies
+func (s *SignalScheduler) scheduleSignalRun() {
+	s.mu.RLock()
+	if !s.running {
+		s.mu.RUnlock()
+		return
+	}
+	s.mu.RUnlock()
+
+	log.Println("Starting scheduled signal rule evaluation")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		start := time.Now()
+		err := s.runSignalRules()
+		duration := time.Since(start)
+
+		if err != nil {
+			log.Printf("Signal rule evaluation completed with errors in %v: %v", duration, err)
+		} else {
+			log.Printf("Signal rule evaluation completed successfully in %v", duration)
+		}
+	}()
+}
+
+// runSignalRules evaluates all enabled signal rules and records the outcome in the metrics
+func (s *SignalScheduler) runSignalRules() error {
+	evaluations, err := s.signalEngine.EvaluateAll(s.ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastRunTime = time.Now()
+	s.evaluatedStocks = 0
+	for _, evaluation := range evaluations {
+		for _, result := range evaluation.Results {
+			s.evaluatedStocks++
+			if result.Error != "" {
+				s.errorCount++
+			} else {
+				s.successCount++
+			}
+		}
+	}
+
+	return err
+}
+
+// ForceRun triggers an immediate evaluation of all signal rules
+func (s *SignalScheduler) ForceRun() error {
+	s.mu.RLock()
+	if !s.running {
+		s.mu.RUnlock()
+		return fmt.Errorf("scheduler is not running")
+	}
+	s.mu.RUnlock()
+
+	log.Println("Force running signal rules for all strategies...")
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		start := time.Now()
+		err := s.runSignalRules()
+		duration := time.Since(start)
+
+		if err != nil {
+			log.Printf("Forced signal rule evaluation completed with errors in %v: %v", duration, err)
+		} else {
+			log.Printf("Forced signal rule evaluation completed successfully in %v", duration)
+		}
+	}()
+
+	return nil
+}
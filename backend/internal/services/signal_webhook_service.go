@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"portfolio-app/internal/models"
+	"portfolio-app/internal/repositories"
+)
+
+const (
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of "<timestamp>.<body>", prefixed with "sha256="
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// WebhookTimestampHeader carries the Unix time the payload was signed at
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	// webhookTimestampTolerance bounds how old or early a signed payload may be, limiting replays
+	webhookTimestampTolerance = 5 * time.Minute
+)
+
+// Webhook errors
+var (
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrWebhookSecretNotSet     = errors.New("webhook secret not configured")
+)
+
+// SignalWebhookService defines the interface for ingesting signals from external alert sources
+type SignalWebhookService interface {
+	RotateSecret(ctx context.Context, userID uuid.UUID) (string, error)
+	VerifySignature(ctx context.Context, userID uuid.UUID, timestamp, signature string, body []byte) error
+	IngestSignals(ctx context.Context, userID uuid.UUID, req *models.SignalWebhookRequest) (*models.SignalWebhookResult, error)
+}
+
+// signalWebhookService implements the SignalWebhookService interface
+type signalWebhookService struct {
+	userRepo     repositories.UserRepository
+	stockRepo    repositories.StockRepository
+	signalRepo   repositories.SignalRepository
+	strategyRepo repositories.StrategyRepository
+	now          func() time.Time
+}
+
+// NewSignalWebhookService creates a new signal webhook service instance
+func NewSignalWebhookService(
+	userRepo repositories.UserRepository,
+	stockRepo repositories.StockRepository,
+	signalRepo repositories.SignalRepository,
+	strategyRepo repositories.StrategyRepository,
+) SignalWebhookService {
+	return &signalWebhookService{
+		userRepo:     userRepo,
+		stockRepo:    stockRepo,
+		signalRepo:   signalRepo,
+		strategyRepo: strategyRepo,
+		now:          time.Now,
+	}
+}
+
+// SignWebhookPayload returns the signature header value for a payload signed at the given Unix time
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// RotateSecret generates a new webhook signing secret for a user, invalidating the previous one
+func (s *signalWebhookService) RotateSecret(ctx context.Context, userID uuid.UUID) (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	secret := hex.EncodeToString(bytes)
+
+	if err := s.userRepo.SetWebhookSecret(ctx, userID, secret); err != nil {
+		return "", fmt.Errorf("failed to store webhook secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// VerifySignature checks that a payload was signed with the user's webhook secret within the allowed time window
+func (s *signalWebhookService) VerifySignature(ctx context.Context, userID uuid.UUID, timestamp, signature string, body []byte) error {
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidWebhookSignature
+	}
+
+	age := s.now().Sub(time.Unix(signedAt, 0))
+	if age > webhookTimestampTolerance || age < -webhookTimestampTolerance {
+		return ErrInvalidWebhookSignature
+	}
+
+	secret, err := s.userRepo.GetWebhookSecret(ctx, userID)
+	if err != nil {
+		var notFound *models.NotFoundError
+		if errors.As(err, &notFound) {
+			return ErrInvalidWebhookSignature
+		}
+		return fmt.Errorf("failed to get webhook secret: %w", err)
+	}
+	if secret == "" {
+		return ErrWebhookSecretNotSet
+	}
+
+	expected := SignWebhookPayload(secret, signedAt, body)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidWebhookSignature
+	}
+
+	return nil
+}
+
+// IngestSignals records each signal of a webhook batch. Items that cannot be recorded are
+// reported as rejected without failing the rest of the batch.
+func (s *signalWebhookService) IngestSignals(ctx context.Context, userID uuid.UUID, req *models.SignalWebhookRequest) (*models.SignalWebhookResult, error) {
+	result := &models.SignalWebhookResult{
+		Results: make([]models.SignalWebhookItemResult, 0, len(req.Signals)),
+	}
+
+	// Strategies are checked once per batch
+	ownedStrategies := make(map[uuid.UUID]bool)
+
+	for i, item := range req.Signals {
+		itemResult := models.SignalWebhookItemResult{
+			Index:  i,
+			Ticker: item.Ticker,
+		}
+
+		signal, err := s.ingestSignal(ctx, userID, &item, ownedStrategies)
+		if err != nil {
+			itemResult.Status = "rejected"
+			itemResult.Error = err.Error()
+			result.Rejected++
+		} else {
+			itemResult.Status = "accepted"
+			itemResult.Signal = signal.ToResponse()
+			result.Accepted++
+		}
+
+		result.Results = append(result.Results, itemResult)
+	}
+
+	return result, nil
+}
+
+// ingestSignal resolves a webhook item to its stock and strategy and upserts the signal
+func (s *signalWebhookService) ingestSignal(ctx context.Context, userID uuid.UUID, item *models.SignalWebhookItem, ownedStrategies map[uuid.UUID]bool) (*models.Signal, error) {
+	date, err := time.Parse("2006-01-02", item.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", item.Date)
+	}
+
+	ticker := strings.ToUpper(strings.TrimSpace(item.Ticker))
+	stock, err := s.stockRepo.GetByTicker(ctx, ticker)
+	if err != nil {
+		return nil, fmt.Errorf("unknown ticker %s", ticker)
+	}
+
+	if item.Strategy != nil {
+		if _, checked := ownedStrategies[*item.Strategy]; !checked {
+			_, err := s.strategyRepo.GetByID(ctx, *item.Strategy, userID)
+			ownedStrategies[*item.Strategy] = err == nil
+		}
+		if !ownedStrategies[*item.Strategy] {
+			return nil, fmt.Errorf("strategy %s not found", item.Strategy)
+		}
+	}
+
+	signal, err := s.signalRepo.Create(ctx, &models.Signal{
+		StockID:    stock.ID,
+		StrategyID: item.Strategy,
+		Signal:     item.Signal,
+		Date:       date,
+		Strength:   item.Strength,
+		Source:     item.Source,
+		CreatedAt:  s.now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record signal: %w", err)
+	}
+
+	return signal, nil
+}
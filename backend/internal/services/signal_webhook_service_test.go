@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"portfolio-app/internal/models"
+)
+
+func setupSignalWebhookServiceTest(now time.Time) (*signalWebhookService, *MockUserRepository, *MockStockRepository, *MockSignalRepository, *MockStrategyRepository) {
+	mockUserRepo := new(MockUserRepository)
+	mockStockRepo := new(MockStockRepository)
+	mockSignalRepo := new(MockSignalRepository)
+	mockStrategyRepo := new(MockStrategyRepository)
+
+	service := NewSignalWebhookService(mockUserRepo, mockStockRepo, mockSignalRepo, mockStrategyRepo).(*signalWebhookService)
+	service.now = func() time.Time { return now }
+	return service, mockUserRepo, mockStockRepo, mockSignalRepo, mockStrategyRepo
+}
+
+func TestSignalWebhookService_VerifySignature(t *testing.T) {
+	ctx := context.Background()
+	now := time.Unix(1700000000, 0)
+	userID := uuid.New()
+	secret := "test-webhook-secret"
+	body := []byte(`{"signals":[{"ticker":"AAPL","signal":"Buy","date":"2023-11-14","source":"tradingview"}]}`)
+
+	service, mockUserRepo, _, _, _ := setupSignalWebhookServiceTest(now)
+	mockUserRepo.On("GetWebhookSecret", ctx, userID).Return(secret, nil)
+
+	timestamp := now.Unix()
+	signature := SignWebhookPayload(secret, timestamp, body)
+
+	tests := []struct {
+		name      string
+		timestamp string
+		signature string
+		body      []byte
+		wantErr   error
+	}{
+		{"valid signature", strconv.FormatInt(timestamp, 10), signature, body, nil},
+		{"tampered body", strconv.FormatInt(timestamp, 10), signature, []byte(`{"signals":[]}`), ErrInvalidWebhookSignature},
+		{"wrong secret", strconv.FormatInt(timestamp, 10), SignWebhookPayload("other-secret", timestamp, body), body, ErrInvalidWebhookSignature},
+		{"stale timestamp", strconv.FormatInt(timestamp-600, 10), SignWebhookPayload(secret, timestamp-600, body), body, ErrInvalidWebhookSignature},
+		{"missing timestamp", "", signature, body, ErrInvalidWebhookSignature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.VerifySignature(ctx, userID, tt.timestamp, tt.signature, tt.body)
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("secret not configured", func(t *testing.T) {
+		otherUserID := uuid.New()
+		mockUserRepo.On("GetWebhookSecret", ctx, otherUserID).Return("", nil)
+
+		err := service.VerifySignature(ctx, otherUserID, strconv.FormatInt(timestamp, 10), signature, body)
+
+		assert.ErrorIs(t, err, ErrWebhookSecretNotSet)
+	})
+}
+
+func TestSignalWebhookService_IngestSignals(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	userID := uuid.New()
+	stockID := uuid.New()
+	ownStrategyID := uuid.New()
+	foreignStrategyID := uuid.New()
+
+	service, _, mockStockRepo, mockSignalRepo, mockStrategyRepo := setupSignalWebhookServiceTest(now)
+
+	mockStockRepo.On("GetByTicker", ctx, "AAPL").Return(&models.Stock{ID: stockID, Ticker: "AAPL"}, nil)
+	mockStockRepo.On("GetByTicker", ctx, "NOPE").Return(nil, &models.NotFoundError{Resource: "stock"})
+	mockStrategyRepo.On("GetByID", ctx, ownStrategyID, userID).Return(&models.Strategy{ID: ownStrategyID, UserID: userID}, nil).Once()
+	mockStrategyRepo.On("GetByID", ctx, foreignStrategyID, userID).Return(nil, &models.NotFoundError{Resource: "strategy"}).Once()
+	var created []*models.Signal
+	mockSignalRepo.On("Create", ctx, mock.AnythingOfType("*models.Signal")).Run(func(args mock.Arguments) {
+		created = append(created, args.Get(1).(*models.Signal))
+	}).Return(&models.Signal{StockID: stockID}, nil)
+
+	req := &models.SignalWebhookRequest{
+		Signals: []models.SignalWebhookItem{
+			{Ticker: "aapl", Signal: models.SignalBuy, Date: "2024-03-01", Source: "tradingview"},
+			{Ticker: "AAPL", Signal: models.SignalSell, Date: "2024-03-01", Strategy: &ownStrategyID, Source: "screener"},
+			{Ticker: "AAPL", Signal: models.SignalHold, Date: "2024-03-02", Strategy: &ownStrategyID, Source: "screener"},
+			{Ticker: "NOPE", Signal: models.SignalBuy, Date: "2024-03-01", Source: "tradingview"},
+			{Ticker: "AAPL", Signal: models.SignalBuy, Date: "2024-03-01", Strategy: &foreignStrategyID, Source: "screener"},
+		},
+	}
+
+	result, err := service.IngestSignals(ctx, userID, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Accepted)
+	assert.Equal(t, 2, result.Rejected)
+	require.Len(t, result.Results, 5)
+
+	assert.Equal(t, "accepted", result.Results[0].Status)
+	assert.Equal(t, "accepted", result.Results[1].Status)
+	assert.Equal(t, "accepted", result.Results[2].Status)
+
+	require.Len(t, created, 3)
+	assert.Equal(t, stockID, created[0].StockID)
+	assert.Equal(t, "tradingview", created[0].Source)
+	assert.Nil(t, created[0].StrategyID)
+	assert.Equal(t, "2024-03-01", created[0].Date.Format("2006-01-02"))
+	assert.Equal(t, "screener", created[1].Source)
+	assert.Equal(t, &ownStrategyID, created[1].StrategyID)
+	assert.Equal(t, models.SignalSell, created[1].Signal)
+
+	assert.Equal(t, "rejected", result.Results[3].Status)
+	assert.Contains(t, result.Results[3].Error, "unknown ticker NOPE")
+
+	assert.Equal(t, "rejected", result.Results[4].Status)
+	assert.Contains(t, result.Results[4].Error, "not found")
+
+	// The strategy is only looked up once per batch
+	mockStrategyRepo.AssertNumberOfCalls(t, "GetByID", 2)
+}
@@ -23,6 +23,7 @@ type StockService interface {
 	GetStocksWithSignals(ctx context.Context, search string, limit, offset int) ([]*models.Stock, error)
 	DeleteStock(ctx context.Context, id uuid.UUID) error
 	UpdateStockSignal(ctx context.Context, stockID uuid.UUID, signal models.SignalType) (*models.Signal, error)
+	UpdateStrategyStockSignal(ctx context.Context, strategyID, stockID uuid.UUID, signal models.SignalType, userID uuid.UUID) (*models.Signal, error)
 	GetStockSignalHistory(ctx context.Context, stockID uuid.UUID, from, to time.Time) ([]*models.Signal, error)
 	ValidateTickerSymbol(ticker string) error
 	AddStockToStrategy(ctx context.Context, strategyID, stockID uuid.UUID, userID uuid.UUID) error
@@ -206,6 +207,28 @@ func (s *stockService) UpdateStockSignal(ctx context.Context, stockID uuid.UUID,
 	return updatedSignal, nil
 }
 
+// UpdateStrategyStockSignal sets a stock's signal within a single strategy with user authorization
+func (s *stockService) UpdateStrategyStockSignal(ctx context.Context, strategyID, stockID uuid.UUID, signal models.SignalType, userID uuid.UUID) (*models.Signal, error) {
+	// Verify strategy belongs to user
+	_, err := s.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	// Verify stock exists
+	_, err = s.stockRepo.GetByID(ctx, stockID)
+	if err != nil {
+		return nil, fmt.Errorf("stock not found: %w", err)
+	}
+
+	updatedSignal, err := s.signalRepo.UpdateForStrategy(ctx, strategyID, stockID, signal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update strategy stock signal: %w", err)
+	}
+
+	return updatedSignal, nil
+}
+
 // GetStockSignalHistory retrieves signal history for a stock
 func (s *stockService) GetStockSignalHistory(ctx context.Context, stockID uuid.UUID, from, to time.Time) ([]*models.Signal, error) {
 	// Verify stock exists
@@ -99,6 +99,14 @@ func (m *MockSignalRepository) Update(ctx context.Context, stockID uuid.UUID, si
 	return args.Get(0).(*models.Signal), args.Error(1)
 }
 
+func (m *MockSignalRepository) UpdateForStrategy(ctx context.Context, strategyID, stockID uuid.UUID, signal models.SignalType) (*models.Signal, error) {
+	args := m.Called(ctx, strategyID, stockID, signal)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Signal), args.Error(1)
+}
+
 func (m *MockSignalRepository) GetCurrentSignal(ctx context.Context, stockID uuid.UUID) (*models.Signal, error) {
 	args := m.Called(ctx, stockID)
 	if args.Get(0) == nil {
@@ -120,8 +128,8 @@ func (m *MockSignalRepository) Delete(ctx context.Context, stockID uuid.UUID, da
 	return args.Error(0)
 }
 
-func (m *MockSignalRepository) GetLatestSignals(ctx context.Context, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
-	args := m.Called(ctx, stockIDs)
+func (m *MockSignalRepository) GetLatestSignals(ctx context.Context, strategyID *uuid.UUID, stockIDs []uuid.UUID) (map[uuid.UUID]*models.Signal, error) {
+	args := m.Called(ctx, strategyID, stockIDs)
 	return args.Get(0).(map[uuid.UUID]*models.Signal), args.Error(1)
 }
 
@@ -284,6 +292,53 @@ func TestStockService_UpdateStockSignal(t *testing.T) {
 	})
 }
 
+func TestStockService_UpdateStrategyStockSignal(t *testing.T) {
+	mockStockRepo := new(MockStockRepository)
+	mockSignalRepo := new(MockSignalRepository)
+	mockStrategyRepo := new(MockStrategyRepository)
+	
+	service := NewStockService(mockStockRepo, mockSignalRepo, mockStrategyRepo, &sql.DB{})
+
+	t.Run("successful strategy signal update", func(t *testing.T) {
+		strategyID := uuid.New()
+		stockID := uuid.New()
+		userID := uuid.New()
+
+		expectedSignal := &models.Signal{
+			StockID:    stockID,
+			StrategyID: &strategyID,
+			Signal:     models.SignalSell,
+			Date:       time.Now(),
+		}
+
+		mockStrategyRepo.On("GetByID", mock.Anything, strategyID, userID).Return(&models.Strategy{ID: strategyID, UserID: userID}, nil)
+		mockStockRepo.On("GetByID", mock.Anything, stockID).Return(&models.Stock{ID: stockID, Ticker: "AAPL"}, nil)
+		mockSignalRepo.On("UpdateForStrategy", mock.Anything, strategyID, stockID, models.SignalSell).Return(expectedSignal, nil)
+
+		result, err := service.UpdateStrategyStockSignal(context.Background(), strategyID, stockID, models.SignalSell, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.SignalSell, result.Signal)
+		assert.Equal(t, &strategyID, result.StrategyID)
+		mockSignalRepo.AssertExpectations(t)
+	})
+
+	t.Run("strategy not found or access denied", func(t *testing.T) {
+		strategyID := uuid.New()
+		stockID := uuid.New()
+		userID := uuid.New()
+
+		mockStrategyRepo.On("GetByID", mock.Anything, strategyID, userID).Return(nil, &models.NotFoundError{Resource: "strategy"})
+
+		result, err := service.UpdateStrategyStockSignal(context.Background(), strategyID, stockID, models.SignalSell, userID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.Contains(t, err.Error(), "strategy not found or access denied")
+		mockSignalRepo.AssertNotCalled(t, "UpdateForStrategy", mock.Anything, strategyID, stockID, models.SignalSell)
+	})
+}
+
 func TestStockService_AddStockToStrategy(t *testing.T) {
 	mockStockRepo := new(MockStockRepository)
 	mockSignalRepo := new(MockSignalRepository)
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+
+	"portfolio-app/internal/models"
+)
+
+const (
+	// volatilityLookbackDays is the price history used for inverse-volatility weighting
+	volatilityLookbackDays = 90
+
+	// minVolatility keeps stocks whose price did not move from receiving an infinite weight
+	minVolatility = 0.0001
+)
+
+// calculateStockWeights returns the relative weight of each eligible stock of a strategy according
+// to the strategy's weighting scheme. Weights are not normalized; callers divide by their sum.
+func (e *AllocationEngine) calculateStockWeights(ctx context.Context, strategy *models.Strategy, eligible []*models.StrategyStock, stockMap map[uuid.UUID]*models.Stock, signals map[uuid.UUID]*models.Signal) (map[uuid.UUID]decimal.Decimal, error) {
+	weights := make(map[uuid.UUID]decimal.Decimal, len(eligible))
+
+	for _, strategyStock := range eligible {
+		stock := stockMap[strategyStock.StockID]
+
+		switch strategy.StockWeighting {
+		case models.StockWeightingMarketCap:
+			if stock.MarketCap == nil {
+				return nil, ErrMissingWeightingData(strategy.Name, strategy.StockWeighting, stock.Ticker)
+			}
+			weights[stock.ID] = *stock.MarketCap
+
+		case models.StockWeightingInverseVolatility:
+			volatility, err := e.calculateVolatility(ctx, stock.Ticker)
+			if err != nil {
+				return nil, err
+			}
+			weights[stock.ID] = decimal.NewFromFloat(1 / volatility)
+
+		case models.StockWeightingCustom:
+			if strategyStock.CustomWeight == nil {
+				return nil, ErrMissingWeightingData(strategy.Name, strategy.StockWeighting, stock.Ticker)
+			}
+			weights[stock.ID] = *strategyStock.CustomWeight
+
+		case models.StockWeightingSignalStrength:
+			// A Buy signal without an explicit strength counts as full strength
+			strength := decimal.NewFromInt(100)
+			if signal := signals[stock.ID]; signal != nil && signal.Strength != nil {
+				strength = *signal.Strength
+			}
+			weights[stock.ID] = strength
+
+		default:
+			weights[stock.ID] = decimal.NewFromInt(1)
+		}
+	}
+
+	return weights, nil
+}
+
+// calculateVolatility returns the standard deviation of a stock's daily returns over the lookback window
+func (e *AllocationEngine) calculateVolatility(ctx context.Context, ticker string) (float64, error) {
+	to := e.asOf
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := to.AddDate(0, 0, -volatilityLookbackDays)
+
+	history, err := e.marketDataService.GetOHLCV(ctx, ticker, from, to, "1day")
+	if err != nil {
+		return 0, fmt.Errorf("failed to get price history for %s: %w", ticker, err)
+	}
+
+	returns := make([]float64, 0, len(history))
+	for i := 1; i < len(history); i++ {
+		previous := history[i-1].Close.InexactFloat64()
+		if previous <= 0 {
+			continue
+		}
+		returns = append(returns, history[i].Close.InexactFloat64()/previous-1)
+	}
+
+	if len(returns) < 2 {
+		return 0, fmt.Errorf("insufficient price history for %s to calculate volatility", ticker)
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	variance /= float64(len(returns) - 1)
+
+	return math.Max(math.Sqrt(variance), minVolatility), nil
+}
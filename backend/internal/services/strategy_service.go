@@ -19,6 +19,7 @@ type StrategyService interface {
 	GetUserStrategies(ctx context.Context, userID uuid.UUID) ([]*models.Strategy, error)
 	DeleteStrategy(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
 	UpdateStockEligibility(ctx context.Context, strategyID, stockID uuid.UUID, eligible bool, userID uuid.UUID) error
+	UpdateStockWeight(ctx context.Context, strategyID, stockID uuid.UUID, weight *decimal.Decimal, userID uuid.UUID) error
 	ValidateStrategyWeights(ctx context.Context, userID uuid.UUID, excludeStrategyID *uuid.UUID) error
 }
 
@@ -38,6 +39,12 @@ func NewStrategyService(strategyRepo repositories.StrategyRepository, db *sql.DB
 
 // CreateStrategy creates a new strategy with weight validation
 func (s *strategyService) CreateStrategy(ctx context.Context, req *models.CreateStrategyRequest, userID uuid.UUID) (*models.Strategy, error) {
+	if req.StockWeighting != "" {
+		if err := validateStockWeighting(req.StockWeighting); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate percentage mode weight constraints
 	if req.WeightMode == models.WeightModePercent {
 		if err := s.validatePercentageWeights(ctx, userID, nil, req.WeightValue); err != nil {
@@ -64,6 +71,12 @@ func (s *strategyService) UpdateStrategy(ctx context.Context, id uuid.UUID, req
 		return nil, fmt.Errorf("failed to get strategy: %w", err)
 	}
 
+	if req.StockWeighting != nil {
+		if err := validateStockWeighting(*req.StockWeighting); err != nil {
+			return nil, err
+		}
+	}
+
 	// Validate percentage mode weight constraints if weight is being updated
 	if req.WeightMode != nil && *req.WeightMode == models.WeightModePercent && req.WeightValue != nil {
 		if err := s.validatePercentageWeights(ctx, userID, &id, *req.WeightValue); err != nil {
@@ -130,6 +143,28 @@ func (s *strategyService) UpdateStockEligibility(ctx context.Context, strategyID
 	return nil
 }
 
+// UpdateStockWeight sets or clears the custom weight of a stock within a strategy
+func (s *strategyService) UpdateStockWeight(ctx context.Context, strategyID, stockID uuid.UUID, weight *decimal.Decimal, userID uuid.UUID) error {
+	if weight != nil && !weight.IsPositive() {
+		return &models.ValidationError{
+			Field:   "custom_weight",
+			Message: "Custom weight must be greater than 0",
+		}
+	}
+
+	// Verify strategy belongs to user
+	_, err := s.strategyRepo.GetByID(ctx, strategyID, userID)
+	if err != nil {
+		return fmt.Errorf("strategy not found or access denied: %w", err)
+	}
+
+	if err := s.strategyRepo.UpdateStockWeight(ctx, strategyID, stockID, weight); err != nil {
+		return fmt.Errorf("failed to update stock weight: %w", err)
+	}
+
+	return nil
+}
+
 // ValidateStrategyWeights validates that percentage mode strategies don't exceed 100%
 func (s *strategyService) ValidateStrategyWeights(ctx context.Context, userID uuid.UUID, excludeStrategyID *uuid.UUID) error {
 	return s.validatePercentageWeights(ctx, userID, excludeStrategyID, decimal.Zero)
@@ -166,5 +201,17 @@ func (s *strategyService) validatePercentageWeights(ctx context.Context, userID
 		}
 	}
 
+	return nil
+}
+
+// validateStockWeighting validates that the stock weighting is a supported scheme
+func validateStockWeighting(weighting models.StockWeighting) error {
+	if !weighting.IsValid() {
+		return &models.ValidationError{
+			Field:   "stock_weighting",
+			Message: fmt.Sprintf("Unsupported stock weighting '%s'", weighting),
+		}
+	}
+
 	return nil
 }
\ No newline at end of file
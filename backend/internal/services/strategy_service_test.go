@@ -68,6 +68,11 @@ func (m *MockStrategyRepository) UpdateStockEligibility(ctx context.Context, str
 	return args.Error(0)
 }
 
+func (m *MockStrategyRepository) UpdateStockWeight(ctx context.Context, strategyID, stockID uuid.UUID, weight *decimal.Decimal) error {
+	args := m.Called(ctx, strategyID, stockID, weight)
+	return args.Error(0)
+}
+
 func (m *MockStrategyRepository) GetStrategyStocks(ctx context.Context, strategyID uuid.UUID) ([]*models.StrategyStock, error) {
 	args := m.Called(ctx, strategyID)
 	return args.Get(0).([]*models.StrategyStock), args.Error(1)
@@ -588,6 +593,63 @@ func BenchmarkStrategyService_ValidateStrategyWeights(b *testing.B) {
 	}
 }
 
+func TestStrategyService_StockWeighting(t *testing.T) {
+	userID := uuid.New()
+	ctx := context.Background()
+
+	t.Run("create rejects unsupported weighting", func(t *testing.T) {
+		mockRepo := new(MockStrategyRepository)
+		service := NewStrategyService(mockRepo, &sql.DB{})
+
+		req := &models.CreateStrategyRequest{
+			Name:           "Test Strategy",
+			WeightMode:     models.WeightModeBudget,
+			WeightValue:    decimal.NewFromInt(1000),
+			StockWeighting: models.StockWeighting("momentum"),
+		}
+
+		result, err := service.CreateStrategy(ctx, req, userID)
+
+		assert.Error(t, err)
+		assert.Nil(t, result)
+		assert.IsType(t, &models.ValidationError{}, err)
+		mockRepo.AssertNotCalled(t, "Create")
+	})
+
+	t.Run("create defaults to equal weighting", func(t *testing.T) {
+		mockRepo := new(MockStrategyRepository)
+		service := NewStrategyService(mockRepo, &sql.DB{})
+
+		req := &models.CreateStrategyRequest{
+			Name:        "Test Strategy",
+			WeightMode:  models.WeightModeBudget,
+			WeightValue: decimal.NewFromInt(1000),
+		}
+
+		mockRepo.On("Create", ctx, mock.MatchedBy(func(s *models.Strategy) bool {
+			return s.StockWeighting == models.StockWeightingEqual
+		})).Return(&models.Strategy{ID: uuid.New(), StockWeighting: models.StockWeightingEqual}, nil)
+
+		result, err := service.CreateStrategy(ctx, req, userID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.StockWeightingEqual, result.StockWeighting)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("update stock weight rejects non-positive weight", func(t *testing.T) {
+		mockRepo := new(MockStrategyRepository)
+		service := NewStrategyService(mockRepo, &sql.DB{})
+
+		weight := decimal.Zero
+		err := service.UpdateStockWeight(ctx, uuid.New(), uuid.New(), &weight, userID)
+
+		assert.Error(t, err)
+		assert.IsType(t, &models.ValidationError{}, err)
+		mockRepo.AssertNotCalled(t, "UpdateStockWeight")
+	})
+}
+
 // Helper function to create string pointers
 func stringPtr(s string) *string {
 	return &s
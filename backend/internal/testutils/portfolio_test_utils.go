@@ -37,7 +37,7 @@ func CreateTestPortfolio(t *testing.T, db *sqlx.DB, userID uuid.UUID, name strin
 }
 
 // CreateTestPosition creates a test position in the database
-func CreateTestPosition(t *testing.T, db *sqlx.DB, portfolioID, stockID uuid.UUID, quantity int, entryPrice, allocationValue decimal.Decimal) *models.Position {
+func CreateTestPosition(t *testing.T, db *sqlx.DB, portfolioID, stockID uuid.UUID, quantity, entryPrice, allocationValue decimal.Decimal) *models.Position {
 	position := &models.Position{
 		PortfolioID:     portfolioID,
 		StockID:         stockID,
@@ -95,7 +95,7 @@ func CreateTestPortfolioWithPositions(t *testing.T, db *sqlx.DB, userID uuid.UUI
 		CreateTestStock(t, db, stockID, fmt.Sprintf("STOCK%d", i+1), fmt.Sprintf("Test Stock %d", i+1), "Technology")
 		
 		// Create position
-		CreateTestPosition(t, db, portfolio.ID, stockID, 100, decimal.NewFromFloat(100.0), allocationPerStock)
+		CreateTestPosition(t, db, portfolio.ID, stockID, decimal.NewFromInt(100), decimal.NewFromFloat(100.0), allocationPerStock)
 	}
 
 	// Create initial NAV history
@@ -147,6 +147,9 @@ func CleanupPortfolioTestData(t *testing.T, db *sqlx.DB) {
 	_, err := db.Exec("DELETE FROM nav_history")
 	require.NoError(t, err)
 
+	_, err = db.Exec("DELETE FROM transactions")
+	require.NoError(t, err)
+
 	_, err = db.Exec("DELETE FROM positions")
 	require.NoError(t, err)
 
@@ -31,6 +31,9 @@ func CleanupTestDB(t *testing.T, db *sqlx.DB) {
 	_, err := db.Exec("DELETE FROM nav_history")
 	require.NoError(t, err)
 
+	_, err = db.Exec("DELETE FROM transactions")
+	require.NoError(t, err)
+
 	_, err = db.Exec("DELETE FROM positions")
 	require.NoError(t, err)
 
@@ -119,12 +119,19 @@ func main() {
 	stockRepo := repositories.NewStockRepository(db.DB)
 	signalRepo := repositories.NewSignalRepository(db.DB)
 	portfolioRepo := repositories.NewPortfolioRepository(db.DB)
+	transactionRepo := repositories.NewTransactionRepository(db.DB)
 	userRepo := repositories.NewUserRepository(db.DB)
+	signalRuleRepo := repositories.NewSignalRuleRepository(db.DB)
+	backtestRepo := repositories.NewBacktestRepository(db.DB)
+	corporateActionRepo := repositories.NewCorporateActionRepository(db.DB)
+	dividendRepo := repositories.NewDividendRepository(db.DB)
+	fxRateRepo := repositories.NewFXRateRepository(db.DB)
 
 	// Initialize services
 	authService := services.NewAuthService(userRepo, redisClient, cfg.JWT.Secret)
 	strategyService := services.NewStrategyService(strategyRepo, db.DB)
 	stockService := services.NewStockService(stockRepo, signalRepo, strategyRepo, db.DB)
+	signalWebhookService := services.NewSignalWebhookService(userRepo, stockRepo, signalRepo, strategyRepo)
 	
 	// Initialize market data service
 	marketDataServiceFactory := services.NewMarketDataServiceFactory(redisClient)
@@ -134,11 +141,31 @@ func main() {
 	}
 	marketDataService := marketDataServiceFactory.CreateService(marketDataProvider, cfg.Market.APIKey)
 
+	// Initialize FX service, reading rates from the market data provider or mock rates
+	var fxRateSource services.FXRateSource = services.NewMockFXRateSource()
+	if os.Getenv("FX_RATE_PROVIDER") == "market_data" {
+		fxRateSource = services.NewMarketDataFXRateSource(marketDataService)
+	}
+	fxService := services.NewFXService(fxRateRepo, fxRateSource)
+
+	// Initialize signal engine and its scheduler
+	signalEngine := services.NewSignalEngine(signalRuleRepo, strategyRepo, stockService, marketDataService)
+	signalScheduler := services.NewSignalScheduler(signalEngine, nil) // Use default config
+
 	// Initialize allocation engine
-	allocationEngine := services.NewAllocationEngine(strategyRepo, stockRepo, signalRepo, marketDataService)
+	allocationEngine := services.NewAllocationEngine(strategyRepo, stockRepo, signalRepo, marketDataService, fxService)
 	
 	// Initialize portfolio service
-	portfolioService := services.NewPortfolioService(allocationEngine, strategyRepo, portfolioRepo, marketDataService)
+	portfolioService := services.NewPortfolioService(allocationEngine, strategyRepo, portfolioRepo, transactionRepo, marketDataService, corporateActionRepo, fxService, stockRepo)
+
+	// Initialize backtest service
+	backtestService := services.NewBacktestService(backtestRepo, strategyRepo, stockRepo, signalRepo, marketDataService)
+
+	// Initialize corporate action service
+	corporateActionService := services.NewCorporateActionService(corporateActionRepo, stockRepo, portfolioRepo, transactionRepo, marketDataService)
+
+	// Initialize dividend service
+	dividendService := services.NewDividendService(dividendRepo, stockRepo, portfolioRepo, transactionRepo, marketDataService, fxService)
 	
 	// Initialize NAV scheduler
 	navScheduler := services.NewNAVScheduler(portfolioService, portfolioRepo, nil) // Use default config
@@ -150,15 +177,26 @@ func main() {
 		} else {
 			log.Println("NAV scheduler started successfully")
 		}
+
+		if err := signalScheduler.Start(); err != nil {
+			log.Printf("Warning: Failed to start signal scheduler: %v", err)
+		} else {
+			log.Println("Signal scheduler started successfully")
+		}
 	}
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
 	strategyHandler := handlers.NewStrategyHandler(strategyService)
+	signalRuleHandler := handlers.NewSignalRuleHandler(signalEngine)
 	stockHandler := handlers.NewStockHandler(stockService)
-	marketDataHandler := handlers.NewMarketDataHandler(marketDataService)
+	marketDataHandler := handlers.NewMarketDataHandler(marketDataService, stockService)
 	portfolioHandler := handlers.NewPortfolioHandler(portfolioService)
 	navSchedulerHandler := handlers.NewNAVSchedulerHandler(navScheduler)
+	webhookHandler := handlers.NewWebhookHandler(signalWebhookService)
+	backtestHandler := handlers.NewBacktestHandler(backtestService)
+	corporateActionHandler := handlers.NewCorporateActionHandler(corporateActionService)
+	dividendHandler := handlers.NewDividendHandler(dividendService)
 
 	// API routes
 	api := app.Group("/api/v1")
@@ -170,11 +208,15 @@ func main() {
 
 	// Setup routes
 	routes.SetupAuthRoutes(api, authHandler, authService, userRepo)
-	routes.SetupStrategyRoutes(api, strategyHandler, authService, userRepo)
+	routes.SetupStrategyRoutes(api, strategyHandler, signalRuleHandler, authService, userRepo)
 	routes.SetupStockRoutes(api, stockHandler, authService, userRepo)
 	routes.SetupMarketDataRoutes(api, marketDataHandler, authService, userRepo)
 	routes.SetupPortfolioRoutes(api, portfolioHandler, authService, userRepo)
 	routes.SetupNAVSchedulerRoutes(api, navSchedulerHandler, authService, userRepo)
+	routes.SetupWebhookRoutes(api, webhookHandler, authService, userRepo)
+	routes.SetupBacktestRoutes(api, backtestHandler, authService, userRepo)
+	routes.SetupCorporateActionRoutes(api, corporateActionHandler, authService, userRepo)
+	routes.SetupDividendRoutes(api, dividendHandler, authService, userRepo)
 
 	// Start server
 	port := os.Getenv("PORT")
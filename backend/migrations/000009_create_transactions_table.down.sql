@@ -0,0 +1,4 @@
+-- Drop transactions table and related objects
+DROP INDEX IF EXISTS idx_transactions_stock_id;
+DROP INDEX IF EXISTS idx_transactions_portfolio_executed;
+DROP TABLE IF EXISTS transactions;
\ No newline at end of file
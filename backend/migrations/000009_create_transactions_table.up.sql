@@ -0,0 +1,29 @@
+-- Create transactions table as the append-only portfolio ledger
+CREATE TABLE transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    stock_id UUID REFERENCES stocks(id),
+    type VARCHAR(20) NOT NULL CHECK (type IN ('buy', 'sell', 'deposit', 'withdrawal', 'dividend', 'fee', 'split')),
+    quantity INTEGER NOT NULL DEFAULT 0, -- Shares traded; for splits the change in shares held
+    price DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (price >= 0),
+    amount DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (amount >= 0), -- Gross cash amount of the entry
+    notes TEXT,
+    executed_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (type IN ('deposit', 'withdrawal', 'fee', 'dividend') OR stock_id IS NOT NULL)
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_transactions_portfolio_executed ON transactions(portfolio_id, executed_at, created_at);
+CREATE INDEX idx_transactions_stock_id ON transactions(stock_id);
+
+-- Backfill the ledger for existing portfolios: the initial deposit followed by a buy per position
+INSERT INTO transactions (portfolio_id, type, amount, notes, executed_at, created_at)
+SELECT id, 'deposit', total_investment, 'Initial investment', created_at, created_at
+FROM portfolios;
+
+INSERT INTO transactions (portfolio_id, stock_id, type, quantity, price, amount, notes, executed_at, created_at)
+SELECT p.portfolio_id, p.stock_id, 'buy', p.quantity, p.entry_price, ROUND(p.quantity * p.entry_price, 2),
+       'Opening position', pf.created_at, pf.created_at
+FROM positions p
+JOIN portfolios pf ON pf.id = p.portfolio_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+-- Drop cash tracking columns
+ALTER TABLE nav_history DROP COLUMN IF EXISTS cash;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS cash_balance;
\ No newline at end of file
@@ -0,0 +1,15 @@
+-- Track the uninvested cash of each portfolio and record it with every NAV entry
+ALTER TABLE portfolios ADD COLUMN cash_balance DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE nav_history ADD COLUMN cash DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Backfill cash balances by replaying the cash effect of the ledger
+UPDATE portfolios p
+SET cash_balance = COALESCE((
+    SELECT SUM(CASE
+        WHEN t.type IN ('deposit', 'sell', 'dividend') THEN t.amount
+        WHEN t.type IN ('withdrawal', 'buy', 'fee') THEN -t.amount
+        ELSE 0
+    END)
+    FROM transactions t
+    WHERE t.portfolio_id = p.id
+), 0);
\ No newline at end of file
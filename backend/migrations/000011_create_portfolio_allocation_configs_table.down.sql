@@ -0,0 +1,2 @@
+-- Drop portfolio_allocation_configs table
+DROP TABLE IF EXISTS portfolio_allocation_configs;
\ No newline at end of file
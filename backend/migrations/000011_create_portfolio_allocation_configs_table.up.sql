@@ -0,0 +1,10 @@
+-- Create portfolio_allocation_configs table storing the allocation request each portfolio was built with
+CREATE TABLE portfolio_allocation_configs (
+    portfolio_id UUID PRIMARY KEY REFERENCES portfolios(id) ON DELETE CASCADE,
+    strategy_ids JSONB NOT NULL, -- Array of strategy IDs
+    max_allocation_per_stock DECIMAL(5,2) NOT NULL CHECK (max_allocation_per_stock > 0 AND max_allocation_per_stock <= 100),
+    min_allocation_amount DECIMAL(15,2) NOT NULL DEFAULT 0 CHECK (min_allocation_amount >= 0),
+    excluded_stocks JSONB NOT NULL DEFAULT '[]', -- Array of excluded stock IDs
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
\ No newline at end of file
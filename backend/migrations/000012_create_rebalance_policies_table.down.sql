@@ -0,0 +1,5 @@
+-- Drop rebalance_runs and rebalance_policies tables and related objects
+DROP INDEX IF EXISTS idx_rebalance_runs_portfolio_status;
+DROP INDEX IF EXISTS idx_rebalance_runs_portfolio_created;
+DROP TABLE IF EXISTS rebalance_runs;
+DROP TABLE IF EXISTS rebalance_policies;
\ No newline at end of file
@@ -0,0 +1,29 @@
+-- Create rebalance_policies table describing when each portfolio should be rebalanced
+CREATE TABLE rebalance_policies (
+    portfolio_id UUID PRIMARY KEY REFERENCES portfolios(id) ON DELETE CASCADE,
+    trigger_type VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (trigger_type IN ('none', 'calendar', 'threshold')),
+    frequency VARCHAR(20) CHECK (frequency IN ('monthly', 'quarterly')),
+    drift_threshold DECIMAL(5,2) CHECK (drift_threshold > 0 AND drift_threshold <= 100),
+    execution_mode VARCHAR(20) NOT NULL DEFAULT 'approval' CHECK (execution_mode IN ('approval', 'auto')),
+    last_evaluated_at TIMESTAMP,
+    last_triggered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Create rebalance_runs table recording every triggered rebalance and its outcome
+CREATE TABLE rebalance_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    trigger_type VARCHAR(20) NOT NULL CHECK (trigger_type IN ('calendar', 'threshold')),
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'executed', 'rejected', 'failed')),
+    max_drift DECIMAL(7,2) NOT NULL DEFAULT 0,
+    plan JSONB NOT NULL,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    resolved_at TIMESTAMP
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_rebalance_runs_portfolio_created ON rebalance_runs(portfolio_id, created_at DESC);
+CREATE INDEX idx_rebalance_runs_portfolio_status ON rebalance_runs(portfolio_id, status);
\ No newline at end of file
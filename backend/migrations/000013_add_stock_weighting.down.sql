@@ -0,0 +1,5 @@
+-- Drop stock weighting columns
+ALTER TABLE signals DROP COLUMN IF EXISTS strength;
+ALTER TABLE stocks DROP COLUMN IF EXISTS market_cap;
+ALTER TABLE strategy_stocks DROP COLUMN IF EXISTS custom_weight;
+ALTER TABLE strategies DROP COLUMN IF EXISTS stock_weighting;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- Choose how each strategy splits its allocation across its stocks
+ALTER TABLE strategies ADD COLUMN stock_weighting VARCHAR(20) NOT NULL DEFAULT 'equal'
+    CHECK (stock_weighting IN ('equal', 'market_cap', 'inverse_volatility', 'custom', 'signal_strength'));
+
+-- Inputs used by the non-equal weighting schemes
+ALTER TABLE strategy_stocks ADD COLUMN custom_weight DECIMAL(10,4) CHECK (custom_weight > 0);
+ALTER TABLE stocks ADD COLUMN market_cap DECIMAL(20,2) CHECK (market_cap > 0);
+ALTER TABLE signals ADD COLUMN strength DECIMAL(5,2) CHECK (strength >= 0 AND strength <= 100);
\ No newline at end of file
@@ -0,0 +1,6 @@
+-- Revert share quantities to whole shares; fractional holdings are truncated
+ALTER TABLE transactions ALTER COLUMN quantity TYPE INTEGER USING TRUNC(quantity);
+ALTER TABLE positions ALTER COLUMN quantity TYPE INTEGER USING TRUNC(quantity);
+
+ALTER TABLE portfolios DROP COLUMN IF EXISTS share_precision;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS fractional_shares;
\ No newline at end of file
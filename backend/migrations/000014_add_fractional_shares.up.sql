@@ -0,0 +1,7 @@
+-- Let portfolios hold fractional shares, rounded down to share_precision decimal places
+ALTER TABLE portfolios ADD COLUMN fractional_shares BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE portfolios ADD COLUMN share_precision SMALLINT NOT NULL DEFAULT 0 CHECK (share_precision >= 0 AND share_precision <= 8);
+
+-- Store share quantities as decimals
+ALTER TABLE positions ALTER COLUMN quantity TYPE DECIMAL(20,8);
+ALTER TABLE transactions ALTER COLUMN quantity TYPE DECIMAL(20,8);
\ No newline at end of file
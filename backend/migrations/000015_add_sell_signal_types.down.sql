@@ -0,0 +1,6 @@
+-- Fold the extra signal levels back into Buy and Hold
+UPDATE signals SET signal = 'Buy' WHERE signal = 'StrongBuy';
+UPDATE signals SET signal = 'Hold' WHERE signal IN ('Sell', 'StrongSell');
+
+ALTER TABLE signals DROP CONSTRAINT IF EXISTS signals_signal_check;
+ALTER TABLE signals ADD CONSTRAINT signals_signal_check CHECK (signal IN ('Buy', 'Hold'));
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Allow Sell and the strong signal levels
+ALTER TABLE signals DROP CONSTRAINT IF EXISTS signals_signal_check;
+ALTER TABLE signals ADD CONSTRAINT signals_signal_check
+    CHECK (signal IN ('StrongBuy', 'Buy', 'Hold', 'Sell', 'StrongSell'));
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- Drop strategy-scoped signals and restore the per-stock primary key
+DELETE FROM signals WHERE strategy_id IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_signals_strategy_stock_date;
+ALTER TABLE signals DROP CONSTRAINT IF EXISTS signals_stock_strategy_date_key;
+ALTER TABLE signals ADD PRIMARY KEY (stock_id, date);
+
+ALTER TABLE signals DROP COLUMN IF EXISTS strategy_id;
\ No newline at end of file
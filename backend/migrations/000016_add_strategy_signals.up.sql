@@ -0,0 +1,7 @@
+-- Let signals be scoped to a strategy; signals without a strategy apply to every strategy
+ALTER TABLE signals ADD COLUMN strategy_id UUID REFERENCES strategies(id) ON DELETE CASCADE;
+
+ALTER TABLE signals DROP CONSTRAINT signals_pkey;
+ALTER TABLE signals ADD CONSTRAINT signals_stock_strategy_date_key UNIQUE NULLS NOT DISTINCT (stock_id, strategy_id, date);
+
+CREATE INDEX idx_signals_strategy_stock_date ON signals(strategy_id, stock_id, date DESC) WHERE strategy_id IS NOT NULL;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- Keep only manual signals and drop the webhook columns
+DELETE FROM signals WHERE source <> 'manual';
+
+ALTER TABLE signals DROP CONSTRAINT IF EXISTS signals_stock_strategy_date_source_key;
+ALTER TABLE signals ADD CONSTRAINT signals_stock_strategy_date_key UNIQUE NULLS NOT DISTINCT (stock_id, strategy_id, date);
+ALTER TABLE signals DROP COLUMN IF EXISTS source;
+
+ALTER TABLE users DROP COLUMN IF EXISTS webhook_secret;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- Per-user secret used to sign signal webhook payloads
+ALTER TABLE users ADD COLUMN webhook_secret VARCHAR(64);
+
+-- Record where each signal came from; webhook deliveries are idempotent per source
+ALTER TABLE signals ADD COLUMN source VARCHAR(50) NOT NULL DEFAULT 'manual';
+
+ALTER TABLE signals DROP CONSTRAINT signals_stock_strategy_date_key;
+ALTER TABLE signals ADD CONSTRAINT signals_stock_strategy_date_source_key UNIQUE NULLS NOT DISTINCT (stock_id, strategy_id, date, source);
\ No newline at end of file
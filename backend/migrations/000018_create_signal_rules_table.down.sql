@@ -0,0 +1,4 @@
+-- Drop signal_rules table and related objects
+DROP INDEX IF EXISTS idx_signal_rules_enabled;
+DROP INDEX IF EXISTS idx_signal_rules_strategy;
+DROP TABLE IF EXISTS signal_rules;
\ No newline at end of file
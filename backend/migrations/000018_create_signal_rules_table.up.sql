@@ -0,0 +1,19 @@
+-- Create signal_rules table holding the technical rules that generate each strategy's signals
+CREATE TABLE signal_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    strategy_id UUID NOT NULL REFERENCES strategies(id) ON DELETE CASCADE,
+    rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('sma_cross', 'ema_cross', 'rsi', 'breakout')),
+    period INTEGER NOT NULL CHECK (period > 0),
+    slow_period INTEGER CHECK (slow_period > 0),
+    lower_threshold DECIMAL(5,2) CHECK (lower_threshold >= 0 AND lower_threshold <= 100),
+    upper_threshold DECIMAL(5,2) CHECK (upper_threshold >= 0 AND upper_threshold <= 100),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (rule_type NOT IN ('sma_cross', 'ema_cross') OR slow_period > period),
+    CHECK (lower_threshold IS NULL OR upper_threshold IS NULL OR lower_threshold < upper_threshold)
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_signal_rules_strategy ON signal_rules(strategy_id);
+CREATE INDEX idx_signal_rules_enabled ON signal_rules(strategy_id) WHERE enabled;
\ No newline at end of file
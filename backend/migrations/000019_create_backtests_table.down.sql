@@ -0,0 +1,3 @@
+-- Drop backtests table and related objects
+DROP INDEX IF EXISTS idx_backtests_user_created;
+DROP TABLE IF EXISTS backtests;
\ No newline at end of file
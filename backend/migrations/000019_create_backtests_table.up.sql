@@ -0,0 +1,15 @@
+-- Create backtests table recording simulated strategy runs and their results
+CREATE TABLE backtests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    request JSONB NOT NULL,
+    result JSONB,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_backtests_user_created ON backtests(user_id, created_at DESC);
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE portfolios DROP COLUMN IF EXISTS benchmark_symbol;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- Let portfolios be compared against a benchmark such as SPY or QQQ
+ALTER TABLE portfolios ADD COLUMN benchmark_symbol VARCHAR(20);
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Drop position_snapshots table and related objects
+DROP INDEX IF EXISTS idx_position_snapshots_timestamp;
+DROP INDEX IF EXISTS idx_position_snapshots_portfolio_stock;
+DROP TABLE IF EXISTS position_snapshots;
\ No newline at end of file
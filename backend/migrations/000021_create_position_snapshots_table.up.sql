@@ -0,0 +1,17 @@
+-- Create position snapshots table recording each holding at every NAV update
+CREATE TABLE position_snapshots (
+    portfolio_id UUID NOT NULL,
+    timestamp TIMESTAMP NOT NULL,
+    stock_id UUID NOT NULL REFERENCES stocks(id),
+    quantity DECIMAL(20,8) NOT NULL,
+    price DECIMAL(10,4) NOT NULL,
+    value DECIMAL(15,2) NOT NULL,
+    weight DECIMAL(8,4) NOT NULL, -- Percentage of NAV
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (portfolio_id, timestamp, stock_id),
+    FOREIGN KEY (portfolio_id, timestamp) REFERENCES nav_history(portfolio_id, timestamp) ON DELETE CASCADE
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_position_snapshots_portfolio_stock ON position_snapshots(portfolio_id, stock_id, timestamp DESC);
+CREATE INDEX idx_position_snapshots_timestamp ON position_snapshots(timestamp);
\ No newline at end of file
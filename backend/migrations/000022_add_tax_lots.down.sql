@@ -0,0 +1,7 @@
+-- Remove tax lot tracking
+ALTER TABLE nav_history DROP COLUMN IF EXISTS unrealized_pnl;
+ALTER TABLE nav_history DROP COLUMN IF EXISTS realized_pnl;
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS lot_selections;
+
+ALTER TABLE portfolios DROP COLUMN IF EXISTS lot_relief_method;
\ No newline at end of file
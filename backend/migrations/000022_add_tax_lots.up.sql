@@ -0,0 +1,9 @@
+-- Track cost basis by tax lot, relieved by a configurable method per portfolio
+ALTER TABLE portfolios ADD COLUMN lot_relief_method VARCHAR(20) NOT NULL DEFAULT 'fifo' CHECK (lot_relief_method IN ('fifo', 'lifo', 'specific_id'));
+
+-- Record the lots each sell relieved, as [{"lot_id": ..., "quantity": ...}]
+ALTER TABLE transactions ADD COLUMN lot_selections JSONB;
+
+-- Split NAV P&L into realized and unrealized gains
+ALTER TABLE nav_history ADD COLUMN realized_pnl DECIMAL(15,2) NOT NULL DEFAULT 0;
+ALTER TABLE nav_history ADD COLUMN unrealized_pnl DECIMAL(15,2) NOT NULL DEFAULT 0;
\ No newline at end of file
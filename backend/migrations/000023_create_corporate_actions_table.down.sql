@@ -0,0 +1,5 @@
+-- Drop corporate action tables and related objects
+DROP INDEX IF EXISTS idx_corporate_action_adjustments_portfolio;
+DROP INDEX IF EXISTS idx_corporate_actions_stock_date;
+DROP TABLE IF EXISTS corporate_action_adjustments;
+DROP TABLE IF EXISTS corporate_actions;
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- Create corporate actions table recording stock splits and reverse splits
+CREATE TABLE corporate_actions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stock_id UUID NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL DEFAULT 'split' CHECK (type IN ('split')),
+    new_shares DECIMAL(12,4) NOT NULL CHECK (new_shares > 0), -- Shares held after the split for every old_shares held before
+    old_shares DECIMAL(12,4) NOT NULL CHECK (old_shares > 0),
+    effective_date DATE NOT NULL,
+    source VARCHAR(20) NOT NULL DEFAULT 'manual' CHECK (source IN ('manual', 'provider')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied')),
+    applied_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (stock_id, effective_date)
+);
+
+-- Create corporate action adjustments table auditing the change applied to each portfolio
+CREATE TABLE corporate_action_adjustments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    corporate_action_id UUID NOT NULL REFERENCES corporate_actions(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    stock_id UUID NOT NULL REFERENCES stocks(id),
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    quantity_before DECIMAL(20,8) NOT NULL,
+    quantity_after DECIMAL(20,8) NOT NULL,
+    entry_price_before DECIMAL(10,4) NOT NULL,
+    entry_price_after DECIMAL(10,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (corporate_action_id, portfolio_id)
+);
+
+-- Create indexes for performance
+CREATE INDEX idx_corporate_actions_stock_date ON corporate_actions(stock_id, effective_date);
+CREATE INDEX idx_corporate_action_adjustments_portfolio ON corporate_action_adjustments(portfolio_id, created_at DESC);
\ No newline at end of file
@@ -0,0 +1,10 @@
+-- Drop dividend tracking
+ALTER TABLE nav_history DROP COLUMN IF EXISTS dividend_income;
+
+ALTER TABLE portfolios DROP COLUMN IF EXISTS drip_enabled;
+
+DROP INDEX IF EXISTS idx_dividend_payments_portfolio;
+DROP INDEX IF EXISTS idx_dividend_events_pay_date;
+DROP INDEX IF EXISTS idx_dividend_events_stock_ex_date;
+DROP TABLE IF EXISTS dividend_payments;
+DROP TABLE IF EXISTS dividend_events;
\ No newline at end of file
@@ -0,0 +1,39 @@
+-- Create dividend events table recording per-share cash dividends
+CREATE TABLE dividend_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    stock_id UUID NOT NULL REFERENCES stocks(id) ON DELETE CASCADE,
+    ex_date DATE NOT NULL, -- Shares held before the ex-date are entitled to the dividend
+    pay_date DATE NOT NULL,
+    amount_per_share DECIMAL(12,6) NOT NULL CHECK (amount_per_share > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid')),
+    paid_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (stock_id, ex_date),
+    CHECK (pay_date >= ex_date)
+);
+
+-- Create dividend payments table recording the dividend credited to each portfolio
+CREATE TABLE dividend_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dividend_event_id UUID NOT NULL REFERENCES dividend_events(id) ON DELETE CASCADE,
+    portfolio_id UUID NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    stock_id UUID NOT NULL REFERENCES stocks(id),
+    shares DECIMAL(20,8) NOT NULL, -- Shares entitled to the dividend
+    amount DECIMAL(15,2) NOT NULL,
+    transaction_id UUID NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    reinvestment_transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    reinvested_shares DECIMAL(20,8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (dividend_event_id, portfolio_id)
+);
+
+-- Reinvest dividends in the paying stock when enabled
+ALTER TABLE portfolios ADD COLUMN drip_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Track dividend income received to date with each NAV update
+ALTER TABLE nav_history ADD COLUMN dividend_income DECIMAL(15,2) NOT NULL DEFAULT 0;
+
+-- Create indexes for performance
+CREATE INDEX idx_dividend_events_stock_ex_date ON dividend_events(stock_id, ex_date);
+CREATE INDEX idx_dividend_events_pay_date ON dividend_events(pay_date) WHERE status = 'pending';
+CREATE INDEX idx_dividend_payments_portfolio ON dividend_payments(portfolio_id, created_at DESC);
\ No newline at end of file
@@ -0,0 +1,9 @@
+-- Drop multi-currency support
+ALTER TABLE nav_history DROP COLUMN IF EXISTS fx_pnl;
+ALTER TABLE positions DROP COLUMN IF EXISTS entry_fx_rate;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fx_rate;
+
+DROP TABLE IF EXISTS fx_rates;
+
+ALTER TABLE portfolios DROP COLUMN IF EXISTS base_currency;
+ALTER TABLE stocks DROP COLUMN IF EXISTS currency;
\ No newline at end of file
@@ -0,0 +1,24 @@
+-- Currency each stock is quoted in and each portfolio is valued in
+ALTER TABLE stocks ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'USD';
+ALTER TABLE portfolios ADD COLUMN base_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Create FX rates table storing daily historical exchange rates: one unit of base_currency is worth rate units of quote_currency
+CREATE TABLE fx_rates (
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate DECIMAL(18,8) NOT NULL CHECK (rate > 0),
+    source VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (base_currency, quote_currency, rate_date),
+    CHECK (base_currency <> quote_currency)
+);
+
+-- Rate converting a trade's stock currency to the portfolio base currency when it executed
+ALTER TABLE transactions ADD COLUMN fx_rate DECIMAL(18,8) NOT NULL DEFAULT 1;
+
+-- Cost-weighted rate the shares of a position were bought at
+ALTER TABLE positions ADD COLUMN entry_fx_rate DECIMAL(18,8) NOT NULL DEFAULT 1;
+
+-- Track the part of unrealized P&L caused by exchange rate moves with each NAV update
+ALTER TABLE nav_history ADD COLUMN fx_pnl DECIMAL(15,2) NOT NULL DEFAULT 0;
\ No newline at end of file
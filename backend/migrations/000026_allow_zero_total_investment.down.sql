@@ -0,0 +1,6 @@
+-- Require a positive total investment again, keeping a nominal cent in portfolios emptied of capital
+UPDATE portfolios SET total_investment = 0.01 WHERE total_investment <= 0;
+
+ALTER TABLE portfolios DROP CONSTRAINT IF EXISTS portfolios_total_investment_check;
+ALTER TABLE portfolios ADD CONSTRAINT portfolios_total_investment_check
+    CHECK (total_investment > 0);
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- Allow a portfolio's total investment to reach zero when all of its capital is withdrawn
+ALTER TABLE portfolios DROP CONSTRAINT IF EXISTS portfolios_total_investment_check;
+ALTER TABLE portfolios ADD CONSTRAINT portfolios_total_investment_check
+    CHECK (total_investment >= 0);
\ No newline at end of file
@@ -39,8 +39,41 @@ export interface PerformanceMetrics {
   current_drawdown?: number;
   volatility_pct?: number;
   sharpe_ratio?: number;
+  sortino_ratio?: number;
+  calmar_ratio?: number;
+  max_drawdown_days?: number;
+  risk_free_rate_pct?: number;
   days_active: number;
   high_water_mark: number;
+  returns?: PeriodReturns;
+  benchmark?: BenchmarkMetrics;
+}
+
+// Portfolio performance relative to its benchmark
+export interface BenchmarkMetrics {
+  symbol: string;
+  benchmark_return_pct?: number;
+  alpha_pct?: number;
+  beta?: number;
+  tracking_error_pct?: number;
+  information_ratio?: number;
+  up_capture_pct?: number;
+  down_capture_pct?: number;
+  observation_count: number;
+}
+
+export type ReturnPeriod = 'mtd' | 'qtd' | 'ytd' | '1y' | 'inception';
+
+// Cash flow adjusted returns over a period
+export interface PeriodReturns {
+  period: ReturnPeriod;
+  from: string;
+  to: string;
+  start_value: number;
+  end_value: number;
+  net_cash_flow: number;
+  time_weighted_return_pct?: number;
+  money_weighted_return_pct?: number;
 }
 
 // Chart data interfaces for visualization
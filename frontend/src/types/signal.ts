@@ -2,12 +2,14 @@ import { z } from 'zod';
 import type { Stock } from './stock';
 
 // Signal types
-export type SignalType = 'Buy' | 'Hold';
+export type SignalType = 'StrongBuy' | 'Buy' | 'Hold' | 'Sell' | 'StrongSell';
 
 export interface Signal {
   stock_id: string;
+  strategy_id?: string;
   signal: SignalType;
   date: string;
+  source: string;
   created_at: string;
   stock?: Stock;
 }
@@ -24,15 +26,17 @@ export interface UpdateSignalRequest {
 
 export interface SignalResponse {
   stock_id: string;
+  strategy_id?: string;
   signal: SignalType;
   date: string;
+  source: string;
   created_at: string;
   stock?: Stock;
 }
 
 // Zod validation schemas
-export const signalTypeSchema = z.enum(['Buy', 'Hold'], {
-  errorMap: () => ({ message: 'Signal must be either Buy or Hold' }),
+export const signalTypeSchema = z.enum(['StrongBuy', 'Buy', 'Hold', 'Sell', 'StrongSell'], {
+  errorMap: () => ({ message: 'Signal must be one of StrongBuy, Buy, Hold, Sell or StrongSell' }),
 });
 
 export const createSignalSchema = z.object({